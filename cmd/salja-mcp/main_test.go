@@ -74,8 +74,8 @@ func TestMCPIntegration(t *testing.T) {
 		if err := json.Unmarshal([]byte(text), &statuses); err != nil {
 			t.Fatalf("invalid JSON: %v", err)
 		}
-		if len(statuses) != 5 {
-			t.Errorf("expected 5 services, got %d", len(statuses))
+		if len(statuses) != 6 {
+			t.Errorf("expected 6 services, got %d", len(statuses))
 		}
 	})
 
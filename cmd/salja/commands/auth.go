@@ -53,7 +53,7 @@ func newAuthLoginCmd() *cobra.Command {
 					AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
 					TokenURL:    "https://oauth2.googleapis.com/token",
 					RedirectURI: cfg.API.Google.RedirectURI,
-					Scopes:      []string{"https://www.googleapis.com/auth/calendar"},
+					Scopes:      []string{"https://www.googleapis.com/auth/calendar", "https://www.googleapis.com/auth/tasks"},
 				}
 			case "microsoft":
 				if cfg.API.Microsoft.ClientID == "" {
@@ -64,7 +64,7 @@ func newAuthLoginCmd() *cobra.Command {
 					AuthURL:     "https://login.microsoftonline.com/common/oauth2/v2.0/authorize",
 					TokenURL:    "https://login.microsoftonline.com/common/oauth2/v2.0/token",
 					RedirectURI: cfg.API.Microsoft.RedirectURI,
-					Scopes:      []string{"Calendars.ReadWrite", "offline_access"},
+					Scopes:      []string{"Calendars.ReadWrite", "Tasks.ReadWrite", "offline_access"},
 				}
 			case "todoist":
 				if cfg.API.Todoist.ClientID == "" {
@@ -106,8 +106,26 @@ func newAuthLoginCmd() *cobra.Command {
 				}
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "✓ Stored Notion integration token\n")
 				return nil
+			case "caldav":
+				if cfg.API.CalDAV.URL == "" || cfg.API.CalDAV.Username == "" {
+					return fmt.Errorf("configure api.caldav.url and api.caldav.username in %s first", config.ConfigPath())
+				}
+				var input string
+				fmt.Fprintf(os.Stderr, "Enter the CalDAV password or app password for %s: ", cfg.API.CalDAV.Username)
+				if _, err := fmt.Fscanln(os.Stdin, &input); err != nil {
+					return fmt.Errorf("reading CalDAV password from stdin: %w", err)
+				}
+				if input == "" {
+					return fmt.Errorf("password cannot be empty")
+				}
+				token := &api.Token{AccessToken: input, TokenType: "Basic", ExpiresAt: time.Now().AddDate(10, 0, 0)}
+				if err := store.Set("caldav", token); err != nil {
+					return fmt.Errorf("failed to save password: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "✓ Stored CalDAV password for %s\n", cfg.API.CalDAV.Username)
+				return nil
 			default:
-				return fmt.Errorf("unsupported service %q; supported: google, microsoft, todoist, ticktick, notion", service)
+				return fmt.Errorf("unsupported service %q; supported: google, microsoft, todoist, ticktick, notion, caldav", service)
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -162,7 +180,7 @@ func newAuthStatusCmd() *cobra.Command {
 				return fmt.Errorf("failed to load tokens: %w", err)
 			}
 
-			services := []string{"google", "microsoft", "todoist", "ticktick", "notion"}
+			services := []string{"google", "microsoft", "todoist", "ticktick", "notion", "caldav"}
 			for _, s := range services {
 				tok, ok := tokens[s]
 				if !ok || tok == nil {
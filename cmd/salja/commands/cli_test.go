@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/gongahkia/salja/cmd/salja/commands"
 )
 
 func buildBinary(t *testing.T) string {
@@ -86,6 +88,39 @@ func TestFormatAutoDetection(t *testing.T) {
 	}
 }
 
+func TestDetectFormat(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tasks.txt":   "(A) Call Mom +Family\nx 2026-03-04 File taxes\n",
+		"notes.txt":   "Remember the milk.\nAnd the bread.\n",
+		"backup.json": `{"format":"salja-json","version":1,"items":[]}`,
+		"tasks.json":  `[{"uuid":"5f1c","description":"Pay rent","status":"pending"}]`,
+		"board.json":  `{"id":"b1","name":"Board","idOrganization":"o1","cards":[]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, want := range map[string]string{
+		"todo.txt":                        "todotxt",
+		"archive/done.txt":                "todotxt",
+		filepath.Join(dir, "tasks.txt"):   "todotxt",
+		filepath.Join(dir, "notes.txt"):   "unknown",
+		"ticktick-backup.csv":             "ticktick",
+		"export.csv":                      "csv",
+		"calendar.ics":                    "ics",
+		filepath.Join(dir, "backup.json"): "salja-json",
+		filepath.Join(dir, "tasks.json"):  "taskwarrior",
+		filepath.Join(dir, "board.json"):  "trello",
+		"new.json":                        "trello",
+	} {
+		if got := commands.DetectFormat(path); got != want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
 func TestDryRunOutput(t *testing.T) {
 	bin := buildBinary(t)
 	dir := t.TempDir()
@@ -109,6 +144,52 @@ func TestDryRunOutput(t *testing.T) {
 	}
 }
 
+func TestConvertExpandRecurrence(t *testing.T) {
+	bin := buildBinary(t)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "weekly.ics")
+	content := `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//Test//EN
+BEGIN:VEVENT
+UID:weekly@test
+DTSTART:20240101T100000Z
+DTEND:20240101T110000Z
+DTSTAMP:20240101T100000Z
+SUMMARY:Weekly Sync
+RRULE:FREQ=WEEKLY;COUNT=4
+EXDATE:20240108T100000Z
+END:VEVENT
+END:VCALENDAR
+`
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "gcal.csv")
+
+	cmd := exec.Command(bin, "convert", "--quiet", "--expand-recurrence", "--until", "2024-01-15", "--to", "gcal", input, output)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("convert failed: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 01/01 and 01/15; 01/08 is excluded and 01/22 is past --until
+	if got := strings.Count(string(data), "Weekly Sync"); got != 2 {
+		t.Errorf("occurrences = %d, want 2:\n%s", got, data)
+	}
+	if !strings.Contains(string(data), "01/15/2024") || strings.Contains(string(data), "01/08/2024") {
+		t.Errorf("unexpected occurrences:\n%s", data)
+	}
+
+	cmd = exec.Command(bin, "convert", "--quiet", "--expand-recurrence", "--until", "15/01/2024", "--to", "gcal", input, output)
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "--until") {
+		t.Errorf("expected --until error, got %v: %s", err, out)
+	}
+}
+
 func TestPipedIO(t *testing.T) {
 	bin := buildBinary(t)
 	dir := t.TempDir()
@@ -149,6 +230,13 @@ func TestListFormats(t *testing.T) {
 	if !strings.Contains(outStr, "ics") || !strings.Contains(outStr, "ticktick") {
 		t.Error("list-formats should show supported formats")
 	}
+	lines := strings.Split(strings.TrimSpace(outStr), "\n")
+	col := strings.Index(lines[0], "|")
+	for _, line := range lines[1:] {
+		if strings.Index(line, "|") != col {
+			t.Errorf("row %q is not aligned with the header %q", line, lines[0])
+		}
+	}
 }
 
 func TestValidateCommand(t *testing.T) {
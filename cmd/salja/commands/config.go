@@ -42,6 +42,7 @@ conflict_strategy = "ask"
 data_loss_mode = "warn"
 streaming_threshold_mb = 10
 api_timeout_seconds = 30
+sync_timeout_minutes = 0 # 0 scales the sync push deadline with the number of items
 
 [conflict_thresholds]
 levenshtein_threshold = 3
@@ -52,6 +53,19 @@ date_proximity_hours = 24
 
 [tag_map]
 
+# Calendars, projects and databases to sync with, by name or ID. Push routes
+# items by tag, then by item type (event, task, journal), then to default.
+# [sync_targets.google]
+# default = "Work"
+# tags = { family = "Family" }
+# types = { task = "Tasks" }
+
+# Per-service request quotas for sync; omitted values keep the defaults.
+# [rate_limits.google]
+# requests_per_second = 10
+# burst = 10
+# concurrency = 4
+
 [api.ticktick]
 client_id = ""
 client_secret = ""
@@ -70,6 +84,12 @@ client_secret = ""
 
 [api.notion]
 token = ""
+
+[api.caldav]
+url = ""
+username = ""
+password = ""
+calendar = ""
 `
 			if err := os.WriteFile(configPath, []byte(defaultConfig), 0644); err != nil {
 				return err
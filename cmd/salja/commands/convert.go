@@ -9,11 +9,14 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gongahkia/salja/internal/config"
 	"github.com/gongahkia/salja/internal/conflict"
+	"github.com/gongahkia/salja/internal/csvmap"
 	salerr "github.com/gongahkia/salja/internal/errors"
 	"github.com/gongahkia/salja/internal/fidelity"
+	"github.com/gongahkia/salja/internal/ics"
 	"github.com/gongahkia/salja/internal/logging"
 	"github.com/gongahkia/salja/internal/model"
 	"github.com/gongahkia/salja/internal/parsers"
@@ -24,8 +27,8 @@ import (
 
 func NewConvertCmd() *cobra.Command {
 	var fromFormat, toFormat string
-	var dryRun, quiet, strict, jsonOutput, merge bool
-	var outputFormat, fidelityMode, locale string
+	var dryRun, quiet, strict, jsonOutput, merge, expand bool
+	var outputFormat, fidelityMode, locale, sheet, mapping, until string
 	var appleCalendar, appleList string
 
 	cmd := &cobra.Command{
@@ -64,6 +67,16 @@ func NewConvertCmd() *cobra.Command {
 			if locale != "" {
 				parsers.SetLocale(locale)
 			}
+			if sheet != "" {
+				parsers.SetXLSXSheet(sheet)
+			}
+			if mapping != "" || fromFormat == "csv" || toFormat == "csv" {
+				profile, err := csvMapping(mapping, cfg)
+				if err != nil {
+					return err
+				}
+				csvmap.Use(profile)
+			}
 
 			// Validate apple-specific flags
 			if (fromFormat == "apple-calendar" || toFormat == "apple-calendar") && appleCalendar == "" {
@@ -77,6 +90,7 @@ func NewConvertCmd() *cobra.Command {
 			defer cancel()
 			handler := salerr.NewSignalHandler(cancel)
 			handler.Start()
+			defer handler.Stop()
 
 			// File size pre-check: warn if file exceeds streaming threshold
 			if inputFile != "-" {
@@ -157,6 +171,18 @@ func NewConvertCmd() *cobra.Command {
 				}
 			}
 
+			if expand && hasCaps && !caps.SupportsRecurrence {
+				end := time.Now().AddDate(1, 0, 0)
+				if until != "" {
+					day, err := time.Parse("2006-01-02", until)
+					if err != nil {
+						return fmt.Errorf("invalid --until date %q (expected YYYY-MM-DD): %w", until, err)
+					}
+					end = day.Add(24*time.Hour - time.Second)
+				}
+				collection.Items = expandRecurrence(collection.Items, end)
+			}
+
 			// Determine data loss mode: --fidelity flag > --strict flag > config > default
 			dataLossMode := "warn"
 			if cfg != nil {
@@ -329,6 +355,10 @@ func NewConvertCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output structured JSON conversion report")
 	cmd.Flags().BoolVar(&merge, "merge", false, "Detect duplicates and resolve conflicts when output file exists")
 	cmd.Flags().StringVar(&locale, "locale", "", "Locale for ambiguous date parsing (e.g. en-gb, de, ja)")
+	cmd.Flags().StringVar(&sheet, "sheet", "", "Sheet to read from an xlsx workbook (default: every sheet with a title column)")
+	cmd.Flags().StringVar(&mapping, "mapping", "", "Column mapping for the csv format: a profile name (default, jira, linear or one under [csv_mappings]) or a .toml file")
+	cmd.Flags().BoolVar(&expand, "expand-recurrence", false, "Write each occurrence of a recurring item as an item of its own when the target format has no recurrence rules")
+	cmd.Flags().StringVar(&until, "until", "", "Last date (YYYY-MM-DD) to expand occurrences through with --expand-recurrence (default: a year from today)")
 	cmd.Flags().StringVar(&appleCalendar, "calendar", "", "Apple Calendar name (required for apple-calendar format)")
 	cmd.Flags().StringVar(&appleList, "list", "", "Apple Reminders list name (required for apple-reminders format)")
 
@@ -347,6 +377,21 @@ type convertReport struct {
 	Errors      []string `json:"errors,omitempty"`
 }
 
+// maxExpandedOccurrences bounds how many items --expand-recurrence makes of
+// one recurring item.
+const maxExpandedOccurrences = 1000
+
+// expandRecurrence replaces each recurring item with its occurrences through
+// end, overridden ones as changed and excluded ones left out.
+func expandRecurrence(items []model.CalendarItem, end time.Time) []model.CalendarItem {
+	flattener := ics.NewFlattener()
+	expanded := make([]model.CalendarItem, 0, len(items))
+	for i := range items {
+		expanded = append(expanded, flattener.FlattenRecurrence(&items[i], maxExpandedOccurrences, end)...)
+	}
+	return expanded
+}
+
 func DetectFormat(filePath string) string {
 	if filePath == "-" {
 		return "ics"
@@ -359,6 +404,13 @@ func DetectFormat(filePath string) string {
 		return hint
 	}
 
+	// Formats sharing an extension such as .json are told apart by content
+	if name := sniffFormat(filePath, func(head []byte) string {
+		return registry.DetectByContentFor(ext, head)
+	}); name != "" {
+		return name
+	}
+
 	// Fall back to extension matching
 	matches := registry.DetectByExtension(ext)
 	if len(matches) == 1 {
@@ -368,9 +420,49 @@ func DetectFormat(filePath string) string {
 		// Multiple formats share this extension (e.g. .csv); can't disambiguate
 		return strings.TrimPrefix(ext, ".")
 	}
+	if name := sniffFormat(filePath, registry.DetectByContent); name != "" {
+		return name
+	}
 	return "unknown"
 }
 
+// sniffFormat reads the start of filePath for formats, such as todo.txt,
+// that no extension identifies and passes it to detect.
+func sniffFormat(filePath string, detect func(head []byte) string) string {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(f, head)
+	return detect(head[:n])
+}
+
+// csvMapping resolves --mapping to a column mapping profile: a TOML file
+// when it names one, else a profile from [csv_mappings] or a built-in one.
+// Without the flag it is the configured or built-in default profile.
+func csvMapping(name string, cfg *config.Config) (*csvmap.Profile, error) {
+	if name == "" {
+		name = "default"
+	}
+	if strings.HasSuffix(strings.ToLower(name), ".toml") {
+		return csvmap.Load(name)
+	}
+	if p, ok := cfg.CSVMappings[name]; ok {
+		return &p, nil
+	}
+	if p, ok := csvmap.Builtin(name); ok {
+		return p, nil
+	}
+	names := csvmap.Names()
+	for configured := range cfg.CSVMappings {
+		names = append(names, configured)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown csv mapping %q; use a .toml file or one of: %s", name, strings.Join(names, ", "))
+}
+
 func ReadInput(ctx context.Context, filePath, format string, cfg *config.Config) (*model.CalendarCollection, error) {
 	var r io.Reader
 	if filePath == "-" {
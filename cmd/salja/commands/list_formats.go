@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/gongahkia/salja/internal/registry"
 	"github.com/spf13/cobra"
@@ -13,20 +14,22 @@ func NewListFormatsCmd() *cobra.Command {
 		Use:   "list-formats",
 		Short: "List all supported formats with capabilities",
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("Format       | Events | Tasks | Recurring | Subtasks")
-			fmt.Println("-------------|--------|-------|-----------|--------")
-
 			allFormats := registry.AvailableFormats()
 			names := make([]string, 0, len(allFormats))
+			// the name column fits the longest name and a space
+			width := len("Format") + 1
 			for name := range allFormats {
 				names = append(names, name)
+				width = max(width, len(name)+1)
 			}
 			sort.Strings(names)
 
+			fmt.Printf("%-*s| Events | Tasks | Recurring | Subtasks\n", width, "Format")
+			fmt.Println(strings.Repeat("-", width) + "|--------|-------|-----------|--------")
 			for _, name := range names {
 				caps := allFormats[name].Capabilities
-				fmt.Printf("%-13s|  %s   |  %s  |    %s    |  %s\n",
-					name,
+				fmt.Printf("%-*s|  %s   |  %s  |    %s    |  %s\n",
+					width, name,
 					checkmark(caps.SupportsEvents),
 					checkmark(caps.SupportsTasks),
 					checkmark(caps.SupportsRecurrence),
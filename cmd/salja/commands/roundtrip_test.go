@@ -3,6 +3,7 @@ package commands_test
 import (
 	"bytes"
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -105,6 +106,11 @@ func TestRoundTripICSToOutlookCSVToICS(t *testing.T) {
 				Location:    "Conference Room B",
 				ItemType:    model.ItemTypeEvent,
 				IsAllDay:    false,
+				Organizer:   &model.Attendee{Email: "ana@example.com", Name: "Ana Ruiz"},
+				Attendees: []model.Attendee{
+					{Email: "sam@example.com", Name: "Sam Lee", Role: model.RoleRequired},
+					{Email: "kim@example.com", Role: model.RoleOptional},
+				},
 			},
 		},
 		SourceApp: "ics",
@@ -154,6 +160,12 @@ func TestRoundTripICSToOutlookCSVToICS(t *testing.T) {
 	if got.ItemType != model.ItemTypeEvent {
 		t.Errorf("item type: got %q, want %q", got.ItemType, model.ItemTypeEvent)
 	}
+	if got.Organizer == nil || *got.Organizer != *orig.Organizer {
+		t.Errorf("organizer: got %+v, want %+v", got.Organizer, orig.Organizer)
+	}
+	if !reflect.DeepEqual(got.Attendees, orig.Attendees) {
+		t.Errorf("attendees: got %+v, want %+v", got.Attendees, orig.Attendees)
+	}
 }
 
 // TestRoundTripICSToTickTickCSVToICS verifies ICS→TickTick CSV→ICS preserves
@@ -0,0 +1,18 @@
+package commands
+
+import (
+	"github.com/gongahkia/salja/internal/native"
+	"github.com/spf13/cobra"
+)
+
+func NewSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the salja-json format",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := cmd.OutOrStdout().Write(native.Schema())
+			return err
+		},
+	}
+}
@@ -2,24 +2,38 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gongahkia/salja/internal/api"
 	"github.com/gongahkia/salja/internal/config"
+	salerr "github.com/gongahkia/salja/internal/errors"
 	"github.com/gongahkia/salja/internal/logging"
 	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/syncer"
 	"github.com/spf13/cobra"
 )
 
 var supportedSyncServices = map[string]bool{
-	"google": true, "microsoft": true, "todoist": true, "ticktick": true, "notion": true,
+	"google": true, "microsoft": true, "microsoft-todo": true, "todoist": true, "ticktick": true, "notion": true, "caldav": true,
+}
+
+// credentialService names the login a sync service uses. Microsoft To Do is
+// reached with the Microsoft account's token.
+func credentialService(service string) string {
+	if service == "microsoft-todo" {
+		return "microsoft"
+	}
+	return service
 }
 
 func validateSyncService(name, flag string) error {
 	if !supportedSyncServices[name] {
-		return fmt.Errorf("unsupported sync service for --%s: %q; supported: google, microsoft, todoist, ticktick, notion", flag, name)
+		return fmt.Errorf("unsupported sync service for --%s: %q; supported: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav", flag, name)
 	}
 	return nil
 }
@@ -32,6 +46,10 @@ func NewSyncCmd() *cobra.Command {
 
 	cmd.AddCommand(newSyncPushCmd())
 	cmd.AddCommand(newSyncPullCmd())
+	cmd.AddCommand(newSyncRunCmd())
+	cmd.AddCommand(newSyncUndoCmd())
+	cmd.AddCommand(newSyncHistoryCmd())
+	cmd.AddCommand(newSyncTargetsCmd())
 	return cmd
 }
 
@@ -74,19 +92,86 @@ func ensureTokenValid(ctx context.Context, store api.TokenStorer, service string
 	return newToken, nil
 }
 
+// loadServiceToken returns a valid token for service from the secure store,
+// refreshing it if needed. CalDAV uses basic auth and returns nil.
+func loadServiceToken(ctx context.Context, service string, cfg *config.Config) (*api.Token, error) {
+	if service == "caldav" {
+		return nil, nil
+	}
+	service = credentialService(service)
+	store, err := api.DefaultSecureStore()
+	if err != nil {
+		return nil, err
+	}
+	token, err := store.Get(service)
+	if err != nil {
+		return nil, err
+	}
+	return ensureTokenValid(ctx, store, service, token, cfg)
+}
+
 func newSyncPushCmd() *cobra.Command {
-	var to string
+	var to, resume string
 	var dryRun bool
+	var timeout time.Duration
+	var flags targetFlags
 
 	cmd := &cobra.Command{
-		Use:   "push <file>",
+		Use:   "push [file]",
 		Short: "Push local file items to a cloud service",
-		Args:  cobra.ExactArgs(1),
+		Long: `Push local file items to a cloud service.
+
+Items go to the calendar, project or database named by --calendar,
+--project or --database. Without one, each item is routed by its tags and
+type through [sync_targets.<service>] in the config, and otherwise to the
+service default. Tasks pushed to google go to the default Google Tasks list.
+
+Each item written is recorded in a journal under the salja data directory.
+If a push is interrupted or runs past its deadline, continue it with
+--resume <journal>, which skips the items already written.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var journal *syncer.Journal
+			defer func() {
+				if journal != nil {
+					_ = journal.Close()
+				}
+			}()
+
+			filePath := ""
+			if len(args) == 1 {
+				filePath = args[0]
+			}
+			if resume != "" {
+				j, err := syncer.OpenJournal(resume)
+				if err != nil {
+					return err
+				}
+				journal = j
+				if to != "" && to != j.Header.Service {
+					return fmt.Errorf("journal %s is for %s, not %s", resume, j.Header.Service, to)
+				}
+				to = j.Header.Service
+				if filePath == "" {
+					filePath = j.Header.Source
+				}
+			}
+			if filePath == "" {
+				return fmt.Errorf("requires a file argument unless --resume is given")
+			}
 			if err := validateSyncService(to, "to"); err != nil {
 				return err
 			}
-			filePath := args[0]
+			ref, err := flags.ref(to)
+			if err != nil {
+				return err
+			}
+			if journal != nil && ref == "" {
+				ref = journal.Header.Target
+				if ref == "" {
+					ref = journal.Header.Container
+				}
+			}
 			format := DetectFormat(filePath)
 
 			cfg, cfgErr := config.Load()
@@ -100,54 +185,87 @@ func newSyncPushCmd() *cobra.Command {
 				apiTimeout = time.Duration(cfg.APITimeoutSeconds) * time.Second
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
+			handler := salerr.NewCancelSignalHandler(cancel)
+			handler.Start()
+			defer handler.Stop()
 
 			collection, err := ReadInput(ctx, filePath, format, nil)
 			if err != nil {
 				return fmt.Errorf("failed to read input: %w", err)
 			}
-
-			store, err := api.DefaultSecureStore()
+			source, err := filepath.Abs(filePath)
 			if err != nil {
 				return err
 			}
+			withPushUIDs(source, collection.Items)
 
-			token, err := store.Get(to)
+			token, err := loadServiceToken(ctx, to, cfg)
 			if err != nil {
 				return err
 			}
 
-			token, err = ensureTokenValid(ctx, store, to, token, cfg)
-			if err != nil {
-				return err
+			routes := routeItems(cfg, to, ref, collection.Items)
+			resolver := newTargetResolver(to, cfg, token, apiTimeout)
+			if to == "google" {
+				if routes, err = routeGoogleTasks(ctx, resolver, routes); err != nil {
+					return err
+				}
+			}
+			rl := rateLimitFor(cfg, to)
+			exec := api.NewExecutor(rl)
+			targets := make([]*pushTarget, len(routes))
+			for i, route := range routes {
+				t, err := resolver.resolve(ctx, route.ref)
+				if err != nil {
+					return err
+				}
+				targets[i], err = newPushTarget(ctx, to, cfg, token, t, exec, dryRun, apiTimeout)
+				if err != nil {
+					return err
+				}
 			}
 
-			switch to {
-			case "google":
-				return pushToGoogle(ctx, token, collection, dryRun, apiTimeout)
-			case "microsoft":
-				return pushToMicrosoft(ctx, token, collection, dryRun, apiTimeout)
-			case "todoist":
-				return pushToTodoist(ctx, token, collection, dryRun, apiTimeout)
-			case "ticktick":
-				return pushToTickTick(ctx, token, collection, dryRun, apiTimeout)
-			case "notion":
-				return pushToNotion(ctx, token, collection, dryRun, apiTimeout)
-			default:
-				return fmt.Errorf("unsupported target %q; supported: google, microsoft, todoist, ticktick, notion", to)
+			if !dryRun && journal == nil {
+				journal, err = syncer.CreateJournal(syncer.JournalHeader{Service: to, Source: source, Target: ref})
+				if err != nil {
+					return err
+				}
+			}
+
+			deadline := pushDeadline(cfg, timeout, len(collection.Items), rl)
+			pushCtx, cancelPush := context.WithTimeout(ctx, deadline)
+			defer cancelPush()
+
+			for i, route := range routes {
+				err = pushItems(pushCtx, targets[i], &model.CalendarCollection{Items: route.items}, journal, dryRun)
+				if err != nil {
+					break
+				}
+			}
+			if journal != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+				return fmt.Errorf("push stopped (%w); resume with: salja sync push --resume %s", err, journal.Path())
 			}
+			if err == nil && journal != nil {
+				fmt.Fprintf(os.Stderr, "Run %s recorded; revert it with: salja sync undo %s\n", journal.ID(), journal.ID())
+			}
+			return err
 		},
 	}
 
-	cmd.Flags().StringVar(&to, "to", "", "Target service: google, microsoft")
-	_ = cmd.MarkFlagRequired("to")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created without making API calls")
+	cmd.Flags().StringVar(&to, "to", "", "Target service: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be pushed without making API calls")
+	cmd.Flags().StringVar(&resume, "resume", "", "Continue an interrupted push from its journal file")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Deadline for the whole push, e.g. 30m (default: sync_timeout_minutes, or scaled to the item count)")
+	flags.register(cmd)
 	return cmd
 }
 
 func newSyncPullCmd() *cobra.Command {
 	var from, output, startFlag, endFlag string
+	var incremental bool
+	var flags targetFlags
 
 	cmd := &cobra.Command{
 		Use:   "pull",
@@ -156,12 +274,7 @@ func newSyncPullCmd() *cobra.Command {
 			if err := validateSyncService(from, "from"); err != nil {
 				return err
 			}
-			store, err := api.DefaultSecureStore()
-			if err != nil {
-				return err
-			}
-
-			token, err := store.Get(from)
+			flagRef, err := flags.ref(from)
 			if err != nil {
 				return err
 			}
@@ -176,7 +289,7 @@ func newSyncPullCmd() *cobra.Command {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 			defer cancel()
 
-			token, err = ensureTokenValid(ctx, store, from, token, cfg)
+			token, err := loadServiceToken(ctx, from, cfg)
 			if err != nil {
 				return err
 			}
@@ -205,20 +318,38 @@ func newSyncPullCmd() *cobra.Command {
 				}
 			}
 
+			ref := targetRef(cfg, from, flagRef)
+			resolver := newTargetResolver(from, cfg, token, apiTimeout)
+			if incremental {
+				if from == "todoist" && flagRef != "" {
+					return fmt.Errorf("--incremental pulls all Todoist projects; drop --project or pull without --incremental")
+				}
+				return pullIncremental(ctx, from, output, cfg, token, resolver, ref, startTime, endTime, startFlag != "" || endFlag != "", apiTimeout)
+			}
+
+			target, err := resolver.resolve(ctx, ref)
+			if err != nil {
+				return err
+			}
+
 			var collection *model.CalendarCollection
 			switch from {
 			case "google":
-				collection, err = pullFromGoogle(ctx, token, startTime, endTime, apiTimeout)
+				collection, err = pullFromGoogle(ctx, token, target.ID, startTime, endTime, apiTimeout)
 			case "microsoft":
-				collection, err = pullFromMicrosoft(ctx, token, startTime, endTime, apiTimeout)
+				collection, err = pullFromMicrosoft(ctx, token, target.ID, startTime, endTime, apiTimeout)
+			case "microsoft-todo":
+				collection, err = pullFromMicrosoftTodo(ctx, token, target.ID, apiTimeout)
 			case "todoist":
-				collection, err = pullFromTodoist(ctx, token, apiTimeout)
+				collection, err = pullFromTodoist(ctx, token, target.ID, apiTimeout)
 			case "ticktick":
-				collection, err = pullFromTickTick(ctx, token, apiTimeout)
+				collection, err = pullFromTickTick(ctx, token, target.ID, apiTimeout)
 			case "notion":
-				collection, err = pullFromNotion(ctx, token, apiTimeout)
+				collection, err = pullFromNotion(ctx, token, target.ID, apiTimeout)
+			case "caldav":
+				collection, err = pullFromCalDAV(ctx, cfg, target.ID, startTime, endTime, apiTimeout)
 			default:
-				return fmt.Errorf("unsupported source %q; supported: google, microsoft, todoist, ticktick, notion", from)
+				return fmt.Errorf("unsupported source %q; supported: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav", from)
 			}
 			if err != nil {
 				return err
@@ -234,141 +365,89 @@ func newSyncPullCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&from, "from", "", "Source service: google, microsoft, todoist, ticktick, notion")
+	cmd.Flags().StringVar(&from, "from", "", "Source service: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav")
 	_ = cmd.MarkFlagRequired("from")
 	cmd.Flags().StringVar(&output, "output", "", "Output file path")
 	_ = cmd.MarkFlagRequired("output")
 	cmd.Flags().StringVar(&startFlag, "start", "", "Start date for pull range (YYYY-MM-DD, default: -1 month)")
 	cmd.Flags().StringVar(&endFlag, "end", "", "End date for pull range (YYYY-MM-DD, default: +3 months)")
+	cmd.Flags().BoolVar(&incremental, "incremental", false, "Fetch only changes since the last pull and apply them to the existing output file")
+	flags.register(cmd)
 	return cmd
 }
 
-func pushToGoogle(ctx context.Context, token *api.Token, collection *model.CalendarCollection, dryRun bool, timeout time.Duration) error {
-	client := api.NewGCalClientWithTimeout(token, timeout)
-	created := 0
-	// Google Calendar API quota: 10 QPS for calendar.events.insert
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-	for _, item := range collection.Items {
-		event := api.CalendarItemToGCal(item)
-		if dryRun {
-			fmt.Printf("  [dry-run] would create: %s\n", event.Summary)
-			continue
-		}
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-		}
-		_, err := client.InsertEvent(ctx, "primary", &event)
+// pullFromGoogle pulls the tasks of a Google Tasks list when targetID names
+// one, and otherwise the events of a calendar with the tasks of the default
+// task list. Tokens from before tasks were supported lack the tasks scope;
+// their calendar pulls leave tasks out with a warning.
+func pullFromGoogle(ctx context.Context, token *api.Token, targetID string, startTime, endTime time.Time, timeout time.Duration) (*model.CalendarCollection, error) {
+	listID, tasksOnly := strings.CutPrefix(targetID, googleTasksPrefix)
+	var events []api.GCalEvent
+	if !tasksOnly {
+		listID = api.GTasksDefaultList
+		var err error
+		events, err = api.NewGCalClientWithTimeout(token, timeout).ListEvents(ctx, targetID, startTime, endTime)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "  ✗ Failed: %s (%v)\n", item.Title, err)
-			continue
+			return nil, fmt.Errorf("google calendar API error: %w", err)
 		}
-		created++
 	}
-	if !dryRun {
-		fmt.Fprintf(os.Stderr, "✓ Created %d/%d events in Google Calendar\n", created, len(collection.Items))
+	tasks, err := api.NewGTasksClientWithTimeout(token, timeout).ListTasks(ctx, listID)
+	var apiErr *salerr.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 403 && !tasksOnly {
+		fmt.Fprintln(os.Stderr, "Warning: not authorized for Google Tasks, pulling events only; run: salja auth login google")
+	} else if err != nil {
+		return nil, fmt.Errorf("google tasks API error: %w", err)
 	}
-	return nil
-}
 
-func pushToMicrosoft(ctx context.Context, token *api.Token, collection *model.CalendarCollection, dryRun bool, timeout time.Duration) error {
-	client := api.NewMSGraphClientWithTimeout(token, timeout)
-	created := 0
-	// Microsoft Graph rate limit: ~4 requests per second
-	ticker := time.NewTicker(250 * time.Millisecond)
-	defer ticker.Stop()
-	for _, item := range collection.Items {
-		event := api.CalendarItemToMSGraph(item)
-		if dryRun {
-			fmt.Printf("  [dry-run] would create: %s\n", event.Subject)
-			continue
-		}
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-		}
-		_, err := client.CreateEvent(ctx, &event)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  ✗ Failed: %s (%v)\n", item.Title, err)
-			continue
-		}
-		created++
-	}
-	if !dryRun {
-		fmt.Fprintf(os.Stderr, "✓ Created %d/%d events in Microsoft Outlook\n", created, len(collection.Items))
+	collection := &model.CalendarCollection{
+		Items:      make([]model.CalendarItem, 0, len(events)+len(tasks)),
+		SourceApp:  "google",
+		ExportDate: time.Now(),
 	}
-	return nil
+	collection.Items = append(collection.Items, api.GCalToCalendarItems(events)...)
+	collection.Items = append(collection.Items, api.GTasksToCalendarItems(tasks)...)
+	return collection, nil
 }
 
-func pullFromGoogle(ctx context.Context, token *api.Token, startTime, endTime time.Time, timeout time.Duration) (*model.CalendarCollection, error) {
-	client := api.NewGCalClientWithTimeout(token, timeout)
-	events, err := client.ListEvents(ctx, "primary", startTime, endTime)
+func pullFromMicrosoft(ctx context.Context, token *api.Token, calendarID string, startTime, endTime time.Time, timeout time.Duration) (*model.CalendarCollection, error) {
+	client := api.NewMSGraphClientWithTimeout(token, timeout)
+	events, err := client.ListEvents(ctx, calendarID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("microsoft graph API error: %w", err)
+	}
+	masters, err := client.SeriesMasters(ctx, events)
 	if err != nil {
-		return nil, fmt.Errorf("google calendar API error: %w", err)
+		return nil, fmt.Errorf("microsoft graph API error: %w", err)
 	}
 
-	collection := &model.CalendarCollection{
-		Items:      make([]model.CalendarItem, 0, len(events)),
-		SourceApp:  "google",
+	return &model.CalendarCollection{
+		Items:      api.MSGraphToCalendarItems(append(masters, events...)),
+		SourceApp:  "microsoft",
 		ExportDate: time.Now(),
-	}
-	for _, event := range events {
-		collection.Items = append(collection.Items, api.GCalToCalendarItem(event))
-	}
-	return collection, nil
+	}, nil
 }
 
-func pullFromMicrosoft(ctx context.Context, token *api.Token, startTime, endTime time.Time, timeout time.Duration) (*model.CalendarCollection, error) {
+func pullFromMicrosoftTodo(ctx context.Context, token *api.Token, listID string, timeout time.Duration) (*model.CalendarCollection, error) {
 	client := api.NewMSGraphClientWithTimeout(token, timeout)
-	events, err := client.ListEvents(ctx, startTime, endTime)
+	tasks, err := client.ListTodoTasks(ctx, listID)
 	if err != nil {
 		return nil, fmt.Errorf("microsoft graph API error: %w", err)
 	}
 
 	collection := &model.CalendarCollection{
-		Items:      make([]model.CalendarItem, 0, len(events)),
-		SourceApp:  "microsoft",
+		Items:      make([]model.CalendarItem, 0, len(tasks)),
+		SourceApp:  "microsoft-todo",
 		ExportDate: time.Now(),
 	}
-	for _, event := range events {
-		collection.Items = append(collection.Items, api.MSGraphToCalendarItem(event))
+	for _, task := range tasks {
+		collection.Items = append(collection.Items, api.MSGraphTodoToCalendarItem(task))
 	}
 	return collection, nil
 }
 
-func pushToTodoist(ctx context.Context, token *api.Token, collection *model.CalendarCollection, dryRun bool, timeout time.Duration) error {
-	client := api.NewTodoistClientWithTimeout(token, timeout)
-	created := 0
-	ticker := time.NewTicker(50 * time.Millisecond)
-	defer ticker.Stop()
-	for _, item := range collection.Items {
-		task := api.CalendarItemToTodoist(item, "")
-		if dryRun {
-			fmt.Printf("  [dry-run] would create: %s\n", task.Content)
-			continue
-		}
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-		}
-		_, err := client.CreateTask(ctx, &task)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  ✗ Failed: %s (%v)\n", item.Title, err)
-			continue
-		}
-		created++
-	}
-	if !dryRun {
-		fmt.Fprintf(os.Stderr, "✓ Created %d/%d tasks in Todoist\n", created, len(collection.Items))
-	}
-	return nil
-}
-
-func pullFromTodoist(ctx context.Context, token *api.Token, timeout time.Duration) (*model.CalendarCollection, error) {
+// pullFromTodoist pulls the tasks of one project, or of all projects when
+// projectID is empty.
+func pullFromTodoist(ctx context.Context, token *api.Token, projectID string, timeout time.Duration) (*model.CalendarCollection, error) {
 	client := api.NewTodoistClientWithTimeout(token, timeout)
 	tasks, err := client.GetTasks(ctx)
 	if err != nil {
@@ -381,82 +460,17 @@ func pullFromTodoist(ctx context.Context, token *api.Token, timeout time.Duratio
 		ExportDate: time.Now(),
 	}
 	for _, task := range tasks {
-		collection.Items = append(collection.Items, api.TodoistToCalendarItem(task))
-	}
-	return collection, nil
-}
-
-func pushToTickTick(ctx context.Context, token *api.Token, collection *model.CalendarCollection, dryRun bool, timeout time.Duration) error {
-	client := api.NewTickTickClientWithTimeout(token, timeout)
-
-	projects, err := client.ListProjects(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to list TickTick projects: %w", err)
-	}
-	if len(projects) == 0 {
-		return fmt.Errorf("no TickTick projects found")
-	}
-
-	fmt.Fprintln(os.Stderr, "Select a TickTick project:")
-	for i, p := range projects {
-		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, p.Name)
-	}
-	fmt.Fprint(os.Stderr, "> ")
-	var choice int
-	if _, err := fmt.Fscan(os.Stdin, &choice); err != nil || choice < 1 || choice > len(projects) {
-		choice = 1
-	}
-	projectID := projects[choice-1].ID
-
-	created := 0
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-	for _, item := range collection.Items {
-		task := api.CalendarItemToTickTick(item, projectID)
-		if dryRun {
-			fmt.Printf("  [dry-run] would create: %s\n", task.Title)
+		if projectID != "" && task.ProjectID != projectID {
 			continue
 		}
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-		}
-		_, err := client.CreateTask(ctx, &task)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  ✗ Failed: %s (%v)\n", item.Title, err)
-			continue
-		}
-		created++
-	}
-	if !dryRun {
-		fmt.Fprintf(os.Stderr, "✓ Created %d/%d tasks in TickTick\n", created, len(collection.Items))
+		collection.Items = append(collection.Items, api.TodoistToCalendarItem(task))
 	}
-	return nil
+	return collection, nil
 }
 
-func pullFromTickTick(ctx context.Context, token *api.Token, timeout time.Duration) (*model.CalendarCollection, error) {
+func pullFromTickTick(ctx context.Context, token *api.Token, projectID string, timeout time.Duration) (*model.CalendarCollection, error) {
 	client := api.NewTickTickClientWithTimeout(token, timeout)
-
-	projects, err := client.ListProjects(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list TickTick projects: %w", err)
-	}
-	if len(projects) == 0 {
-		return nil, fmt.Errorf("no TickTick projects found")
-	}
-
-	fmt.Fprintln(os.Stderr, "Select a TickTick project to pull from:")
-	for i, p := range projects {
-		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, p.Name)
-	}
-	fmt.Fprint(os.Stderr, "> ")
-	var choice int
-	if _, err := fmt.Fscan(os.Stdin, &choice); err != nil || choice < 1 || choice > len(projects) {
-		choice = 1
-	}
-
-	tasks, err := client.ListTasks(ctx, projects[choice-1].ID)
+	tasks, err := client.ListTasks(ctx, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("TickTick API error: %w", err)
 	}
@@ -472,59 +486,10 @@ func pullFromTickTick(ctx context.Context, token *api.Token, timeout time.Durati
 	return collection, nil
 }
 
-func pushToNotion(ctx context.Context, token *api.Token, collection *model.CalendarCollection, dryRun bool, timeout time.Duration) error {
+func pullFromNotion(ctx context.Context, token *api.Token, databaseID string, timeout time.Duration) (*model.CalendarCollection, error) {
 	client := api.NewNotionClientWithTimeout(token.AccessToken, timeout)
 	pm := api.DefaultNotionPropertyMap()
 
-	fmt.Fprint(os.Stderr, "Enter Notion database ID: ")
-	var databaseID string
-	if _, err := fmt.Fscanln(os.Stdin, &databaseID); err != nil {
-		return fmt.Errorf("reading Notion database ID from stdin: %w", err)
-	}
-	if databaseID == "" {
-		return fmt.Errorf("database ID cannot be empty")
-	}
-
-	created := 0
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-	for _, item := range collection.Items {
-		page := api.CalendarItemToNotion(item, pm)
-		if dryRun {
-			fmt.Printf("  [dry-run] would create: %s\n", item.Title)
-			continue
-		}
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-		}
-		_, err := client.CreatePage(ctx, databaseID, &page)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  ✗ Failed: %s (%v)\n", item.Title, err)
-			continue
-		}
-		created++
-	}
-	if !dryRun {
-		fmt.Fprintf(os.Stderr, "✓ Created %d/%d pages in Notion\n", created, len(collection.Items))
-	}
-	return nil
-}
-
-func pullFromNotion(ctx context.Context, token *api.Token, timeout time.Duration) (*model.CalendarCollection, error) {
-	client := api.NewNotionClientWithTimeout(token.AccessToken, timeout)
-	pm := api.DefaultNotionPropertyMap()
-
-	fmt.Fprint(os.Stderr, "Enter Notion database ID: ")
-	var databaseID string
-	if _, err := fmt.Fscanln(os.Stdin, &databaseID); err != nil {
-		return nil, fmt.Errorf("reading Notion database ID from stdin: %w", err)
-	}
-	if databaseID == "" {
-		return nil, fmt.Errorf("database ID cannot be empty")
-	}
-
 	collection := &model.CalendarCollection{
 		Items:      []model.CalendarItem{},
 		SourceApp:  "notion",
@@ -548,3 +513,42 @@ func pullFromNotion(ctx context.Context, token *api.Token, timeout time.Duration
 
 	return collection, nil
 }
+
+// newCalDAVClient builds a client from api.caldav in the config. The password
+// falls back to the one stored by `salja auth login caldav`.
+func newCalDAVClient(cfg *config.Config, timeout time.Duration) (*api.CalDAVClient, error) {
+	dav := cfg.API.CalDAV
+	if dav.URL == "" {
+		return nil, fmt.Errorf("configure api.caldav.url in %s first", config.ConfigPath())
+	}
+	password := dav.Password
+	if password == "" {
+		store, err := api.DefaultSecureStore()
+		if err != nil {
+			return nil, err
+		}
+		token, err := store.Get("caldav")
+		if err != nil {
+			return nil, fmt.Errorf("no CalDAV password configured; set api.caldav.password or run: salja auth login caldav")
+		}
+		password = token.AccessToken
+	}
+	return api.NewCalDAVClientWithTimeout(dav.URL, dav.Username, password, timeout)
+}
+
+func pullFromCalDAV(ctx context.Context, cfg *config.Config, calendarHref string, startTime, endTime time.Time, timeout time.Duration) (*model.CalendarCollection, error) {
+	client, err := newCalDAVClient(cfg, timeout)
+	if err != nil {
+		return nil, err
+	}
+	remote := &api.CalDAVRemote{Client: client, CalendarHref: calendarHref, Start: startTime, End: endTime}
+	items, err := remote.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("CalDAV error: %w", err)
+	}
+	return &model.CalendarCollection{
+		Items:      items,
+		SourceApp:  "caldav",
+		ExportDate: time.Now(),
+	}, nil
+}
@@ -0,0 +1,381 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/api"
+	"github.com/gongahkia/salja/internal/config"
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/syncer"
+)
+
+// pushRemote is a sync remote that can find the copy of an item written by
+// an earlier push, which makes pushes safe to re-run.
+type pushRemote interface {
+	syncer.Remote
+	Lookup(ctx context.Context, uid string) (*model.CalendarItem, error)
+}
+
+// batchRemote is a pushRemote that can look up and write many items per
+// request.
+type batchRemote interface {
+	pushRemote
+	BatchSize() int
+	LookupBatch(ctx context.Context, uids []string) (*salerr.PartialResult[api.BatchResult[*model.CalendarItem]], error)
+	CreateBatch(ctx context.Context, items []model.CalendarItem) (*salerr.PartialResult[api.BatchResult[model.CalendarItem]], error)
+	UpdateBatch(ctx context.Context, remoteIDs []string, items []model.CalendarItem) (*salerr.PartialResult[api.BatchResult[model.CalendarItem]], error)
+	DeleteBatch(ctx context.Context, remoteIDs []string) (*salerr.PartialResult[api.BatchResult[string]], error)
+}
+
+// pushTarget is a pushRemote with the executor that paces requests to its
+// service and a display name. container is the ID of the calendar, project
+// or database pushed to, recorded in the journal for undo.
+type pushTarget struct {
+	remote    pushRemote
+	exec      *api.Executor
+	label     string
+	container string
+}
+
+// rateLimitFor returns the built-in quota for a service with any
+// [rate_limits.<service>] values from the config laid over it.
+func rateLimitFor(cfg *config.Config, service string) api.RateLimit {
+	rl := api.DefaultRateLimit(service)
+	if cfg == nil {
+		return rl
+	}
+	if o, ok := cfg.RateLimits[service]; ok {
+		if o.RequestsPerSecond > 0 {
+			rl.RequestsPerSecond = o.RequestsPerSecond
+		}
+		if o.Burst > 0 {
+			rl.Burst = o.Burst
+		}
+		if o.Concurrency > 0 {
+			rl.Concurrency = o.Concurrency
+		}
+	}
+	return rl
+}
+
+// newPushTarget builds the remote for one target of a service. Targets of
+// the same service share exec so they draw from one rate limit.
+func newPushTarget(ctx context.Context, to string, cfg *config.Config, token *api.Token, target api.SyncTarget, exec *api.Executor, dryRun bool, timeout time.Duration) (*pushTarget, error) {
+	label := serviceLabel(to)
+	if target.Name != "" && !target.Default {
+		label += fmt.Sprintf(" %q", target.Name)
+	}
+	pt := &pushTarget{exec: exec, label: label, container: target.ID}
+
+	switch to {
+	case "google":
+		if listID, ok := strings.CutPrefix(target.ID, googleTasksPrefix); ok {
+			client := api.NewGTasksClientWithTimeout(token, timeout)
+			client.UseLimiter(exec.Limiter)
+			pt.remote = &api.GTasksRemote{Client: client, ListID: listID}
+			pt.label = "Google Tasks"
+			break
+		}
+		client := api.NewGCalClientWithTimeout(token, timeout)
+		client.UseLimiter(exec.Limiter)
+		pt.remote = &api.GCalRemote{Client: client, CalendarID: target.ID}
+	case "microsoft":
+		client := api.NewMSGraphClientWithTimeout(token, timeout)
+		client.UseLimiter(exec.Limiter)
+		pt.remote = &api.MSGraphRemote{Client: client, CalendarID: target.ID}
+	case "microsoft-todo":
+		client := api.NewMSGraphClientWithTimeout(token, timeout)
+		client.UseLimiter(exec.Limiter)
+		pt.remote = &api.MSGraphTodoRemote{Client: client, ListID: target.ID}
+	case "todoist":
+		client := api.NewTodoistClientWithTimeout(token, timeout)
+		client.UseLimiter(exec.Limiter)
+		pt.remote = &api.TodoistRemote{Client: client, ProjectID: target.ID}
+	case "ticktick":
+		client := api.NewTickTickClientWithTimeout(token, timeout)
+		client.UseLimiter(exec.Limiter)
+		pt.remote = &api.TickTickRemote{Client: client, ProjectID: target.ID}
+	case "notion":
+		client := api.NewNotionClientWithTimeout(token.AccessToken, timeout)
+		client.UseLimiter(exec.Limiter)
+		pm := api.DefaultNotionPropertyMap()
+		if !dryRun {
+			if err := client.EnsureRichTextProperty(ctx, target.ID, api.NotionSourceUIDProperty); err != nil {
+				return nil, fmt.Errorf("failed to add %q property to Notion database: %w", api.NotionSourceUIDProperty, err)
+			}
+			pm.SourceUID = api.NotionSourceUIDProperty
+		}
+		pt.remote = &api.NotionRemote{Client: client, DatabaseID: target.ID, PropertyMap: pm}
+	case "caldav":
+		client, err := newCalDAVClient(cfg, timeout)
+		if err != nil {
+			return nil, err
+		}
+		client.UseLimiter(exec.Limiter)
+		pt.remote = &api.CalDAVRemote{Client: client, CalendarHref: target.ID}
+	default:
+		return nil, fmt.Errorf("unsupported target %q; supported: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav", to)
+	}
+	return pt, nil
+}
+
+// pushDeadline bounds a whole push. --timeout wins over sync_timeout_minutes;
+// with neither set, five minutes are allowed plus three requests per item at
+// the service's rate limit, and at least a second per item.
+func pushDeadline(cfg *config.Config, flag time.Duration, items int, rl api.RateLimit) time.Duration {
+	if flag > 0 {
+		return flag
+	}
+	if cfg != nil && cfg.SyncTimeoutMinutes > 0 {
+		return time.Duration(cfg.SyncTimeoutMinutes) * time.Minute
+	}
+	perItem := time.Second
+	if rl.RequestsPerSecond > 0 {
+		if d := time.Duration(3 / rl.RequestsPerSecond * float64(time.Second)); d > perItem {
+			perItem = d
+		}
+	}
+	return 5*time.Minute + time.Duration(items)*perItem
+}
+
+// pushResult is what pushItems wrote for one item, to be journaled. An
+// empty action means the item was not written.
+type pushResult struct {
+	remoteID string
+	action   string
+	before   *model.CalendarItem
+	err      error
+}
+
+// pushItems upserts every item: an item already pushed by an earlier run is
+// found by its UID and updated, anything else is created. Items recorded in
+// the journal are skipped, and each item written is added to it. Items are
+// pushed concurrently through the target's executor, in batches where the
+// service supports them. It stops at the first context error, returning it
+// after the summary.
+func pushItems(ctx context.Context, target *pushTarget, collection *model.CalendarCollection, journal *syncer.Journal, dryRun bool) error {
+	var pending []model.CalendarItem
+	skipped := 0
+	for _, item := range collection.Items {
+		if journal != nil && journal.Done(item.UID) {
+			skipped++
+			continue
+		}
+		if dryRun {
+			fmt.Printf("  [dry-run] would push to %s: %s\n", target.label, item.Title)
+			continue
+		}
+		pending = append(pending, item)
+	}
+	if dryRun {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]pushResult, len(pending))
+	size := 1
+	if br, ok := target.remote.(batchRemote); ok {
+		size = br.BatchSize()
+	}
+
+	var stopErr, journalErr error
+	created, updated := 0, 0
+	runChunks(runCtx, target.exec, len(pending), size, func(ctx context.Context, lo, hi int) error {
+		if br, ok := target.remote.(batchRemote); ok {
+			return upsertBatch(ctx, br, pending[lo:hi], results[lo:hi])
+		}
+		results[lo] = upsertItem(ctx, target.remote, pending[lo])
+		return results[lo].err
+	}, func(lo, hi int, err error) {
+		for i := lo; i < hi; i++ {
+			r := results[i]
+			if r.action == "" {
+				itemErr := r.err
+				if itemErr == nil {
+					itemErr = err
+				}
+				if ctx.Err() != nil {
+					stopErr = ctx.Err()
+				} else if itemErr != nil && journalErr == nil {
+					fmt.Fprintf(os.Stderr, "  ✗ Failed: %s (%v)\n", pending[i].Title, itemErr)
+				}
+				continue
+			}
+			if r.action == syncer.ActionCreated {
+				created++
+			} else {
+				updated++
+			}
+			if journal != nil && journalErr == nil {
+				if err := journal.RecordIn(target.container, pending[i].UID, r.remoteID, r.action, r.before); err != nil {
+					// without a journal the push can be neither resumed nor undone
+					journalErr = err
+					cancel()
+				}
+			}
+		}
+	})
+	if journalErr != nil {
+		return journalErr
+	}
+	if stopErr == nil {
+		stopErr = ctx.Err()
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Pushed %d/%d items to %s (%d created, %d updated",
+		created+updated+skipped, len(collection.Items), target.label, created, updated)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, ", %d already done", skipped)
+	}
+	fmt.Fprintln(os.Stderr, ")")
+	return stopErr
+}
+
+// runChunks runs fn over [0, n) in chunks of size on exec and calls done,
+// from a single goroutine, with the bounds and error of each finished chunk.
+func runChunks(ctx context.Context, exec *api.Executor, n, size int, fn func(ctx context.Context, lo, hi int) error, done func(lo, hi int, err error)) {
+	bounds := func(c int) (int, int) {
+		lo, hi := c*size, (c+1)*size
+		if hi > n {
+			hi = n
+		}
+		return lo, hi
+	}
+	chunks := (n + size - 1) / size
+	for p := range exec.Run(ctx, chunks, func(ctx context.Context, c int) error {
+		lo, hi := bounds(c)
+		return fn(ctx, lo, hi)
+	}) {
+		lo, hi := bounds(p.Index)
+		done(lo, hi, p.Err)
+	}
+}
+
+// upsertItem updates the remote copy of item if an earlier push left one,
+// and creates it otherwise.
+func upsertItem(ctx context.Context, remote pushRemote, item model.CalendarItem) pushResult {
+	existing, err := remote.Lookup(ctx, item.UID)
+	if err != nil {
+		return pushResult{err: err}
+	}
+	if existing == nil {
+		created, err := remote.Create(ctx, item)
+		if err != nil {
+			return pushResult{err: err}
+		}
+		return pushResult{remoteID: created.UID, action: syncer.ActionCreated}
+	}
+	result, err := remote.Update(ctx, existing.UID, item)
+	if err != nil {
+		return pushResult{err: err}
+	}
+	if result.UID == "" {
+		result.UID = existing.UID
+	}
+	return pushResult{remoteID: result.UID, action: syncer.ActionUpdated, before: existing}
+}
+
+// upsertBatch is upsertItem for a batch of items, with one batched lookup
+// and one batched write for each of creates and updates. Items that already
+// have a result are skipped, so a retried batch only resends failed parts.
+func upsertBatch(ctx context.Context, remote batchRemote, items []model.CalendarItem, results []pushResult) error {
+	var todo []int
+	var uids []string
+	for k := range items {
+		if results[k].action == "" {
+			results[k].err = nil
+			todo = append(todo, k)
+			uids = append(uids, items[k].UID)
+		}
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+
+	found, err := remote.LookupBatch(ctx, uids)
+	if err != nil {
+		return err
+	}
+	for _, e := range found.Errors {
+		results[todo[e.Index]].err = e.Err
+	}
+
+	var creates, updates []int
+	existing := make(map[int]*model.CalendarItem)
+	for _, r := range found.Items {
+		k := todo[r.Index]
+		results[k].err = nil
+		if r.Value == nil {
+			creates = append(creates, k)
+		} else {
+			updates = append(updates, k)
+			existing[k] = r.Value
+		}
+	}
+
+	if len(creates) > 0 {
+		batch := make([]model.CalendarItem, len(creates))
+		for j, k := range creates {
+			batch[j] = items[k]
+		}
+		created, err := remote.CreateBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, r := range created.Items {
+			results[creates[r.Index]] = pushResult{remoteID: r.Value.UID, action: syncer.ActionCreated}
+		}
+		for _, e := range created.Errors {
+			results[creates[e.Index]].err = e.Err
+		}
+	}
+
+	if len(updates) > 0 {
+		batch := make([]model.CalendarItem, len(updates))
+		ids := make([]string, len(updates))
+		for j, k := range updates {
+			batch[j] = items[k]
+			ids[j] = existing[k].UID
+		}
+		updated, err := remote.UpdateBatch(ctx, ids, batch)
+		if err != nil {
+			return err
+		}
+		for _, r := range updated.Items {
+			k := updates[r.Index]
+			id := r.Value.UID
+			if id == "" {
+				id = existing[k].UID
+			}
+			results[k] = pushResult{remoteID: id, action: syncer.ActionUpdated, before: existing[k]}
+		}
+		for _, e := range updated.Errors {
+			results[updates[e.Index]].err = e.Err
+		}
+	}
+	return nil
+}
+
+// withPushUIDs gives items from formats without stable IDs a UID derived
+// from the source file and their place among such items in it, so pushing
+// the file again after editing an item updates its earlier copy rather than
+// duplicating it.
+func withPushUIDs(source string, items []model.CalendarItem) {
+	n := 0
+	for i := range items {
+		if items[i].UID != "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", source, n)))
+		items[i].UID = "salja-" + hex.EncodeToString(sum[:])[:16]
+		n++
+	}
+}
@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/api"
+	"github.com/gongahkia/salja/internal/config"
+	"github.com/gongahkia/salja/internal/conflict"
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/logging"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+func newSyncRunCmd() *cobra.Command {
+	var localPath, remote, startFlag, endFlag, strategyFlag string
+	var dryRun bool
+	var timeout time.Duration
+	var flags targetFlags
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Two-way sync a local file with a cloud service",
+		Long: `Two-way sync a local file with a cloud service.
+
+A sync state database under the salja data directory maps local item UIDs to
+remote IDs and records what each side looked like at the last run, so edits,
+completions and deletions on either side are propagated instead of creating
+duplicates. Items edited on both sides are settled by the conflict strategy.
+
+The calendar, project or database named by --calendar, --project or
+--database on the first run is remembered in the state; later runs may omit
+it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateSyncService(remote, "remote"); err != nil {
+				return err
+			}
+			flagRef, err := flags.ref(remote)
+			if err != nil {
+				return err
+			}
+
+			cfg, cfgErr := config.Load()
+			if cfgErr != nil {
+				logging.Default().Warn("system", fmt.Sprintf("config load failed: %v", cfgErr))
+				fmt.Fprintf(os.Stderr, "Warning: config load failed, using defaults: %v\n", cfgErr)
+				cfg = config.DefaultConfig()
+			}
+			apiTimeout := 30 * time.Second
+			if cfg != nil && cfg.APITimeoutSeconds > 0 {
+				apiTimeout = time.Duration(cfg.APITimeoutSeconds) * time.Second
+			}
+
+			strategy := conflict.StrategyAsk
+			if cfg != nil && cfg.ConflictStrategy != "" {
+				strategy = conflict.Strategy(cfg.ConflictStrategy)
+			}
+			if strategyFlag != "" {
+				strategy = conflict.Strategy(strategyFlag)
+			}
+			resolver, err := conflict.NewResolver(strategy)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			startTime := now.AddDate(0, -1, 0)
+			endTime := now.AddDate(0, 3, 0)
+			if startFlag != "" {
+				t, err := time.Parse("2006-01-02", startFlag)
+				if err != nil {
+					return fmt.Errorf("invalid --start date %q; use YYYY-MM-DD", startFlag)
+				}
+				startTime = t
+			}
+			if endFlag != "" {
+				t, err := time.Parse("2006-01-02", endFlag)
+				if err != nil {
+					return fmt.Errorf("invalid --end date %q; use YYYY-MM-DD", endFlag)
+				}
+				endTime = t
+			}
+
+			statePath, err := syncer.StatePath(localPath, remote)
+			if err != nil {
+				return err
+			}
+			state, err := syncer.LoadState(statePath, localPath, remote)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			handler := salerr.NewCancelSignalHandler(cancel)
+			handler.Start()
+			defer handler.Stop()
+
+			format := DetectFormat(localPath)
+			local := &model.CalendarCollection{SourceApp: remote, ExportDate: now}
+			if _, statErr := os.Stat(localPath); statErr == nil {
+				local, err = ReadInput(ctx, localPath, format, cfg)
+				if err != nil {
+					return fmt.Errorf("failed to read local file: %w", err)
+				}
+			} else if !os.IsNotExist(statErr) {
+				return statErr
+			}
+
+			token, err := loadServiceToken(ctx, remote, cfg)
+			if err != nil {
+				return err
+			}
+			container, err := newTargetResolver(remote, cfg, token, apiTimeout).bindState(ctx, state, targetRef(cfg, remote, flagRef))
+			if err != nil {
+				return err
+			}
+
+			engine, err := newSyncEngine(remote, cfg, token, state, container, startTime, endTime, apiTimeout)
+			if err != nil {
+				return err
+			}
+			engine.Resolver = resolver
+
+			runCtx, cancelRun := context.WithTimeout(ctx, pushDeadline(cfg, timeout, len(local.Items), rateLimitFor(cfg, remote)))
+			defer cancelRun()
+
+			actions, err := engine.Plan(runCtx, local)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				changes := syncer.Changes(local, actions)
+				for _, a := range changes {
+					fmt.Printf("  [dry-run] would %s\n", a)
+				}
+				fmt.Fprintf(os.Stderr, "%d change(s) planned between %s and %s\n", len(changes), localPath, remote)
+				return nil
+			}
+
+			result := engine.Apply(runCtx, local, actions)
+			for _, e := range result.Errors {
+				fmt.Fprintf(os.Stderr, "  ✗ %v\n", e)
+			}
+
+			// The remote side is already changed, so the local file and the
+			// mappings are written even when the run was cut short; a lost
+			// mapping would make the next run create its items again.
+			writeErr := WriteOutput(context.Background(), local, localPath, format)
+			if err := state.Save(); err != nil {
+				return fmt.Errorf("failed to save sync state: %w", err)
+			}
+			if writeErr != nil {
+				return fmt.Errorf("failed to write local file: %w", writeErr)
+			}
+			if logErr := resolver.WriteLog(); logErr != nil {
+				logging.Default().Error("error", fmt.Sprintf("conflict log write failed: %v", logErr))
+				fmt.Fprintf(os.Stderr, "Warning: failed to write conflict log: %v\n", logErr)
+			}
+
+			fmt.Fprintf(os.Stderr, "✓ Synced %s with %s: %s\n", localPath, remote, result.Summary())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&localPath, "local", "", "Local file to sync")
+	_ = cmd.MarkFlagRequired("local")
+	cmd.Flags().StringVar(&remote, "remote", "", "Remote service: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav")
+	_ = cmd.MarkFlagRequired("remote")
+	cmd.Flags().StringVar(&startFlag, "start", "", "Start of the synced range for calendars (YYYY-MM-DD, default: -1 month)")
+	cmd.Flags().StringVar(&endFlag, "end", "", "End of the synced range for calendars (YYYY-MM-DD, default: +3 months)")
+	cmd.Flags().StringVar(&strategyFlag, "strategy", "", "Conflict strategy for items changed on both sides (default: conflict_strategy from config)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show planned changes without modifying either side")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Deadline for planning and applying the sync, e.g. 30m (default: sync_timeout_minutes, or scaled to the item count)")
+	flags.register(cmd)
+	return cmd
+}
+
+// newSyncEngine builds the remote adapter for a service against the calendar,
+// project or database container.
+func newSyncEngine(service string, cfg *config.Config, token *api.Token, state *syncer.State, container string, start, end time.Time, timeout time.Duration) (*syncer.Engine, error) {
+	var remote syncer.Remote
+	var interval time.Duration
+	windowed := false
+
+	switch service {
+	case "google":
+		// Google Calendar API quota: 10 QPS for calendar.events.insert
+		interval = 100 * time.Millisecond
+		tasks := &api.GTasksRemote{Client: api.NewGTasksClientWithTimeout(token, timeout), ListID: api.GTasksDefaultList}
+		if listID, ok := strings.CutPrefix(container, googleTasksPrefix); ok {
+			tasks.ListID = listID
+			remote = tasks
+			break
+		}
+		// Calendar has no tasks; they sync with the default task list
+		remote = &api.GoogleRemote{
+			Events: &api.GCalRemote{Client: api.NewGCalClientWithTimeout(token, timeout), CalendarID: container, Start: start, End: end},
+			Tasks:  tasks,
+		}
+		windowed = true
+	case "microsoft":
+		remote = &api.MSGraphRemote{Client: api.NewMSGraphClientWithTimeout(token, timeout), CalendarID: container, Start: start, End: end}
+		interval = 250 * time.Millisecond
+		windowed = true
+	case "microsoft-todo":
+		remote = &api.MSGraphTodoRemote{Client: api.NewMSGraphClientWithTimeout(token, timeout), ListID: container}
+		interval = 250 * time.Millisecond
+	case "todoist":
+		remote = &api.TodoistRemote{Client: api.NewTodoistClientWithTimeout(token, timeout), ProjectID: container}
+		interval = 50 * time.Millisecond
+	case "ticktick":
+		remote = &api.TickTickRemote{Client: api.NewTickTickClientWithTimeout(token, timeout), ProjectID: container}
+		interval = 100 * time.Millisecond
+	case "notion":
+		remote = &api.NotionRemote{
+			Client:      api.NewNotionClientWithTimeout(token.AccessToken, timeout),
+			DatabaseID:  container,
+			PropertyMap: api.DefaultNotionPropertyMap(),
+		}
+		interval = 100 * time.Millisecond
+	case "caldav":
+		client, err := newCalDAVClient(cfg, timeout)
+		if err != nil {
+			return nil, err
+		}
+		remote = &api.CalDAVRemote{Client: client, CalendarHref: container, Start: start, End: end}
+		windowed = true
+	default:
+		return nil, fmt.Errorf("unsupported sync service %q", service)
+	}
+
+	engine := syncer.NewEngine(remote, state)
+	engine.Interval = interval
+	if windowed {
+		engine.Window = &syncer.Window{Start: start, End: end}
+	}
+	return engine, nil
+}
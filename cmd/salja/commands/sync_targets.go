@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/api"
+	"github.com/gongahkia/salja/internal/config"
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/logging"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+// targetFlags are the --calendar, --project and --database flags, each of
+// which applies to some services only.
+type targetFlags struct {
+	calendar, project, database string
+}
+
+func (f *targetFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.calendar, "calendar", "", "Calendar name or ID for google, microsoft or caldav (see: salja sync targets <service>)")
+	cmd.Flags().StringVar(&f.project, "project", "", "Project or list name or ID for todoist, ticktick or microsoft-todo")
+	cmd.Flags().StringVar(&f.database, "database", "", "Database name or ID for notion")
+}
+
+// ref returns the flag that applies to service, rejecting flags that do not.
+func (f *targetFlags) ref(service string) (string, error) {
+	want := targetFlagFor(service)
+	for flag, value := range map[string]string{"calendar": f.calendar, "project": f.project, "database": f.database} {
+		if value != "" && flag != want {
+			return "", fmt.Errorf("--%s does not apply to %s; use --%s", flag, service, want)
+		}
+	}
+	switch want {
+	case "calendar":
+		return f.calendar, nil
+	case "project":
+		return f.project, nil
+	default:
+		return f.database, nil
+	}
+}
+
+func targetFlagFor(service string) string {
+	switch service {
+	case "todoist", "ticktick", "microsoft-todo":
+		return "project"
+	case "notion":
+		return "database"
+	default:
+		return "calendar"
+	}
+}
+
+// targetRef picks the target named by a flag, or else by
+// sync_targets.<service>.default in the config.
+func targetRef(cfg *config.Config, service, flagRef string) string {
+	if flagRef != "" || cfg == nil {
+		return flagRef
+	}
+	return cfg.SyncTargets[service].Default
+}
+
+// targetResolver turns target names and IDs into api.SyncTargets, listing a
+// service's targets at most once.
+type targetResolver struct {
+	service string
+	cfg     *config.Config
+	token   *api.Token
+	timeout time.Duration
+	targets []api.SyncTarget
+}
+
+func newTargetResolver(service string, cfg *config.Config, token *api.Token, timeout time.Duration) *targetResolver {
+	return &targetResolver{service: service, cfg: cfg, token: token, timeout: timeout}
+}
+
+// resolve finds the target for ref. An empty ref means the service default:
+// the primary Google calendar, the default Outlook calendar, the To Do Tasks
+// list, all Todoist projects (the inbox when creating), or
+// api.caldav.calendar. TickTick and
+// Notion have no default and must be named.
+func (r *targetResolver) resolve(ctx context.Context, ref string) (api.SyncTarget, error) {
+	if ref == "" {
+		switch r.service {
+		case "google":
+			return api.SyncTarget{ID: "primary", Name: "primary", Default: true}, nil
+		case "microsoft", "todoist":
+			return api.SyncTarget{Default: true}, nil
+		case "ticktick", "notion":
+			flag := targetFlagFor(r.service)
+			return api.SyncTarget{}, fmt.Errorf("name a %s %s with --%s or sync_targets.%s.default in the config; list them with: salja sync targets %s",
+				serviceLabel(r.service), flag, flag, r.service, r.service)
+		case "caldav":
+			if r.cfg != nil {
+				ref = r.cfg.API.CalDAV.Calendar
+			}
+		}
+	}
+	if r.service == "google" && ref == "primary" {
+		return api.SyncTarget{ID: "primary", Name: "primary", Default: true}, nil
+	}
+	if r.service == "google" && strings.HasPrefix(ref, googleTasksPrefix) {
+		return api.SyncTarget{ID: ref, Name: "Tasks", Default: true}, nil
+	}
+
+	if r.targets == nil {
+		targets, err := listTargets(ctx, r.service, r.cfg, r.token, r.timeout)
+		if err != nil {
+			return api.SyncTarget{}, err
+		}
+		r.targets = targets
+	}
+	t, err := api.ResolveTarget(r.targets, ref)
+	if err != nil {
+		return api.SyncTarget{}, fmt.Errorf("%s %s: %w; list them with: salja sync targets %s", serviceLabel(r.service), targetFlagFor(r.service), err, r.service)
+	}
+	return t, nil
+}
+
+// bindState returns the container a stateful sync (sync run or an
+// incremental pull) works against. The state remembers the container of its
+// first run, which an empty ref reuses; naming a different one is an error,
+// since the stored mappings and cursor belong to the old one.
+func (r *targetResolver) bindState(ctx context.Context, state *syncer.State, ref string) (string, error) {
+	if ref == "" && state.Container != "" {
+		return state.Container, nil
+	}
+	t, err := r.resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	bound := state.Container != "" && t.ID != state.Container
+	// state from before containers were recorded used the service default
+	legacy := state.Container == "" && (len(state.Mappings) > 0 || state.Cursor != "") && !t.Default
+	if bound || legacy {
+		return "", fmt.Errorf("%s is synced with a different %s %s; use another local file to sync with %q",
+			state.LocalPath, serviceLabel(r.service), targetFlagFor(r.service), ref)
+	}
+	state.Container = t.ID
+	return t.ID, nil
+}
+
+// listTargets returns the calendars, projects or databases of a service.
+func listTargets(ctx context.Context, service string, cfg *config.Config, token *api.Token, timeout time.Duration) ([]api.SyncTarget, error) {
+	var targets []api.SyncTarget
+	var err error
+	switch service {
+	case "google":
+		targets, err = api.NewGCalClientWithTimeout(token, timeout).Targets(ctx)
+		if err != nil {
+			break
+		}
+		lists, lerr := api.NewGTasksClientWithTimeout(token, timeout).Targets(ctx)
+		var apiErr *salerr.APIError
+		if errors.As(lerr, &apiErr) && apiErr.StatusCode == 403 {
+			fmt.Fprintln(os.Stderr, "Warning: not authorized for Google Tasks, listing calendars only; run: salja auth login google")
+		} else if lerr != nil {
+			return nil, fmt.Errorf("failed to list Google Tasks lists: %w", lerr)
+		}
+		targets = append(targets, lists...)
+	case "microsoft":
+		targets, err = api.NewMSGraphClientWithTimeout(token, timeout).Targets(ctx)
+	case "microsoft-todo":
+		targets, err = api.NewMSGraphClientWithTimeout(token, timeout).TodoTargets(ctx)
+	case "todoist":
+		targets, err = api.NewTodoistClientWithTimeout(token, timeout).Targets(ctx)
+	case "ticktick":
+		targets, err = api.NewTickTickClientWithTimeout(token, timeout).Targets(ctx)
+	case "notion":
+		targets, err = api.NewNotionClientWithTimeout(token.AccessToken, timeout).Targets(ctx)
+	case "caldav":
+		client, cerr := newCalDAVClient(cfg, timeout)
+		if cerr != nil {
+			return nil, cerr
+		}
+		targets, err = client.Targets(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported sync service %q", service)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s %ss: %w", serviceLabel(service), targetFlagFor(service), err)
+	}
+	return targets, nil
+}
+
+func serviceLabel(service string) string {
+	switch service {
+	case "google":
+		return "Google Calendar"
+	case "microsoft":
+		return "Microsoft Outlook"
+	case "microsoft-todo":
+		return "Microsoft To Do"
+	case "todoist":
+		return "Todoist"
+	case "ticktick":
+		return "TickTick"
+	case "notion":
+		return "Notion"
+	case "caldav":
+		return "CalDAV"
+	default:
+		return service
+	}
+}
+
+// googleTasksPrefix marks a google target that is a Google Tasks list rather
+// than a calendar. Calendar has no tasks, so pushes to a calendar send their
+// tasks to the default task list.
+const googleTasksPrefix = api.GTasksIDPrefix
+
+// pushRoute is the items of one push bound for one target.
+type pushRoute struct {
+	ref   string
+	items []model.CalendarItem
+}
+
+// routeItems groups items by the target they are pushed to, in order of
+// first appearance. An explicit ref sends everything there; otherwise each
+// item goes to the target mapped to its first routed tag, then to its item
+// type, then to sync_targets.<service>.default.
+func routeItems(cfg *config.Config, service, flagRef string, items []model.CalendarItem) []pushRoute {
+	var st config.SyncTargetConfig
+	if cfg != nil {
+		st = cfg.SyncTargets[service]
+	}
+	fallback := targetRef(cfg, service, flagRef)
+
+	var routes []pushRoute
+	index := make(map[string]int)
+	for _, item := range items {
+		ref := fallback
+		if flagRef == "" {
+			ref = routeFor(st, item, fallback)
+		}
+		k, ok := index[ref]
+		if !ok {
+			k = len(routes)
+			index[ref] = k
+			routes = append(routes, pushRoute{ref: ref})
+		}
+		routes[k].items = append(routes[k].items, item)
+	}
+	return routes
+}
+
+// routeGoogleTasks moves the tasks of routes bound for a calendar into one
+// route to the default Google Tasks list. Routes to a task list, named by
+// ID or by name, keep their tasks.
+func routeGoogleTasks(ctx context.Context, resolver *targetResolver, routes []pushRoute) ([]pushRoute, error) {
+	var out []pushRoute
+	var tasks []model.CalendarItem
+	for _, r := range routes {
+		t, err := resolver.resolve(ctx, r.ref)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(t.ID, googleTasksPrefix) {
+			out = append(out, r)
+			continue
+		}
+		var events []model.CalendarItem
+		for _, item := range r.items {
+			if item.ItemType == model.ItemTypeTask {
+				tasks = append(tasks, item)
+			} else {
+				events = append(events, item)
+			}
+		}
+		if len(events) > 0 {
+			out = append(out, pushRoute{ref: r.ref, items: events})
+		}
+	}
+	if len(tasks) == 0 {
+		return out, nil
+	}
+	def := googleTasksPrefix + api.GTasksDefaultList
+	for i := range out {
+		if out[i].ref == def {
+			out[i].items = append(out[i].items, tasks...)
+			return out, nil
+		}
+	}
+	return append(out, pushRoute{ref: def, items: tasks}), nil
+}
+
+func routeFor(st config.SyncTargetConfig, item model.CalendarItem, fallback string) string {
+	for _, tag := range item.Tags {
+		if ref, ok := st.Tags[tag]; ok {
+			return ref
+		}
+	}
+	if ref, ok := st.Types[string(item.ItemType)]; ok {
+		return ref
+	}
+	return fallback
+}
+
+func newSyncTargetsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "targets <service>",
+		Short: "List the calendars, projects or databases of a cloud service",
+		Long: `List the calendars, projects or databases of a cloud service.
+
+The names and IDs shown can be passed to --calendar, --project or --database
+on sync push, pull and run, or used under [sync_targets.<service>] in the
+config.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service := args[0]
+			if !supportedSyncServices[service] {
+				return fmt.Errorf("unsupported sync service %q; supported: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav", service)
+			}
+
+			cfg, cfgErr := config.Load()
+			if cfgErr != nil {
+				logging.Default().Warn("system", fmt.Sprintf("config load failed: %v", cfgErr))
+				fmt.Fprintf(os.Stderr, "Warning: config load failed, using defaults: %v\n", cfgErr)
+				cfg = config.DefaultConfig()
+			}
+			apiTimeout := 30 * time.Second
+			if cfg != nil && cfg.APITimeoutSeconds > 0 {
+				apiTimeout = time.Duration(cfg.APITimeoutSeconds) * time.Second
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			token, err := loadServiceToken(ctx, service, cfg)
+			if err != nil {
+				return err
+			}
+			targets, err := listTargets(ctx, service, cfg, token, apiTimeout)
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				fmt.Fprintf(os.Stderr, "No %s %ss found\n", serviceLabel(service), targetFlagFor(service))
+				return nil
+			}
+
+			fmt.Printf("%-44s %-8s %s\n", "ID", "DEFAULT", "NAME")
+			for _, t := range targets {
+				def := ""
+				if t.Default {
+					def = "yes"
+				}
+				fmt.Printf("%-44s %-8s %s\n", t.ID, def, t.Name)
+			}
+			return nil
+		},
+	}
+}
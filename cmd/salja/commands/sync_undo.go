@@ -0,0 +1,293 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gongahkia/salja/internal/api"
+	"github.com/gongahkia/salja/internal/config"
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/logging"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+func newSyncUndoCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "undo <run-id>",
+		Short: "Revert a sync push",
+		Long: `Revert a sync push recorded in its run journal.
+
+Items the push created are deleted and items it updated are restored to
+the state recorded before the push, newest first. Reverted items are
+marked in the journal, so an interrupted undo can simply be run again.
+Run IDs are listed by 'salja sync history'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := syncer.FindJournal(args[0])
+			if err != nil {
+				return err
+			}
+			journal, err := syncer.OpenJournal(path)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = journal.Close() }()
+
+			pending := journal.Pending()
+			if len(pending) == 0 {
+				fmt.Fprintf(os.Stderr, "Nothing to undo in run %s\n", journal.ID())
+				return nil
+			}
+
+			service := journal.Header.Service
+			if dryRun {
+				for _, e := range pending {
+					if e.Action == syncer.ActionCreated {
+						fmt.Printf("  [dry-run] would delete %s\n", e.RemoteID)
+					} else {
+						fmt.Printf("  [dry-run] would restore %s\n", e.RemoteID)
+					}
+				}
+				fmt.Fprintf(os.Stderr, "%d change(s) would be reverted in %s\n", len(pending), service)
+				return nil
+			}
+
+			cfg, cfgErr := config.Load()
+			if cfgErr != nil {
+				logging.Default().Warn("system", fmt.Sprintf("config load failed: %v", cfgErr))
+				fmt.Fprintf(os.Stderr, "Warning: config load failed, using defaults: %v\n", cfgErr)
+				cfg = config.DefaultConfig()
+			}
+			apiTimeout := 30 * time.Second
+			if cfg != nil && cfg.APITimeoutSeconds > 0 {
+				apiTimeout = time.Duration(cfg.APITimeoutSeconds) * time.Second
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			handler := salerr.NewCancelSignalHandler(cancel)
+			handler.Start()
+			defer handler.Stop()
+
+			token, err := loadServiceToken(ctx, service, cfg)
+			if err != nil {
+				return err
+			}
+
+			// a push routed by tags or type may span several containers
+			var containers []string
+			groups := make(map[string][]syncer.JournalEntry)
+			for _, e := range pending {
+				c := journal.ContainerOf(e)
+				if _, ok := groups[c]; !ok {
+					containers = append(containers, c)
+				}
+				groups[c] = append(groups[c], e)
+			}
+
+			rl := rateLimitFor(cfg, service)
+			exec := api.NewExecutor(rl)
+			pushCtx, cancelPush := context.WithTimeout(ctx, pushDeadline(cfg, 0, len(pending), rl))
+			defer cancelPush()
+			for _, c := range containers {
+				target, err := newPushTarget(ctx, service, cfg, token, api.SyncTarget{ID: c}, exec, false, apiTimeout)
+				if err != nil {
+					return err
+				}
+				if err := undoRun(pushCtx, target, journal, groups[c]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be reverted without making API calls")
+	return cmd
+}
+
+// undoResult is the outcome of reverting one journal entry.
+type undoResult struct {
+	done bool
+	err  error
+}
+
+// undoRun deletes created items and restores updated ones through the
+// target's executor, in batches where the service supports them, recording
+// each reverted entry in the journal.
+func undoRun(ctx context.Context, target *pushTarget, journal *syncer.Journal, pending []syncer.JournalEntry) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]undoResult, len(pending))
+	size := 1
+	if br, ok := target.remote.(batchRemote); ok {
+		size = br.BatchSize()
+	}
+
+	var journalErr error
+	deleted, restored, failed := 0, 0, 0
+	runChunks(runCtx, target.exec, len(pending), size, func(ctx context.Context, lo, hi int) error {
+		if br, ok := target.remote.(batchRemote); ok {
+			return undoBatch(ctx, br, pending[lo:hi], results[lo:hi])
+		}
+		results[lo] = undoEntry(ctx, target.remote, pending[lo])
+		return results[lo].err
+	}, func(lo, hi int, err error) {
+		for i := lo; i < hi; i++ {
+			e := pending[i]
+			if !results[i].done {
+				itemErr := results[i].err
+				if itemErr == nil {
+					itemErr = err
+				}
+				if itemErr != nil && journalErr == nil {
+					fmt.Fprintf(os.Stderr, "  ✗ Failed to revert %s (%v)\n", e.RemoteID, itemErr)
+				}
+				failed++
+				continue
+			}
+			if e.Action == syncer.ActionCreated {
+				deleted++
+			} else {
+				restored++
+			}
+			if journalErr == nil {
+				if err := journal.Record(e.UID, e.RemoteID, syncer.ActionUndone, nil); err != nil {
+					journalErr = err
+					cancel()
+				}
+			}
+		}
+	})
+	if journalErr != nil {
+		return journalErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Reverted run %s in %s: %d deleted, %d restored\n", journal.ID(), target.label, deleted, restored)
+	if failed > 0 {
+		return fmt.Errorf("%d change(s) could not be reverted; run 'salja sync undo %s' again to retry", failed, journal.ID())
+	}
+	return nil
+}
+
+// undoEntry reverts one journal entry.
+func undoEntry(ctx context.Context, remote pushRemote, e syncer.JournalEntry) undoResult {
+	var err error
+	switch {
+	case e.Action == syncer.ActionCreated:
+		err = remote.Delete(ctx, e.RemoteID)
+	case e.Before != nil:
+		_, err = remote.Update(ctx, e.RemoteID, restoredItem(e))
+	default:
+		err = errNoPriorState
+	}
+	return undoResult{done: err == nil, err: err}
+}
+
+// undoBatch is undoEntry for a batch of entries, with one batched delete and
+// one batched update. Entries already reverted are skipped, so a retried
+// batch only resends failed parts.
+func undoBatch(ctx context.Context, remote batchRemote, entries []syncer.JournalEntry, results []undoResult) error {
+	var deletes, restores []int
+	for k, e := range entries {
+		switch {
+		case results[k].done:
+		case e.Action == syncer.ActionCreated:
+			deletes = append(deletes, k)
+		case e.Before != nil:
+			restores = append(restores, k)
+		default:
+			results[k].err = errNoPriorState
+		}
+	}
+
+	if len(deletes) > 0 {
+		ids := make([]string, len(deletes))
+		for j, k := range deletes {
+			ids[j] = entries[k].RemoteID
+		}
+		res, err := remote.DeleteBatch(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, r := range res.Items {
+			results[deletes[r.Index]] = undoResult{done: true}
+		}
+		for _, e := range res.Errors {
+			results[deletes[e.Index]].err = e.Err
+		}
+	}
+
+	if len(restores) > 0 {
+		ids := make([]string, len(restores))
+		items := make([]model.CalendarItem, len(restores))
+		for j, k := range restores {
+			ids[j] = entries[k].RemoteID
+			items[j] = restoredItem(entries[k])
+		}
+		res, err := remote.UpdateBatch(ctx, ids, items)
+		if err != nil {
+			return err
+		}
+		for _, r := range res.Items {
+			results[restores[r.Index]] = undoResult{done: true}
+		}
+		for _, e := range res.Errors {
+			results[restores[e.Index]].err = e.Err
+		}
+	}
+	return nil
+}
+
+var errNoPriorState = errors.New("no prior state recorded")
+
+// restoredItem is the item an update entry replaced, keeping the source UID
+// stamp it carried before the push.
+func restoredItem(e syncer.JournalEntry) model.CalendarItem {
+	before := *e.Before
+	before.UID = e.UID
+	return before
+}
+
+func newSyncHistoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history",
+		Short: "List recorded sync push runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			journals, err := syncer.ListJournals()
+			if err != nil {
+				return err
+			}
+			if len(journals) == 0 {
+				fmt.Fprintf(os.Stderr, "No sync runs recorded in %s\n", syncer.JournalDir())
+				return nil
+			}
+
+			fmt.Printf("%-32s %-10s %-8s %-8s %-10s %s\n", "RUN ID", "SERVICE", "CREATED", "UPDATED", "STATUS", "SOURCE")
+			for _, j := range journals {
+				counts := j.Counts()
+				status := "done"
+				switch {
+				case counts[syncer.ActionUndone] > 0 && len(j.Pending()) == 0:
+					status = "undone"
+				case counts[syncer.ActionUndone] > 0:
+					status = "partial"
+				}
+				fmt.Printf("%-32s %-10s %-8d %-8d %-10s %s\n",
+					j.ID(), j.Header.Service, counts[syncer.ActionCreated], counts[syncer.ActionUpdated], status, j.Header.Source)
+			}
+			return nil
+		},
+	}
+}
@@ -47,6 +47,7 @@ func main() {
 
 	rootCmd.AddCommand(commands.NewConvertCmd())
 	rootCmd.AddCommand(commands.NewListFormatsCmd())
+	rootCmd.AddCommand(commands.NewSchemaCmd())
 	rootCmd.AddCommand(commands.NewValidateCmd())
 	rootCmd.AddCommand(commands.NewDiffCmd())
 	rootCmd.AddCommand(commands.NewConfigCmd())
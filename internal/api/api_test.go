@@ -1,16 +1,24 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"net/url"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	salerr "github.com/gongahkia/salja/internal/errors"
 	"github.com/gongahkia/salja/internal/model"
 )
 
@@ -148,12 +156,16 @@ func TestGCalMapper(t *testing.T) {
 	start := "2024-03-15T10:00:00Z"
 	end := "2024-03-15T11:00:00Z"
 	event := GCalEvent{
-		ID:        "gcal1",
-		Summary:   "Team Standup",
-		Location:  "Room 42",
-		Start:     &GCalDateTime{DateTime: start},
-		End:       &GCalDateTime{DateTime: end},
-		Attendees: []GCalAttendee{{Email: "alice@example.com"}},
+		ID:       "gcal1",
+		Summary:  "Team Standup",
+		Location: "Room 42",
+		Start:    &GCalDateTime{DateTime: start},
+		End:      &GCalDateTime{DateTime: end},
+		Attendees: []GCalAttendee{
+			{Email: "alice@example.com", DisplayName: "Alice", ResponseStatus: "accepted", Organizer: true},
+			{Email: "bob@example.com", ResponseStatus: "tentative", Optional: true},
+		},
+		Organizer: &GCalPerson{Email: "alice@example.com", DisplayName: "Alice"},
 	}
 
 	item := GCalToCalendarItem(event)
@@ -163,24 +175,37 @@ func TestGCalMapper(t *testing.T) {
 	if item.StartTime == nil {
 		t.Error("start time should be set")
 	}
-	if len(item.Tags) != 1 {
-		t.Errorf("expected 1 attendee tag, got %d", len(item.Tags))
+	if item.Organizer == nil || item.Organizer.Email != "alice@example.com" || len(item.Tags) != 0 {
+		t.Errorf("organizer, tags = %+v %v", item.Organizer, item.Tags)
+	}
+	if len(item.Attendees) != 2 || item.Attendees[0].Status != model.PartStatAccepted || item.Attendees[0].Name != "Alice" ||
+		item.Attendees[1].Role != model.RoleOptional || item.Attendees[1].Status != model.PartStatTentative {
+		t.Errorf("attendees = %+v", item.Attendees)
 	}
 
 	back := CalendarItemToGCal(item)
 	if back.Summary != "Team Standup" {
 		t.Errorf("roundtrip summary: got %q", back.Summary)
 	}
+	if back.Organizer != nil || len(back.Attendees) != 2 || !back.Attendees[1].Optional || back.Attendees[1].ResponseStatus != "tentative" {
+		t.Errorf("roundtrip attendees: got %+v %+v", back.Organizer, back.Attendees)
+	}
 }
 
 func TestMSGraphMapper(t *testing.T) {
 	event := MSGraphEvent{
-		ID:       "msg1",
-		Subject:  "Budget Review",
-		Start:    &MSGraphDateTime{DateTime: "2024-03-15T14:00:00.0000000", TimeZone: "UTC"},
-		End:      &MSGraphDateTime{DateTime: "2024-03-15T15:00:00.0000000", TimeZone: "UTC"},
-		Body:     &MSGraphBody{ContentType: "text", Content: "Q1 numbers"},
-		Location: &MSGraphLocation{DisplayName: "Board Room"},
+		ID:        "msg1",
+		Subject:   "Budget Review",
+		Start:     &MSGraphDateTime{DateTime: "2024-03-15T14:00:00.0000000", TimeZone: "UTC"},
+		End:       &MSGraphDateTime{DateTime: "2024-03-15T15:00:00.0000000", TimeZone: "UTC"},
+		Body:      &MSGraphBody{ContentType: "text", Content: "Q1 numbers"},
+		Location:  &MSGraphLocation{DisplayName: "Board Room"},
+		Organizer: &MSGraphRecipient{EmailAddress: MSGraphEmailAddress{Name: "Dana", Address: "dana@example.com"}},
+		Attendees: []MSGraphAttendee{
+			{Type: "required", Status: &MSGraphResponseStatus{Response: "accepted"}, EmailAddress: MSGraphEmailAddress{Address: "eli@example.com"}},
+			{Type: "optional", Status: &MSGraphResponseStatus{Response: "notResponded"}, EmailAddress: MSGraphEmailAddress{Name: "Fay", Address: "fay@example.com"}},
+		},
+		ResponseRequested: true,
 	}
 
 	item := MSGraphToCalendarItem(event)
@@ -191,10 +216,44 @@ func TestMSGraphMapper(t *testing.T) {
 		t.Errorf("location: got %q", item.Location)
 	}
 
+	if item.Organizer == nil || item.Organizer.Name != "Dana" || len(item.Attendees) != 2 {
+		t.Fatalf("organizer, attendees = %+v %+v", item.Organizer, item.Attendees)
+	}
+	if fay := item.Attendees[1]; fay.Role != model.RoleOptional || fay.Status != model.PartStatNeedsAction || !fay.RSVP {
+		t.Errorf("optional attendee = %+v", fay)
+	}
+
 	back := CalendarItemToMSGraph(item)
 	if back.Subject != "Budget Review" {
 		t.Errorf("roundtrip: got %q", back.Subject)
 	}
+	if len(back.Attendees) != 2 || back.Attendees[1].Type != "optional" || back.Attendees[1].EmailAddress.Name != "Fay" || back.Organizer != nil {
+		t.Errorf("roundtrip attendees: got %+v", back.Attendees)
+	}
+}
+
+func TestMSGraphWindowsTimezones(t *testing.T) {
+	event := MSGraphEvent{
+		ID:      "msg2",
+		Subject: "Planning",
+		Start:   &MSGraphDateTime{DateTime: "2026-07-01T09:00:00.0000000", TimeZone: "W. Europe Standard Time"},
+		End:     &MSGraphDateTime{DateTime: "2026-07-01T10:00:00.0000000", TimeZone: "W. Europe Standard Time"},
+	}
+	item := MSGraphToCalendarItem(event)
+	if item.Timezone != "Europe/Berlin" || !item.StartTime.Equal(time.Date(2026, 7, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("zone %q, start %v", item.Timezone, item.StartTime)
+	}
+
+	// times are sent on the zone's wall clock, under its Windows name
+	start := time.Date(2026, 7, 1, 16, 0, 0, 0, time.UTC)
+	back := CalendarItemToMSGraph(model.CalendarItem{Title: "Call", StartTime: &start, Timezone: "America/New_York"})
+	if back.Start.TimeZone != "Eastern Standard Time" || back.Start.DateTime != "2026-07-01T12:00:00.0000000" {
+		t.Errorf("start = %+v", back.Start)
+	}
+	unmapped := CalendarItemToMSGraph(model.CalendarItem{Title: "Call", StartTime: &start})
+	if unmapped.Start.TimeZone != "UTC" || unmapped.Start.DateTime != "2026-07-01T16:00:00.0000000" {
+		t.Errorf("start = %+v", unmapped.Start)
+	}
 }
 
 func TestNotionMapper(t *testing.T) {
@@ -541,3 +600,1554 @@ func TestNotionQueryDatabase429(t *testing.T) {
 		t.Errorf("error should contain rate limit message: %v", err)
 	}
 }
+
+func TestTodoistRemoteUpdateCloseDelete(t *testing.T) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case "POST":
+			if strings.HasSuffix(r.URL.Path, "/close") {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(TodoistTask{ID: "t1", Content: "Renamed"})
+		case "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewTodoistClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	remote := &TodoistRemote{Client: client}
+
+	updated, err := remote.Update(context.Background(), "t1", model.CalendarItem{
+		UID:    "local-1",
+		Title:  "Renamed",
+		Status: model.StatusCompleted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.UID != "t1" || updated.Title != "Renamed" {
+		t.Errorf("unexpected updated item: %+v", updated)
+	}
+	if err := remote.Delete(context.Background(), "t1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"POST /rest/v2/tasks/t1", "POST /rest/v2/tasks/t1/close", "DELETE /rest/v2/tasks/t1"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls: got %v, want %v", calls, want)
+	}
+}
+
+func TestMSGraphDeleteEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || !strings.HasSuffix(r.URL.Path, "/me/events/ev-1") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	if err := client.DeleteEvent(context.Background(), "ev-1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGTasksMapper(t *testing.T) {
+	tasks := []GTask{
+		{ID: "t1", Title: "Plan trip", Notes: "Book early\n\nsalja-uid: trip-1", Status: "needsAction", Due: "2026-05-01T00:00:00.000Z"},
+		{ID: "s2", Title: "Hotel", Status: "needsAction", Parent: "t1", Position: "00000000000000000001"},
+		{ID: "s1", Title: "Flights", Status: "completed", Parent: "t1", Position: "00000000000000000000"},
+		{ID: "t2", Title: "Renew passport", Status: "completed", Completed: "2026-03-02T10:00:00.000Z"},
+	}
+	items := GTasksToCalendarItems(tasks)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want subtasks folded into 2", len(items))
+	}
+	trip := items[0]
+	if trip.Description != "Book early" || GTasksSourceUID(tasks[0]) != "trip-1" {
+		t.Errorf("description %q, source UID %q", trip.Description, GTasksSourceUID(tasks[0]))
+	}
+	if trip.DueDate == nil || trip.DueDate.Format("2006-01-02") != "2026-05-01" {
+		t.Errorf("due = %v", trip.DueDate)
+	}
+	if len(trip.Subtasks) != 2 || trip.Subtasks[0].Title != "Flights" || trip.Subtasks[0].Status != model.StatusCompleted {
+		t.Errorf("subtasks = %+v", trip.Subtasks)
+	}
+	if items[1].Status != model.StatusCompleted || items[1].CompletionDate == nil {
+		t.Errorf("completed task = %+v", items[1])
+	}
+
+	trip.UID = "trip-1"
+	back := CalendarItemToGTask(trip)
+	if back.Notes != tasks[0].Notes || back.Due != tasks[0].Due || back.Status != "needsAction" {
+		t.Errorf("back = %+v", back)
+	}
+	subs := GTaskSubtasks(trip)
+	if len(subs) != 2 || subs[0].Status != "completed" || subs[1].Title != "Hotel" {
+		t.Errorf("subtasks back = %+v", subs)
+	}
+}
+
+func TestGTasksRemote(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/tasks/v1/lists/@default/tasks")
+		requests = append(requests, strings.TrimSpace(r.Method+" "+path+" "+r.URL.Query().Get("parent")+" "+r.URL.Query().Get("previous")))
+		var task GTask
+		_ = json.NewDecoder(r.Body).Decode(&task)
+		switch {
+		case r.Method == "GET":
+			if r.URL.Query().Get("showHidden") != "true" {
+				t.Errorf("hidden tasks not listed: %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": []GTask{
+				{ID: "t1", Title: "Plan trip", Notes: "salja-uid: trip-1"},
+				{ID: "s1", Title: "Flights", Parent: "t1", Position: "1"},
+				{ID: "s2", Title: "Hotel", Parent: "t1", Position: "2"},
+			}})
+		case r.Method == "POST":
+			task.ID = "new-" + task.Title
+			_ = json.NewEncoder(w).Encode(task)
+		case r.Method == "PATCH":
+			task.ID = strings.TrimPrefix(path, "/")
+			_ = json.NewEncoder(w).Encode(task)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewGTasksClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	remote := &GTasksRemote{Client: client, ListID: GTasksDefaultList}
+	ctx := context.Background()
+
+	created, err := remote.Create(ctx, model.CalendarItem{UID: "milk-1", Title: "Shop", ItemType: model.ItemTypeTask,
+		Subtasks: []model.Subtask{{Title: "Milk"}, {Title: "Eggs"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UID != "new-Shop" || len(created.Subtasks) != 2 || created.Subtasks[1].Title != "Eggs" {
+		t.Errorf("created = %+v", created)
+	}
+
+	found, err := remote.Lookup(ctx, "trip-1")
+	if err != nil || found == nil || found.UID != "t1" || len(found.Subtasks) != 2 {
+		t.Fatalf("lookup: %+v, %v", found, err)
+	}
+	updated, err := remote.Update(ctx, "t1", model.CalendarItem{UID: "trip-1", Title: "Plan trip", Status: model.StatusCompleted,
+		Subtasks: []model.Subtask{{Title: "Flights", Status: model.StatusCompleted}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.IsCompleted() || len(updated.Subtasks) != 1 || updated.Subtasks[0].Status != model.StatusCompleted {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	want := []string{
+		"POST", "POST  new-Shop", "POST  new-Shop new-Milk",
+		"GET",
+		"PATCH /t1", "PATCH /s1", "DELETE /s2",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %q\nwant %q", requests, want)
+	}
+}
+
+func TestGoogleRemote(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		tasks := strings.HasPrefix(r.URL.Path, "/tasks/v1/lists/L2/tasks")
+		switch {
+		case r.Method == "GET" && tasks:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": []GTask{{ID: "t1", Title: "Pay rent"}}})
+		case r.Method == "GET":
+			_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{{ID: "e1", Summary: "Dentist",
+				Start: &GCalDateTime{DateTime: "2026-03-02T10:00:00Z"}, End: &GCalDateTime{DateTime: "2026-03-02T11:00:00Z"}}}})
+		case r.Method == "POST" && tasks:
+			_ = json.NewEncoder(w).Encode(GTask{ID: "t2", Title: "Buy milk"})
+		case r.Method == "PATCH" && tasks:
+			_ = json.NewEncoder(w).Encode(GTask{ID: "t1", Title: "Pay rent", Status: "completed"})
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	gcal := NewGCalClient(newTestToken())
+	gcal.httpClient = redirectClient(ts)
+	gtasks := NewGTasksClient(newTestToken())
+	gtasks.httpClient = redirectClient(ts)
+	remote := &GoogleRemote{
+		Events: &GCalRemote{Client: gcal, CalendarID: "primary"},
+		Tasks:  &GTasksRemote{Client: gtasks, ListID: "L2"},
+	}
+	ctx := context.Background()
+
+	items, err := remote.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].UID != "e1" || items[1].UID != "tasks:t1" {
+		t.Fatalf("listed %+v", items)
+	}
+	created, err := remote.Create(ctx, model.CalendarItem{Title: "Buy milk", ItemType: model.ItemTypeTask})
+	if err != nil || created.UID != "tasks:t2" {
+		t.Errorf("created %+v, %v", created, err)
+	}
+	updated, err := remote.Update(ctx, "tasks:t1", model.CalendarItem{Title: "Pay rent", ItemType: model.ItemTypeTask, Status: model.StatusCompleted})
+	if err != nil || updated.UID != "tasks:t1" || !updated.IsCompleted() {
+		t.Errorf("updated %+v, %v", updated, err)
+	}
+	if err := remote.Delete(ctx, "tasks:t1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Delete(ctx, "e1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /calendar/v3/calendars/primary/events", "GET /tasks/v1/lists/L2/tasks",
+		"POST /tasks/v1/lists/L2/tasks",
+		"PATCH /tasks/v1/lists/L2/tasks/t1",
+		"DELETE /tasks/v1/lists/L2/tasks/t1", "DELETE /calendar/v3/calendars/primary/events/e1",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %q\nwant %q", requests, want)
+	}
+}
+
+func TestGTasksTargets(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tasks/v1/users/@me/lists" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": []GTaskList{{ID: "L1", Title: "My Tasks"}, {ID: "L2", Title: "Errands"}}})
+	}))
+	defer ts.Close()
+
+	client := NewGTasksClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	targets, err := client.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target, err := ResolveTarget(targets, "Errands"); err != nil || target.ID != "tasks:L2" {
+		t.Errorf("Errands resolved to %+v, %v", target, err)
+	}
+}
+
+func TestGoogleRemoteWithoutTasksScope(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/tasks/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GCalEventList{})
+	}))
+	defer ts.Close()
+
+	gcal := NewGCalClient(newTestToken())
+	gcal.httpClient = redirectClient(ts)
+	gtasks := NewGTasksClient(newTestToken())
+	gtasks.httpClient = redirectClient(ts)
+	remote := &GoogleRemote{
+		Events: &GCalRemote{Client: gcal, CalendarID: "primary"},
+		Tasks:  &GTasksRemote{Client: gtasks, ListID: GTasksDefaultList},
+	}
+	if _, err := remote.List(context.Background()); err != nil {
+		t.Fatalf("list without the tasks scope: %v", err)
+	}
+	_, err := remote.Create(context.Background(), model.CalendarItem{Title: "Buy milk", ItemType: model.ItemTypeTask})
+	if err == nil || !strings.Contains(err.Error(), "salja auth login google") {
+		t.Errorf("creating a task without the tasks scope: %v", err)
+	}
+}
+
+func TestMSGraphTodoMapper(t *testing.T) {
+	task := MSGraphTodoTask{
+		ID:               "AAMk-task",
+		Title:            "Submit expenses",
+		Body:             &MSGraphBody{ContentType: "text", Content: "Receipts in the drawer\n\nsalja-uid: local-1"},
+		Status:           "inProgress",
+		Importance:       "high",
+		Categories:       []string{"Finance"},
+		DueDateTime:      &MSGraphDateTime{DateTime: "2026-03-31T00:00:00.0000000", TimeZone: "UTC"},
+		IsReminderOn:     true,
+		ReminderDateTime: &MSGraphDateTime{DateTime: "2026-03-30T09:00:00.0000000", TimeZone: "UTC"},
+		Recurrence: &MSGraphRecurrence{
+			Pattern: &MSGraphRecurrencePattern{Type: "relativeMonthly", Interval: 1, DaysOfWeek: []string{"friday"}, Index: "last"},
+			Range:   &MSGraphRecurrenceRange{Type: "numbered", StartDate: "2026-03-31", NumberOfOccurrences: 6},
+		},
+		ChecklistItems: []MSGraphChecklistItem{
+			{ID: "c1", DisplayName: "Scan receipts", IsChecked: true},
+			{ID: "c2", DisplayName: "Fill in the form"},
+		},
+	}
+
+	item := MSGraphTodoToCalendarItem(task)
+	if item.ItemType != model.ItemTypeTask || item.Status != model.StatusInProgress || item.Priority != model.PriorityHigh {
+		t.Errorf("type, status, priority = %s, %s, %d", item.ItemType, item.Status, item.Priority)
+	}
+	if item.Description != "Receipts in the drawer" || MSGraphTodoSourceUID(task) != "local-1" {
+		t.Errorf("description %q, source UID %q", item.Description, MSGraphTodoSourceUID(task))
+	}
+	if item.DueDate == nil || !item.DueDate.Equal(time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due = %v", item.DueDate)
+	}
+	if len(item.Reminders) != 1 || item.Reminders[0].AbsoluteTime == nil || item.Reminders[0].AbsoluteTime.Hour() != 9 {
+		t.Errorf("reminders = %+v", item.Reminders)
+	}
+	rec := item.Recurrence
+	if rec == nil || rec.Freq != model.FreqMonthly || len(rec.ByDay) != 1 || rec.ByDay[0] != model.WeekdayFR ||
+		len(rec.BySetPos) != 1 || rec.BySetPos[0] != -1 || rec.Count == nil || *rec.Count != 6 {
+		t.Errorf("recurrence = %+v", rec)
+	}
+	if len(item.Subtasks) != 2 || item.Subtasks[0].Status != model.StatusCompleted || item.Subtasks[1].Title != "Fill in the form" {
+		t.Errorf("subtasks = %+v", item.Subtasks)
+	}
+
+	item.UID = "local-1"
+	back := CalendarItemToMSGraphTodo(item)
+	if back.Status != "inProgress" || back.Importance != "high" || !back.IsReminderOn {
+		t.Errorf("status, importance, reminder = %s, %s, %t", back.Status, back.Importance, back.IsReminderOn)
+	}
+	if back.Body == nil || back.Body.Content != task.Body.Content {
+		t.Errorf("body = %+v", back.Body)
+	}
+	if back.DueDateTime.DateTime != "2026-03-31T00:00:00.0000000" || back.ReminderDateTime.DateTime != "2026-03-30T09:00:00.0000000" {
+		t.Errorf("due %+v, reminder %+v", back.DueDateTime, back.ReminderDateTime)
+	}
+	if p := back.Recurrence.Pattern; p.Type != "relativeMonthly" || p.Index != "last" || len(p.DaysOfWeek) != 1 || p.DaysOfWeek[0] != "friday" {
+		t.Errorf("pattern = %+v", p)
+	}
+	if r := back.Recurrence.Range; r.Type != "numbered" || r.NumberOfOccurrences != 6 || r.StartDate != "2026-03-31" {
+		t.Errorf("range = %+v", r)
+	}
+	if len(back.ChecklistItems) != 2 || !back.ChecklistItems[0].IsChecked {
+		t.Errorf("checklist = %+v", back.ChecklistItems)
+	}
+}
+
+func TestMSGraphTodoRecurrence(t *testing.T) {
+	anchor := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		rec     model.Recurrence
+		pattern MSGraphRecurrencePattern
+	}{
+		{model.Recurrence{Freq: model.FreqDaily, Interval: 2}, MSGraphRecurrencePattern{Type: "daily", Interval: 2}},
+		{model.Recurrence{Freq: model.FreqWeekly}, MSGraphRecurrencePattern{Type: "weekly", Interval: 1, DaysOfWeek: []string{"wednesday"}}},
+		{model.Recurrence{Freq: model.FreqDaily, ByDay: []model.Weekday{model.WeekdayMO, model.WeekdayFR}},
+			MSGraphRecurrencePattern{Type: "weekly", Interval: 1, DaysOfWeek: []string{"monday", "friday"}}},
+		{model.Recurrence{Freq: model.FreqMonthly}, MSGraphRecurrencePattern{Type: "absoluteMonthly", Interval: 1, DayOfMonth: 11}},
+		{model.Recurrence{Freq: model.FreqYearly, ByMonth: []int{4}, ByMonthDay: []int{1}},
+			MSGraphRecurrencePattern{Type: "absoluteYearly", Interval: 1, Month: 4, DayOfMonth: 1}},
+		{model.Recurrence{Freq: model.FreqYearly, ByMonth: []int{11}, ByDay: []model.Weekday{model.WeekdayTH}, BySetPos: []int{4}},
+			MSGraphRecurrencePattern{Type: "relativeYearly", Interval: 1, Month: 11, DaysOfWeek: []string{"thursday"}, Index: "fourth"}},
+	}
+	for _, tt := range tests {
+		got := recurrenceToMSGraph(&tt.rec, anchor)
+		if got == nil || fmt.Sprintf("%+v", *got.Pattern) != fmt.Sprintf("%+v", tt.pattern) {
+			t.Errorf("%+v: pattern = %+v, want %+v", tt.rec, got.Pattern, tt.pattern)
+			continue
+		}
+		back := msGraphToRecurrence(got)
+		if back.Freq != tt.rec.Freq && !(tt.rec.Freq == model.FreqDaily && back.Freq == model.FreqWeekly) {
+			t.Errorf("%+v: read back as %+v", tt.rec, back)
+		}
+	}
+
+	until := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	got := recurrenceToMSGraph(&model.Recurrence{Freq: model.FreqWeekly, Until: &until}, anchor)
+	if got.Range.Type != "endDate" || got.Range.EndDate != "2026-12-31" {
+		t.Errorf("range = %+v", got.Range)
+	}
+	if back := msGraphToRecurrence(got); back.Until == nil || !back.Until.Equal(until) {
+		t.Errorf("until read back as %v", back.Until)
+	}
+}
+
+func TestMSGraphTodoRemote(t *testing.T) {
+	var requests []string
+	var patched MSGraphTodoTask
+	var patchBody map[string]json.RawMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/v1.0/me/todo/lists/L1/tasks"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1.0/me/todo/lists":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []MSGraphTodoList{
+					{ID: "L1", DisplayName: "Tasks", WellknownListName: "defaultList"},
+					{ID: "L2", DisplayName: "Groceries", WellknownListName: "none"},
+				},
+			})
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/tasks"):
+			if r.URL.Query().Get("$expand") != "checklistItems" {
+				t.Errorf("tasks listed without checklist items: %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []MSGraphTodoTask{{ID: "T1", Title: "Pay rent", Status: "completed",
+					Body: &MSGraphBody{Content: "salja-uid: rent-1"}}},
+			})
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/tasks"):
+			var task MSGraphTodoTask
+			_ = json.NewDecoder(r.Body).Decode(&task)
+			if task.ID != "" || len(task.ChecklistItems) > 0 {
+				t.Errorf("created with ID %q and %d checklist items", task.ID, len(task.ChecklistItems))
+			}
+			task.ID = "T2"
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(task)
+		case r.Method == "PATCH" && strings.HasSuffix(r.URL.Path, "/tasks/T1"):
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &patchBody)
+			_ = json.Unmarshal(data, &patched)
+			patched.ID = "T1"
+			_ = json.NewEncoder(w).Encode(patched)
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/T1/checklistItems"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []MSGraphChecklistItem{{ID: "c1", DisplayName: "Transfer"}, {ID: "c2", DisplayName: "File receipt"}},
+			})
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/checklistItems"):
+			var ci MSGraphChecklistItem
+			_ = json.NewDecoder(r.Body).Decode(&ci)
+			ci.ID = "c-new"
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(ci)
+		case r.Method == "PATCH" && strings.HasSuffix(r.URL.Path, "/checklistItems/c1"):
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == "DELETE" && strings.HasSuffix(r.URL.Path, "/checklistItems/c2"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	ctx := context.Background()
+
+	targets, err := client.TodoTargets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def, _ := ResolveTarget(targets, ""); def.ID != "L1" {
+		t.Errorf("default list = %+v", def)
+	}
+
+	remote := &MSGraphTodoRemote{Client: client, ListID: "L1"}
+	items, err := remote.List(ctx)
+	if err != nil || len(items) != 1 || !items[0].IsCompleted() {
+		t.Fatalf("list: %+v, %v", items, err)
+	}
+	found, err := remote.Lookup(ctx, "rent-1")
+	if err != nil || found == nil || found.UID != "T1" {
+		t.Fatalf("lookup: %+v, %v", found, err)
+	}
+
+	created, err := remote.Create(ctx, model.CalendarItem{UID: "milk-1", Title: "Buy milk",
+		Subtasks: []model.Subtask{{Title: "Oat"}, {Title: "Whole"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UID != "T2" || len(created.Subtasks) != 2 {
+		t.Errorf("created = %+v", created)
+	}
+
+	updated, err := remote.Update(ctx, "T1", model.CalendarItem{UID: "rent-1", Title: "Pay rent", Status: model.StatusCompleted,
+		Subtasks: []model.Subtask{{Title: "Transfer", Status: model.StatusCompleted}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.Status != "completed" || len(updated.Subtasks) != 1 || updated.Subtasks[0].Status != model.StatusCompleted {
+		t.Errorf("patched %+v, updated %+v", patched, updated)
+	}
+	// the update has no due date or reminder, so both are cleared
+	for key, want := range map[string]string{"dueDateTime": "null", "reminderDateTime": "null", "isReminderOn": "false", "title": `"Pay rent"`} {
+		if got := string(patchBody[key]); got != want {
+			t.Errorf("PATCH %s = %s, want %s", key, got, want)
+		}
+	}
+	if _, ok := patchBody["id"]; ok {
+		t.Error("PATCH body carries the task id")
+	}
+
+	want := []string{
+		"GET /v1.0/me/todo/lists", "GET ", "GET ",
+		"POST ", "POST /T2/checklistItems", "POST /T2/checklistItems",
+		"PATCH /T1", "GET /T1/checklistItems", "PATCH /T1/checklistItems/c1", "DELETE /T1/checklistItems/c2",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %q\nwant %q", requests, want)
+	}
+}
+
+func TestMSGraphCompleteTodoTask(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/v1.0/me/todo/lists/L1/tasks/T1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		data, _ := io.ReadAll(r.Body)
+		body = strings.TrimSpace(string(data))
+		_, _ = w.Write([]byte(`{"id":"T1","title":"Pay rent","status":"completed"}`))
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	if err := client.CompleteTodoTask(context.Background(), "L1", "T1"); err != nil {
+		t.Fatal(err)
+	}
+	if body != `{"status":"completed"}` {
+		t.Errorf("PATCH body = %s", body)
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	targets := []SyncTarget{
+		{ID: "cal-1", Name: "Personal", Default: true},
+		{ID: "cal-2", Name: "Work"},
+		{ID: "cal-3", Name: "work"},
+		{ID: "cal-4", Name: "Holidays"},
+	}
+	tests := []struct {
+		ref, want string
+		wantErr   bool
+	}{
+		{ref: "", want: "cal-1"},
+		{ref: "cal-2", want: "cal-2"},
+		{ref: "HOLIDAYS", want: "cal-4"},
+		{ref: "Work", wantErr: true},
+		{ref: "Missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveTarget(targets, tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ResolveTarget(%q) = %+v, want error", tt.ref, got)
+			}
+			continue
+		}
+		if err != nil || got.ID != tt.want {
+			t.Errorf("ResolveTarget(%q) = %+v, %v; want %s", tt.ref, got, err, tt.want)
+		}
+	}
+	if _, err := ResolveTarget([]SyncTarget{{ID: "p1", Name: "Inbox"}}, ""); err == nil {
+		t.Error("expected an error when no target is the default")
+	}
+}
+
+func TestMSGraphTargetsAndCalendarPaths(t *testing.T) {
+	var created string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/me/calendars"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []MSGraphCalendar{
+					{ID: "AQMk1", Name: "Calendar", IsDefaultCalendar: true},
+					{ID: "AQMk2", Name: "Team"},
+				},
+			})
+		case r.Method == "POST":
+			created = r.URL.Path
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(MSGraphEvent{ID: "ev-1"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	targets, err := client.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := ResolveTarget(targets, "team")
+	if err != nil || target.ID != "AQMk2" {
+		t.Fatalf("resolve team: %+v, %v", target, err)
+	}
+	if def, _ := ResolveTarget(targets, ""); def.ID != "AQMk1" {
+		t.Errorf("default calendar = %+v", def)
+	}
+
+	if _, err := client.CreateEvent(context.Background(), target.ID, &MSGraphEvent{Subject: "Standup"}); err != nil {
+		t.Fatal(err)
+	}
+	if created != "/v1.0/me/calendars/AQMk2/events" {
+		t.Errorf("created in %s", created)
+	}
+}
+
+func TestNotionTargetsListsDatabases(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "POST" || !strings.HasSuffix(r.URL.Path, "/search") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if calls == 1 {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"results":     []map[string]interface{}{{"id": "db-1", "title": []map[string]string{{"plain_text": "Tasks"}}}},
+				"has_more":    true,
+				"next_cursor": "c2",
+			})
+			return
+		}
+		if body["start_cursor"] != "c2" {
+			t.Errorf("second page should send the cursor: %v", body)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]interface{}{{"id": "db-2", "title": []map[string]string{{"plain_text": "Reading "}, {"plain_text": "list"}}}},
+		})
+	}))
+	defer ts.Close()
+
+	client := NewNotionClient("test-notion-token")
+	client.httpClient = redirectClient(ts)
+	targets, err := client.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[1].Name != "Reading list" {
+		t.Fatalf("targets: %+v", targets)
+	}
+	if got, err := ResolveTarget(targets, "reading list"); err != nil || got.ID != "db-2" {
+		t.Errorf("resolve: %+v, %v", got, err)
+	}
+}
+
+func TestNotionRemoteUpdateAndArchive(t *testing.T) {
+	var archived bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || !strings.HasSuffix(r.URL.Path, "/pages/page-1") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["archived"] == true {
+			archived = true
+		}
+		_ = json.NewEncoder(w).Encode(NotionPage{ID: "page-1"})
+	}))
+	defer ts.Close()
+
+	client := NewNotionClient("test-notion-token")
+	client.httpClient = redirectClient(ts)
+	remote := &NotionRemote{Client: client, DatabaseID: "db-1", PropertyMap: DefaultNotionPropertyMap()}
+
+	updated, err := remote.Update(context.Background(), "page-1", model.CalendarItem{Title: "Edited", Status: model.StatusPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.UID != "page-1" || updated.Title != "Edited" {
+		t.Errorf("unexpected updated item: UID=%q Title=%q", updated.UID, updated.Title)
+	}
+	if err := remote.Delete(context.Background(), "page-1"); err != nil {
+		t.Fatal(err)
+	}
+	if !archived {
+		t.Error("expected page to be archived")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Incremental change feeds
+// ---------------------------------------------------------------------------
+
+func TestGCalChangesSyncToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch q.Get("syncToken") {
+		case "":
+			if q.Get("timeMin") == "" {
+				t.Error("initial fetch should be bounded by timeMin")
+			}
+			if q.Get("pageToken") == "" {
+				_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{{ID: "e1", Summary: "One"}}, NextPageToken: "p2"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{{ID: "e2", Summary: "Two"}}, NextSyncToken: "tok-1"})
+		case "tok-1":
+			if q.Get("timeMin") != "" {
+				t.Error("timeMin must not be sent with a sync token")
+			}
+			_ = json.NewEncoder(w).Encode(GCalEventList{
+				Items:         []GCalEvent{{ID: "e1", Summary: "One (edited)"}, {ID: "e2", Status: "cancelled"}},
+				NextSyncToken: "tok-2",
+			})
+		default:
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewGCalClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	full, err := client.Changes(ctx, "primary", "", start, start.AddDate(0, 3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !full.Full || len(full.Items) != 2 || full.Cursor != "tok-1" || !full.Present["e2"] {
+		t.Errorf("unexpected full fetch: %+v", full)
+	}
+
+	delta, err := client.Changes(ctx, "primary", full.Cursor, start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.Full || delta.Present != nil {
+		t.Error("delta should not be a full listing")
+	}
+	if len(delta.Items) != 1 || delta.Items[0].Title != "One (edited)" {
+		t.Errorf("items: %+v", delta.Items)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != "e2" || delta.Cursor != "tok-2" {
+		t.Errorf("removed=%v cursor=%q", delta.Removed, delta.Cursor)
+	}
+
+	if _, err := client.Changes(ctx, "primary", "stale", start, start); !errors.Is(err, ErrCursorExpired) {
+		t.Errorf("expected ErrCursorExpired, got %v", err)
+	}
+}
+
+func TestGCalChangesRefetchesSeries(t *testing.T) {
+	master := GCalEvent{ID: "weekly", Summary: "Sync",
+		Start:      &GCalDateTime{DateTime: "2026-03-04T09:00:00Z"},
+		End:        &GCalDateTime{DateTime: "2026-03-04T10:00:00Z"},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY;COUNT=6"}}
+	moved := GCalEvent{ID: "weekly_20260311T090000Z", RecurringEventID: "weekly", Summary: "Sync",
+		OriginalStartTime: &GCalDateTime{DateTime: "2026-03-11T09:00:00Z"},
+		Start:             &GCalDateTime{DateTime: "2026-03-12T15:00:00Z"}, End: &GCalDateTime{DateTime: "2026-03-12T16:00:00Z"}}
+	cancelled := GCalEvent{ID: "weekly_20260318T090000Z", RecurringEventID: "weekly", Status: "cancelled",
+		OriginalStartTime: &GCalDateTime{DateTime: "2026-03-18T09:00:00Z"}}
+	unchanged := GCalEvent{ID: "weekly_20260325T090000Z", RecurringEventID: "weekly", Summary: "Sync",
+		OriginalStartTime: &GCalDateTime{DateTime: "2026-03-25T09:00:00Z"},
+		Start:             &GCalDateTime{DateTime: "2026-03-25T09:00:00Z"}, End: &GCalDateTime{DateTime: "2026-03-25T10:00:00Z"}}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/calendar/v3/calendars/primary/events":
+			_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{moved, cancelled}, NextSyncToken: "tok-2"})
+		case "/calendar/v3/calendars/primary/events/weekly":
+			_ = json.NewEncoder(w).Encode(master)
+		case "/calendar/v3/calendars/primary/events/weekly/instances":
+			if r.URL.Query().Get("showDeleted") != "true" {
+				t.Error("instances should be listed with showDeleted")
+			}
+			_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{moved, cancelled, unchanged}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewGCalClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	delta, err := client.Changes(context.Background(), "primary", "tok-1", start, start.AddDate(0, 3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delta.Items) != 1 || delta.Items[0].UID != "weekly" {
+		t.Fatalf("items = %+v", delta.Items)
+	}
+	rec := delta.Items[0].Recurrence
+	if rec == nil || len(rec.Overrides) != 1 || len(rec.ExDates) != 1 {
+		t.Fatalf("recurrence = %+v", rec)
+	}
+	if !rec.ExDates[0].Equal(time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("exdate = %v", rec.ExDates[0])
+	}
+	ov := rec.Override(time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC))
+	if ov == nil || ov.Item.StartTime.Day() != 12 {
+		t.Errorf("override = %+v", ov)
+	}
+}
+
+func TestMSGraphChangesDelta(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/me/calendarView/delta") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch {
+		case r.URL.Query().Get("$skiptoken") == "" && r.URL.Query().Get("$deltatoken") == "":
+			_, _ = w.Write([]byte(`{"value":[{"id":"m1","subject":"Standup"}],
+				"@odata.nextLink":"https://graph.microsoft.com/v1.0/me/calendarView/delta?$skiptoken=s1"}`))
+		case r.URL.Query().Get("$skiptoken") == "s1":
+			_, _ = w.Write([]byte(`{"value":[{"id":"m2","subject":"Review"}],
+				"@odata.deltaLink":"https://graph.microsoft.com/v1.0/me/calendarView/delta?$deltatoken=d1"}`))
+		case r.URL.Query().Get("$deltatoken") == "d1":
+			_, _ = w.Write([]byte(`{"value":[{"id":"m1","@removed":{"reason":"deleted"}}],
+				"@odata.deltaLink":"https://graph.microsoft.com/v1.0/me/calendarView/delta?$deltatoken=d2"}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	ctx := context.Background()
+	now := time.Now()
+
+	full, err := client.Changes(ctx, "", "", now, now.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !full.Full || len(full.Items) != 2 || !strings.HasSuffix(full.Cursor, "$deltatoken=d1") {
+		t.Errorf("unexpected full fetch: %+v", full)
+	}
+
+	delta, err := client.Changes(ctx, "", full.Cursor, now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delta.Items) != 0 || len(delta.Removed) != 1 || delta.Removed[0] != "m1" {
+		t.Errorf("unexpected delta: %+v", delta)
+	}
+	if !strings.HasSuffix(delta.Cursor, "$deltatoken=d2") {
+		t.Errorf("cursor: %q", delta.Cursor)
+	}
+}
+
+func TestTodoistSyncChanges(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["sync_token"] != "tok-1" {
+			t.Errorf("sync_token: got %v", body["sync_token"])
+		}
+		_, _ = w.Write([]byte(`{"sync_token":"tok-2","full_sync":false,"items":[
+			{"id":"t1","content":"Write report","priority":4,"checked":true},
+			{"id":"t2","content":"Gone","is_deleted":true}]}`))
+	}))
+	defer ts.Close()
+
+	client := NewTodoistSyncClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+
+	cs, err := client.Changes(context.Background(), "tok-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Full || cs.Cursor != "tok-2" || client.SyncToken() != "tok-2" {
+		t.Errorf("unexpected change set: %+v", cs)
+	}
+	if len(cs.Items) != 1 || cs.Items[0].Status != model.StatusCompleted || cs.Items[0].Priority != model.PriorityHighest {
+		t.Errorf("items: %+v", cs.Items)
+	}
+	if len(cs.Removed) != 1 || cs.Removed[0] != "t2" {
+		t.Errorf("removed: %v", cs.Removed)
+	}
+}
+
+func TestNotionChangesSinceCursor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Query().Get("filter_properties") == "title" {
+			if body["filter"] != nil {
+				t.Error("ID sweep should not be filtered")
+			}
+			_ = json.NewEncoder(w).Encode(NotionQueryResult{Results: []NotionPage{{ID: "p1"}, {ID: "p3"}}})
+			return
+		}
+		filter, _ := body["filter"].(map[string]interface{})
+		if filter["timestamp"] != "last_edited_time" {
+			t.Errorf("expected last_edited_time filter, got %v", body["filter"])
+		}
+		_ = json.NewEncoder(w).Encode(NotionQueryResult{Results: []NotionPage{{
+			ID:             "p1",
+			LastEditedTime: "2025-03-02T10:00:00Z",
+			Properties: map[string]NotionProperty{
+				"Name": {Type: "title", Title: []NotionRichText{{PlainText: "Edited"}}},
+			},
+		}}})
+	}))
+	defer ts.Close()
+
+	client := NewNotionClient("test-notion-token")
+	client.httpClient = redirectClient(ts)
+
+	cs, err := client.Changes(context.Background(), "db-1", "2025-03-01T00:00:00Z", DefaultNotionPropertyMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs.Items) != 1 || cs.Items[0].Title != "Edited" || cs.Items[0].UpdatedAt == nil {
+		t.Errorf("items: %+v", cs.Items)
+	}
+	if cs.Cursor != "2025-03-02T10:00:00Z" {
+		t.Errorf("cursor: %q", cs.Cursor)
+	}
+	if !cs.Present["p3"] || cs.Present["p2"] {
+		t.Errorf("present: %v", cs.Present)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Source UID stamping
+// ---------------------------------------------------------------------------
+
+func TestDescriptionMarkerRoundTrip(t *testing.T) {
+	tests := []struct{ desc, uid string }{
+		{"", "abc"},
+		{"Call the bank", "abc"},
+		{"line one\nline two", "uid-2@example.com"},
+	}
+	for _, tt := range tests {
+		stamped := stampDescription(tt.desc, tt.uid)
+		if again := stampDescription(stamped, tt.uid); again != stamped {
+			t.Errorf("stamping twice should not add a second marker: %q", again)
+		}
+		desc, uid := splitDescriptionMarker(stamped)
+		if desc != tt.desc || uid != tt.uid {
+			t.Errorf("split(%q) = %q, %q; want %q, %q", stamped, desc, uid, tt.desc, tt.uid)
+		}
+	}
+	if desc, uid := splitDescriptionMarker("mentions salja-uid: inline"); uid != "" || desc != "mentions salja-uid: inline" {
+		t.Errorf("marker must start its own line, got %q, %q", desc, uid)
+	}
+}
+
+func TestMappersStampSourceUID(t *testing.T) {
+	item := model.CalendarItem{UID: "local-1", Title: "Dentist", Description: "Bring forms"}
+
+	if got := GCalSourceUID(CalendarItemToGCal(item)); got != "local-1" {
+		t.Errorf("gcal: got %q", got)
+	}
+	ev := CalendarItemToMSGraph(item)
+	if len(ev.SingleValueExtendedProperties) != 1 || ev.SingleValueExtendedProperties[0].Value != "local-1" {
+		t.Errorf("graph: got %+v", ev.SingleValueExtendedProperties)
+	}
+
+	task := CalendarItemToTodoist(item, "")
+	if TodoistSourceUID(task) != "local-1" {
+		t.Errorf("todoist description: %q", task.Description)
+	}
+	if back := TodoistToCalendarItem(task); back.Description != "Bring forms" {
+		t.Errorf("todoist marker leaked into description: %q", back.Description)
+	}
+
+	tt := CalendarItemToTickTick(item, "p1")
+	if TickTickSourceUID(tt) != "local-1" {
+		t.Errorf("ticktick content: %q", tt.Content)
+	}
+	if back := TickTickToCalendarItem(tt); back.Description != "Bring forms" {
+		t.Errorf("ticktick marker leaked into description: %q", back.Description)
+	}
+
+	pm := DefaultNotionPropertyMap()
+	if _, ok := CalendarItemToNotion(item, pm).Properties[NotionSourceUIDProperty]; ok {
+		t.Error("notion should not write the source UID unless the property map names it")
+	}
+	pm.SourceUID = NotionSourceUIDProperty
+	page := CalendarItemToNotion(item, pm)
+	page.Properties[NotionSourceUIDProperty].RichText[0].PlainText = "local-1"
+	if got := NotionSourceUID(page, pm); got != "local-1" {
+		t.Errorf("notion: got %q", got)
+	}
+}
+
+func TestGCalRemoteLookup(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("privateExtendedProperty"); got != SourceUIDKey+"=local-1" {
+			_ = json.NewEncoder(w).Encode(GCalEventList{})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{{ID: "g-9", Summary: "Dentist"}}})
+	}))
+	defer ts.Close()
+
+	client := NewGCalClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	remote := &GCalRemote{Client: client, CalendarID: "primary"}
+
+	found, err := remote.Lookup(context.Background(), "local-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.UID != "g-9" {
+		t.Fatalf("expected g-9, got %+v", found)
+	}
+	missing, err := remote.Lookup(context.Background(), "local-2")
+	if err != nil || missing != nil {
+		t.Errorf("expected nil for unknown UID, got %+v, %v", missing, err)
+	}
+}
+
+func TestRemotesCheckCompleted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.URL.Path == "/sync/v9/items/get" && body["item_id"] == "t1":
+			_, _ = w.Write([]byte(`{"item":{"id":"t1","checked":true,"completed_at":"2026-05-01T08:00:00Z"}}`))
+		case r.URL.Path == "/sync/v9/items/get":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/open/v1/project/p1/task/k1":
+			_, _ = w.Write([]byte(`{"id":"k1","projectId":"p1","status":2,"completedTime":"2026-05-01T08:00:00.000+0000"}`))
+		case r.URL.Path == "/open/v1/project/p1/task/k2":
+			_, _ = w.Write([]byte(`{"id":"k2","projectId":"p1","status":0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	todoist := NewTodoistClient(newTestToken())
+	todoist.httpClient = redirectClient(ts)
+	tr := &TodoistRemote{Client: todoist}
+	if done, at, err := tr.CheckCompleted(ctx, "t1"); err != nil || !done || at == nil || at.Day() != 1 {
+		t.Errorf("todoist t1 = %v %v %v", done, at, err)
+	}
+	if done, _, err := tr.CheckCompleted(ctx, "t2"); err != nil || done {
+		t.Errorf("todoist t2 = %v %v", done, err)
+	}
+
+	ticktick := NewTickTickClient(newTestToken())
+	ticktick.httpClient = redirectClient(ts)
+	kr := &TickTickRemote{Client: ticktick, ProjectID: "p1"}
+	if done, at, err := kr.CheckCompleted(ctx, "k1"); err != nil || !done || at == nil {
+		t.Errorf("ticktick k1 = %v %v %v", done, at, err)
+	}
+	for _, id := range []string{"k2", "k3"} {
+		if done, _, err := kr.CheckCompleted(ctx, id); err != nil || done {
+			t.Errorf("ticktick %s = %v %v", id, done, err)
+		}
+	}
+}
+
+func TestTodoistRemoteLookupIndexesOnce(t *testing.T) {
+	var calls int32
+	var reopened string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/sync/v9/completed/get_all":
+			_, _ = w.Write([]byte(`{"items":[{"task_id":"t3","item_object":{"id":"t3","content":"Done","description":"salja-uid: local-3"}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/reopen"):
+			reopened = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			atomic.AddInt32(&calls, 1)
+			_ = json.NewEncoder(w).Encode([]TodoistTask{
+				{ID: "t1", Content: "Stamped", Description: "notes\n\nsalja-uid: local-1"},
+				{ID: "t2", Content: "Foreign"},
+			})
+		}
+	}))
+	defer ts.Close()
+
+	client := NewTodoistClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	remote := &TodoistRemote{Client: client}
+	ctx := context.Background()
+
+	found, err := remote.Lookup(ctx, "local-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.UID != "t1" || found.Description != "notes" {
+		t.Errorf("unexpected match: %+v", found)
+	}
+	if other, _ := remote.Lookup(ctx, "local-2"); other != nil {
+		t.Errorf("expected no match, got %+v", other)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected one listing, got %d", n)
+	}
+
+	// a completed copy is found and reopened before it is edited
+	done, err := remote.Lookup(ctx, "local-3")
+	if err != nil || done == nil || done.UID != "t3" || !done.IsCompleted() {
+		t.Fatalf("completed lookup = %+v, %v", done, err)
+	}
+	if _, err := remote.Update(ctx, "t3", model.CalendarItem{UID: "local-3", Title: "Done again"}); err != nil {
+		t.Fatal(err)
+	}
+	if reopened != "/rest/v2/tasks/t3/reopen" {
+		t.Errorf("reopened %q", reopened)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	h := http.Header{}
+	if d := retryAfter(h); d != 0 {
+		t.Errorf("absent header: got %v", d)
+	}
+	h.Set("Retry-After", "7")
+	if d := retryAfter(h); d != 7*time.Second {
+		t.Errorf("seconds: got %v", d)
+	}
+	h.Set("Retry-After", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+	if d := retryAfter(h); d < 28*time.Second || d > 30*time.Second {
+		t.Errorf("http date: got %v", d)
+	}
+	h.Set("Retry-After", "soon")
+	if d := retryAfter(h); d != 0 {
+		t.Errorf("garbage: got %v", d)
+	}
+}
+
+func TestLimiterPacesRequests(t *testing.T) {
+	l := NewLimiter(RateLimit{RequestsPerSecond: 20, Burst: 1})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first token is free, the next three arrive 50ms apart
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 waits at 20/s with burst 1 took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Pause(time.Minute)
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled while paused, got %v", err)
+	}
+}
+
+func TestExecutorBoundsConcurrency(t *testing.T) {
+	exec := NewExecutor(RateLimit{Concurrency: 3})
+	var inFlight, peak int32
+	progress := exec.Run(context.Background(), 12, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		if i == 5 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	seen := make(map[int]bool)
+	var last Progress
+	failures := 0
+	for p := range progress {
+		seen[p.Index] = true
+		if p.Err != nil {
+			failures++
+		}
+		last = p
+	}
+	if len(seen) != 12 || last.Done != 12 || last.Total != 12 {
+		t.Errorf("expected 12 reports ending at 12/12, got %d ending at %d/%d", len(seen), last.Done, last.Total)
+	}
+	if failures != 1 {
+		t.Errorf("expected 1 failure, got %d", failures)
+	}
+	if peak > 3 {
+		t.Errorf("expected at most 3 tasks in flight, saw %d", peak)
+	}
+}
+
+func TestExecutorHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(TodoistTask{ID: "t1", Content: "Deploy"})
+	}))
+	defer ts.Close()
+
+	exec := NewExecutor(RateLimit{RequestsPerSecond: 100, Burst: 10, Concurrency: 2})
+	client := NewTodoistClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	client.UseLimiter(exec.Limiter)
+
+	start := time.Now()
+	var results []Progress
+	for p := range exec.Run(context.Background(), 1, func(ctx context.Context, i int) error {
+		_, err := client.CreateTask(ctx, &TodoistTask{Content: "Deploy"})
+		return err
+	}) {
+		results = append(results, p)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("expected one successful task, got %+v", results)
+	}
+	if calls != 2 {
+		t.Errorf("expected a retry after the 429, got %d calls", calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retry did not wait out Retry-After: %v", elapsed)
+	}
+}
+
+func TestExecutorStopsOnCancel(t *testing.T) {
+	exec := NewExecutor(RateLimit{Concurrency: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran int32
+	progress := exec.Run(ctx, 100, func(ctx context.Context, i int) error {
+		if atomic.AddInt32(&ran, 1) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	for range progress {
+	}
+	if n := atomic.LoadInt32(&ran); n >= 100 {
+		t.Errorf("expected the run to stop early, ran %d tasks", n)
+	}
+}
+
+func TestGCalBatchInsertRetriesFailedParts(t *testing.T) {
+	var calls int32
+	var sizes []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/batch/calendar/v3" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		call := atomic.AddInt32(&calls, 1)
+		mr, err := r.MultipartReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		mw := multipart.NewWriter(&out)
+		n := 0
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			n++
+			contentID := strings.Trim(part.Header.Get("Content-ID"), "<>")
+			inner, err := http.ReadRequest(bufio.NewReader(part))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inner.Method != "POST" || inner.URL.Path != "/calendar/v3/calendars/primary/events" {
+				t.Errorf("unexpected part request %s %s", inner.Method, inner.URL)
+			}
+			var ev GCalEvent
+			_ = json.NewDecoder(inner.Body).Decode(&ev)
+
+			status := "200 OK"
+			body := ""
+			switch {
+			case ev.Summary == "Busy" && call == 1:
+				status = "429 Too Many Requests"
+			case ev.Summary == "Bad":
+				status = "400 Bad Request"
+				body = `{"error":"invalid"}`
+			default:
+				ev.ID = "id-" + ev.Summary
+				data, _ := json.Marshal(ev)
+				body = string(data)
+			}
+			pw, _ := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type": {"application/http"},
+				"Content-ID":   {"<response-" + contentID + ">"},
+			})
+			fmt.Fprintf(pw, "HTTP/1.1 %s\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", status, len(body), body)
+		}
+		sizes = append(sizes, n)
+		_ = mw.Close()
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+		_, _ = w.Write(out.Bytes())
+	}))
+	defer ts.Close()
+
+	client := NewGCalClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+
+	events := []*GCalEvent{{Summary: "Ok"}, {Summary: "Busy"}, {Summary: "Bad"}}
+	result, err := client.BatchInsertEvents(context.Background(), "primary", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 2 || sizes[0] != 3 || sizes[1] != 1 {
+		t.Errorf("expected a batch of 3 then a retry of only the throttled part, got %v", sizes)
+	}
+	if result.SuccessCount() != 2 || len(result.Errors) != 1 || result.Total != 3 {
+		t.Fatalf("expected 2 created and 1 failed, got %+v", result)
+	}
+	ids := map[int]string{}
+	for _, r := range result.Items {
+		ids[r.Index] = r.Value.ID
+	}
+	if ids[0] != "id-Ok" || ids[1] != "id-Busy" {
+		t.Errorf("results not matched to inputs: %v", ids)
+	}
+	var apiErr *salerr.APIError
+	if result.Errors[0].Index != 2 || !errors.As(result.Errors[0].Err, &apiErr) || apiErr.StatusCode != 400 {
+		t.Errorf("expected a 400 for item 2, got %+v", result.Errors[0])
+	}
+}
+
+func TestMSGraphBatchCreateMatchesResponsesByID(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/v1.0/$batch" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var in struct {
+			Requests []msGraphBatchRequest `json:"requests"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		var out struct {
+			Responses []msGraphBatchResponse `json:"responses"`
+		}
+		// answer in reverse order; the client must match on id
+		for k := len(in.Requests) - 1; k >= 0; k-- {
+			req := in.Requests[k]
+			if req.Method != "POST" || req.URL != "/me/events" {
+				t.Errorf("unexpected request %s %s", req.Method, req.URL)
+			}
+			if req.ID == "1" {
+				out.Responses = append(out.Responses, msGraphBatchResponse{ID: req.ID, Status: 409, Body: json.RawMessage(`{"error":{"code":"conflict"}}`)})
+				continue
+			}
+			out.Responses = append(out.Responses, msGraphBatchResponse{ID: req.ID, Status: 201, Body: json.RawMessage(`{"id":"evt-` + req.ID + `","subject":"x"}`)})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+
+	var events []*MSGraphEvent
+	for i := 0; i < MSGraphBatchSize+2; i++ {
+		events = append(events, &MSGraphEvent{Subject: fmt.Sprintf("e%d", i)})
+	}
+	result, err := client.BatchCreateEvents(context.Background(), "", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("expected %d events to need 2 batches, got %d", len(events), requests)
+	}
+	// id "1" fails in both batches: inputs 1 and MSGraphBatchSize+1
+	if len(result.Errors) != 2 || result.SuccessCount() != len(events)-2 {
+		t.Fatalf("expected 2 failures, got %d failures and %d successes", len(result.Errors), result.SuccessCount())
+	}
+	for _, r := range result.Items {
+		k := r.Index % MSGraphBatchSize
+		if r.Value.ID != fmt.Sprintf("evt-%d", k) {
+			t.Errorf("input %d got event %s", r.Index, r.Value.ID)
+		}
+	}
+}
+
+func TestGCalFoldsRecurringInstances(t *testing.T) {
+	events := []GCalEvent{
+		{ID: "moved_20260311T090000Z", RecurringEventID: "weekly", Summary: "Sync (moved)",
+			OriginalStartTime: &GCalDateTime{DateTime: "2026-03-11T09:00:00Z"},
+			Start:             &GCalDateTime{DateTime: "2026-03-12T15:00:00Z"}, End: &GCalDateTime{DateTime: "2026-03-12T16:00:00Z"}},
+		{ID: "weekly", Summary: "Sync",
+			Start:      &GCalDateTime{DateTime: "2026-03-04T09:00:00Z"},
+			End:        &GCalDateTime{DateTime: "2026-03-04T10:00:00Z"},
+			Recurrence: []string{"RRULE:FREQ=WEEKLY;COUNT=6", "EXDATE:20260401T090000Z"}},
+		{ID: "weekly_20260318T090000Z", RecurringEventID: "weekly", Status: "cancelled",
+			OriginalStartTime: &GCalDateTime{DateTime: "2026-03-18T09:00:00Z"}},
+		{ID: "other_20260305T120000Z", RecurringEventID: "other", Summary: "Lunch",
+			OriginalStartTime: &GCalDateTime{DateTime: "2026-03-05T12:00:00Z"},
+			Start:             &GCalDateTime{DateTime: "2026-03-05T12:00:00Z"}},
+		{ID: "other_20260306T120000Z", RecurringEventID: "other", Status: "cancelled",
+			OriginalStartTime: &GCalDateTime{DateTime: "2026-03-06T12:00:00Z"}},
+	}
+
+	items := GCalToCalendarItems(events)
+	// the series, and the occurrence of a series not listed
+	if len(items) != 2 || items[0].UID != "weekly" || items[1].UID != "other_20260305T120000Z" {
+		t.Fatalf("items = %+v", items)
+	}
+	rec := items[0].Recurrence
+	if rec == nil || rec.Count == nil || *rec.Count != 6 || len(rec.ExDates) != 2 || len(rec.Overrides) != 1 {
+		t.Fatalf("recurrence = %+v", rec)
+	}
+	ov := rec.Override(time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC))
+	if ov == nil || ov.Item.Title != "Sync (moved)" || ov.Item.UID != "weekly" || ov.Item.StartTime.Day() != 12 {
+		t.Errorf("override = %+v", ov)
+	}
+
+	back := CalendarItemToGCal(items[0])
+	want := []string{"RRULE:FREQ=WEEKLY;COUNT=6", "EXDATE:20260401T090000Z,20260318T090000Z"}
+	if strings.Join(back.Recurrence, "|") != strings.Join(want, "|") {
+		t.Errorf("recurrence lines = %q", back.Recurrence)
+	}
+}
+
+func TestGCalRemoteWritesOverrides(t *testing.T) {
+	var updated []GCalEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			var event GCalEvent
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			event.ID = "weekly"
+			_ = json.NewEncoder(w).Encode(event)
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/events/weekly/instances"):
+			_ = json.NewEncoder(w).Encode(GCalEventList{Items: []GCalEvent{
+				{ID: "weekly_20260304T090000Z", RecurringEventID: "weekly", OriginalStartTime: &GCalDateTime{DateTime: "2026-03-04T09:00:00Z"}},
+				{ID: "weekly_20260311T090000Z", RecurringEventID: "weekly", OriginalStartTime: &GCalDateTime{DateTime: "2026-03-11T09:00:00Z"}},
+			}})
+		case r.Method == "PUT" && strings.HasSuffix(r.URL.Path, "/events/weekly_20260311T090000Z"):
+			var event GCalEvent
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			updated = append(updated, event)
+			_ = json.NewEncoder(w).Encode(event)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewGCalClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	remote := &GCalRemote{Client: client, CalendarID: "primary"}
+
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	movedStart, movedEnd := start.AddDate(0, 0, 8).Add(6*time.Hour), start.AddDate(0, 0, 8).Add(7*time.Hour)
+	item := model.CalendarItem{
+		UID: "local-1", Title: "Sync", ItemType: model.ItemTypeEvent, StartTime: &start, EndTime: &end,
+		Recurrence: &model.Recurrence{Freq: model.FreqWeekly, Interval: 1, Overrides: []model.Override{{
+			RecurrenceID: start.AddDate(0, 0, 7),
+			Item:         model.CalendarItem{UID: "local-1", Title: "Sync (moved)", ItemType: model.ItemTypeEvent, StartTime: &movedStart, EndTime: &movedEnd},
+		}}},
+	}
+
+	got, err := remote.Create(context.Background(), item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 1 || updated[0].Summary != "Sync (moved)" || updated[0].RecurringEventID != "weekly" || updated[0].ExtendedProps != nil {
+		t.Fatalf("updated instances = %+v", updated)
+	}
+	if got.UID != "weekly" || got.Recurrence == nil || len(got.Recurrence.Overrides) != 1 || got.Recurrence.Overrides[0].Item.Title != "Sync (moved)" {
+		t.Errorf("created = %+v", got)
+	}
+}
+
+func TestMSGraphFoldsSeries(t *testing.T) {
+	master := MSGraphEvent{ID: "s1", Type: "seriesMaster", Subject: "Sync",
+		Start: &MSGraphDateTime{DateTime: "2026-03-04T09:00:00.0000000", TimeZone: "UTC"},
+		End:   &MSGraphDateTime{DateTime: "2026-03-04T10:00:00.0000000", TimeZone: "UTC"},
+		Recurrence: &MSGraphRecurrence{
+			Pattern: &MSGraphRecurrencePattern{Type: "weekly", Interval: 1, DaysOfWeek: []string{"wednesday"}},
+			Range:   &MSGraphRecurrenceRange{Type: "numbered", StartDate: "2026-03-04", NumberOfOccurrences: 6},
+		}}
+	events := []MSGraphEvent{
+		master,
+		{ID: "o1", Type: "occurrence", SeriesMasterID: "s1", OriginalStart: "2026-03-04T09:00:00Z", Subject: "Sync"},
+		{ID: "e1", Type: "exception", SeriesMasterID: "s1", OriginalStart: "2026-03-11T09:00:00Z", Subject: "Sync (moved)",
+			Start: &MSGraphDateTime{DateTime: "2026-03-12T15:00:00.0000000", TimeZone: "UTC"}},
+		{ID: "o9", Type: "occurrence", SeriesMasterID: "s9", OriginalStart: "2026-03-05T12:00:00Z", Subject: "Lunch"},
+		{ID: "single", Type: "singleInstance", Subject: "Dentist"},
+	}
+
+	items := MSGraphToCalendarItems(events)
+	if len(items) != 3 || items[0].UID != "s1" || items[1].UID != "o9" || items[2].UID != "single" {
+		t.Fatalf("items = %+v", items)
+	}
+	rec := items[0].Recurrence
+	if rec == nil || rec.Freq != model.FreqWeekly || rec.Count == nil || *rec.Count != 6 || len(rec.Overrides) != 1 {
+		t.Fatalf("recurrence = %+v", rec)
+	}
+	if ov := rec.Override(time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC)); ov == nil || ov.Item.Title != "Sync (moved)" || ov.Item.UID != "s1" {
+		t.Errorf("override = %+v", ov)
+	}
+
+	back := CalendarItemToMSGraph(items[0])
+	if back.Recurrence == nil || back.Recurrence.Pattern.Type != "weekly" || back.Recurrence.Range.NumberOfOccurrences != 6 || back.Type != "" {
+		t.Errorf("recurrence = %+v", back.Recurrence)
+	}
+}
+
+func TestMSGraphRemoteWritesOverrides(t *testing.T) {
+	var patched, deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PATCH" && r.URL.Path == "/v1.0/me/events/s1":
+			var event MSGraphEvent
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			event.Type = "seriesMaster"
+			_ = json.NewEncoder(w).Encode(event)
+		case r.Method == "GET" && r.URL.Path == "/v1.0/me/events/s1/instances":
+			_ = json.NewEncoder(w).Encode(MSGraphEventList{Value: []MSGraphEvent{
+				{ID: "i1", Type: "occurrence", SeriesMasterID: "s1", OriginalStart: "2026-03-04T09:00:00Z"},
+				{ID: "i2", Type: "occurrence", SeriesMasterID: "s1", OriginalStart: "2026-03-11T09:00:00Z"},
+				{ID: "i3", Type: "occurrence", SeriesMasterID: "s1", OriginalStart: "2026-03-18T09:00:00Z"},
+			}})
+		case r.Method == "PATCH":
+			var event MSGraphEvent
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			patched = append(patched, strings.TrimPrefix(r.URL.Path, "/v1.0/me/events/"))
+			event.Type, event.SeriesMasterID, event.OriginalStart = "exception", "s1", "2026-03-11T09:00:00Z"
+			_ = json.NewEncoder(w).Encode(event)
+		case r.Method == "DELETE":
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v1.0/me/events/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewMSGraphClient(newTestToken())
+	client.httpClient = redirectClient(ts)
+	remote := &MSGraphRemote{Client: client}
+
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	movedStart := start.AddDate(0, 0, 8)
+	item := model.CalendarItem{
+		UID: "local-1", Title: "Sync", ItemType: model.ItemTypeEvent, StartTime: &start, EndTime: &end,
+		Recurrence: &model.Recurrence{Freq: model.FreqWeekly, Interval: 1,
+			ExDates: []time.Time{start.AddDate(0, 0, 14)},
+			Overrides: []model.Override{{
+				RecurrenceID: start.AddDate(0, 0, 7),
+				Item:         model.CalendarItem{Title: "Sync (moved)", ItemType: model.ItemTypeEvent, StartTime: &movedStart},
+			}}},
+	}
+
+	got, err := remote.Update(context.Background(), "s1", item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(patched, ",") != "i2" || strings.Join(deleted, ",") != "i3" {
+		t.Errorf("patched %v, deleted %v", patched, deleted)
+	}
+	if got.Recurrence == nil || len(got.Recurrence.Overrides) != 1 || got.Recurrence.Overrides[0].Item.Title != "Sync (moved)" {
+		t.Errorf("updated = %+v", got)
+	}
+}
@@ -0,0 +1,567 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+const (
+	gcalBatchURL = "https://www.googleapis.com/batch/calendar/v3"
+	// Google accepts up to 1000 calls per batch but recommends staying at 50.
+	GCalBatchSize = 50
+	// Graph rejects JSON batches of more than 20 requests.
+	MSGraphBatchSize = 20
+
+	batchMaxRetries = 3
+)
+
+// BatchResult is one successful part of a batch, with the index of the
+// input it answers.
+type BatchResult[T any] struct {
+	Index int
+	Value T
+}
+
+// batchRequest is one call packed into a batch. path is relative to the
+// service host, body is sent as JSON.
+type batchRequest struct {
+	method string
+	path   string
+	body   interface{}
+}
+
+// batchPart is the response to one batchRequest.
+type batchPart struct {
+	status     int
+	body       []byte
+	retryAfter time.Duration
+}
+
+// runBatches sends n requests in chunks of size through send, which makes
+// one batch call for the given request indexes and returns a part for each.
+// Parts that fail with 429 or a server error are resent on their own, after
+// the longest Retry-After among them, up to batchMaxRetries times. handle is
+// called once per request with its final part. An error from send aborts the
+// remaining chunks.
+func runBatches(ctx context.Context, n, size int, send func(ctx context.Context, idx []int) ([]batchPart, error), handle func(i int, p batchPart)) error {
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		pending := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			pending = append(pending, i)
+		}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			parts, err := send(ctx, pending)
+			if err != nil {
+				return err
+			}
+			var retry []int
+			var wait time.Duration
+			for k, i := range pending {
+				p := parts[k]
+				if attempt < batchMaxRetries && (&salerr.APIError{StatusCode: p.status}).IsRetryable() {
+					retry = append(retry, i)
+					if p.retryAfter > wait {
+						wait = p.retryAfter
+					}
+					continue
+				}
+				handle(i, p)
+			}
+			if len(retry) == 0 {
+				break
+			}
+			backoff := time.Duration(1<<attempt) * time.Second
+			if wait > backoff {
+				backoff = wait
+			}
+			if err := sleepCtx(ctx, backoff); err != nil {
+				return err
+			}
+			pending = retry
+		}
+	}
+	return nil
+}
+
+// collectPart adds part p for input i to result: decoded into the result on
+// one of the ok statuses, as an item error otherwise.
+func collectPart[T any](result *salerr.PartialResult[BatchResult[T]], service string, i int, id string, p batchPart, decode func([]byte) (T, error), ok ...int) {
+	for _, status := range ok {
+		if p.status != status {
+			continue
+		}
+		v, err := decode(p.body)
+		if err != nil {
+			result.AddError(i, id, err.Error(), err)
+			return
+		}
+		result.Add(BatchResult[T]{Index: i, Value: v})
+		return
+	}
+	err := &salerr.APIError{Service: service, StatusCode: p.status, Message: string(p.body), RetryAfter: p.retryAfter}
+	result.AddError(i, id, err.Error(), err)
+}
+
+func decodeJSON[T any](data []byte) (T, error) {
+	var v T
+	return v, json.Unmarshal(data, &v)
+}
+
+// Google Calendar batches are multipart/mixed bodies with one embedded HTTP
+// request per part, answered by a multipart response in the same shape.
+
+func (c *GCalClient) doBatch(ctx context.Context, body []byte, contentType string) ([]byte, string, int, error) {
+	var respBody []byte
+	var respType string
+	var statusCode int
+
+	err := retryRequest(c.limited, func() error {
+		req, err := http.NewRequestWithContext(ctx, "POST", gcalBatchURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token.AccessToken)
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err = io.ReadAll(resp.Body)
+		respType = resp.Header.Get("Content-Type")
+		statusCode = resp.StatusCode
+
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			return &salerr.APIError{Service: "google-calendar", StatusCode: resp.StatusCode, Message: string(respBody), RetryAfter: retryAfter(resp.Header)}
+		}
+		return err
+	})
+
+	return respBody, respType, statusCode, err
+}
+
+// batch sends up to GCalBatchSize requests in one call and returns their
+// responses in order. Parts missing from the response come back with
+// status 0 so they are retried.
+func (c *GCalClient) batch(ctx context.Context, reqs []batchRequest) ([]batchPart, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, r := range reqs {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {"application/http"},
+			"Content-ID":   {fmt.Sprintf("<item-%d>", k)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(pw, "%s %s HTTP/1.1\r\n", r.method, r.path)
+		if r.body != nil {
+			data, err := json.Marshal(r.body)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(pw, "Content-Type: application/json\r\nContent-Length: %d\r\n\r\n", len(data))
+			_, _ = pw.Write(data)
+		} else {
+			_, _ = io.WriteString(pw, "\r\n")
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	data, respType, status, err := c.doBatch(ctx, buf.Bytes(), "multipart/mixed; boundary="+mw.Boundary())
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Google Calendar", StatusCode: status, Message: string(data)}
+	}
+	mediaType, params, err := mime.ParseMediaType(respType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("google calendar batch: unexpected response type %q", respType)
+	}
+
+	parts := make([]batchPart, len(reqs))
+	mr := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("google calendar batch: %w", err)
+		}
+		k, ok := gcalPartIndex(part.Header.Get("Content-ID"))
+		if !ok || k >= len(parts) {
+			continue
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(part), nil)
+		if err != nil {
+			return nil, fmt.Errorf("google calendar batch part %d: %w", k, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		parts[k] = batchPart{status: resp.StatusCode, body: body, retryAfter: retryAfter(resp.Header)}
+	}
+	return parts, nil
+}
+
+// gcalPartIndex reads k from a response Content-ID of <response-item-k>.
+func gcalPartIndex(contentID string) (int, bool) {
+	id := strings.Trim(contentID, "<>")
+	id = strings.TrimPrefix(id, "response-")
+	id = strings.TrimPrefix(id, "item-")
+	k, err := strconv.Atoi(id)
+	return k, err == nil && k >= 0
+}
+
+func (c *GCalClient) runBatch(ctx context.Context, n int, request func(i int) batchRequest, handle func(i int, p batchPart)) error {
+	send := func(ctx context.Context, idx []int) ([]batchPart, error) {
+		reqs := make([]batchRequest, len(idx))
+		for k, i := range idx {
+			reqs[k] = request(i)
+		}
+		return c.batch(ctx, reqs)
+	}
+	return runBatches(ctx, n, GCalBatchSize, send, handle)
+}
+
+func gcalEventsPath(calendarID string) string {
+	return "/calendar/v3/calendars/" + url.PathEscape(calendarID) + "/events"
+}
+
+// BatchInsertEvents creates events GCalBatchSize at a time. Parts throttled
+// or failed by the server are retried; the result holds the created events
+// and an error for each event that still failed. The error return is only
+// set when a batch could not be sent at all.
+func (c *GCalClient) BatchInsertEvents(ctx context.Context, calendarID string, events []*GCalEvent) (*salerr.PartialResult[BatchResult[GCalEvent]], error) {
+	result := salerr.NewPartialResult[BatchResult[GCalEvent]]()
+	err := c.runBatch(ctx, len(events), func(i int) batchRequest {
+		return batchRequest{"POST", gcalEventsPath(calendarID), events[i]}
+	}, func(i int, p batchPart) {
+		collectPart(result, "Google Calendar", i, GCalSourceUID(*events[i]), p, decodeJSON[GCalEvent], 200)
+	})
+	return result, err
+}
+
+// BatchUpdateEvents replaces events, identified by their IDs, in batches.
+func (c *GCalClient) BatchUpdateEvents(ctx context.Context, calendarID string, events []*GCalEvent) (*salerr.PartialResult[BatchResult[GCalEvent]], error) {
+	result := salerr.NewPartialResult[BatchResult[GCalEvent]]()
+	err := c.runBatch(ctx, len(events), func(i int) batchRequest {
+		return batchRequest{"PUT", gcalEventsPath(calendarID) + "/" + url.PathEscape(events[i].ID), events[i]}
+	}, func(i int, p batchPart) {
+		collectPart(result, "Google Calendar", i, events[i].ID, p, decodeJSON[GCalEvent], 200)
+	})
+	return result, err
+}
+
+// BatchDeleteEvents deletes events by ID in batches. The result holds the
+// deleted IDs.
+func (c *GCalClient) BatchDeleteEvents(ctx context.Context, calendarID string, eventIDs []string) (*salerr.PartialResult[BatchResult[string]], error) {
+	result := salerr.NewPartialResult[BatchResult[string]]()
+	err := c.runBatch(ctx, len(eventIDs), func(i int) batchRequest {
+		return batchRequest{"DELETE", gcalEventsPath(calendarID) + "/" + url.PathEscape(eventIDs[i]), nil}
+	}, func(i int, p batchPart) {
+		id := eventIDs[i]
+		collectPart(result, "Google Calendar", i, id, p, func([]byte) (string, error) { return id, nil }, 204, 200)
+	})
+	return result, err
+}
+
+// BatchFindBySourceUID looks up the events stamped with each uid in
+// batches. Values are nil for UIDs with no event.
+func (c *GCalClient) BatchFindBySourceUID(ctx context.Context, calendarID string, uids []string) (*salerr.PartialResult[BatchResult[*GCalEvent]], error) {
+	result := salerr.NewPartialResult[BatchResult[*GCalEvent]]()
+	err := c.runBatch(ctx, len(uids), func(i int) batchRequest {
+		params := url.Values{}
+		params.Set("privateExtendedProperty", SourceUIDKey+"="+uids[i])
+		params.Set("maxResults", "1")
+		return batchRequest{"GET", gcalEventsPath(calendarID) + "?" + params.Encode(), nil}
+	}, func(i int, p batchPart) {
+		collectPart(result, "Google Calendar", i, uids[i], p, func(data []byte) (*GCalEvent, error) {
+			var list GCalEventList
+			if err := json.Unmarshal(data, &list); err != nil {
+				return nil, err
+			}
+			for _, event := range list.Items {
+				if event.Status != "cancelled" {
+					return &event, nil
+				}
+			}
+			return nil, nil
+		}, 200)
+	})
+	return result, err
+}
+
+// Microsoft Graph batches are a JSON envelope of requests answered by a JSON
+// envelope of responses, matched up by id.
+
+type msGraphBatchRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+type msGraphBatchResponse struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// batch sends up to MSGraphBatchSize requests in one $batch call.
+func (c *MSGraphClient) batch(ctx context.Context, reqs []batchRequest) ([]batchPart, error) {
+	envelope := struct {
+		Requests []msGraphBatchRequest `json:"requests"`
+	}{}
+	for k, r := range reqs {
+		br := msGraphBatchRequest{ID: strconv.Itoa(k), Method: r.method, URL: r.path, Body: r.body}
+		if r.body != nil {
+			br.Headers = map[string]string{"Content-Type": "application/json"}
+		}
+		envelope.Requests = append(envelope.Requests, br)
+	}
+
+	data, status, err := c.doRequest(ctx, "POST", graphBaseURL+"/$batch", envelope)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	var resp struct {
+		Responses []msGraphBatchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	parts := make([]batchPart, len(reqs))
+	for _, r := range resp.Responses {
+		k, err := strconv.Atoi(r.ID)
+		if err != nil || k < 0 || k >= len(parts) {
+			continue
+		}
+		h := http.Header{}
+		for name, v := range r.Headers {
+			h.Set(name, v)
+		}
+		parts[k] = batchPart{status: r.Status, body: r.Body, retryAfter: retryAfter(h)}
+	}
+	return parts, nil
+}
+
+func (c *MSGraphClient) runBatch(ctx context.Context, n int, request func(i int) batchRequest, handle func(i int, p batchPart)) error {
+	send := func(ctx context.Context, idx []int) ([]batchPart, error) {
+		reqs := make([]batchRequest, len(idx))
+		for k, i := range idx {
+			reqs[k] = request(i)
+		}
+		return c.batch(ctx, reqs)
+	}
+	return runBatches(ctx, n, MSGraphBatchSize, send, handle)
+}
+
+// BatchCreateEvents creates events MSGraphBatchSize at a time, retrying
+// throttled or failed parts. The error return is only set when a batch could
+// not be sent at all.
+func (c *MSGraphClient) BatchCreateEvents(ctx context.Context, calendarID string, events []*MSGraphEvent) (*salerr.PartialResult[BatchResult[MSGraphEvent]], error) {
+	result := salerr.NewPartialResult[BatchResult[MSGraphEvent]]()
+	err := c.runBatch(ctx, len(events), func(i int) batchRequest {
+		return batchRequest{"POST", graphCalendarPath(calendarID) + "/events", events[i]}
+	}, func(i int, p batchPart) {
+		collectPart(result, "Microsoft Graph", i, events[i].TransactionID, p, decodeJSON[MSGraphEvent], 201)
+	})
+	return result, err
+}
+
+// BatchUpdateEvents patches events, identified by their IDs, in batches.
+func (c *MSGraphClient) BatchUpdateEvents(ctx context.Context, events []*MSGraphEvent) (*salerr.PartialResult[BatchResult[MSGraphEvent]], error) {
+	result := salerr.NewPartialResult[BatchResult[MSGraphEvent]]()
+	err := c.runBatch(ctx, len(events), func(i int) batchRequest {
+		return batchRequest{"PATCH", "/me/events/" + url.PathEscape(events[i].ID), events[i]}
+	}, func(i int, p batchPart) {
+		collectPart(result, "Microsoft Graph", i, events[i].ID, p, decodeJSON[MSGraphEvent], 200)
+	})
+	return result, err
+}
+
+// BatchDeleteEvents deletes events by ID in batches. The result holds the
+// deleted IDs.
+func (c *MSGraphClient) BatchDeleteEvents(ctx context.Context, eventIDs []string) (*salerr.PartialResult[BatchResult[string]], error) {
+	result := salerr.NewPartialResult[BatchResult[string]]()
+	err := c.runBatch(ctx, len(eventIDs), func(i int) batchRequest {
+		return batchRequest{"DELETE", "/me/events/" + url.PathEscape(eventIDs[i]), nil}
+	}, func(i int, p batchPart) {
+		id := eventIDs[i]
+		collectPart(result, "Microsoft Graph", i, id, p, func([]byte) (string, error) { return id, nil }, 204)
+	})
+	return result, err
+}
+
+// BatchFindBySourceUID looks up the events stamped with each uid in
+// batches. Values are nil for UIDs with no event.
+func (c *MSGraphClient) BatchFindBySourceUID(ctx context.Context, calendarID string, uids []string) (*salerr.PartialResult[BatchResult[*MSGraphEvent]], error) {
+	result := salerr.NewPartialResult[BatchResult[*MSGraphEvent]]()
+	err := c.runBatch(ctx, len(uids), func(i int) batchRequest {
+		return batchRequest{"GET", graphCalendarPath(calendarID) + "/events?$filter=" + url.QueryEscape(msGraphSourceUIDFilter(uids[i])) + "&$top=1", nil}
+	}, func(i int, p batchPart) {
+		collectPart(result, "Microsoft Graph", i, uids[i], p, func(data []byte) (*MSGraphEvent, error) {
+			var list MSGraphEventList
+			if err := json.Unmarshal(data, &list); err != nil {
+				return nil, err
+			}
+			if len(list.Value) == 0 {
+				return nil, nil
+			}
+			return &list.Value[0], nil
+		}, 200)
+	})
+	return result, err
+}
+
+// Batch writes on the remotes map items the same way Create, Update and
+// Delete do, so a push can switch between single and batched calls.
+
+func (r *GCalRemote) BatchSize() int { return GCalBatchSize }
+
+func (r *GCalRemote) LookupBatch(ctx context.Context, uids []string) (*salerr.PartialResult[BatchResult[*model.CalendarItem]], error) {
+	found, err := r.Client.BatchFindBySourceUID(ctx, r.CalendarID, uids)
+	return mapBatch(found, func(event *GCalEvent) *model.CalendarItem {
+		if event == nil {
+			return nil
+		}
+		item := GCalToCalendarItem(*event)
+		return &item
+	}), err
+}
+
+func (r *GCalRemote) CreateBatch(ctx context.Context, items []model.CalendarItem) (*salerr.PartialResult[BatchResult[model.CalendarItem]], error) {
+	events := make([]*GCalEvent, len(items))
+	for i, item := range items {
+		event := CalendarItemToGCal(item)
+		event.ID = ""
+		events[i] = &event
+	}
+	created, err := r.Client.BatchInsertEvents(ctx, r.CalendarID, events)
+	return writeBatchOverrides(ctx, created, items, r.writeOverrides), err
+}
+
+func (r *GCalRemote) UpdateBatch(ctx context.Context, remoteIDs []string, items []model.CalendarItem) (*salerr.PartialResult[BatchResult[model.CalendarItem]], error) {
+	events := make([]*GCalEvent, len(items))
+	for i, item := range items {
+		event := CalendarItemToGCal(item)
+		event.ID = remoteIDs[i]
+		events[i] = &event
+	}
+	updated, err := r.Client.BatchUpdateEvents(ctx, r.CalendarID, events)
+	return writeBatchOverrides(ctx, updated, items, r.writeOverrides), err
+}
+
+func (r *GCalRemote) DeleteBatch(ctx context.Context, remoteIDs []string) (*salerr.PartialResult[BatchResult[string]], error) {
+	return r.Client.BatchDeleteEvents(ctx, r.CalendarID, remoteIDs)
+}
+
+func (r *MSGraphRemote) BatchSize() int { return MSGraphBatchSize }
+
+func (r *MSGraphRemote) LookupBatch(ctx context.Context, uids []string) (*salerr.PartialResult[BatchResult[*model.CalendarItem]], error) {
+	found, err := r.Client.BatchFindBySourceUID(ctx, r.CalendarID, uids)
+	return mapBatch(found, func(event *MSGraphEvent) *model.CalendarItem {
+		if event == nil {
+			return nil
+		}
+		item := MSGraphToCalendarItem(*event)
+		return &item
+	}), err
+}
+
+func (r *MSGraphRemote) CreateBatch(ctx context.Context, items []model.CalendarItem) (*salerr.PartialResult[BatchResult[model.CalendarItem]], error) {
+	events := make([]*MSGraphEvent, len(items))
+	for i, item := range items {
+		event := CalendarItemToMSGraph(item)
+		event.ID = ""
+		event.TransactionID = item.UID
+		events[i] = &event
+	}
+	created, err := r.Client.BatchCreateEvents(ctx, r.CalendarID, events)
+	return writeBatchOverrides(ctx, created, items, r.writeOverrides), err
+}
+
+func (r *MSGraphRemote) UpdateBatch(ctx context.Context, remoteIDs []string, items []model.CalendarItem) (*salerr.PartialResult[BatchResult[model.CalendarItem]], error) {
+	events := make([]*MSGraphEvent, len(items))
+	for i, item := range items {
+		event := CalendarItemToMSGraph(item)
+		event.ID = remoteIDs[i]
+		events[i] = &event
+	}
+	updated, err := r.Client.BatchUpdateEvents(ctx, events)
+	return writeBatchOverrides(ctx, updated, items, r.writeOverrides), err
+}
+
+func (r *MSGraphRemote) DeleteBatch(ctx context.Context, remoteIDs []string) (*salerr.PartialResult[BatchResult[string]], error) {
+	return r.Client.BatchDeleteEvents(ctx, remoteIDs)
+}
+
+// writeBatchOverrides finishes batch-written series with the remote's
+// writeOverrides; a series whose occurrences fail to update is reported as
+// failed.
+func writeBatchOverrides[E any](ctx context.Context, in *salerr.PartialResult[BatchResult[E]], items []model.CalendarItem, write func(context.Context, E, model.CalendarItem) (model.CalendarItem, error)) *salerr.PartialResult[BatchResult[model.CalendarItem]] {
+	out := salerr.NewPartialResult[BatchResult[model.CalendarItem]]()
+	if in == nil {
+		return out
+	}
+	out.Errors = in.Errors
+	for _, res := range in.Items {
+		item, err := write(ctx, res.Value, items[res.Index])
+		if err != nil {
+			out.AddError(res.Index, items[res.Index].UID, err.Error(), err)
+			continue
+		}
+		out.Items = append(out.Items, BatchResult[model.CalendarItem]{Index: res.Index, Value: item})
+	}
+	out.Total = in.Total
+	return out
+}
+
+// mapBatch converts the values of a batch result, keeping indexes and
+// errors.
+func mapBatch[T, U any](in *salerr.PartialResult[BatchResult[T]], f func(T) U) *salerr.PartialResult[BatchResult[U]] {
+	out := salerr.NewPartialResult[BatchResult[U]]()
+	if in == nil {
+		return out
+	}
+	for _, r := range in.Items {
+		out.Add(BatchResult[U]{Index: r.Index, Value: f(r.Value)})
+	}
+	out.Errors = in.Errors
+	out.Total = in.Total
+	return out
+}
@@ -0,0 +1,454 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/ics"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// CalDAVClient is a CalDAV (RFC 4791) client using HTTP basic auth, which
+// covers Nextcloud, Radicale, Fastmail and iCloud app passwords.
+type CalDAVClient struct {
+	baseURL    *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+	limited    bool
+}
+
+func NewCalDAVClient(baseURL, username, password string) (*CalDAVClient, error) {
+	return NewCalDAVClientWithTimeout(baseURL, username, password, 30*time.Second)
+}
+
+func NewCalDAVClientWithTimeout(baseURL, username, password string, timeout time.Duration) (*CalDAVClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid CalDAV URL %q", baseURL)
+	}
+	return &CalDAVClient{
+		baseURL:    u,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// CalDAVCalendar is a calendar collection found during discovery.
+type CalDAVCalendar struct {
+	Href        string
+	DisplayName string
+	CTag        string
+	// Components lists the supported component types, e.g. VEVENT, VTODO.
+	Components []string
+}
+
+// Supports reports whether the calendar accepts the given component type.
+// Servers that do not advertise a component set accept everything.
+func (c CalDAVCalendar) Supports(component string) bool {
+	if len(c.Components) == 0 {
+		return true
+	}
+	for _, comp := range c.Components {
+		if strings.EqualFold(comp, component) {
+			return true
+		}
+	}
+	return false
+}
+
+// CalDAVObject is one calendar object resource with its parsed item.
+type CalDAVObject struct {
+	Href string
+	ETag string
+	Item model.CalendarItem
+}
+
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Status    string        `xml:"DAV: status"`
+	Propstats []davPropstat `xml:"DAV: propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"DAV: prop"`
+	Status string  `xml:"DAV: status"`
+}
+
+type davProp struct {
+	DisplayName          string          `xml:"DAV: displayname"`
+	ResourceType         davResourceType `xml:"DAV: resourcetype"`
+	ETag                 string          `xml:"DAV: getetag"`
+	CTag                 string          `xml:"http://calendarserver.org/ns/ getctag"`
+	CurrentUserPrincipal davHref         `xml:"DAV: current-user-principal"`
+	CalendarHomeSet      davHref         `xml:"urn:ietf:params:xml:ns:caldav calendar-home-set"`
+	CalendarData         string          `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+	SupportedComponents  davCompSet      `xml:"urn:ietf:params:xml:ns:caldav supported-calendar-component-set"`
+}
+
+type davResourceType struct {
+	Calendar *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+}
+
+type davHref struct {
+	Href string `xml:"DAV: href"`
+}
+
+type davCompSet struct {
+	Comps []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav comp"`
+}
+
+// okProp returns the merged properties of all 200 propstats in a response.
+func (r davResponse) okProp() davProp {
+	var out davProp
+	for _, ps := range r.Propstats {
+		if ps.Status != "" && !strings.Contains(ps.Status, " 200 ") {
+			continue
+		}
+		p := ps.Prop
+		if p.DisplayName != "" {
+			out.DisplayName = p.DisplayName
+		}
+		if p.ResourceType.Calendar != nil {
+			out.ResourceType = p.ResourceType
+		}
+		if p.ETag != "" {
+			out.ETag = p.ETag
+		}
+		if p.CTag != "" {
+			out.CTag = p.CTag
+		}
+		if p.CurrentUserPrincipal.Href != "" {
+			out.CurrentUserPrincipal = p.CurrentUserPrincipal
+		}
+		if p.CalendarHomeSet.Href != "" {
+			out.CalendarHomeSet = p.CalendarHomeSet
+		}
+		if p.CalendarData != "" {
+			out.CalendarData = p.CalendarData
+		}
+		if len(p.SupportedComponents.Comps) > 0 {
+			out.SupportedComponents = p.SupportedComponents
+		}
+	}
+	return out
+}
+
+func (c *CalDAVClient) doRequest(ctx context.Context, method, target string, headers map[string]string, body []byte) ([]byte, http.Header, int, error) {
+	var respBody []byte
+	var respHeader http.Header
+	var statusCode int
+
+	err := retryRequest(c.limited, func() error {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.resolve(target), reqBody)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(c.username, c.password)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err = io.ReadAll(resp.Body)
+		respHeader = resp.Header
+		statusCode = resp.StatusCode
+
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			return &salerr.APIError{Service: "caldav", StatusCode: resp.StatusCode, Message: string(respBody), RetryAfter: retryAfter(resp.Header)}
+		}
+		return err
+	})
+
+	return respBody, respHeader, statusCode, err
+}
+
+// resolve turns a server-relative href into an absolute URL.
+func (c *CalDAVClient) resolve(href string) string {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return c.baseURL.ResolveReference(ref).String()
+}
+
+func (c *CalDAVClient) multistatus(ctx context.Context, method, target, depth, body string) (*davMultistatus, error) {
+	headers := map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        depth,
+	}
+	data, _, status, err := c.doRequest(ctx, method, target, headers, []byte(body))
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusMultiStatus {
+		return nil, &salerr.APIError{Service: "CalDAV", StatusCode: status, Message: fmt.Sprintf("%s %s: %s", method, target, truncateBody(data))}
+	}
+	var ms davMultistatus
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return nil, fmt.Errorf("invalid CalDAV %s response: %w", method, err)
+	}
+	return &ms, nil
+}
+
+const propfindPrincipal = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop><d:current-user-principal/></d:prop>
+</d:propfind>`
+
+const propfindHomeSet = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-home-set/></d:prop>
+</d:propfind>`
+
+const propfindCalendars = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop>
+    <d:resourcetype/>
+    <d:displayname/>
+    <cs:getctag/>
+    <c:supported-calendar-component-set/>
+  </d:prop>
+</d:propfind>`
+
+// FindCalendarHome discovers the calendar home set by following
+// current-user-principal from the configured URL. Servers that do not
+// expose a principal are assumed to host calendars directly under the URL.
+func (c *CalDAVClient) FindCalendarHome(ctx context.Context) (string, error) {
+	principal := c.baseURL.Path
+	ms, err := c.multistatus(ctx, "PROPFIND", c.baseURL.String(), "0", propfindPrincipal)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range ms.Responses {
+		if href := r.okProp().CurrentUserPrincipal.Href; href != "" {
+			principal = href
+			break
+		}
+	}
+
+	ms, err = c.multistatus(ctx, "PROPFIND", principal, "0", propfindHomeSet)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range ms.Responses {
+		if href := r.okProp().CalendarHomeSet.Href; href != "" {
+			return href, nil
+		}
+	}
+	return c.baseURL.Path, nil
+}
+
+// ListCalendars returns the calendar collections in the user's home set.
+func (c *CalDAVClient) ListCalendars(ctx context.Context) ([]CalDAVCalendar, error) {
+	home, err := c.FindCalendarHome(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := c.multistatus(ctx, "PROPFIND", home, "1", propfindCalendars)
+	if err != nil {
+		return nil, err
+	}
+	var calendars []CalDAVCalendar
+	for _, r := range ms.Responses {
+		p := r.okProp()
+		if p.ResourceType.Calendar == nil {
+			continue
+		}
+		cal := CalDAVCalendar{Href: r.Href, DisplayName: p.DisplayName, CTag: p.CTag}
+		for _, comp := range p.SupportedComponents.Comps {
+			cal.Components = append(cal.Components, comp.Name)
+		}
+		calendars = append(calendars, cal)
+	}
+	return calendars, nil
+}
+
+// QueryCalendar runs a calendar-query REPORT for one component type
+// (VEVENT or VTODO). Zero start and end times disable the time-range filter.
+func (c *CalDAVClient) QueryCalendar(ctx context.Context, calendarHref, component string, start, end time.Time) ([]CalDAVObject, error) {
+	timeRange := ""
+	if !start.IsZero() || !end.IsZero() {
+		timeRange = "<c:time-range"
+		if !start.IsZero() {
+			timeRange += fmt.Sprintf(` start="%s"`, start.UTC().Format("20060102T150405Z"))
+		}
+		if !end.IsZero() {
+			timeRange += fmt.Sprintf(` end="%s"`, end.UTC().Format("20060102T150405Z"))
+		}
+		timeRange += "/>"
+	}
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="%s">%s</c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`, xmlEscape(component), timeRange)
+
+	ms, err := c.multistatus(ctx, "REPORT", calendarHref, "1", body)
+	if err != nil {
+		return nil, err
+	}
+	return c.objects(ctx, ms)
+}
+
+// MultiGet fetches specific calendar object resources with a
+// calendar-multiget REPORT.
+func (c *CalDAVClient) MultiGet(ctx context.Context, calendarHref string, hrefs []string) ([]CalDAVObject, error) {
+	if len(hrefs) == 0 {
+		return nil, nil
+	}
+	var sb strings.Builder
+	for _, h := range hrefs {
+		sb.WriteString("<d:href>" + xmlEscape(h) + "</d:href>")
+	}
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  %s
+</c:calendar-multiget>`, sb.String())
+
+	ms, err := c.multistatus(ctx, "REPORT", calendarHref, "1", body)
+	if err != nil {
+		return nil, err
+	}
+	return c.objects(ctx, ms)
+}
+
+func (c *CalDAVClient) objects(ctx context.Context, ms *davMultistatus) ([]CalDAVObject, error) {
+	parser := ics.NewParser()
+	var objects []CalDAVObject
+	for _, r := range ms.Responses {
+		p := r.okProp()
+		if p.CalendarData == "" {
+			continue
+		}
+		col, err := parser.Parse(ctx, strings.NewReader(p.CalendarData), r.Href)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", r.Href, err)
+		}
+		for _, item := range col.Items {
+			objects = append(objects, CalDAVObject{Href: r.Href, ETag: p.ETag, Item: item})
+		}
+	}
+	return objects, nil
+}
+
+// PutItem writes an item as a calendar object resource. With an empty etag
+// the resource must not exist yet (If-None-Match: *); otherwise the write
+// only succeeds if the server copy still has that ETag ("*" matches any).
+// It returns the new ETag, which may be empty when the server does not
+// report one.
+func (c *CalDAVClient) PutItem(ctx context.Context, href string, item model.CalendarItem, etag string) (string, error) {
+	var buf bytes.Buffer
+	col := &model.CalendarCollection{Items: []model.CalendarItem{item}}
+	if err := ics.NewWriter().Write(ctx, col, &buf); err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = etag
+	}
+	data, respHeader, status, err := c.doRequest(ctx, "PUT", href, headers, buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusCreated && status != http.StatusNoContent && status != http.StatusOK {
+		return "", &salerr.APIError{Service: "CalDAV", StatusCode: status, Message: fmt.Sprintf("PUT %s: %s", href, truncateBody(data))}
+	}
+	return respHeader.Get("ETag"), nil
+}
+
+// DeleteItem removes a calendar object resource, guarded by etag if set.
+func (c *CalDAVClient) DeleteItem(ctx context.Context, href, etag string) error {
+	var headers map[string]string
+	if etag != "" {
+		headers = map[string]string{"If-Match": etag}
+	}
+	data, _, status, err := c.doRequest(ctx, "DELETE", href, headers, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusNoContent && status != http.StatusOK && status != http.StatusNotFound {
+		return &salerr.APIError{Service: "CalDAV", StatusCode: status, Message: fmt.Sprintf("DELETE %s: %s", href, truncateBody(data))}
+	}
+	return nil
+}
+
+// ObjectHref returns the resource path used for a new item in a calendar.
+func ObjectHref(calendarHref, uid string) string {
+	if !strings.HasSuffix(calendarHref, "/") {
+		calendarHref += "/"
+	}
+	return calendarHref + url.PathEscape(uid) + ".ics"
+}
+
+// FindCalendar resolves a calendar by href or display name. An empty name
+// selects the first calendar that accepts events.
+func FindCalendar(calendars []CalDAVCalendar, name string) (CalDAVCalendar, error) {
+	for _, cal := range calendars {
+		if name == "" && cal.Supports("VEVENT") {
+			return cal, nil
+		}
+		if name != "" && (cal.Href == name || strings.TrimSuffix(cal.Href, "/") == strings.TrimSuffix(name, "/") || strings.EqualFold(cal.DisplayName, name)) {
+			return cal, nil
+		}
+	}
+	if name == "" {
+		return CalDAVCalendar{}, fmt.Errorf("no CalDAV calendars found")
+	}
+	return CalDAVCalendar{}, fmt.Errorf("CalDAV calendar %q not found", name)
+}
+
+func newCalDAVUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b) + "@salja"
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func truncateBody(data []byte) string {
+	const max = 200
+	if len(data) > max {
+		return string(data[:max]) + "..."
+	}
+	return string(data)
+}
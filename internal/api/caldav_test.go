@@ -0,0 +1,324 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// fakeCalDAV is a minimal in-memory CalDAV server with one principal and
+// one calendar collection.
+type fakeCalDAV struct {
+	mu        sync.Mutex
+	objects   map[string]string // href -> iCalendar data
+	etags     map[string]string
+	version   int
+	lastRange string
+}
+
+const fakeCalendarHref = "/calendars/alice/work/"
+
+func newFakeCalDAV() *fakeCalDAV {
+	return &fakeCalDAV{objects: map[string]string{}, etags: map[string]string{}}
+}
+
+var (
+	compFilterRe = regexp.MustCompile(`<c:comp-filter name="(VEVENT|VTODO)">(.*?)</c:comp-filter>`)
+	hrefRe       = regexp.MustCompile(`<d:href>(.*?)</d:href>`)
+)
+
+func (f *fakeCalDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "alice" || pass != "app-password" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == "PROPFIND" && r.URL.Path == "/dav/":
+		multistatus(w, `<d:response><d:href>/dav/</d:href><d:propstat><d:prop>
+			<d:current-user-principal><d:href>/principals/alice/</d:href></d:current-user-principal>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	case r.Method == "PROPFIND" && r.URL.Path == "/principals/alice/":
+		multistatus(w, `<d:response><d:href>/principals/alice/</d:href><d:propstat><d:prop>
+			<c:calendar-home-set><d:href>/calendars/alice/</d:href></c:calendar-home-set>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	case r.Method == "PROPFIND" && r.URL.Path == "/calendars/alice/":
+		if r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		multistatus(w, `<d:response><d:href>/calendars/alice/</d:href><d:propstat><d:prop>
+			<d:resourcetype><d:collection/></d:resourcetype>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+			<d:response><d:href>/calendars/alice/work/</d:href><d:propstat><d:prop>
+			<d:resourcetype><d:collection/><c:calendar/></d:resourcetype>
+			<d:displayname>Work</d:displayname>
+			<cs:getctag>ctag-1</cs:getctag>
+			<c:supported-calendar-component-set><c:comp name="VEVENT"/><c:comp name="VTODO"/></c:supported-calendar-component-set>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+			<d:propstat><d:prop><d:getetag/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat></d:response>`)
+	case r.Method == "REPORT" && r.URL.Path == fakeCalendarHref:
+		var hrefs []string
+		if strings.Contains(string(body), "calendar-multiget") {
+			for _, m := range hrefRe.FindAllStringSubmatch(string(body), -1) {
+				hrefs = append(hrefs, m[1])
+			}
+		} else {
+			m := compFilterRe.FindStringSubmatch(string(body))
+			if m == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if m[2] != "" {
+				f.lastRange = m[2]
+			}
+			for href, data := range f.objects {
+				if strings.Contains(data, "BEGIN:"+m[1]) {
+					hrefs = append(hrefs, href)
+				}
+			}
+		}
+		var sb strings.Builder
+		for _, href := range hrefs {
+			data, ok := f.objects[href]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&sb, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>%s</d:getetag><c:calendar-data>%s</c:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`,
+				href, f.etags[href], xmlEscape(data))
+		}
+		multistatus(w, sb.String())
+	case r.Method == "PUT":
+		current, exists := f.etags[r.URL.Path]
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || (match != "*" && match != current)) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "text/calendar") {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		f.version++
+		f.objects[r.URL.Path] = string(body)
+		f.etags[r.URL.Path] = fmt.Sprintf(`"v%d"`, f.version)
+		w.Header().Set("ETag", f.etags[r.URL.Path])
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case r.Method == "DELETE":
+		current, exists := f.etags[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != current {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		delete(f.etags, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func multistatus(w http.ResponseWriter, responses string) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">%s</d:multistatus>`, responses)
+}
+
+func newTestCalDAV(t *testing.T) (*fakeCalDAV, *CalDAVClient) {
+	t.Helper()
+	fake := newFakeCalDAV()
+	ts := httptest.NewServer(fake)
+	t.Cleanup(ts.Close)
+	client, err := NewCalDAVClient(ts.URL+"/dav/", "alice", "app-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, client
+}
+
+func TestCalDAVDiscovery(t *testing.T) {
+	_, client := newTestCalDAV(t)
+
+	calendars, err := client.ListCalendars(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calendars) != 1 {
+		t.Fatalf("expected 1 calendar, got %d: %+v", len(calendars), calendars)
+	}
+	cal := calendars[0]
+	if cal.Href != fakeCalendarHref || cal.DisplayName != "Work" || cal.CTag != "ctag-1" {
+		t.Errorf("unexpected calendar: %+v", cal)
+	}
+	if !cal.Supports("VTODO") || cal.Supports("VJOURNAL") {
+		t.Errorf("unexpected components: %v", cal.Components)
+	}
+
+	found, err := FindCalendar(calendars, "work")
+	if err != nil || found.Href != fakeCalendarHref {
+		t.Errorf("FindCalendar by name: %+v, %v", found, err)
+	}
+	if _, err := FindCalendar(calendars, "Personal"); err == nil {
+		t.Error("expected error for unknown calendar")
+	}
+}
+
+func TestCalDAVBadCredentials(t *testing.T) {
+	fake := newFakeCalDAV()
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+	client, _ := NewCalDAVClient(ts.URL+"/dav/", "alice", "wrong")
+
+	_, err := client.ListCalendars(context.Background())
+	var apiErr *salerr.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 APIError, got %v", err)
+	}
+}
+
+func TestCalDAVPutQueryDelete(t *testing.T) {
+	fake, client := newTestCalDAV(t)
+	ctx := context.Background()
+
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	event := model.CalendarItem{UID: "ev-1@example.com", Title: "Standup", ItemType: model.ItemTypeEvent, StartTime: &start, EndTime: &end}
+	task := model.CalendarItem{UID: "todo-1@example.com", Title: "Write notes", ItemType: model.ItemTypeTask, Status: model.StatusPending}
+
+	etag, err := client.PutItem(ctx, ObjectHref(fakeCalendarHref, event.UID), event, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag == "" {
+		t.Error("expected ETag from PUT")
+	}
+	if _, err := client.PutItem(ctx, ObjectHref(fakeCalendarHref, task.UID), task, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// creating the same resource again must not overwrite it
+	_, err = client.PutItem(ctx, ObjectHref(fakeCalendarHref, event.UID), event, "")
+	var apiErr *salerr.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 on duplicate create, got %v", err)
+	}
+
+	events, err := client.QueryCalendar(ctx, fakeCalendarHref, "VEVENT", start.AddDate(0, 0, -1), start.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Item.Title != "Standup" || events[0].ETag != etag {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if !strings.Contains(fake.lastRange, `start="20250430T100000Z"`) || !strings.Contains(fake.lastRange, `end="20250502T100000Z"`) {
+		t.Errorf("time-range not sent, got %q", fake.lastRange)
+	}
+
+	todos, err := client.QueryCalendar(ctx, fakeCalendarHref, "VTODO", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 1 || todos[0].Item.ItemType != model.ItemTypeTask {
+		t.Fatalf("unexpected todos: %+v", todos)
+	}
+
+	got, err := client.MultiGet(ctx, fakeCalendarHref, []string{events[0].Href})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Item.UID != "ev-1@example.com" {
+		t.Fatalf("unexpected multiget: %+v", got)
+	}
+
+	// a stale ETag is rejected
+	event.Title = "Standup (moved)"
+	if _, err := client.PutItem(ctx, events[0].Href, event, `"stale"`); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for stale ETag, got %v", err)
+	}
+	newETag, err := client.PutItem(ctx, events[0].Href, event, etag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newETag == etag {
+		t.Error("expected ETag to change after update")
+	}
+
+	if err := client.DeleteItem(ctx, events[0].Href, newETag); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.objects[events[0].Href]; ok {
+		t.Error("expected object to be deleted")
+	}
+}
+
+func TestCalDAVRemote(t *testing.T) {
+	fake, client := newTestCalDAV(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	later := now.Add(time.Hour)
+	remote := &CalDAVRemote{Client: client, CalendarHref: fakeCalendarHref, Start: now.AddDate(0, -1, 0), End: now.AddDate(0, 1, 0)}
+
+	created, err := remote.Create(ctx, model.CalendarItem{Title: "Review", ItemType: model.ItemTypeEvent, StartTime: &now, EndTime: &later})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UID == "" {
+		t.Fatal("expected a UID to be generated")
+	}
+
+	items, err := remote.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].UID != created.UID {
+		t.Fatalf("unexpected list: %+v", items)
+	}
+
+	edited := items[0]
+	edited.Title = "Review (edited)"
+	updated, err := remote.Update(ctx, created.UID, edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "Review (edited)" {
+		t.Errorf("updated title = %q", updated.Title)
+	}
+	href := ObjectHref(fakeCalendarHref, created.UID)
+	if !strings.Contains(fake.objects[href], "Review (edited)") {
+		t.Error("server copy was not updated")
+	}
+
+	if err := remote.Delete(ctx, created.UID); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("expected no objects left, got %d", len(fake.objects))
+	}
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+)
+
+// RateLimit is a token-bucket quota for one service plus the number of
+// requests allowed in flight at once.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+	Concurrency       int
+}
+
+var defaultRateLimits = map[string]RateLimit{
+	// Google Calendar API quota: 10 QPS for calendar.events.insert
+	"google": {RequestsPerSecond: 10, Burst: 10, Concurrency: 4},
+	// Microsoft Graph: ~4 requests per second and 4 concurrent per mailbox
+	"microsoft":      {RequestsPerSecond: 4, Burst: 4, Concurrency: 4},
+	"microsoft-todo": {RequestsPerSecond: 4, Burst: 4, Concurrency: 4},
+	"todoist":        {RequestsPerSecond: 20, Burst: 10, Concurrency: 4},
+	"ticktick":       {RequestsPerSecond: 10, Burst: 5, Concurrency: 2},
+	// Notion: an average of three requests per second per integration
+	"notion": {RequestsPerSecond: 3, Burst: 3, Concurrency: 2},
+	"caldav": {RequestsPerSecond: 10, Burst: 5, Concurrency: 4},
+}
+
+// DefaultRateLimit returns the built-in quota for a service.
+func DefaultRateLimit(service string) RateLimit {
+	if rl, ok := defaultRateLimits[service]; ok {
+		return rl
+	}
+	return RateLimit{RequestsPerSecond: 5, Burst: 5, Concurrency: 1}
+}
+
+// Limiter is a token bucket shared by every request to one service. When any
+// request is throttled, Pause holds all of them back.
+type Limiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewLimiter returns a full bucket. A non-positive rate disables the quota
+// but still honours Pause.
+func NewLimiter(rl RateLimit) *Limiter {
+	burst := float64(rl.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rl.RequestsPerSecond, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Wait blocks until a request may be sent.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		if now.Before(l.pausedUntil) {
+			d := l.pausedUntil.Sub(now)
+			l.mu.Unlock()
+			if err := sleepCtx(ctx, d); err != nil {
+				return err
+			}
+			continue
+		}
+		if l.rate <= 0 {
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		d := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		if err := sleepCtx(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// Pause stops all requests for d and empties the bucket so they resume at
+// the steady rate rather than in a burst.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+	l.last = l.pausedUntil
+}
+
+func (l *Limiter) waitPause(ctx context.Context) error {
+	l.mu.Lock()
+	d := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+	if d <= 0 {
+		return ctx.Err()
+	}
+	return sleepCtx(ctx, d)
+}
+
+// wrap returns a copy of client whose requests pass through the limiter.
+func (l *Limiter) wrap(client *http.Client) *http.Client {
+	wrapped := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped.Transport = &limitedTransport{base: base, limiter: l}
+	return &wrapped
+}
+
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *Limiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		d := retryAfter(resp.Header)
+		if d == 0 {
+			d = time.Second
+		}
+		t.limiter.Pause(d)
+	}
+	return resp, err
+}
+
+// UseLimiter routes the client's requests through l. Clients that retry on
+// their own leave that to the Executor once limited.
+func (c *GCalClient) UseLimiter(l *Limiter) {
+	c.httpClient = l.wrap(c.httpClient)
+	c.limited = true
+}
+
+// UseLimiter routes the client's requests through l.
+func (c *GTasksClient) UseLimiter(l *Limiter) {
+	c.httpClient = l.wrap(c.httpClient)
+	c.limited = true
+}
+
+// UseLimiter routes the client's requests through l.
+func (c *MSGraphClient) UseLimiter(l *Limiter) {
+	c.httpClient = l.wrap(c.httpClient)
+	c.limited = true
+}
+
+// UseLimiter routes the client's requests through l.
+func (c *TodoistClient) UseLimiter(l *Limiter) { c.httpClient = l.wrap(c.httpClient) }
+
+// UseLimiter routes the client's requests through l.
+func (c *TickTickClient) UseLimiter(l *Limiter) { c.httpClient = l.wrap(c.httpClient) }
+
+// UseLimiter routes the client's requests through l.
+func (c *NotionClient) UseLimiter(l *Limiter) { c.httpClient = l.wrap(c.httpClient) }
+
+// UseLimiter routes the client's requests through l.
+func (c *CalDAVClient) UseLimiter(l *Limiter) {
+	c.httpClient = l.wrap(c.httpClient)
+	c.limited = true
+}
+
+// retryRequest retries fn with salerr.Retry, or runs it once for clients
+// behind a Limiter, whose Executor retries with a shared back-off instead.
+func retryRequest(limited bool, fn func() error) error {
+	if limited {
+		return fn()
+	}
+	return salerr.Retry(salerr.DefaultRetryConfig(), fn)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is absent or unparseable.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Progress reports the outcome of one executor task.
+type Progress struct {
+	Index int
+	Err   error
+	Done  int
+	Total int
+}
+
+// Executor runs tasks on a bounded pool of workers that share a Limiter.
+type Executor struct {
+	Limiter     *Limiter
+	Concurrency int
+	MaxRetries  int
+}
+
+// NewExecutor returns an executor with the concurrency from rl and a limiter
+// built from it. Clients used by the tasks should UseLimiter(e.Limiter).
+func NewExecutor(rl RateLimit) *Executor {
+	concurrency := rl.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Executor{Limiter: NewLimiter(rl), Concurrency: concurrency, MaxRetries: 3}
+}
+
+// Run calls fn for every index in [0, n) and reports each result on the
+// returned channel, which is closed once all tasks have finished or ctx is
+// done. Tasks failing with a rate-limit or transient API error are retried
+// after a back-off that pauses every worker.
+func (e *Executor) Run(ctx context.Context, n int, fn func(ctx context.Context, i int) error) <-chan Progress {
+	out := make(chan Progress)
+	jobs := make(chan int)
+
+	var mu sync.Mutex
+	done := 0
+	report := func(i int, err error) {
+		mu.Lock()
+		done++
+		p := Progress{Index: i, Err: err, Done: done, Total: n}
+		mu.Unlock()
+		out <- p
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < e.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				report(i, e.runTask(ctx, i, fn))
+			}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		defer close(jobs)
+		for i := 0; i < n; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- i:
+			}
+		}
+	}()
+	return out
+}
+
+func (e *Executor) runTask(ctx context.Context, i int, fn func(ctx context.Context, i int) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err := e.Limiter.waitPause(ctx); err != nil {
+			return err
+		}
+		err = fn(ctx, i)
+		var apiErr *salerr.APIError
+		if err == nil || attempt >= e.MaxRetries || !errors.As(err, &apiErr) || !apiErr.IsRetryable() {
+			return err
+		}
+		backoff := time.Duration(1<<attempt) * time.Second
+		if apiErr.RetryAfter > backoff {
+			backoff = apiErr.RetryAfter
+		}
+		e.Limiter.Pause(backoff)
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/ics"
 	"github.com/gongahkia/salja/internal/logging"
 	"github.com/gongahkia/salja/internal/model"
 )
@@ -22,6 +24,7 @@ const gcalBaseURL = "https://www.googleapis.com/calendar/v3"
 type GCalClient struct {
 	token      *Token
 	httpClient *http.Client
+	limited    bool
 }
 
 func NewGCalClient(token *Token) *GCalClient {
@@ -39,7 +42,7 @@ func (c *GCalClient) doRequest(ctx context.Context, method, url string, body int
 	var respBody []byte
 	var statusCode int
 
-	err := salerr.Retry(salerr.DefaultRetryConfig(), func() error {
+	err := retryRequest(c.limited, func() error {
 		var reqBody io.Reader
 		if body != nil {
 			data, err := json.Marshal(body)
@@ -66,7 +69,7 @@ func (c *GCalClient) doRequest(ctx context.Context, method, url string, body int
 		statusCode = resp.StatusCode
 
 		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
-			return &salerr.APIError{Service: "google-calendar", StatusCode: resp.StatusCode, Message: string(respBody)}
+			return &salerr.APIError{Service: "google-calendar", StatusCode: resp.StatusCode, Message: string(respBody), RetryAfter: retryAfter(resp.Header)}
 		}
 
 		return err
@@ -85,9 +88,15 @@ type GCalEvent struct {
 	End            *GCalDateTime      `json:"end"`
 	Recurrence     []string           `json:"recurrence,omitempty"`
 	Attendees      []GCalAttendee     `json:"attendees,omitempty"`
+	Organizer      *GCalPerson        `json:"organizer,omitempty"`
 	ConferenceData *GCalConference    `json:"conferenceData,omitempty"`
 	Status         string             `json:"status,omitempty"`
 	ExtendedProps  *GCalExtendedProps `json:"extendedProperties,omitempty"`
+	Updated        string             `json:"updated,omitempty"`
+	// RecurringEventID and OriginalStartTime name the series and the
+	// occurrence an instance of a recurring event stands for.
+	RecurringEventID  string        `json:"recurringEventId,omitempty"`
+	OriginalStartTime *GCalDateTime `json:"originalStartTime,omitempty"`
 }
 
 type GCalDateTime struct {
@@ -100,6 +109,25 @@ type GCalAttendee struct {
 	Email          string `json:"email"`
 	DisplayName    string `json:"displayName,omitempty"`
 	ResponseStatus string `json:"responseStatus,omitempty"`
+	Optional       bool   `json:"optional,omitempty"`
+	Resource       bool   `json:"resource,omitempty"`
+	// Organizer marks the organizer's own entry; it is read-only.
+	Organizer bool `json:"organizer,omitempty"`
+}
+
+// GCalPerson is an event's organizer, which Google sets from the calendar
+// the event is created in.
+type GCalPerson struct {
+	Email       string `json:"email,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// gcalResponses maps Google's attendee response statuses to PARTSTAT.
+var gcalResponses = map[string]model.PartStat{
+	"needsAction": model.PartStatNeedsAction,
+	"accepted":    model.PartStatAccepted,
+	"declined":    model.PartStatDeclined,
+	"tentative":   model.PartStatTentative,
 }
 
 type GCalConference struct {
@@ -129,6 +157,7 @@ type GCalCalendar struct {
 type GCalEventList struct {
 	Items         []GCalEvent `json:"items"`
 	NextPageToken string      `json:"nextPageToken,omitempty"`
+	NextSyncToken string      `json:"nextSyncToken,omitempty"`
 }
 
 func (c *GCalClient) ListCalendars(ctx context.Context) ([]GCalCalendar, error) {
@@ -178,6 +207,62 @@ func (c *GCalClient) ListEvents(ctx context.Context, calendarID string, timeMin,
 	return allEvents, nil
 }
 
+// ListInstances returns the occurrences of a recurring event that start
+// between timeMin and timeMax.
+func (c *GCalClient) ListInstances(ctx context.Context, calendarID, eventID string, timeMin, timeMax time.Time) ([]GCalEvent, error) {
+	return c.listInstances(ctx, calendarID, eventID, timeMin, timeMax, false)
+}
+
+// listInstances is ListInstances that, with showDeleted, also returns the
+// cancelled occurrences.
+func (c *GCalClient) listInstances(ctx context.Context, calendarID, eventID string, timeMin, timeMax time.Time, showDeleted bool) ([]GCalEvent, error) {
+	base := fmt.Sprintf("%s/calendars/%s/events/%s/instances?timeMin=%s&timeMax=%s&maxResults=2500",
+		gcalBaseURL, calendarID, eventID,
+		url.QueryEscape(timeMin.Format(time.RFC3339)),
+		url.QueryEscape(timeMax.Format(time.RFC3339)),
+	)
+	if showDeleted {
+		base += "&showDeleted=true"
+	}
+	var instances []GCalEvent
+	for link := base; link != ""; {
+		data, status, err := c.doRequest(ctx, "GET", link, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Google Calendar", StatusCode: status, Message: string(data)}
+		}
+		var list GCalEventList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		instances = append(instances, list.Items...)
+		link = ""
+		if list.NextPageToken != "" {
+			link = base + "&pageToken=" + url.QueryEscape(list.NextPageToken)
+		}
+	}
+	return instances, nil
+}
+
+// GetEvent returns an event, or nil when it no longer exists.
+func (c *GCalClient) GetEvent(ctx context.Context, calendarID, eventID string) (*GCalEvent, error) {
+	url := fmt.Sprintf("%s/calendars/%s/events/%s", gcalBaseURL, calendarID, eventID)
+	data, status, err := c.doRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound || status == http.StatusGone {
+		return nil, nil
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Google Calendar", StatusCode: status, Message: string(data)}
+	}
+	var event GCalEvent
+	return &event, json.Unmarshal(data, &event)
+}
+
 func (c *GCalClient) InsertEvent(ctx context.Context, calendarID string, event *GCalEvent) (*GCalEvent, error) {
 	url := fmt.Sprintf("%s/calendars/%s/events", gcalBaseURL, calendarID)
 	data, status, err := c.doRequest(ctx, "POST", url, event)
@@ -231,6 +316,12 @@ func GCalToCalendarItem(event GCalEvent) model.CalendarItem {
 		item.Status = model.StatusCancelled
 	}
 
+	if event.Updated != "" {
+		if t, err := time.Parse(time.RFC3339, event.Updated); err == nil {
+			item.UpdatedAt = &t
+		}
+	}
+
 	if event.Start != nil {
 		if t := parseGCalTime(event.Start); t != nil {
 			item.StartTime = t
@@ -246,13 +337,23 @@ func GCalToCalendarItem(event GCalEvent) model.CalendarItem {
 	}
 
 	if len(event.Recurrence) > 0 {
+		var exDates, rDates []time.Time
 		for _, rule := range event.Recurrence {
-			if len(rule) > 6 && rule[:6] == "RRULE:" {
+			switch {
+			case len(rule) > 6 && rule[:6] == "RRULE:":
 				if rec, err := parseRRuleString(rule[6:]); err == nil {
 					item.Recurrence = rec
 				}
+			case strings.HasPrefix(rule, "EXDATE"):
+				exDates = append(exDates, parseGCalDateList(rule)...)
+			case strings.HasPrefix(rule, "RDATE"):
+				rDates = append(rDates, parseGCalDateList(rule)...)
 			}
 		}
+		if item.Recurrence != nil {
+			item.Recurrence.ExDates = exDates
+			item.Recurrence.RDates = rDates
+		}
 	}
 
 	// Map conference link to description
@@ -265,14 +366,101 @@ func GCalToCalendarItem(event GCalEvent) model.CalendarItem {
 		}
 	}
 
-	// Map attendees to tags for interop
+	if event.Organizer != nil && event.Organizer.Email != "" {
+		item.Organizer = &model.Attendee{Email: event.Organizer.Email, Name: event.Organizer.DisplayName}
+	}
 	for _, a := range event.Attendees {
-		item.Tags = append(item.Tags, "attendee:"+a.Email)
+		attendee := model.Attendee{
+			Email:  a.Email,
+			Name:   a.DisplayName,
+			Role:   model.RoleRequired,
+			Status: gcalResponses[a.ResponseStatus],
+		}
+		if a.Optional {
+			attendee.Role = model.RoleOptional
+		}
+		if a.Resource {
+			attendee.Role = model.RoleNonParticipant
+		}
+		item.Attendees = append(item.Attendees, attendee)
 	}
 
 	return item
 }
 
+// GCalToCalendarItems maps events listed with singleEvents=false. Changed
+// occurrences of a listed series become its overrides and cancelled ones
+// its exception dates; occurrences as the series has them, which an
+// instances listing returns, are dropped. An occurrence whose series is not
+// listed stays an item of its own.
+func GCalToCalendarItems(events []GCalEvent) []model.CalendarItem {
+	items := make([]model.CalendarItem, 0, len(events))
+	series := make(map[string]int)
+	masters := make(map[string]GCalEvent)
+	for _, event := range events {
+		if event.RecurringEventID == "" {
+			series[event.ID] = len(items)
+			masters[event.ID] = event
+			items = append(items, GCalToCalendarItem(event))
+		}
+	}
+	for _, event := range events {
+		if event.RecurringEventID == "" {
+			continue
+		}
+		i, ok := series[event.RecurringEventID]
+		var rid *time.Time
+		if event.OriginalStartTime != nil {
+			rid = parseGCalTime(event.OriginalStartTime)
+		}
+		if !ok || rid == nil || items[i].Recurrence == nil {
+			if event.Status != "cancelled" {
+				items = append(items, GCalToCalendarItem(event))
+			}
+			continue
+		}
+		rec := items[i].Recurrence
+		if event.Status == "cancelled" {
+			rec.ExDates = append(rec.ExDates, *rid)
+			continue
+		}
+		if !gcalInstanceChanged(masters[event.RecurringEventID], event) {
+			continue
+		}
+		override := GCalToCalendarItem(event)
+		override.UID = items[i].UID
+		rec.Overrides = append(rec.Overrides, model.Override{RecurrenceID: *rid, Item: override})
+	}
+	return items
+}
+
+// gcalInstanceChanged reports whether an occurrence differs from its series
+// in title, description, location, start or length.
+func gcalInstanceChanged(master, instance GCalEvent) bool {
+	if instance.Summary != master.Summary || instance.Description != master.Description || instance.Location != master.Location {
+		return true
+	}
+	if instance.Start == nil || instance.OriginalStartTime == nil {
+		return true
+	}
+	start, original := parseGCalTime(instance.Start), parseGCalTime(instance.OriginalStartTime)
+	if start == nil || original == nil || !start.Equal(*original) {
+		return true
+	}
+	return gcalLength(instance) != gcalLength(master)
+}
+
+func gcalLength(event GCalEvent) time.Duration {
+	if event.Start == nil || event.End == nil {
+		return 0
+	}
+	start, end := parseGCalTime(event.Start), parseGCalTime(event.End)
+	if start == nil || end == nil {
+		return 0
+	}
+	return end.Sub(*start)
+}
+
 // CalendarItemToGCal maps the unified model to a Google Calendar event.
 func CalendarItemToGCal(item model.CalendarItem) GCalEvent {
 	event := GCalEvent{
@@ -281,6 +469,9 @@ func CalendarItemToGCal(item model.CalendarItem) GCalEvent {
 		Description: item.Description,
 		Location:    item.Location,
 	}
+	if item.UID != "" {
+		event.ExtendedProps = &GCalExtendedProps{Private: map[string]string{SourceUIDKey: item.UID}}
+	}
 
 	if item.StartTime != nil {
 		event.Start = &GCalDateTime{}
@@ -299,9 +490,105 @@ func CalendarItemToGCal(item model.CalendarItem) GCalEvent {
 		}
 	}
 
+	if rec := item.Recurrence; rec != nil {
+		event.Recurrence = []string{"RRULE:" + ics.FormatRRule(rec)}
+		if len(rec.ExDates) > 0 {
+			event.Recurrence = append(event.Recurrence, gcalDateList("EXDATE", rec.ExDates, item))
+		}
+		if len(rec.RDates) > 0 {
+			event.Recurrence = append(event.Recurrence, gcalDateList("RDATE", rec.RDates, item))
+		}
+		// Google expands a series in the zone of its start, which it requires
+		if !item.IsAllDay {
+			tz := item.Timezone
+			if tz == "" {
+				tz = "UTC"
+			}
+			if event.Start != nil {
+				event.Start.TimeZone = tz
+			}
+			if event.End != nil {
+				event.End.TimeZone = tz
+			}
+		}
+	}
+
+	// the organizer is whoever owns the calendar; only attendees are sent
+	for _, a := range item.Attendees {
+		if a.Email == "" {
+			continue
+		}
+		attendee := GCalAttendee{
+			Email:       a.Email,
+			DisplayName: a.Name,
+			Optional:    a.Role == model.RoleOptional,
+			Resource:    a.Role == model.RoleNonParticipant,
+		}
+		for response, status := range gcalResponses {
+			if status == a.Status {
+				attendee.ResponseStatus = response
+			}
+		}
+		event.Attendees = append(event.Attendees, attendee)
+	}
+
 	return event
 }
 
+// gcalDateList writes an EXDATE or RDATE line for an event's recurrence
+// list, in the zone the series is expanded in.
+func gcalDateList(name string, dates []time.Time, item model.CalendarItem) string {
+	values := make([]string, len(dates))
+	switch {
+	case item.IsAllDay:
+		for i, d := range dates {
+			values[i] = d.Format("20060102")
+		}
+		return name + ";VALUE=DATE:" + strings.Join(values, ",")
+	case item.Timezone != "" && item.Timezone != "UTC":
+		if loc, err := time.LoadLocation(item.Timezone); err == nil {
+			for i, d := range dates {
+				values[i] = d.In(loc).Format("20060102T150405")
+			}
+			return name + ";TZID=" + item.Timezone + ":" + strings.Join(values, ",")
+		}
+	}
+	for i, d := range dates {
+		values[i] = d.UTC().Format("20060102T150405Z")
+	}
+	return name + ":" + strings.Join(values, ",")
+}
+
+// parseGCalDateList reads the dates of an EXDATE or RDATE line.
+func parseGCalDateList(line string) []time.Time {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return nil
+	}
+	loc := time.UTC
+	for _, param := range strings.Split(head, ";")[1:] {
+		if tz, ok := strings.CutPrefix(param, "TZID="); ok {
+			if l, err := time.LoadLocation(tz); err == nil {
+				loc = l
+			}
+		}
+	}
+	var dates []time.Time
+	for _, v := range strings.Split(value, ",") {
+		for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+			l := loc
+			if strings.HasSuffix(v, "Z") {
+				l = time.UTC
+			}
+			if t, err := time.ParseInLocation(layout, v, l); err == nil {
+				dates = append(dates, t)
+				break
+			}
+		}
+	}
+	return dates
+}
+
 func parseGCalTime(dt *GCalDateTime) *time.Time {
 	if dt.DateTime != "" {
 		if t, err := time.Parse(time.RFC3339, dt.DateTime); err == nil {
@@ -0,0 +1,363 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+const gtasksBaseURL = "https://tasks.googleapis.com/tasks/v1"
+
+// GTasksDefaultList is the ID Google Tasks accepts for the user's default
+// task list.
+const GTasksDefaultList = "@default"
+
+// GTasksClient is a REST API client for Google Tasks. It takes the same
+// Google token as GCalClient.
+type GTasksClient struct {
+	token      *Token
+	httpClient *http.Client
+	limited    bool
+}
+
+func NewGTasksClient(token *Token) *GTasksClient {
+	return NewGTasksClientWithTimeout(token, 30*time.Second)
+}
+
+func NewGTasksClientWithTimeout(token *Token, timeout time.Duration) *GTasksClient {
+	return &GTasksClient{
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *GTasksClient) doRequest(ctx context.Context, method, url string, body interface{}) ([]byte, int, error) {
+	var respBody []byte
+	var statusCode int
+
+	err := retryRequest(c.limited, func() error {
+		var reqBody io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			reqBody = bytes.NewReader(data)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token.AccessToken)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err = io.ReadAll(resp.Body)
+		statusCode = resp.StatusCode
+
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			return &salerr.APIError{Service: "google-tasks", StatusCode: resp.StatusCode, Message: string(respBody), RetryAfter: retryAfter(resp.Header)}
+		}
+
+		return err
+	})
+
+	return respBody, statusCode, err
+}
+
+// GTask is a Google task. Status is needsAction or completed. A subtask is a
+// task of its own whose Parent is the ID of the task it sits under; Position
+// orders tasks among their siblings and sorts as a string.
+type GTask struct {
+	ID        string `json:"id,omitempty"`
+	Title     string `json:"title"`
+	Notes     string `json:"notes,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Due       string `json:"due,omitempty"`
+	Completed string `json:"completed,omitempty"`
+	Parent    string `json:"parent,omitempty"`
+	Position  string `json:"position,omitempty"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	Updated   string `json:"updated,omitempty"`
+}
+
+// GTaskList is a Google Tasks list.
+type GTaskList struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+func gtasksListPath(listID string) string {
+	return gtasksBaseURL + "/lists/" + url.PathEscape(listID) + "/tasks"
+}
+
+func (c *GTasksClient) ListTaskLists(ctx context.Context) ([]GTaskList, error) {
+	var lists []GTaskList
+	pageToken := ""
+	for {
+		u := gtasksBaseURL + "/users/@me/lists?maxResults=100"
+		if pageToken != "" {
+			u += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		data, status, err := c.doRequest(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Google Tasks", StatusCode: status, Message: string(data)}
+		}
+		var page struct {
+			Items         []GTaskList `json:"items"`
+			NextPageToken string      `json:"nextPageToken,omitempty"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		lists = append(lists, page.Items...)
+		if page.NextPageToken == "" {
+			return lists, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// ListTasks returns every task of a list, completed and subtasks included.
+func (c *GTasksClient) ListTasks(ctx context.Context, listID string) ([]GTask, error) {
+	var tasks []GTask
+	pageToken := ""
+	for {
+		params := url.Values{}
+		params.Set("maxResults", "100")
+		params.Set("showCompleted", "true")
+		// tasks completed in the apps are hidden rather than just completed
+		params.Set("showHidden", "true")
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		data, status, err := c.doRequest(ctx, "GET", gtasksListPath(listID)+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Google Tasks", StatusCode: status, Message: string(data)}
+		}
+		var page struct {
+			Items         []GTask `json:"items"`
+			NextPageToken string  `json:"nextPageToken,omitempty"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page.Items...)
+		if page.NextPageToken == "" {
+			return tasks, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// InsertTask creates a task, under parent when it is set and after the
+// sibling previous, or first when previous is empty.
+func (c *GTasksClient) InsertTask(ctx context.Context, listID string, task *GTask, parent, previous string) (*GTask, error) {
+	params := url.Values{}
+	if parent != "" {
+		params.Set("parent", parent)
+	}
+	if previous != "" {
+		params.Set("previous", previous)
+	}
+	u := gtasksListPath(listID)
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	data, status, err := c.doRequest(ctx, "POST", u, task)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Google Tasks", StatusCode: status, Message: string(data)}
+	}
+	var created GTask
+	return &created, json.Unmarshal(data, &created)
+}
+
+// UpdateTask patches a task's fields. Its parent and position cannot be
+// changed this way.
+func (c *GTasksClient) UpdateTask(ctx context.Context, listID string, task *GTask) (*GTask, error) {
+	data, status, err := c.doRequest(ctx, "PATCH", gtasksListPath(listID)+"/"+url.PathEscape(task.ID), task)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Google Tasks", StatusCode: status, Message: string(data)}
+	}
+	var updated GTask
+	return &updated, json.Unmarshal(data, &updated)
+}
+
+// DeleteTask deletes a task and its subtasks.
+func (c *GTasksClient) DeleteTask(ctx context.Context, listID, taskID string) error {
+	data, status, err := c.doRequest(ctx, "DELETE", gtasksListPath(listID)+"/"+url.PathEscape(taskID), nil)
+	if err != nil {
+		return err
+	}
+	if status != 204 {
+		return &salerr.APIError{Service: "Google Tasks", StatusCode: status, Message: string(data)}
+	}
+	return nil
+}
+
+// GTaskToCalendarItem maps a Google task to the unified model, without its
+// subtasks; GTasksToCalendarItems folds those in.
+func GTaskToCalendarItem(task GTask) model.CalendarItem {
+	notes, _ := splitDescriptionMarker(task.Notes)
+	item := model.CalendarItem{
+		UID:         task.ID,
+		Title:       task.Title,
+		Description: notes,
+		ItemType:    model.ItemTypeTask,
+		Status:      model.StatusPending,
+	}
+	if task.Status == "completed" {
+		item.Status = model.StatusCompleted
+		if t, err := time.Parse(time.RFC3339, task.Completed); err == nil {
+			item.CompletionDate = &t
+		}
+	}
+	// due is a date; the API discards the time of day
+	if t, err := time.Parse(time.RFC3339, task.Due); err == nil {
+		item.DueDate = &t
+	}
+	if t, err := time.Parse(time.RFC3339, task.Updated); err == nil {
+		item.UpdatedAt = &t
+	}
+	return item
+}
+
+// GTasksToCalendarItems maps the tasks of a list, folding each subtask into
+// the Subtasks of its parent in position order.
+func GTasksToCalendarItems(tasks []GTask) []model.CalendarItem {
+	children := gtaskChildren(tasks)
+	var items []model.CalendarItem
+	for _, task := range tasks {
+		if task.Parent != "" {
+			continue
+		}
+		item := GTaskToCalendarItem(task)
+		for i, sub := range children[task.ID] {
+			st := model.Subtask{Title: sub.Title, Status: model.StatusPending, SortOrder: i}
+			if sub.Status == "completed" {
+				st.Status = model.StatusCompleted
+			}
+			item.Subtasks = append(item.Subtasks, st)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// gtaskChildren groups subtasks by parent ID, each group in position order.
+func gtaskChildren(tasks []GTask) map[string][]GTask {
+	children := make(map[string][]GTask)
+	for _, task := range tasks {
+		if task.Parent != "" {
+			children[task.Parent] = append(children[task.Parent], task)
+		}
+	}
+	for _, subs := range children {
+		sort.SliceStable(subs, func(i, j int) bool { return subs[i].Position < subs[j].Position })
+	}
+	return children
+}
+
+// CalendarItemToGTask maps the unified model to a Google task. Google Tasks
+// has no priority, tags, recurrence or reminders, and its due date has no
+// time of day. Subtasks are mapped separately by GTaskSubtasks.
+func CalendarItemToGTask(item model.CalendarItem) GTask {
+	task := GTask{
+		ID:     item.UID,
+		Title:  item.Title,
+		Notes:  stampDescription(item.Description, item.UID),
+		Status: "needsAction",
+	}
+	// Google Tasks has no cancelled state; closing the task keeps it off the list
+	if item.Status == model.StatusCompleted || item.Status == model.StatusCancelled {
+		task.Status = "completed"
+		if item.CompletionDate != nil {
+			task.Completed = item.CompletionDate.UTC().Format(time.RFC3339)
+		}
+	}
+	due := item.DueDate
+	if due == nil {
+		due = item.StartTime
+	}
+	if due != nil {
+		task.Due = due.Format("2006-01-02") + "T00:00:00.000Z"
+	}
+	return task
+}
+
+// GTaskSubtasks maps an item's subtasks to the tasks written under it.
+func GTaskSubtasks(item model.CalendarItem) []GTask {
+	var subs []GTask
+	for _, st := range item.Subtasks {
+		sub := GTask{Title: st.Title, Status: "needsAction"}
+		if st.Status == model.StatusCompleted {
+			sub.Status = "completed"
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// SyncSubtasks makes the subtasks of parent read want, given its current
+// ones in order. Subtasks are matched by position: changed ones are
+// updated, missing ones added after the last and surplus ones deleted. It
+// returns the resulting subtasks.
+func (c *GTasksClient) SyncSubtasks(ctx context.Context, listID, parent string, current, want []GTask) ([]GTask, error) {
+	result := make([]GTask, 0, len(want))
+	previous := ""
+	for i, w := range want {
+		if i < len(current) {
+			cur := current[i]
+			previous = cur.ID
+			if cur.Title == w.Title && cur.Status == w.Status {
+				result = append(result, cur)
+				continue
+			}
+			w.ID = cur.ID
+			updated, err := c.UpdateTask(ctx, listID, &w)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, *updated)
+			continue
+		}
+		created, err := c.InsertTask(ctx, listID, &w, parent, previous)
+		if err != nil {
+			return nil, err
+		}
+		previous = created.ID
+		result = append(result, *created)
+	}
+	for i := len(want); i < len(current); i++ {
+		if err := c.DeleteTask(ctx, listID, current[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// ErrCursorExpired is returned when a provider no longer accepts a stored
+// sync token or delta link. Callers should discard it and fetch everything.
+var ErrCursorExpired = errors.New("sync cursor expired; a full fetch is required")
+
+// ChangeSet is the result of an incremental fetch.
+type ChangeSet struct {
+	// Items holds items created or modified since the cursor.
+	Items []model.CalendarItem
+	// Removed lists the IDs of items deleted or cancelled since the cursor.
+	Removed []string
+	// Present, when non-nil, is the complete set of IDs that still exist
+	// remotely. Local items not in it have been removed.
+	Present map[string]bool
+	// Cursor is the token to pass on the next call.
+	Cursor string
+	// Full reports that the provider returned a complete listing rather
+	// than a delta.
+	Full bool
+}
+
+func (cs *ChangeSet) markFull() {
+	cs.Full = true
+	cs.Present = make(map[string]bool, len(cs.Items))
+	for _, item := range cs.Items {
+		cs.Present[item.UID] = true
+	}
+}
+
+// Changes returns events changed since cursor, or every event between start
+// and end when cursor is empty. Cancelled events are reported as removed. A
+// changed or cancelled occurrence reports its whole series, read again with
+// its exceptions between start and end.
+func (c *GCalClient) Changes(ctx context.Context, calendarID, cursor string, start, end time.Time) (*ChangeSet, error) {
+	params := url.Values{}
+	params.Set("singleEvents", "false")
+	params.Set("maxResults", "2500")
+	if cursor != "" {
+		params.Set("syncToken", cursor)
+	} else {
+		params.Set("timeMin", start.Format(time.RFC3339))
+		params.Set("timeMax", end.Format(time.RFC3339))
+	}
+	base := fmt.Sprintf("%s/calendars/%s/events", gcalBaseURL, url.PathEscape(calendarID))
+
+	cs := &ChangeSet{}
+	var events []GCalEvent
+	pageToken := ""
+	for {
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		data, status, err := c.doRequest(ctx, "GET", base+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusGone {
+			return nil, ErrCursorExpired
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Google Calendar", StatusCode: status, Message: string(data)}
+		}
+		var list GCalEventList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		for _, event := range list.Items {
+			if event.Status == "cancelled" {
+				cs.Removed = append(cs.Removed, event.ID)
+				if event.RecurringEventID == "" {
+					continue
+				}
+			}
+			events = append(events, event)
+		}
+		if list.NextPageToken == "" {
+			cs.Cursor = list.NextSyncToken
+			break
+		}
+		pageToken = list.NextPageToken
+	}
+	events, err := c.withSeries(ctx, calendarID, events, start, end)
+	if err != nil {
+		return nil, err
+	}
+	cs.Items = GCalToCalendarItems(events)
+	if cursor == "" {
+		cs.markFull()
+	}
+	return cs, nil
+}
+
+// withSeries adds the series of occurrences in events whose series is not
+// listed, each with its instances between start and end. The instances
+// replace the occurrences events holds for it.
+func (c *GCalClient) withSeries(ctx context.Context, calendarID string, events []GCalEvent, start, end time.Time) ([]GCalEvent, error) {
+	fetched := make(map[string]bool)
+	for _, event := range events {
+		if event.RecurringEventID == "" {
+			fetched[event.ID] = true
+		}
+	}
+	var out []GCalEvent
+	for _, event := range events {
+		id := event.RecurringEventID
+		if id == "" || fetched[id] {
+			continue
+		}
+		fetched[id] = true
+		master, err := c.GetEvent(ctx, calendarID, id)
+		if err != nil {
+			return nil, err
+		}
+		if master == nil || master.Status == "cancelled" {
+			continue
+		}
+		instances, err := c.listInstances(ctx, calendarID, id, start, end, true)
+		if err != nil {
+			return nil, err
+		}
+		out = append(append(out, *master), instances...)
+	}
+	if len(out) == 0 {
+		return events, nil
+	}
+	listed := make(map[string]bool, len(out))
+	for _, event := range out {
+		listed[event.ID] = true
+	}
+	for _, event := range events {
+		if !listed[event.ID] {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+type msGraphDeltaEvent struct {
+	MSGraphEvent
+	Removed *struct {
+		Reason string `json:"reason"`
+	} `json:"@removed,omitempty"`
+}
+
+type msGraphDeltaList struct {
+	Value     []msGraphDeltaEvent `json:"value"`
+	NextLink  string              `json:"@odata.nextLink,omitempty"`
+	DeltaLink string              `json:"@odata.deltaLink,omitempty"`
+}
+
+// Changes follows a calendarView delta query on a calendar, or on the
+// default calendar when calendarID is empty. cursor is the deltaLink
+// returned by the previous call; when empty a new query over start..end is
+// started.
+func (c *MSGraphClient) Changes(ctx context.Context, calendarID, cursor string, start, end time.Time) (*ChangeSet, error) {
+	link := cursor
+	if link == "" {
+		link = fmt.Sprintf("%s%s/calendarView/delta?startDateTime=%s&endDateTime=%s",
+			graphBaseURL, graphCalendarPath(calendarID),
+			url.QueryEscape(start.Format(time.RFC3339)),
+			url.QueryEscape(end.Format(time.RFC3339)),
+		)
+	}
+
+	cs := &ChangeSet{}
+	var events []MSGraphEvent
+	for link != "" {
+		data, status, err := c.doRequest(ctx, "GET", link, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusGone {
+			return nil, ErrCursorExpired
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+		var list msGraphDeltaList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		for _, event := range list.Value {
+			if event.Removed != nil || event.IsCancelled {
+				cs.Removed = append(cs.Removed, event.ID)
+				continue
+			}
+			events = append(events, event.MSGraphEvent)
+		}
+		link = list.NextLink
+		if list.DeltaLink != "" {
+			cs.Cursor = list.DeltaLink
+		}
+	}
+
+	// a changed occurrence reports its whole series, read again with all
+	// of its instances in the window
+	masters, err := c.SeriesMasters(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	for _, master := range masters {
+		instances, err := c.ListInstances(ctx, master.ID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, instances...)
+	}
+	cs.Items = MSGraphToCalendarItems(append(masters, events...))
+	if cursor == "" {
+		cs.markFull()
+	}
+	return cs, nil
+}
+
+// TodoistSyncItem is an item resource as returned by the Sync API.
+type TodoistSyncItem struct {
+	ID          string      `json:"id"`
+	ProjectID   string      `json:"project_id"`
+	ParentID    string      `json:"parent_id,omitempty"`
+	Content     string      `json:"content"`
+	Description string      `json:"description,omitempty"`
+	Priority    int         `json:"priority"`
+	Labels      []string    `json:"labels,omitempty"`
+	Due         *TodoistDue `json:"due,omitempty"`
+	Checked     bool        `json:"checked"`
+	IsDeleted   bool        `json:"is_deleted"`
+}
+
+// Changes runs a Sync API request for items. An empty cursor requests a
+// full sync.
+func (c *TodoistSyncClient) Changes(ctx context.Context, cursor string) (*ChangeSet, error) {
+	if cursor == "" {
+		cursor = "*"
+	}
+	c.SetSyncToken(cursor)
+	resp, err := c.Sync(ctx, []string{"items"})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []TodoistSyncItem
+	if len(resp.Items) > 0 {
+		if err := json.Unmarshal(resp.Items, &items); err != nil {
+			return nil, fmt.Errorf("decode todoist sync items: %w", err)
+		}
+	}
+
+	cs := &ChangeSet{Cursor: resp.SyncToken}
+	for _, it := range items {
+		if it.IsDeleted {
+			cs.Removed = append(cs.Removed, it.ID)
+			continue
+		}
+		cs.Items = append(cs.Items, TodoistToCalendarItem(TodoistTask{
+			ID:          it.ID,
+			ProjectID:   it.ProjectID,
+			ParentID:    it.ParentID,
+			Content:     it.Content,
+			Description: it.Description,
+			Priority:    it.Priority,
+			Labels:      it.Labels,
+			Due:         it.Due,
+			IsCompleted: it.Checked,
+		}))
+	}
+	if resp.FullSync || cursor == "*" {
+		cs.markFull()
+	}
+	return cs, nil
+}
+
+// Changes returns pages edited at or after cursor, an RFC 3339 timestamp.
+// Notion reports no deletions, so each call also lists the IDs of every page
+// still in the database. An empty cursor fetches all pages.
+func (c *NotionClient) Changes(ctx context.Context, databaseID, cursor string, pm NotionPropertyMap) (*ChangeSet, error) {
+	var filter interface{}
+	if cursor != "" {
+		filter = map[string]interface{}{
+			"timestamp":        "last_edited_time",
+			"last_edited_time": map[string]string{"on_or_after": cursor},
+		}
+	}
+
+	cs := &ChangeSet{Cursor: cursor}
+	latest, _ := time.Parse(time.RFC3339, cursor)
+	queryURL := notionBaseURL + "/databases/" + databaseID + "/query"
+	pages, err := c.queryAll(ctx, queryURL, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		cs.Items = append(cs.Items, NotionToCalendarItem(page, pm))
+		if t, err := time.Parse(time.RFC3339, page.LastEditedTime); err == nil && t.After(latest) {
+			latest = t
+			cs.Cursor = page.LastEditedTime
+		}
+	}
+
+	if cursor == "" {
+		cs.markFull()
+		return cs, nil
+	}
+
+	// Only the title property is requested to keep the ID sweep cheap.
+	ids, err := c.queryAll(ctx, queryURL+"?filter_properties=title", nil)
+	if err != nil {
+		return nil, err
+	}
+	cs.Present = make(map[string]bool, len(ids))
+	for _, page := range ids {
+		cs.Present[page.ID] = true
+	}
+	return cs, nil
+}
+
+func (c *NotionClient) queryAll(ctx context.Context, queryURL string, filter interface{}) ([]NotionPage, error) {
+	var pages []NotionPage
+	next := ""
+	for {
+		result, err := c.queryDatabase(ctx, queryURL, next, filter)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, result.Results...)
+		if !result.HasMore {
+			return pages, nil
+		}
+		next = result.NextCursor
+	}
+}
@@ -7,18 +7,23 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	salerr "github.com/gongahkia/salja/internal/errors"
 	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/tzmap"
 )
 
 const graphBaseURL = "https://graph.microsoft.com/v1.0"
 
-// MSGraphClient is a REST API client for Microsoft Graph (Outlook Calendar).
+// MSGraphClient is a REST API client for Microsoft Graph (Outlook Calendar
+// and Microsoft To Do).
 type MSGraphClient struct {
 	token      *Token
 	httpClient *http.Client
+	limited    bool
 }
 
 func NewMSGraphClient(token *Token) *MSGraphClient {
@@ -36,7 +41,7 @@ func (c *MSGraphClient) doRequest(ctx context.Context, method, url string, body
 	var respBody []byte
 	var statusCode int
 
-	err := salerr.Retry(salerr.DefaultRetryConfig(), func() error {
+	err := retryRequest(c.limited, func() error {
 		var reqBody io.Reader
 		if body != nil {
 			data, err := json.Marshal(body)
@@ -63,7 +68,7 @@ func (c *MSGraphClient) doRequest(ctx context.Context, method, url string, body
 		statusCode = resp.StatusCode
 
 		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
-			return &salerr.APIError{Service: "microsoft-graph", StatusCode: resp.StatusCode, Message: string(respBody)}
+			return &salerr.APIError{Service: "microsoft-graph", StatusCode: resp.StatusCode, Message: string(respBody), RetryAfter: retryAfter(resp.Header)}
 		}
 
 		return err
@@ -74,15 +79,30 @@ func (c *MSGraphClient) doRequest(ctx context.Context, method, url string, body
 
 // MSGraphEvent represents an Outlook calendar event.
 type MSGraphEvent struct {
-	ID          string             `json:"id,omitempty"`
-	Subject     string             `json:"subject"`
-	Body        *MSGraphBody       `json:"body,omitempty"`
-	Start       *MSGraphDateTime   `json:"start"`
-	End         *MSGraphDateTime   `json:"end"`
-	Location    *MSGraphLocation   `json:"location,omitempty"`
-	IsAllDay    bool               `json:"isAllDay"`
-	Recurrence  *MSGraphRecurrence `json:"recurrence,omitempty"`
-	IsCancelled bool               `json:"isCancelled"`
+	ID                   string             `json:"id,omitempty"`
+	Subject              string             `json:"subject"`
+	Body                 *MSGraphBody       `json:"body,omitempty"`
+	Start                *MSGraphDateTime   `json:"start"`
+	End                  *MSGraphDateTime   `json:"end"`
+	Location             *MSGraphLocation   `json:"location,omitempty"`
+	IsAllDay             bool               `json:"isAllDay"`
+	Recurrence           *MSGraphRecurrence `json:"recurrence,omitempty"`
+	IsCancelled          bool               `json:"isCancelled"`
+	LastModifiedDateTime string             `json:"lastModifiedDateTime,omitempty"`
+	Attendees            []MSGraphAttendee  `json:"attendees,omitempty"`
+	// Organizer and ResponseRequested are read-only here: Outlook sets the
+	// organizer from the mailbox and asks for replies by default.
+	Organizer         *MSGraphRecipient `json:"organizer,omitempty"`
+	ResponseRequested bool              `json:"responseRequested,omitempty"`
+	// TransactionID makes a retried create return the original event.
+	TransactionID                 string                    `json:"transactionId,omitempty"`
+	SingleValueExtendedProperties []MSGraphExtendedProperty `json:"singleValueExtendedProperties,omitempty"`
+	// Type is singleInstance, seriesMaster, occurrence or exception. The
+	// occurrences and exceptions of a series name its master and the UTC
+	// start they had before any change. All three are read-only.
+	Type           string `json:"type,omitempty"`
+	SeriesMasterID string `json:"seriesMasterId,omitempty"`
+	OriginalStart  string `json:"originalStart,omitempty"`
 }
 
 type MSGraphBody struct {
@@ -99,6 +119,37 @@ type MSGraphLocation struct {
 	DisplayName string `json:"displayName"`
 }
 
+type MSGraphEmailAddress struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"`
+}
+
+type MSGraphRecipient struct {
+	EmailAddress MSGraphEmailAddress `json:"emailAddress"`
+}
+
+type MSGraphAttendee struct {
+	// Type is required, optional or resource.
+	Type         string                 `json:"type"`
+	Status       *MSGraphResponseStatus `json:"status,omitempty"`
+	EmailAddress MSGraphEmailAddress    `json:"emailAddress"`
+}
+
+type MSGraphResponseStatus struct {
+	Response string `json:"response"`
+}
+
+// msGraphResponses maps Outlook attendee responses to PARTSTAT; none and
+// notResponded both mean no reply yet.
+var msGraphResponses = map[string]model.PartStat{
+	"none":                model.PartStatNeedsAction,
+	"notResponded":        model.PartStatNeedsAction,
+	"organizer":           model.PartStatAccepted,
+	"accepted":            model.PartStatAccepted,
+	"tentativelyAccepted": model.PartStatTentative,
+	"declined":            model.PartStatDeclined,
+}
+
 type MSGraphRecurrence struct {
 	Pattern *MSGraphRecurrencePattern `json:"pattern,omitempty"`
 	Range   *MSGraphRecurrenceRange   `json:"range,omitempty"`
@@ -108,12 +159,18 @@ type MSGraphRecurrencePattern struct {
 	Type       string   `json:"type"`
 	Interval   int      `json:"interval"`
 	DaysOfWeek []string `json:"daysOfWeek,omitempty"`
+	DayOfMonth int      `json:"dayOfMonth,omitempty"`
+	Month      int      `json:"month,omitempty"`
+	// Index is the week of the month for relative patterns: first to
+	// fourth, or last.
+	Index string `json:"index,omitempty"`
 }
 
 type MSGraphRecurrenceRange struct {
-	Type      string `json:"type"`
-	StartDate string `json:"startDate"`
-	EndDate   string `json:"endDate,omitempty"`
+	Type                string `json:"type"`
+	StartDate           string `json:"startDate"`
+	EndDate             string `json:"endDate,omitempty"`
+	NumberOfOccurrences int    `json:"numberOfOccurrences,omitempty"`
 }
 
 type MSGraphEventList struct {
@@ -121,9 +178,52 @@ type MSGraphEventList struct {
 	NextLink string         `json:"@odata.nextLink,omitempty"`
 }
 
-func (c *MSGraphClient) ListEvents(ctx context.Context, startTime, endTime time.Time) ([]MSGraphEvent, error) {
-	url := fmt.Sprintf("%s/me/calendarview?startdatetime=%s&enddatetime=%s&$top=100",
-		graphBaseURL,
+// graphCalendarPath is the path of an Outlook calendar relative to the API
+// root. An empty ID is the user's default calendar.
+func graphCalendarPath(calendarID string) string {
+	if calendarID == "" {
+		return "/me"
+	}
+	return "/me/calendars/" + url.PathEscape(calendarID)
+}
+
+// MSGraphCalendar is an Outlook calendar.
+type MSGraphCalendar struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	IsDefaultCalendar bool   `json:"isDefaultCalendar"`
+	CanEdit           bool   `json:"canEdit"`
+}
+
+func (c *MSGraphClient) ListCalendars(ctx context.Context) ([]MSGraphCalendar, error) {
+	var calendars []MSGraphCalendar
+	link := graphBaseURL + "/me/calendars"
+	for link != "" {
+		data, status, err := c.doRequest(ctx, "GET", link, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+		var list struct {
+			Value    []MSGraphCalendar `json:"value"`
+			NextLink string            `json:"@odata.nextLink,omitempty"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, list.Value...)
+		link = list.NextLink
+	}
+	return calendars, nil
+}
+
+// ListEvents returns the events of a calendar, or of the default calendar
+// when calendarID is empty, between startTime and endTime.
+func (c *MSGraphClient) ListEvents(ctx context.Context, calendarID string, startTime, endTime time.Time) ([]MSGraphEvent, error) {
+	url := fmt.Sprintf("%s%s/calendarView?startDateTime=%s&endDateTime=%s&$top=100",
+		graphBaseURL, graphCalendarPath(calendarID),
 		startTime.Format(time.RFC3339),
 		endTime.Format(time.RFC3339),
 	)
@@ -147,8 +247,72 @@ func (c *MSGraphClient) ListEvents(ctx context.Context, startTime, endTime time.
 	return allEvents, nil
 }
 
-func (c *MSGraphClient) CreateEvent(ctx context.Context, event *MSGraphEvent) (*MSGraphEvent, error) {
-	data, status, err := c.doRequest(ctx, "POST", graphBaseURL+"/me/events", event)
+// GetEvent returns one event by ID.
+func (c *MSGraphClient) GetEvent(ctx context.Context, eventID string) (*MSGraphEvent, error) {
+	data, status, err := c.doRequest(ctx, "GET", fmt.Sprintf("%s/me/events/%s", graphBaseURL, eventID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	var event MSGraphEvent
+	return &event, json.Unmarshal(data, &event)
+}
+
+// ListInstances returns the occurrences and exceptions of a series master
+// between startTime and endTime.
+func (c *MSGraphClient) ListInstances(ctx context.Context, eventID string, startTime, endTime time.Time) ([]MSGraphEvent, error) {
+	link := fmt.Sprintf("%s/me/events/%s/instances?startDateTime=%s&endDateTime=%s&$top=100",
+		graphBaseURL, eventID,
+		url.QueryEscape(startTime.Format(time.RFC3339)),
+		url.QueryEscape(endTime.Format(time.RFC3339)),
+	)
+	var instances []MSGraphEvent
+	for link != "" {
+		data, status, err := c.doRequest(ctx, "GET", link, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+		var list MSGraphEventList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		instances = append(instances, list.Value...)
+		link = list.NextLink
+	}
+	return instances, nil
+}
+
+// SeriesMasters fetches the masters of the series that listed occurrences
+// belong to, each once.
+func (c *MSGraphClient) SeriesMasters(ctx context.Context, events []MSGraphEvent) ([]MSGraphEvent, error) {
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.Type == "seriesMaster" {
+			seen[event.ID] = true
+		}
+	}
+	var masters []MSGraphEvent
+	for _, event := range events {
+		if event.SeriesMasterID == "" || seen[event.SeriesMasterID] {
+			continue
+		}
+		seen[event.SeriesMasterID] = true
+		master, err := c.GetEvent(ctx, event.SeriesMasterID)
+		if err != nil {
+			return nil, err
+		}
+		masters = append(masters, *master)
+	}
+	return masters, nil
+}
+
+func (c *MSGraphClient) CreateEvent(ctx context.Context, calendarID string, event *MSGraphEvent) (*MSGraphEvent, error) {
+	data, status, err := c.doRequest(ctx, "POST", graphBaseURL+graphCalendarPath(calendarID)+"/events", event)
 	if err != nil {
 		return nil, err
 	}
@@ -172,6 +336,18 @@ func (c *MSGraphClient) UpdateEvent(ctx context.Context, event *MSGraphEvent) (*
 	return &updated, json.Unmarshal(data, &updated)
 }
 
+func (c *MSGraphClient) DeleteEvent(ctx context.Context, eventID string) error {
+	url := fmt.Sprintf("%s/me/events/%s", graphBaseURL, eventID)
+	data, status, err := c.doRequest(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+	if status != 204 {
+		return &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	return nil
+}
+
 // MSGraphToCalendarItem maps an Outlook event to the unified model.
 func MSGraphToCalendarItem(event MSGraphEvent) model.CalendarItem {
 	item := model.CalendarItem{
@@ -186,6 +362,12 @@ func MSGraphToCalendarItem(event MSGraphEvent) model.CalendarItem {
 		item.Status = model.StatusCancelled
 	}
 
+	if event.LastModifiedDateTime != "" {
+		if t, err := time.Parse(time.RFC3339, event.LastModifiedDateTime); err == nil {
+			item.UpdatedAt = &t
+		}
+	}
+
 	if event.Body != nil {
 		item.Description = event.Body.Content
 	}
@@ -193,31 +375,86 @@ func MSGraphToCalendarItem(event MSGraphEvent) model.CalendarItem {
 		item.Location = event.Location.DisplayName
 	}
 
-	if event.Start != nil {
-		if t, err := time.Parse("2006-01-02T15:04:05.0000000", event.Start.DateTime); err == nil {
-			if event.Start.TimeZone != "" && event.Start.TimeZone != "UTC" {
-				if loc, locErr := time.LoadLocation(event.Start.TimeZone); locErr == nil {
-					t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
-				}
-				item.Timezone = event.Start.TimeZone
+	if t, ok := parseGraphDateTime(event.Start); ok {
+		if event.Start.TimeZone != "" && event.Start.TimeZone != "UTC" {
+			item.Timezone = event.Start.TimeZone
+			if name, ok := tzmap.ToIANA(event.Start.TimeZone); ok {
+				item.Timezone = name
 			}
-			item.StartTime = &t
 		}
+		item.StartTime = &t
 	}
-	if event.End != nil {
-		if t, err := time.Parse("2006-01-02T15:04:05.0000000", event.End.DateTime); err == nil {
-			if event.End.TimeZone != "" && event.End.TimeZone != "UTC" {
-				if loc, locErr := time.LoadLocation(event.End.TimeZone); locErr == nil {
-					t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
-				}
-			}
-			item.EndTime = &t
+	if t, ok := parseGraphDateTime(event.End); ok {
+		item.EndTime = &t
+	}
+	if event.Type == "seriesMaster" {
+		item.Recurrence = msGraphToRecurrence(event.Recurrence)
+	}
+
+	if event.Organizer != nil && event.Organizer.EmailAddress.Address != "" {
+		item.Organizer = &model.Attendee{Email: event.Organizer.EmailAddress.Address, Name: event.Organizer.EmailAddress.Name}
+	}
+	for _, a := range event.Attendees {
+		attendee := model.Attendee{
+			Email: a.EmailAddress.Address,
+			Name:  a.EmailAddress.Name,
+			Role:  model.RoleRequired,
+			RSVP:  event.ResponseRequested,
+		}
+		switch a.Type {
+		case "optional":
+			attendee.Role = model.RoleOptional
+		case "resource":
+			attendee.Role = model.RoleNonParticipant
+		}
+		if a.Status != nil {
+			attendee.Status = msGraphResponses[a.Status.Response]
 		}
+		item.Attendees = append(item.Attendees, attendee)
 	}
 
 	return item
 }
 
+// MSGraphToCalendarItems maps a calendar view together with the masters of
+// its series. Exceptions of a listed master become its overrides and its
+// plain occurrences are dropped; occurrences whose master is not listed
+// stay items of their own. Outlook does not list deleted occurrences, so
+// series read back carry no exception dates.
+func MSGraphToCalendarItems(events []MSGraphEvent) []model.CalendarItem {
+	items := make([]model.CalendarItem, 0, len(events))
+	series := make(map[string]int)
+	for _, event := range events {
+		if event.Type == "seriesMaster" {
+			if _, ok := series[event.ID]; ok {
+				continue
+			}
+			series[event.ID] = len(items)
+			items = append(items, MSGraphToCalendarItem(event))
+		}
+	}
+	for _, event := range events {
+		if event.Type == "seriesMaster" {
+			continue
+		}
+		i, ok := series[event.SeriesMasterID]
+		rid, err := time.Parse(time.RFC3339, event.OriginalStart)
+		if event.SeriesMasterID == "" || !ok || err != nil || items[i].Recurrence == nil {
+			items = append(items, MSGraphToCalendarItem(event))
+			continue
+		}
+		if event.Type != "exception" {
+			continue
+		}
+		// overrides share their series' UID, as in iCalendar
+		override := MSGraphToCalendarItem(event)
+		override.UID = items[i].UID
+		rec := items[i].Recurrence
+		rec.Overrides = append(rec.Overrides, model.Override{RecurrenceID: rid, Item: override})
+	}
+	return items
+}
+
 // CalendarItemToMSGraph maps the unified model to an Outlook event.
 func CalendarItemToMSGraph(item model.CalendarItem) MSGraphEvent {
 	event := MSGraphEvent{
@@ -226,6 +463,9 @@ func CalendarItemToMSGraph(item model.CalendarItem) MSGraphEvent {
 		IsAllDay: item.IsAllDay,
 	}
 
+	if item.UID != "" {
+		event.SingleValueExtendedProperties = []MSGraphExtendedProperty{{ID: msGraphSourceUIDProperty, Value: item.UID}}
+	}
 	if item.Description != "" {
 		event.Body = &MSGraphBody{ContentType: "text", Content: item.Description}
 	}
@@ -234,25 +474,596 @@ func CalendarItemToMSGraph(item model.CalendarItem) MSGraphEvent {
 	}
 
 	if item.StartTime != nil {
-		tz := "UTC"
-		if item.Timezone != "" {
-			tz = item.Timezone
-		}
-		event.Start = &MSGraphDateTime{
-			DateTime: item.StartTime.Format("2006-01-02T15:04:05.0000000"),
-			TimeZone: tz,
+		start := graphZoned(*item.StartTime, item.Timezone)
+		event.Start = &start
+		anchor := *item.StartTime
+		if loc, err := time.LoadLocation(item.Timezone); item.Timezone != "" && err == nil {
+			anchor = anchor.In(loc)
 		}
+		event.Recurrence = recurrenceToMSGraph(item.Recurrence, anchor)
 	}
 	if item.EndTime != nil {
-		tz := "UTC"
-		if item.Timezone != "" {
-			tz = item.Timezone
+		end := graphZoned(*item.EndTime, item.Timezone)
+		event.End = &end
+	}
+
+	for _, a := range item.Attendees {
+		if a.Email == "" {
+			continue
 		}
-		event.End = &MSGraphDateTime{
-			DateTime: item.EndTime.Format("2006-01-02T15:04:05.0000000"),
-			TimeZone: tz,
+		attendee := MSGraphAttendee{Type: "required", EmailAddress: MSGraphEmailAddress{Name: a.Name, Address: a.Email}}
+		switch a.Role {
+		case model.RoleOptional:
+			attendee.Type = "optional"
+		case model.RoleNonParticipant:
+			attendee.Type = "resource"
 		}
+		event.Attendees = append(event.Attendees, attendee)
 	}
 
 	return event
 }
+
+// graphZoned writes t on the wall clock of an IANA zone, named the Windows
+// way Graph expects. Zones with no Windows name are sent as they are, and
+// an empty zone is UTC.
+func graphZoned(t time.Time, tz string) MSGraphDateTime {
+	if tz == "" {
+		tz = "UTC"
+	}
+	if loc, err := time.LoadLocation(tz); err == nil {
+		t = t.In(loc)
+	}
+	if name, ok := tzmap.ToWindows(tz); ok {
+		tz = name
+	}
+	return MSGraphDateTime{DateTime: t.Format("2006-01-02T15:04:05.0000000"), TimeZone: tz}
+}
+
+// parseGraphDateTime reads a dateTimeTimeZone value as wall-clock time in its
+// zone, named either the Windows or the IANA way, or in UTC when the zone is
+// unknown.
+func parseGraphDateTime(dt *MSGraphDateTime) (time.Time, bool) {
+	if dt == nil || dt.DateTime == "" {
+		return time.Time{}, false
+	}
+	loc := time.UTC
+	if name, ok := tzmap.ToIANA(dt.TimeZone); ok {
+		if l, err := time.LoadLocation(name); err == nil {
+			loc = l
+		}
+	}
+	// the layout takes any number of fractional second digits
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", dt.DateTime, loc)
+	return t, err == nil
+}
+
+// MSGraphTodoList is a Microsoft To Do task list.
+type MSGraphTodoList struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	// WellknownListName is "defaultList" for the built-in Tasks list.
+	WellknownListName string `json:"wellknownListName,omitempty"`
+}
+
+// MSGraphTodoTask is a Microsoft To Do task. Status is one of notStarted,
+// inProgress, completed, waitingOnOthers or deferred; Importance is low,
+// normal or high.
+type MSGraphTodoTask struct {
+	ID                   string             `json:"id,omitempty"`
+	Title                string             `json:"title"`
+	Body                 *MSGraphBody       `json:"body,omitempty"`
+	Status               string             `json:"status,omitempty"`
+	Importance           string             `json:"importance,omitempty"`
+	Categories           []string           `json:"categories,omitempty"`
+	DueDateTime          *MSGraphDateTime   `json:"dueDateTime,omitempty"`
+	StartDateTime        *MSGraphDateTime   `json:"startDateTime,omitempty"`
+	CompletedDateTime    *MSGraphDateTime   `json:"completedDateTime,omitempty"`
+	IsReminderOn         bool               `json:"isReminderOn"`
+	ReminderDateTime     *MSGraphDateTime   `json:"reminderDateTime,omitempty"`
+	Recurrence           *MSGraphRecurrence `json:"recurrence,omitempty"`
+	CreatedDateTime      string             `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime string             `json:"lastModifiedDateTime,omitempty"`
+	// ChecklistItems are only returned when expanded and cannot be written
+	// with the task; see SyncChecklist.
+	ChecklistItems []MSGraphChecklistItem `json:"checklistItems,omitempty"`
+}
+
+// MSGraphChecklistItem is a step of a To Do task.
+type MSGraphChecklistItem struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName"`
+	IsChecked   bool   `json:"isChecked"`
+}
+
+// graphTodoTasksPath is the path of a To Do list's tasks relative to the API
+// root.
+func graphTodoTasksPath(listID string) string {
+	return "/me/todo/lists/" + url.PathEscape(listID) + "/tasks"
+}
+
+func (c *MSGraphClient) ListTodoLists(ctx context.Context) ([]MSGraphTodoList, error) {
+	var lists []MSGraphTodoList
+	link := graphBaseURL + "/me/todo/lists"
+	for link != "" {
+		data, status, err := c.doRequest(ctx, "GET", link, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+		var page struct {
+			Value    []MSGraphTodoList `json:"value"`
+			NextLink string            `json:"@odata.nextLink,omitempty"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		lists = append(lists, page.Value...)
+		link = page.NextLink
+	}
+	return lists, nil
+}
+
+// ListTodoTasks returns all tasks of a To Do list, completed ones included,
+// with their checklist items.
+func (c *MSGraphClient) ListTodoTasks(ctx context.Context, listID string) ([]MSGraphTodoTask, error) {
+	var tasks []MSGraphTodoTask
+	link := graphBaseURL + graphTodoTasksPath(listID) + "?$expand=checklistItems&$top=100"
+	for link != "" {
+		data, status, err := c.doRequest(ctx, "GET", link, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+		var page struct {
+			Value    []MSGraphTodoTask `json:"value"`
+			NextLink string            `json:"@odata.nextLink,omitempty"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page.Value...)
+		link = page.NextLink
+	}
+	return tasks, nil
+}
+
+func (c *MSGraphClient) CreateTodoTask(ctx context.Context, listID string, task *MSGraphTodoTask) (*MSGraphTodoTask, error) {
+	data, status, err := c.doRequest(ctx, "POST", graphBaseURL+graphTodoTasksPath(listID), task)
+	if err != nil {
+		return nil, err
+	}
+	if status != 201 {
+		return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	var created MSGraphTodoTask
+	return &created, json.Unmarshal(data, &created)
+}
+
+// UpdateTodoTask replaces a task's fields with task's. Dates, the reminder,
+// the recurrence and the body task leaves unset are cleared on the task.
+func (c *MSGraphClient) UpdateTodoTask(ctx context.Context, listID string, task *MSGraphTodoTask) (*MSGraphTodoTask, error) {
+	body, err := todoTaskPatch(task)
+	if err != nil {
+		return nil, err
+	}
+	return c.patchTodoTask(ctx, listID, task.ID, body)
+}
+
+// CompleteTodoTask marks a task completed, leaving its other fields alone.
+// To Do creates the next occurrence of a recurring task when it is
+// completed.
+func (c *MSGraphClient) CompleteTodoTask(ctx context.Context, listID, taskID string) error {
+	_, err := c.patchTodoTask(ctx, listID, taskID, map[string]string{"status": "completed"})
+	return err
+}
+
+func (c *MSGraphClient) patchTodoTask(ctx context.Context, listID, taskID string, body interface{}) (*MSGraphTodoTask, error) {
+	data, status, err := c.doRequest(ctx, "PATCH", graphBaseURL+graphTodoTasksPath(listID)+"/"+url.PathEscape(taskID), body)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	var updated MSGraphTodoTask
+	return &updated, json.Unmarshal(data, &updated)
+}
+
+// todoTaskPatch is the PATCH body that makes a task read task. A PATCH
+// leaves out fields alone, so the cleared ones are sent as null.
+func todoTaskPatch(task *MSGraphTodoTask) (map[string]interface{}, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	// read-only, or written through their own endpoints
+	for _, key := range []string{"id", "createdDateTime", "lastModifiedDateTime", "checklistItems"} {
+		delete(body, key)
+	}
+	for _, key := range []string{"dueDateTime", "startDateTime", "reminderDateTime", "recurrence"} {
+		if _, ok := body[key]; !ok {
+			body[key] = nil
+		}
+	}
+	if task.Body == nil {
+		body["body"] = MSGraphBody{ContentType: "text"}
+	}
+	if len(task.Categories) == 0 {
+		body["categories"] = []string{}
+	}
+	return body, nil
+}
+
+func (c *MSGraphClient) DeleteTodoTask(ctx context.Context, listID, taskID string) error {
+	data, status, err := c.doRequest(ctx, "DELETE", graphBaseURL+graphTodoTasksPath(listID)+"/"+url.PathEscape(taskID), nil)
+	if err != nil {
+		return err
+	}
+	if status != 204 {
+		return &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	return nil
+}
+
+func (c *MSGraphClient) ListChecklistItems(ctx context.Context, listID, taskID string) ([]MSGraphChecklistItem, error) {
+	data, status, err := c.doRequest(ctx, "GET", graphBaseURL+graphTodoTasksPath(listID)+"/"+url.PathEscape(taskID)+"/checklistItems", nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	var page struct {
+		Value []MSGraphChecklistItem `json:"value"`
+	}
+	return page.Value, json.Unmarshal(data, &page)
+}
+
+// SyncChecklist makes a task's checklist read want, given its current items.
+// Items are matched by position: changed ones are updated, missing ones
+// added and surplus ones deleted. It returns the resulting checklist.
+func (c *MSGraphClient) SyncChecklist(ctx context.Context, listID, taskID string, current, want []MSGraphChecklistItem) ([]MSGraphChecklistItem, error) {
+	base := graphBaseURL + graphTodoTasksPath(listID) + "/" + url.PathEscape(taskID) + "/checklistItems"
+	result := make([]MSGraphChecklistItem, 0, len(want))
+	for i, w := range want {
+		w.ID = ""
+		if i < len(current) {
+			cur := current[i]
+			if cur.DisplayName == w.DisplayName && cur.IsChecked == w.IsChecked {
+				result = append(result, cur)
+				continue
+			}
+			data, status, err := c.doRequest(ctx, "PATCH", base+"/"+url.PathEscape(cur.ID), w)
+			if err != nil {
+				return nil, err
+			}
+			if status != 200 {
+				return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+			}
+			w.ID = cur.ID
+			result = append(result, w)
+			continue
+		}
+		data, status, err := c.doRequest(ctx, "POST", base, w)
+		if err != nil {
+			return nil, err
+		}
+		if status != 201 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+		var created MSGraphChecklistItem
+		if err := json.Unmarshal(data, &created); err != nil {
+			return nil, err
+		}
+		result = append(result, created)
+	}
+	for i := len(want); i < len(current); i++ {
+		data, status, err := c.doRequest(ctx, "DELETE", base+"/"+url.PathEscape(current[i].ID), nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 204 {
+			return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+		}
+	}
+	return result, nil
+}
+
+// MSGraphTodoToCalendarItem maps a To Do task to the unified model.
+func MSGraphTodoToCalendarItem(task MSGraphTodoTask) model.CalendarItem {
+	item := model.CalendarItem{
+		UID:      task.ID,
+		Title:    task.Title,
+		ItemType: model.ItemTypeTask,
+		Tags:     task.Categories,
+		Status:   model.StatusPending,
+	}
+	if task.Body != nil {
+		item.Description, _ = splitDescriptionMarker(task.Body.Content)
+	}
+
+	// waitingOnOthers and deferred tasks are still open
+	switch task.Status {
+	case "completed":
+		item.Status = model.StatusCompleted
+	case "inProgress":
+		item.Status = model.StatusInProgress
+	}
+	switch task.Importance {
+	case "high":
+		item.Priority = model.PriorityHigh
+	case "low":
+		item.Priority = model.PriorityLow
+	}
+
+	if t, ok := parseGraphDateTime(task.DueDateTime); ok {
+		item.DueDate = &t
+	}
+	if t, ok := parseGraphDateTime(task.StartDateTime); ok {
+		item.StartTime = &t
+	}
+	if t, ok := parseGraphDateTime(task.CompletedDateTime); ok && item.Status == model.StatusCompleted {
+		item.CompletionDate = &t
+	}
+	if t, ok := parseGraphDateTime(task.ReminderDateTime); ok && task.IsReminderOn {
+		item.Reminders = []model.Reminder{{AbsoluteTime: &t}}
+	}
+	item.Recurrence = msGraphToRecurrence(task.Recurrence)
+
+	for i, ci := range task.ChecklistItems {
+		st := model.Subtask{Title: ci.DisplayName, Status: model.StatusPending, SortOrder: i}
+		if ci.IsChecked {
+			st.Status = model.StatusCompleted
+		}
+		item.Subtasks = append(item.Subtasks, st)
+	}
+
+	if t, err := time.Parse(time.RFC3339, task.CreatedDateTime); err == nil {
+		item.CreatedAt = &t
+	}
+	if t, err := time.Parse(time.RFC3339, task.LastModifiedDateTime); err == nil {
+		item.UpdatedAt = &t
+	}
+	return item
+}
+
+// CalendarItemToMSGraphTodo maps the unified model to a To Do task. To Do
+// due and start dates are days, so only the date is sent; of the reminders,
+// the earliest is kept, as a task has one.
+func CalendarItemToMSGraphTodo(item model.CalendarItem) MSGraphTodoTask {
+	task := MSGraphTodoTask{
+		ID:         item.UID,
+		Title:      item.Title,
+		Categories: item.Tags,
+		Status:     "notStarted",
+		Importance: "normal",
+	}
+	if body := stampDescription(item.Description, item.UID); body != "" {
+		task.Body = &MSGraphBody{ContentType: "text", Content: body}
+	}
+
+	// To Do has no cancelled state; closing the task keeps it off the list
+	switch item.Status {
+	case model.StatusCompleted, model.StatusCancelled:
+		task.Status = "completed"
+		if item.CompletionDate != nil {
+			task.CompletedDateTime = graphUTC(*item.CompletionDate)
+		}
+	case model.StatusInProgress:
+		task.Status = "inProgress"
+	}
+	switch {
+	case item.Priority >= model.PriorityHigh:
+		task.Importance = "high"
+	case item.Priority > model.PriorityNone && item.Priority <= model.PriorityLow:
+		task.Importance = "low"
+	}
+
+	anchor := item.DueDate
+	if anchor == nil {
+		anchor = item.StartTime
+	}
+	if item.DueDate != nil {
+		task.DueDateTime = graphDate(*item.DueDate)
+	}
+	if item.StartTime != nil {
+		task.StartDateTime = graphDate(*item.StartTime)
+	}
+	var reminder *time.Time
+	for _, r := range item.Reminders {
+		var at time.Time
+		switch {
+		case r.AbsoluteTime != nil:
+			at = *r.AbsoluteTime
+		case r.Offset != nil && anchor != nil:
+			at = anchor.Add(*r.Offset)
+		default:
+			continue
+		}
+		if reminder == nil || at.Before(*reminder) {
+			reminder = &at
+		}
+	}
+	if reminder != nil {
+		task.IsReminderOn = true
+		task.ReminderDateTime = graphUTC(*reminder)
+	}
+	// a recurrence range needs a start date
+	if anchor != nil {
+		task.Recurrence = recurrenceToMSGraph(item.Recurrence, *anchor)
+	}
+
+	for _, st := range item.Subtasks {
+		task.ChecklistItems = append(task.ChecklistItems, MSGraphChecklistItem{
+			DisplayName: st.Title,
+			IsChecked:   st.Status == model.StatusCompleted,
+		})
+	}
+	return task
+}
+
+func graphDate(t time.Time) *MSGraphDateTime {
+	return &MSGraphDateTime{DateTime: t.Format("2006-01-02") + "T00:00:00.0000000", TimeZone: "UTC"}
+}
+
+func graphUTC(t time.Time) *MSGraphDateTime {
+	return &MSGraphDateTime{DateTime: t.UTC().Format("2006-01-02T15:04:05.0000000"), TimeZone: "UTC"}
+}
+
+var graphWeekdays = map[model.Weekday]string{
+	model.WeekdayMO: "monday", model.WeekdayTU: "tuesday", model.WeekdayWE: "wednesday",
+	model.WeekdayTH: "thursday", model.WeekdayFR: "friday", model.WeekdaySA: "saturday", model.WeekdaySU: "sunday",
+}
+
+var graphWeekIndexes = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "last": -1}
+
+// msGraphToRecurrence maps a Graph recurrence pattern and range to the model.
+func msGraphToRecurrence(r *MSGraphRecurrence) *model.Recurrence {
+	if r == nil || r.Pattern == nil {
+		return nil
+	}
+	p := r.Pattern
+	rec := &model.Recurrence{Interval: p.Interval}
+	if rec.Interval < 1 {
+		rec.Interval = 1
+	}
+	var days []model.Weekday
+	for _, name := range p.DaysOfWeek {
+		for wd, graphName := range graphWeekdays {
+			if strings.EqualFold(name, graphName) {
+				days = append(days, wd)
+			}
+		}
+	}
+	relative := func() {
+		rec.ByDay = days
+		if pos, ok := graphWeekIndexes[p.Index]; ok {
+			rec.BySetPos = []int{pos}
+		} else {
+			rec.BySetPos = []int{1}
+		}
+	}
+	switch p.Type {
+	case "daily":
+		rec.Freq = model.FreqDaily
+	case "weekly":
+		rec.Freq = model.FreqWeekly
+		rec.ByDay = days
+	case "absoluteMonthly":
+		rec.Freq = model.FreqMonthly
+		rec.ByMonthDay = []int{p.DayOfMonth}
+	case "relativeMonthly":
+		rec.Freq = model.FreqMonthly
+		relative()
+	case "absoluteYearly":
+		rec.Freq = model.FreqYearly
+		rec.ByMonth = []int{p.Month}
+		rec.ByMonthDay = []int{p.DayOfMonth}
+	case "relativeYearly":
+		rec.Freq = model.FreqYearly
+		rec.ByMonth = []int{p.Month}
+		relative()
+	default:
+		return nil
+	}
+
+	if r.Range != nil {
+		switch r.Range.Type {
+		case "endDate":
+			if t, err := time.Parse("2006-01-02", r.Range.EndDate); err == nil {
+				rec.Until = &t
+			}
+		case "numbered":
+			if n := r.Range.NumberOfOccurrences; n > 0 {
+				rec.Count = &n
+			}
+		}
+	}
+	return rec
+}
+
+// recurrenceToMSGraph maps a recurrence to a Graph pattern starting on
+// anchor's date. Weekly, monthly and yearly patterns fill in the weekday,
+// day or month from anchor when the rule leaves them out, since Graph
+// requires them. Rules Graph cannot express, such as several days of the
+// month, keep only what it can.
+func recurrenceToMSGraph(rec *model.Recurrence, anchor time.Time) *MSGraphRecurrence {
+	if rec == nil {
+		return nil
+	}
+	p := &MSGraphRecurrencePattern{Interval: rec.Interval}
+	if p.Interval < 1 {
+		p.Interval = 1
+	}
+	for _, d := range rec.ByDay {
+		if name, ok := graphWeekdays[d]; ok {
+			p.DaysOfWeek = append(p.DaysOfWeek, name)
+		}
+	}
+	index := ""
+	if len(p.DaysOfWeek) > 0 && len(rec.BySetPos) == 1 {
+		for name, pos := range graphWeekIndexes {
+			if pos == rec.BySetPos[0] {
+				index = name
+			}
+		}
+	}
+	dayOfMonth := anchor.Day()
+	if len(rec.ByMonthDay) > 0 && rec.ByMonthDay[0] > 0 {
+		dayOfMonth = rec.ByMonthDay[0]
+	}
+	month := int(anchor.Month())
+	if len(rec.ByMonth) > 0 {
+		month = rec.ByMonth[0]
+	}
+	anchorDay := []string{strings.ToLower(anchor.Weekday().String())}
+
+	switch rec.Freq {
+	case model.FreqDaily:
+		// a daily rule on some weekdays is a weekly one on those days
+		if len(p.DaysOfWeek) == 0 || p.Interval > 1 {
+			p.Type = "daily"
+			p.DaysOfWeek = nil
+		} else {
+			p.Type = "weekly"
+		}
+	case model.FreqWeekly:
+		p.Type = "weekly"
+		if len(p.DaysOfWeek) == 0 {
+			p.DaysOfWeek = anchorDay
+		}
+	case model.FreqMonthly:
+		if index != "" {
+			p.Type, p.Index = "relativeMonthly", index
+		} else {
+			p.Type, p.DayOfMonth, p.DaysOfWeek = "absoluteMonthly", dayOfMonth, nil
+		}
+	case model.FreqYearly:
+		p.Month = month
+		if index != "" {
+			p.Type, p.Index = "relativeYearly", index
+		} else {
+			p.Type, p.DayOfMonth, p.DaysOfWeek = "absoluteYearly", dayOfMonth, nil
+		}
+	default:
+		return nil
+	}
+
+	r := &MSGraphRecurrenceRange{Type: "noEnd", StartDate: anchor.Format("2006-01-02")}
+	switch {
+	case rec.Until != nil:
+		r.Type, r.EndDate = "endDate", rec.Until.Format("2006-01-02")
+	case rec.Count != nil:
+		r.Type, r.NumberOfOccurrences = "numbered", *rec.Count
+	}
+	return &MSGraphRecurrence{Pattern: p, Range: r}
+}
@@ -65,8 +65,9 @@ func (c *NotionClient) doRequest(ctx context.Context, method, url string, body i
 
 // NotionPage represents a page in a Notion database.
 type NotionPage struct {
-	ID         string                    `json:"id,omitempty"`
-	Properties map[string]NotionProperty `json:"properties"`
+	ID             string                    `json:"id,omitempty"`
+	Properties     map[string]NotionProperty `json:"properties"`
+	LastEditedTime string                    `json:"last_edited_time,omitempty"`
 }
 
 // NotionProperty is a generic Notion property value.
@@ -112,6 +113,9 @@ type NotionPropertyMap struct {
 	Status      string `toml:"status" json:"status"`
 	Priority    string `toml:"priority" json:"priority"`
 	Tags        string `toml:"tags" json:"tags"`
+	// SourceUID, when set, names a rich text property that stores the salja
+	// UID. The database must already have it; see EnsureRichTextProperty.
+	SourceUID string `toml:"source_uid" json:"source_uid"`
 }
 
 func DefaultNotionPropertyMap() NotionPropertyMap {
@@ -126,13 +130,20 @@ func DefaultNotionPropertyMap() NotionPropertyMap {
 }
 
 func (c *NotionClient) QueryDatabase(ctx context.Context, databaseID string, startCursor string) (*NotionQueryResult, error) {
+	return c.queryDatabase(ctx, notionBaseURL+"/databases/"+databaseID+"/query", startCursor, nil)
+}
+
+func (c *NotionClient) queryDatabase(ctx context.Context, url, startCursor string, filter interface{}) (*NotionQueryResult, error) {
 	body := map[string]interface{}{}
 	if startCursor != "" {
 		body["start_cursor"] = startCursor
 	}
+	if filter != nil {
+		body["filter"] = filter
+	}
 	body["page_size"] = 100
 
-	data, status, err := c.doRequest(ctx, "POST", notionBaseURL+"/databases/"+databaseID+"/query", body)
+	data, status, err := c.doRequest(ctx, "POST", url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +186,22 @@ func (c *NotionClient) UpdatePage(ctx context.Context, pageID string, properties
 	return nil
 }
 
+// ArchivePage moves a page to the trash, which is how the Notion API deletes pages.
+func (c *NotionClient) ArchivePage(ctx context.Context, pageID string) error {
+	body := map[string]interface{}{
+		"archived": true,
+	}
+
+	_, status, err := c.doRequest(ctx, "PATCH", notionBaseURL+"/pages/"+pageID, body)
+	if err != nil {
+		return err
+	}
+	if status != 200 {
+		return &salerr.APIError{Service: "notion", StatusCode: status, Message: "archive failed"}
+	}
+	return nil
+}
+
 // NotionToCalendarItem maps a Notion page to the unified model using property mapping.
 func NotionToCalendarItem(page NotionPage, pm NotionPropertyMap) model.CalendarItem {
 	item := model.CalendarItem{
@@ -227,6 +254,10 @@ func NotionToCalendarItem(page NotionPage, pm NotionPropertyMap) model.CalendarI
 		}
 	}
 
+	if t, err := time.Parse(time.RFC3339, page.LastEditedTime); err == nil {
+		item.UpdatedAt = &t
+	}
+
 	return item
 }
 
@@ -296,6 +327,15 @@ func CalendarItemToNotion(item model.CalendarItem, pm NotionPropertyMap) NotionP
 		}
 	}
 
+	if pm.SourceUID != "" && item.UID != "" {
+		props[pm.SourceUID] = NotionProperty{
+			Type: "rich_text",
+			RichText: []NotionRichText{{Text: &struct {
+				Content string `json:"content"`
+			}{Content: item.UID}}},
+		}
+	}
+
 	return NotionPage{
 		ID:         item.UID,
 		Properties: props,
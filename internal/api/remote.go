@@ -0,0 +1,974 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/ics"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// The *Remote types adapt each service client to a common item-level
+// interface used by the sync engine. Items they return carry the remote ID
+// in their UID field.
+
+// GCalRemote syncs events in one Google calendar within a time window.
+type GCalRemote struct {
+	Client     *GCalClient
+	CalendarID string
+	Start      time.Time
+	End        time.Time
+}
+
+func (r *GCalRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	events, err := r.Client.ListEvents(ctx, r.CalendarID, r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	return GCalToCalendarItems(events), nil
+}
+
+func (r *GCalRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	event := CalendarItemToGCal(item)
+	// Google event IDs are server-assigned base32hex; local UIDs are not valid IDs
+	event.ID = ""
+	created, err := r.Client.InsertEvent(ctx, r.CalendarID, &event)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return r.writeOverrides(ctx, *created, item)
+}
+
+func (r *GCalRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	event := CalendarItemToGCal(item)
+	event.ID = remoteID
+	updated, err := r.Client.UpdateEvent(ctx, r.CalendarID, &event)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return r.writeOverrides(ctx, *updated, item)
+}
+
+// writeOverrides updates the occurrences of a just written series that item
+// overrides and returns the series as a later List sees it. Cancelled
+// occurrences need no call; they are EXDATE lines of the series. Instances
+// are looked up within a year of the overridden dates, which bounds how far
+// an earlier push may have moved one.
+func (r *GCalRemote) writeOverrides(ctx context.Context, series GCalEvent, item model.CalendarItem) (model.CalendarItem, error) {
+	if item.Recurrence == nil || len(item.Recurrence.Overrides) == 0 {
+		return GCalToCalendarItem(series), nil
+	}
+	overrides := item.Recurrence.Overrides
+	first, last := overrides[0].RecurrenceID, overrides[0].RecurrenceID
+	for _, ov := range overrides[1:] {
+		if ov.RecurrenceID.Before(first) {
+			first = ov.RecurrenceID
+		}
+		if ov.RecurrenceID.After(last) {
+			last = ov.RecurrenceID
+		}
+	}
+	instances, err := r.Client.ListInstances(ctx, r.CalendarID, series.ID, first.AddDate(-1, 0, 0), last.AddDate(1, 0, 0))
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+
+	written := []GCalEvent{series}
+	for _, ov := range overrides {
+		for _, inst := range instances {
+			if !isOccurrence(inst.OriginalStartTime, ov.RecurrenceID) {
+				continue
+			}
+			event := CalendarItemToGCal(ov.Item)
+			event.ID = inst.ID
+			event.RecurringEventID = series.ID
+			event.OriginalStartTime = inst.OriginalStartTime
+			event.ExtendedProps = nil
+			updated, err := r.Client.UpdateEvent(ctx, r.CalendarID, &event)
+			if err != nil {
+				return model.CalendarItem{}, fmt.Errorf("occurrence %s: %w", ov.RecurrenceID.Format(time.RFC3339), err)
+			}
+			written = append(written, *updated)
+			break
+		}
+	}
+	return GCalToCalendarItems(written)[0], nil
+}
+
+// isOccurrence reports whether an instance's original start is t; all-day
+// instances compare by date.
+func isOccurrence(original *GCalDateTime, t time.Time) bool {
+	if original == nil {
+		return false
+	}
+	if original.Date != "" {
+		return original.Date == t.Format("2006-01-02")
+	}
+	start := parseGCalTime(original)
+	return start != nil && start.Equal(t)
+}
+
+func (r *GCalRemote) Delete(ctx context.Context, remoteID string) error {
+	return r.Client.DeleteEvent(ctx, r.CalendarID, remoteID)
+}
+
+// GTasksRemote syncs the tasks of one Google Tasks list, completed ones
+// included. Subtasks are tasks of their own in Google Tasks; they are written
+// under their parent after it, and the ones seen for each parent are
+// remembered from List and Lookup so updates can adjust them in place.
+type GTasksRemote struct {
+	Client *GTasksClient
+	ListID string
+
+	mu       sync.Mutex
+	bySource map[string]model.CalendarItem
+	children map[string][]GTask
+}
+
+func (r *GTasksRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	tasks, err := r.Client.ListTasks(ctx, r.ListID)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.children = gtaskChildren(tasks)
+	r.mu.Unlock()
+	return GTasksToCalendarItems(tasks), nil
+}
+
+func (r *GTasksRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToGTask(item)
+	task.ID = ""
+	created, err := r.Client.InsertTask(ctx, r.ListID, &task, "", "")
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	subs, err := r.Client.SyncSubtasks(ctx, r.ListID, created.ID, nil, GTaskSubtasks(item))
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return r.view(*created, subs), nil
+}
+
+func (r *GTasksRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToGTask(item)
+	task.ID = remoteID
+	updated, err := r.Client.UpdateTask(ctx, r.ListID, &task)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	current, err := r.subtasks(ctx, remoteID)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	subs, err := r.Client.SyncSubtasks(ctx, r.ListID, remoteID, current, GTaskSubtasks(item))
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return r.view(*updated, subs), nil
+}
+
+func (r *GTasksRemote) Delete(ctx context.Context, remoteID string) error {
+	if err := r.Client.DeleteTask(ctx, r.ListID, remoteID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	delete(r.children, remoteID)
+	r.mu.Unlock()
+	return nil
+}
+
+// subtasks returns the current subtasks of parent, listing the task list
+// if it has not been listed yet.
+func (r *GTasksRemote) subtasks(ctx context.Context, parent string) ([]GTask, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.children == nil {
+		tasks, err := r.Client.ListTasks(ctx, r.ListID)
+		if err != nil {
+			return nil, err
+		}
+		r.children = gtaskChildren(tasks)
+	}
+	return r.children[parent], nil
+}
+
+// view remembers the subtasks written under task and maps both back.
+func (r *GTasksRemote) view(task GTask, subs []GTask) model.CalendarItem {
+	r.mu.Lock()
+	if r.children == nil {
+		r.children = make(map[string][]GTask)
+	}
+	r.children[task.ID] = subs
+	r.mu.Unlock()
+	for i := range subs {
+		subs[i].Parent = task.ID
+	}
+	return GTasksToCalendarItems(append([]GTask{task}, subs...))[0]
+}
+
+// GTasksIDPrefix marks an ID as a Google Tasks list or task rather than a
+// calendar or event of the same Google account.
+const GTasksIDPrefix = "tasks:"
+
+// GoogleRemote syncs the events of a Google calendar together with the
+// tasks of a Google Tasks list, since Calendar cannot hold tasks. Task
+// remote IDs carry GTasksIDPrefix so updates and deletes reach the right API.
+// Tokens from before tasks were supported lack the tasks scope; with them
+// only events are listed and writing a task fails.
+type GoogleRemote struct {
+	Events *GCalRemote
+	Tasks  *GTasksRemote
+
+	tasksDenied bool
+}
+
+func (r *GoogleRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	items, err := r.Events.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.Tasks.List(ctx)
+	var apiErr *salerr.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 403 {
+		r.tasksDenied = true
+		return items, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		t.UID = GTasksIDPrefix + t.UID
+		items = append(items, t)
+	}
+	return items, nil
+}
+
+func (r *GoogleRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	if item.ItemType != model.ItemTypeTask {
+		return r.Events.Create(ctx, item)
+	}
+	if r.tasksDenied {
+		return model.CalendarItem{}, errGTasksDenied
+	}
+	created, err := r.Tasks.Create(ctx, item)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	created.UID = GTasksIDPrefix + created.UID
+	return created, nil
+}
+
+func (r *GoogleRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	id, ok := strings.CutPrefix(remoteID, GTasksIDPrefix)
+	if !ok {
+		return r.Events.Update(ctx, remoteID, item)
+	}
+	if r.tasksDenied {
+		return model.CalendarItem{}, errGTasksDenied
+	}
+	updated, err := r.Tasks.Update(ctx, id, item)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	updated.UID = GTasksIDPrefix + updated.UID
+	return updated, nil
+}
+
+func (r *GoogleRemote) Delete(ctx context.Context, remoteID string) error {
+	if id, ok := strings.CutPrefix(remoteID, GTasksIDPrefix); ok {
+		if r.tasksDenied {
+			return errGTasksDenied
+		}
+		return r.Tasks.Delete(ctx, id)
+	}
+	return r.Events.Delete(ctx, remoteID)
+}
+
+var errGTasksDenied = errors.New("not authorized for Google Tasks; run: salja auth login google")
+
+// MSGraphRemote syncs events in one of the signed-in user's Outlook
+// calendars within a time window. An empty CalendarID is the default
+// calendar.
+type MSGraphRemote struct {
+	Client     *MSGraphClient
+	CalendarID string
+	Start      time.Time
+	End        time.Time
+}
+
+func (r *MSGraphRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	events, err := r.Client.ListEvents(ctx, r.CalendarID, r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	masters, err := r.Client.SeriesMasters(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	return MSGraphToCalendarItems(append(masters, events...)), nil
+}
+
+func (r *MSGraphRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	event := CalendarItemToMSGraph(item)
+	event.ID = ""
+	event.TransactionID = item.UID
+	created, err := r.Client.CreateEvent(ctx, r.CalendarID, &event)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return r.writeOverrides(ctx, *created, item)
+}
+
+func (r *MSGraphRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	event := CalendarItemToMSGraph(item)
+	event.ID = remoteID
+	updated, err := r.Client.UpdateEvent(ctx, &event)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return r.writeOverrides(ctx, *updated, item)
+}
+
+// writeOverrides updates the occurrences of a just written series that item
+// overrides, deletes the ones it cancels, and returns the series as a later
+// List sees it. Instances are looked up within a year of those dates.
+func (r *MSGraphRemote) writeOverrides(ctx context.Context, series MSGraphEvent, item model.CalendarItem) (model.CalendarItem, error) {
+	rec := item.Recurrence
+	if rec == nil || len(rec.Overrides)+len(rec.ExDates) == 0 {
+		return MSGraphToCalendarItem(series), nil
+	}
+	dates := append([]time.Time(nil), rec.ExDates...)
+	for _, ov := range rec.Overrides {
+		dates = append(dates, ov.RecurrenceID)
+	}
+	first, last := dates[0], dates[0]
+	for _, t := range dates[1:] {
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	instances, err := r.Client.ListInstances(ctx, series.ID, first.AddDate(-1, 0, 0), last.AddDate(1, 0, 0))
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	find := func(t time.Time) *MSGraphEvent {
+		for i := range instances {
+			if isGraphOccurrence(instances[i].OriginalStart, t, item.IsAllDay) {
+				return &instances[i]
+			}
+		}
+		return nil
+	}
+
+	for _, t := range rec.ExDates {
+		if inst := find(t); inst != nil {
+			if err := r.Client.DeleteEvent(ctx, inst.ID); err != nil {
+				return model.CalendarItem{}, fmt.Errorf("occurrence %s: %w", t.Format(time.RFC3339), err)
+			}
+		}
+	}
+	written := []MSGraphEvent{series}
+	for _, ov := range rec.Overrides {
+		inst := find(ov.RecurrenceID)
+		if inst == nil {
+			continue
+		}
+		event := CalendarItemToMSGraph(ov.Item)
+		event.ID = inst.ID
+		event.Recurrence = nil
+		event.SingleValueExtendedProperties = nil
+		updated, err := r.Client.UpdateEvent(ctx, &event)
+		if err != nil {
+			return model.CalendarItem{}, fmt.Errorf("occurrence %s: %w", ov.RecurrenceID.Format(time.RFC3339), err)
+		}
+		written = append(written, *updated)
+	}
+	return MSGraphToCalendarItems(written)[0], nil
+}
+
+// isGraphOccurrence reports whether an instance's original start is t;
+// all-day instances compare by date.
+func isGraphOccurrence(original string, t time.Time, allDay bool) bool {
+	start, err := time.Parse(time.RFC3339, original)
+	if err != nil {
+		return false
+	}
+	if allDay {
+		return start.UTC().Format("2006-01-02") == t.Format("2006-01-02")
+	}
+	return start.Equal(t)
+}
+
+func (r *MSGraphRemote) Delete(ctx context.Context, remoteID string) error {
+	return r.Client.DeleteEvent(ctx, remoteID)
+}
+
+// TodoistRemote syncs active Todoist tasks.
+type TodoistRemote struct {
+	Client    *TodoistClient
+	ProjectID string
+
+	mu        sync.Mutex
+	bySource  map[string]model.CalendarItem
+	closedIDs map[string]bool
+}
+
+func (r *TodoistRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	tasks, err := r.Client.GetTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.CalendarItem, 0, len(tasks))
+	for _, t := range tasks {
+		if r.ProjectID != "" && t.ProjectID != r.ProjectID {
+			continue
+		}
+		items = append(items, TodoistToCalendarItem(t))
+	}
+	return items, nil
+}
+
+func (r *TodoistRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToTodoist(item, r.ProjectID)
+	task.ID = ""
+	created, err := r.Client.CreateTask(ctx, &task)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	if item.IsCompleted() {
+		if err := r.Client.CloseTask(ctx, created.ID); err != nil {
+			return model.CalendarItem{}, err
+		}
+		created.IsCompleted = true
+	}
+	return TodoistToCalendarItem(*created), nil
+}
+
+func (r *TodoistRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToTodoist(item, r.ProjectID)
+	task.ID = remoteID
+	if r.closed(remoteID) {
+		// closed tasks cannot be edited until they are reopened
+		if err := r.Client.ReopenTask(ctx, remoteID); err != nil {
+			return model.CalendarItem{}, err
+		}
+	}
+	if err := r.Client.UpdateTask(ctx, remoteID, &task); err != nil {
+		return model.CalendarItem{}, err
+	}
+	if item.IsCompleted() {
+		if err := r.Client.CloseTask(ctx, remoteID); err != nil {
+			return model.CalendarItem{}, err
+		}
+	}
+	// The update endpoint's response is not relied upon; report the mapped view.
+	return TodoistToCalendarItem(task), nil
+}
+
+// closed reports whether Lookup found the task completed.
+func (r *TodoistRemote) closed(remoteID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closedIDs[remoteID]
+}
+
+func (r *TodoistRemote) Delete(ctx context.Context, remoteID string) error {
+	return r.Client.DeleteTask(ctx, remoteID)
+}
+
+// HidesCompleted reports that the REST task listing omits closed tasks.
+func (r *TodoistRemote) HidesCompleted() bool { return true }
+
+// CheckCompleted tells a task closed in Todoist from one deleted there.
+func (r *TodoistRemote) CheckCompleted(ctx context.Context, remoteID string) (bool, *time.Time, error) {
+	item, err := r.Client.GetItem(ctx, remoteID)
+	if err != nil || item == nil || !item.Checked {
+		return false, nil, err
+	}
+	if t, err := time.Parse(time.RFC3339, item.CompletedAt); err == nil {
+		return true, &t, nil
+	}
+	return true, nil, nil
+}
+
+// TickTickRemote syncs the open tasks of one TickTick project.
+type TickTickRemote struct {
+	Client    *TickTickClient
+	ProjectID string
+
+	mu       sync.Mutex
+	bySource map[string]model.CalendarItem
+}
+
+func (r *TickTickRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	tasks, err := r.Client.ListTasks(ctx, r.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.CalendarItem, 0, len(tasks))
+	for _, t := range tasks {
+		items = append(items, TickTickToCalendarItem(t))
+	}
+	return items, nil
+}
+
+func (r *TickTickRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToTickTick(item, r.ProjectID)
+	task.ID = ""
+	created, err := r.Client.CreateTask(ctx, &task)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return TickTickToCalendarItem(*created), nil
+}
+
+func (r *TickTickRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToTickTick(item, r.ProjectID)
+	task.ID = remoteID
+	updated, err := r.Client.UpdateTask(ctx, &task)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return TickTickToCalendarItem(*updated), nil
+}
+
+func (r *TickTickRemote) Delete(ctx context.Context, remoteID string) error {
+	return r.Client.DeleteTask(ctx, r.ProjectID, remoteID)
+}
+
+// HidesCompleted reports that project data only includes uncompleted tasks.
+func (r *TickTickRemote) HidesCompleted() bool { return true }
+
+// CheckCompleted tells a task completed in TickTick from one deleted there.
+func (r *TickTickRemote) CheckCompleted(ctx context.Context, remoteID string) (bool, *time.Time, error) {
+	task, err := r.Client.GetTask(ctx, r.ProjectID, remoteID)
+	if err != nil || task == nil || task.Status != 2 {
+		return false, nil, err
+	}
+	if t, err := parseTickTickDate(task.CompletedTime); err == nil {
+		return true, &t, nil
+	}
+	return true, nil, nil
+}
+
+// MSGraphTodoRemote syncs the tasks of one Microsoft To Do list, completed
+// ones included. Checklist items are written after the task itself.
+type MSGraphTodoRemote struct {
+	Client *MSGraphClient
+	ListID string
+
+	mu       sync.Mutex
+	bySource map[string]model.CalendarItem
+}
+
+func (r *MSGraphTodoRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	tasks, err := r.Client.ListTodoTasks(ctx, r.ListID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.CalendarItem, 0, len(tasks))
+	for _, t := range tasks {
+		items = append(items, MSGraphTodoToCalendarItem(t))
+	}
+	return items, nil
+}
+
+func (r *MSGraphTodoRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToMSGraphTodo(item)
+	task.ID = ""
+	checklist := task.ChecklistItems
+	task.ChecklistItems = nil
+	created, err := r.Client.CreateTodoTask(ctx, r.ListID, &task)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	created.ChecklistItems, err = r.Client.SyncChecklist(ctx, r.ListID, created.ID, nil, checklist)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return MSGraphTodoToCalendarItem(*created), nil
+}
+
+func (r *MSGraphTodoRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	task := CalendarItemToMSGraphTodo(item)
+	task.ID = remoteID
+	checklist := task.ChecklistItems
+	task.ChecklistItems = nil
+	updated, err := r.Client.UpdateTodoTask(ctx, r.ListID, &task)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	current, err := r.Client.ListChecklistItems(ctx, r.ListID, remoteID)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	updated.ChecklistItems, err = r.Client.SyncChecklist(ctx, r.ListID, remoteID, current, checklist)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return MSGraphTodoToCalendarItem(*updated), nil
+}
+
+func (r *MSGraphTodoRemote) Delete(ctx context.Context, remoteID string) error {
+	return r.Client.DeleteTodoTask(ctx, r.ListID, remoteID)
+}
+
+// NotionRemote syncs the pages of one Notion database.
+type NotionRemote struct {
+	Client      *NotionClient
+	DatabaseID  string
+	PropertyMap NotionPropertyMap
+
+	mu       sync.Mutex
+	bySource map[string]model.CalendarItem
+}
+
+func (r *NotionRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	var items []model.CalendarItem
+	cursor := ""
+	for {
+		result, err := r.Client.QueryDatabase(ctx, r.DatabaseID, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range result.Results {
+			items = append(items, NotionToCalendarItem(page, r.PropertyMap))
+		}
+		if !result.HasMore {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return items, nil
+}
+
+func (r *NotionRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	page := CalendarItemToNotion(item, r.PropertyMap)
+	created, err := r.Client.CreatePage(ctx, r.DatabaseID, &page)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	return NotionToCalendarItem(*created, r.PropertyMap), nil
+}
+
+func (r *NotionRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	page := CalendarItemToNotion(item, r.PropertyMap)
+	if err := r.Client.UpdatePage(ctx, remoteID, page.Properties); err != nil {
+		return model.CalendarItem{}, err
+	}
+	page.ID = remoteID
+	return notionView(page, r.PropertyMap), nil
+}
+
+func (r *NotionRemote) Delete(ctx context.Context, remoteID string) error {
+	return r.Client.ArchivePage(ctx, remoteID)
+}
+
+// notionView maps an outgoing page back through the reader so the result
+// matches what a later List returns. Outgoing rich text only sets Text, so
+// it is copied into PlainText first.
+func notionView(page NotionPage, pm NotionPropertyMap) model.CalendarItem {
+	for name, prop := range page.Properties {
+		for i, rt := range prop.Title {
+			if rt.Text != nil && rt.PlainText == "" {
+				prop.Title[i].PlainText = rt.Text.Content
+			}
+		}
+		for i, rt := range prop.RichText {
+			if rt.Text != nil && rt.PlainText == "" {
+				prop.RichText[i].PlainText = rt.Text.Content
+			}
+		}
+		page.Properties[name] = prop
+	}
+	return NotionToCalendarItem(page, pm)
+}
+
+// CalDAVRemote syncs events within a time window and all tasks of one
+// CalDAV calendar. Remote IDs are iCalendar UIDs; the resource href and ETag
+// seen for each UID are remembered from List so updates are conditional.
+type CalDAVRemote struct {
+	Client       *CalDAVClient
+	CalendarHref string
+	Start        time.Time
+	End          time.Time
+
+	mu      sync.Mutex
+	objects map[string]CalDAVObject
+}
+
+func (r *CalDAVRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	events, err := r.Client.QueryCalendar(ctx, r.CalendarHref, "VEVENT", r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := r.Client.QueryCalendar(ctx, r.CalendarHref, "VTODO", time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.objects = make(map[string]CalDAVObject, len(events)+len(todos))
+	items := make([]model.CalendarItem, 0, len(events)+len(todos))
+	for _, obj := range append(events, todos...) {
+		if _, dup := r.objects[obj.Item.UID]; dup {
+			continue
+		}
+		r.objects[obj.Item.UID] = obj
+		items = append(items, obj.Item)
+	}
+	return items, nil
+}
+
+func (r *CalDAVRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	if item.UID == "" {
+		item.UID = newCalDAVUID()
+	}
+	href := ObjectHref(r.CalendarHref, item.UID)
+	etag, err := r.Client.PutItem(ctx, href, item, "")
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	r.remember(CalDAVObject{Href: href, ETag: etag, Item: item})
+	return icsView(ctx, item)
+}
+
+func (r *CalDAVRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	item.UID = remoteID
+	obj, ok := r.object(remoteID)
+	if !ok {
+		obj = CalDAVObject{Href: ObjectHref(r.CalendarHref, remoteID)}
+	}
+	etag := obj.ETag
+	if etag == "" {
+		etag = "*"
+	}
+	newETag, err := r.Client.PutItem(ctx, obj.Href, item, etag)
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	r.remember(CalDAVObject{Href: obj.Href, ETag: newETag, Item: item})
+	return icsView(ctx, item)
+}
+
+func (r *CalDAVRemote) Delete(ctx context.Context, remoteID string) error {
+	obj, ok := r.object(remoteID)
+	if !ok {
+		obj = CalDAVObject{Href: ObjectHref(r.CalendarHref, remoteID)}
+	}
+	if err := r.Client.DeleteItem(ctx, obj.Href, obj.ETag); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	delete(r.objects, remoteID)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *CalDAVRemote) object(uid string) (CalDAVObject, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	obj, ok := r.objects[uid]
+	return obj, ok
+}
+
+func (r *CalDAVRemote) remember(obj CalDAVObject) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.objects == nil {
+		r.objects = make(map[string]CalDAVObject)
+	}
+	r.objects[obj.Item.UID] = obj
+}
+
+// icsView round-trips an item through the iCalendar writer and parser so it
+// matches what a later List returns for it.
+func icsView(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	var buf bytes.Buffer
+	if err := ics.NewWriter().Write(ctx, &model.CalendarCollection{Items: []model.CalendarItem{item}}, &buf); err != nil {
+		return model.CalendarItem{}, err
+	}
+	col, err := ics.NewParser().Parse(ctx, &buf, "")
+	if err != nil {
+		return model.CalendarItem{}, err
+	}
+	if len(col.Items) == 0 {
+		return item, nil
+	}
+	return col.Items[0], nil
+}
+
+// The Lookup methods find the remote copy of an item by the salja UID the
+// mappers stamp on everything they send. They return nil when there is none.
+// Task services are listed once and indexed on the first call.
+
+func (r *GCalRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	event, err := r.Client.FindBySourceUID(ctx, r.CalendarID, uid)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	item := GCalToCalendarItem(*event)
+	return &item, nil
+}
+
+func (r *GTasksRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySource == nil {
+		tasks, err := r.Client.ListTasks(ctx, r.ListID)
+		if err != nil {
+			return nil, err
+		}
+		r.children = gtaskChildren(tasks)
+		sources := make(map[string]string)
+		for _, t := range tasks {
+			if src := GTasksSourceUID(t); src != "" {
+				sources[t.ID] = src
+			}
+		}
+		r.bySource = make(map[string]model.CalendarItem)
+		for _, item := range GTasksToCalendarItems(tasks) {
+			if src, ok := sources[item.UID]; ok {
+				r.bySource[src] = item
+			}
+		}
+	}
+	return lookupIndex(r.bySource, uid), nil
+}
+
+func (r *MSGraphRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	event, err := r.Client.FindBySourceUID(ctx, r.CalendarID, uid)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	item := MSGraphToCalendarItem(*event)
+	return &item, nil
+}
+
+func (r *TodoistRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySource == nil {
+		tasks, err := r.Client.GetTasks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// completed copies count too, or pushing again would recreate them
+		completed, err := r.Client.GetCompletedTasks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.closedIDs = make(map[string]bool, len(completed))
+		for _, t := range completed {
+			r.closedIDs[t.ID] = true
+		}
+		tasks = append(completed, tasks...)
+		r.bySource = make(map[string]model.CalendarItem)
+		for _, t := range tasks {
+			if src := TodoistSourceUID(t); src != "" {
+				r.bySource[src] = TodoistToCalendarItem(t)
+			}
+		}
+	}
+	return lookupIndex(r.bySource, uid), nil
+}
+
+func (r *TickTickRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySource == nil {
+		tasks, err := r.Client.ListTasks(ctx, r.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		r.bySource = make(map[string]model.CalendarItem)
+		for _, t := range tasks {
+			if src := TickTickSourceUID(t); src != "" {
+				r.bySource[src] = TickTickToCalendarItem(t)
+			}
+		}
+	}
+	return lookupIndex(r.bySource, uid), nil
+}
+
+func (r *MSGraphTodoRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySource == nil {
+		tasks, err := r.Client.ListTodoTasks(ctx, r.ListID)
+		if err != nil {
+			return nil, err
+		}
+		r.bySource = make(map[string]model.CalendarItem)
+		for _, t := range tasks {
+			if src := MSGraphTodoSourceUID(t); src != "" {
+				r.bySource[src] = MSGraphTodoToCalendarItem(t)
+			}
+		}
+	}
+	return lookupIndex(r.bySource, uid), nil
+}
+
+func (r *NotionRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	if r.PropertyMap.SourceUID == "" {
+		return nil, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySource == nil {
+		r.bySource = make(map[string]model.CalendarItem)
+		cursor := ""
+		for {
+			result, err := r.Client.QueryDatabase(ctx, r.DatabaseID, cursor)
+			if err != nil {
+				r.bySource = nil
+				return nil, err
+			}
+			for _, page := range result.Results {
+				if src := NotionSourceUID(page, r.PropertyMap); src != "" {
+					r.bySource[src] = NotionToCalendarItem(page, r.PropertyMap)
+				}
+			}
+			if !result.HasMore {
+				break
+			}
+			cursor = result.NextCursor
+		}
+	}
+	return lookupIndex(r.bySource, uid), nil
+}
+
+// Lookup fetches the object at the href salja writes uid to. CalDAV items
+// keep their UID, so no separate stamp is needed.
+func (r *CalDAVRemote) Lookup(ctx context.Context, uid string) (*model.CalendarItem, error) {
+	objs, err := r.Client.MultiGet(ctx, r.CalendarHref, []string{ObjectHref(r.CalendarHref, uid)})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if obj.Item.UID == uid {
+			r.remember(obj)
+			item := obj.Item
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+func lookupIndex(index map[string]model.CalendarItem, uid string) *model.CalendarItem {
+	item, ok := index[uid]
+	if !ok {
+		return nil
+	}
+	return &item
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+)
+
+// The mappers stamp the salja item UID onto every object they send so that a
+// re-run push finds what an earlier, possibly interrupted, run created and
+// updates it instead of inserting a duplicate.
+
+// SourceUIDKey names the salja UID in Google extended properties.
+const SourceUIDKey = "saljaUID"
+
+// NotionSourceUIDProperty is the rich text property holding the salja UID in
+// a Notion database. Hide it in the database view to keep it out of the way.
+const NotionSourceUIDProperty = "Salja UID"
+
+// msGraphSourceUIDProperty is a named property in the PS_PUBLIC_STRINGS set.
+const msGraphSourceUIDProperty = "String {00020329-0000-0000-C000-000000000046} Name " + SourceUIDKey
+
+// descriptionMarker prefixes the line appended to task descriptions on
+// services without a structured place for external IDs.
+const descriptionMarker = "salja-uid: "
+
+func stampDescription(desc, uid string) string {
+	desc, _ = splitDescriptionMarker(desc)
+	if uid == "" {
+		return desc
+	}
+	if desc == "" {
+		return descriptionMarker + uid
+	}
+	return desc + "\n\n" + descriptionMarker + uid
+}
+
+// splitDescriptionMarker removes a trailing salja UID marker from desc.
+func splitDescriptionMarker(desc string) (string, string) {
+	i := strings.LastIndex(desc, descriptionMarker)
+	if i < 0 || (i > 0 && desc[i-1] != '\n') || strings.Contains(desc[i:], "\n") {
+		return desc, ""
+	}
+	return strings.TrimRight(desc[:i], "\n"), strings.TrimSpace(desc[i+len(descriptionMarker):])
+}
+
+// GCalSourceUID returns the salja UID stamped on an event, if any.
+func GCalSourceUID(event GCalEvent) string {
+	if event.ExtendedProps == nil {
+		return ""
+	}
+	return event.ExtendedProps.Private[SourceUIDKey]
+}
+
+// FindBySourceUID returns the event stamped with uid, or nil if none exists.
+func (c *GCalClient) FindBySourceUID(ctx context.Context, calendarID, uid string) (*GCalEvent, error) {
+	params := url.Values{}
+	params.Set("privateExtendedProperty", SourceUIDKey+"="+uid)
+	params.Set("maxResults", "1")
+	reqURL := fmt.Sprintf("%s/calendars/%s/events?%s", gcalBaseURL, url.PathEscape(calendarID), params.Encode())
+	data, status, err := c.doRequest(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Google Calendar", StatusCode: status, Message: string(data)}
+	}
+	var list GCalEventList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, event := range list.Items {
+		if event.Status != "cancelled" {
+			return &event, nil
+		}
+	}
+	return nil, nil
+}
+
+// MSGraphExtendedProperty is a single-value extended property on an event.
+type MSGraphExtendedProperty struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// msGraphSourceUIDFilter is the OData filter matching events stamped with uid.
+func msGraphSourceUIDFilter(uid string) string {
+	return fmt.Sprintf("singleValueExtendedProperties/Any(ep: ep/id eq '%s' and ep/value eq '%s')",
+		msGraphSourceUIDProperty, strings.ReplaceAll(uid, "'", "''"))
+}
+
+// FindBySourceUID returns the event stamped with uid, or nil if none exists.
+func (c *MSGraphClient) FindBySourceUID(ctx context.Context, calendarID, uid string) (*MSGraphEvent, error) {
+	reqURL := fmt.Sprintf("%s%s/events?$filter=%s&$top=1", graphBaseURL, graphCalendarPath(calendarID), url.QueryEscape(msGraphSourceUIDFilter(uid)))
+	data, status, err := c.doRequest(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "Microsoft Graph", StatusCode: status, Message: string(data)}
+	}
+	var list MSGraphEventList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Value) == 0 {
+		return nil, nil
+	}
+	return &list.Value[0], nil
+}
+
+// TodoistSourceUID returns the salja UID marked in a task description.
+func TodoistSourceUID(task TodoistTask) string {
+	_, uid := splitDescriptionMarker(task.Description)
+	return uid
+}
+
+// TickTickSourceUID returns the salja UID marked in a task's content.
+func TickTickSourceUID(task TickTickTask) string {
+	_, uid := splitDescriptionMarker(task.Content)
+	return uid
+}
+
+// GTasksSourceUID returns the salja UID marked in a Google task's notes.
+func GTasksSourceUID(task GTask) string {
+	_, uid := splitDescriptionMarker(task.Notes)
+	return uid
+}
+
+// MSGraphTodoSourceUID returns the salja UID marked in a To Do task's body.
+func MSGraphTodoSourceUID(task MSGraphTodoTask) string {
+	if task.Body == nil {
+		return ""
+	}
+	_, uid := splitDescriptionMarker(task.Body.Content)
+	return uid
+}
+
+// NotionSourceUID returns the salja UID stored on a page, if the property map
+// names a source UID property.
+func NotionSourceUID(page NotionPage, pm NotionPropertyMap) string {
+	if pm.SourceUID == "" {
+		return ""
+	}
+	if prop, ok := page.Properties[pm.SourceUID]; ok && len(prop.RichText) > 0 {
+		return prop.RichText[0].PlainText
+	}
+	return ""
+}
+
+// EnsureRichTextProperty adds a rich text property to a database. Patching
+// an existing rich text property of that name is a no-op.
+func (c *NotionClient) EnsureRichTextProperty(ctx context.Context, databaseID, name string) error {
+	body := map[string]interface{}{
+		"properties": map[string]interface{}{
+			name: map[string]interface{}{"rich_text": map[string]interface{}{}},
+		},
+	}
+	data, status, err := c.doRequest(ctx, "PATCH", notionBaseURL+"/databases/"+databaseID, body)
+	if err != nil {
+		return err
+	}
+	if status != 200 {
+		return &salerr.APIError{Service: "notion", StatusCode: status, Message: string(data)}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+)
+
+// SyncTarget is a container items sync with: a calendar, a task project or
+// a Notion database.
+type SyncTarget struct {
+	ID   string
+	Name string
+	// Default marks the container used when none is named.
+	Default bool
+}
+
+// ResolveTarget finds the target whose ID or name matches ref, comparing
+// names case-insensitively. An empty ref selects the default target.
+func ResolveTarget(targets []SyncTarget, ref string) (SyncTarget, error) {
+	if ref == "" {
+		for _, t := range targets {
+			if t.Default {
+				return t, nil
+			}
+		}
+		return SyncTarget{}, fmt.Errorf("no default target; name one")
+	}
+	for _, t := range targets {
+		if t.ID == ref {
+			return t, nil
+		}
+	}
+	var matches []SyncTarget
+	for _, t := range targets {
+		if strings.EqualFold(t.Name, ref) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return SyncTarget{}, fmt.Errorf("%q not found", ref)
+	default:
+		return SyncTarget{}, fmt.Errorf("%q is ambiguous; %d targets have that name, use an ID", ref, len(matches))
+	}
+}
+
+// Targets lists the calendars on the user's calendar list.
+func (c *GCalClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	calendars, err := c.ListCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]SyncTarget, 0, len(calendars))
+	for _, cal := range calendars {
+		targets = append(targets, SyncTarget{ID: cal.ID, Name: cal.Summary, Default: cal.Primary})
+	}
+	return targets, nil
+}
+
+// Targets lists the user's Google Tasks lists, their IDs marked with
+// GTasksIDPrefix so they can sit beside the calendars of the same account.
+func (c *GTasksClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	lists, err := c.ListTaskLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]SyncTarget, 0, len(lists))
+	for _, l := range lists {
+		targets = append(targets, SyncTarget{ID: GTasksIDPrefix + l.ID, Name: l.Title})
+	}
+	return targets, nil
+}
+
+// Targets lists the user's Outlook calendars.
+func (c *MSGraphClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	calendars, err := c.ListCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]SyncTarget, 0, len(calendars))
+	for _, cal := range calendars {
+		targets = append(targets, SyncTarget{ID: cal.ID, Name: cal.Name, Default: cal.IsDefaultCalendar})
+	}
+	return targets, nil
+}
+
+// TodoTargets lists the user's Microsoft To Do lists. The Tasks list is the
+// default.
+func (c *MSGraphClient) TodoTargets(ctx context.Context) ([]SyncTarget, error) {
+	lists, err := c.ListTodoLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]SyncTarget, 0, len(lists))
+	for _, l := range lists {
+		targets = append(targets, SyncTarget{ID: l.ID, Name: l.DisplayName, Default: l.WellknownListName == "defaultList"})
+	}
+	return targets, nil
+}
+
+// Targets lists the user's Todoist projects. The inbox is the default.
+func (c *TodoistClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	projects, err := c.GetProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]SyncTarget, 0, len(projects))
+	for _, p := range projects {
+		targets = append(targets, SyncTarget{ID: p.ID, Name: p.Name, Default: p.IsInboxProject})
+	}
+	return targets, nil
+}
+
+// Targets lists the user's TickTick projects. TickTick has no default.
+func (c *TickTickClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	projects, err := c.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]SyncTarget, 0, len(projects))
+	for _, p := range projects {
+		targets = append(targets, SyncTarget{ID: p.ID, Name: p.Name})
+	}
+	return targets, nil
+}
+
+// Targets lists the databases shared with the integration. Notion has no
+// default.
+func (c *NotionClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	var targets []SyncTarget
+	cursor := ""
+	for {
+		body := map[string]interface{}{
+			"filter": map[string]string{"property": "object", "value": "database"},
+		}
+		if cursor != "" {
+			body["start_cursor"] = cursor
+		}
+		data, status, err := c.doRequest(ctx, "POST", notionBaseURL+"/search", body)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "notion", StatusCode: status, Message: string(data)}
+		}
+		var result struct {
+			Results []struct {
+				ID    string `json:"id"`
+				Title []struct {
+					PlainText string `json:"plain_text"`
+				} `json:"title"`
+			} `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		for _, db := range result.Results {
+			var name strings.Builder
+			for _, t := range db.Title {
+				name.WriteString(t.PlainText)
+			}
+			targets = append(targets, SyncTarget{ID: db.ID, Name: name.String()})
+		}
+		if !result.HasMore {
+			return targets, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// Targets lists the calendars in the CalDAV home set by href. The first one
+// that accepts events is the default.
+func (c *CalDAVClient) Targets(ctx context.Context) ([]SyncTarget, error) {
+	calendars, err := c.ListCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	def, _ := FindCalendar(calendars, "")
+	targets := make([]SyncTarget, 0, len(calendars))
+	for _, cal := range calendars {
+		targets = append(targets, SyncTarget{ID: cal.Href, Name: cal.DisplayName, Default: cal.Href == def.Href})
+	}
+	return targets, nil
+}
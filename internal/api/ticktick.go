@@ -83,6 +83,8 @@ type TickTickTask struct {
 	Items      []TickTickSubtask `json:"items,omitempty"`
 	TimeZone   string            `json:"timeZone,omitempty"`
 	RepeatFlag string            `json:"repeatFlag,omitempty"`
+	// CompletedTime is set by TickTick; salja never writes it.
+	CompletedTime string `json:"completedTime,omitempty"`
 }
 
 type TickTickSubtask struct {
@@ -127,6 +129,33 @@ func (c *TickTickClient) ListTasks(ctx context.Context, projectID string) ([]Tic
 	return result.Tasks, nil
 }
 
+// GetTask fetches one task of a project, completed ones included. A deleted
+// task comes back nil.
+func (c *TickTickClient) GetTask(ctx context.Context, projectID, taskID string) (*TickTickTask, error) {
+	path := fmt.Sprintf("/project/%s/task/%s", projectID, taskID)
+	data, status, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "ticktick", StatusCode: status, Message: string(data)}
+	}
+	var task TickTickTask
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, err
+	}
+	if task.ID == "" {
+		return nil, nil
+	}
+	return &task, nil
+}
+
 func (c *TickTickClient) CreateTask(ctx context.Context, task *TickTickTask) (*TickTickTask, error) {
 	data, status, err := c.doRequest(ctx, "POST", "/task", task)
 	if err != nil {
@@ -166,10 +195,11 @@ func (c *TickTickClient) DeleteTask(ctx context.Context, projectID, taskID strin
 
 // TickTickToCalendarItem maps a TickTick API task to the unified model.
 func TickTickToCalendarItem(task TickTickTask) model.CalendarItem {
+	content, _ := splitDescriptionMarker(task.Content)
 	item := model.CalendarItem{
 		UID:         task.ID,
 		Title:       task.Title,
-		Description: task.Content,
+		Description: content,
 		ItemType:    model.ItemTypeTask,
 		Tags:        task.Tags,
 	}
@@ -227,7 +257,7 @@ func CalendarItemToTickTick(item model.CalendarItem, projectID string) TickTickT
 		ID:        item.UID,
 		ProjectID: projectID,
 		Title:     item.Title,
-		Content:   item.Description,
+		Content:   stampDescription(item.Description, item.UID),
 		Tags:      item.Tags,
 	}
 
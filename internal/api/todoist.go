@@ -90,8 +90,9 @@ type TodoistDue struct {
 }
 
 type TodoistProject struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	IsInboxProject bool   `json:"is_inbox_project,omitempty"`
 }
 
 func (c *TodoistClient) GetTasks(ctx context.Context) ([]TodoistTask, error) {
@@ -123,7 +124,7 @@ func (c *TodoistClient) UpdateTask(ctx context.Context, taskID string, task *Tod
 	if err != nil {
 		return err
 	}
-	if status != 204 {
+	if status != 200 && status != 204 {
 		return &salerr.APIError{Service: "todoist", StatusCode: status, Message: "update failed"}
 	}
 	return nil
@@ -140,6 +141,106 @@ func (c *TodoistClient) CloseTask(ctx context.Context, taskID string) error {
 	return nil
 }
 
+func (c *TodoistClient) ReopenTask(ctx context.Context, taskID string) error {
+	_, status, err := c.doRequest(ctx, "POST", todoistRESTURL+"/tasks/"+taskID+"/reopen", nil)
+	if err != nil {
+		return err
+	}
+	if status != 204 {
+		return &salerr.APIError{Service: "todoist", StatusCode: status, Message: "reopen failed"}
+	}
+	return nil
+}
+
+// GetCompletedTasks returns the completed tasks the REST listing leaves
+// out, read page by page from the Sync API's completed archive.
+func (c *TodoistClient) GetCompletedTasks(ctx context.Context) ([]TodoistTask, error) {
+	const limit = 200
+	var tasks []TodoistTask
+	for offset := 0; ; offset += limit {
+		url := fmt.Sprintf("%s/completed/get_all?annotate_items=true&limit=%d&offset=%d", todoistSyncURL, limit, offset)
+		data, status, err := c.doRequest(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != 200 {
+			return nil, &salerr.APIError{Service: "todoist", StatusCode: status, Message: string(data)}
+		}
+		var page struct {
+			Items []struct {
+				TaskID     string       `json:"task_id"`
+				ItemObject *TodoistTask `json:"item_object"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, it := range page.Items {
+			if it.ItemObject == nil {
+				continue
+			}
+			task := *it.ItemObject
+			if task.ID == "" {
+				task.ID = it.TaskID
+			}
+			task.IsCompleted = true
+			tasks = append(tasks, task)
+		}
+		if len(page.Items) < limit {
+			return tasks, nil
+		}
+	}
+}
+
+func (c *TodoistClient) DeleteTask(ctx context.Context, taskID string) error {
+	_, status, err := c.doRequest(ctx, "DELETE", todoistRESTURL+"/tasks/"+taskID, nil)
+	if err != nil {
+		return err
+	}
+	if status != 204 {
+		return &salerr.APIError{Service: "todoist", StatusCode: status, Message: "delete failed"}
+	}
+	return nil
+}
+
+// TodoistItem is a task as the Sync API reports it, completed and deleted
+// ones included.
+type TodoistItem struct {
+	ID          string `json:"id"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	ProjectID   string `json:"project_id"`
+	Checked     bool   `json:"checked"`
+	IsDeleted   bool   `json:"is_deleted"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+// GetItem fetches one task through the Sync API, which still has it after
+// it is completed, unlike the REST API. A task deleted for good comes back
+// nil.
+func (c *TodoistClient) GetItem(ctx context.Context, taskID string) (*TodoistItem, error) {
+	data, status, err := c.doRequest(ctx, "POST", todoistSyncURL+"/items/get", map[string]string{"item_id": taskID})
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status != 200 {
+		return nil, &salerr.APIError{Service: "todoist", StatusCode: status, Message: string(data)}
+	}
+	var result struct {
+		Item *TodoistItem `json:"item"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result.Item != nil && result.Item.IsDeleted {
+		return nil, nil
+	}
+	return result.Item, nil
+}
+
 func (c *TodoistClient) GetProjects(ctx context.Context) ([]TodoistProject, error) {
 	data, status, err := c.doRequest(ctx, "GET", todoistRESTURL+"/projects", nil)
 	if err != nil {
@@ -160,15 +261,26 @@ type TodoistSyncClient struct {
 }
 
 func NewTodoistSyncClient(token *Token) *TodoistSyncClient {
+	return NewTodoistSyncClientWithTimeout(token, 30*time.Second)
+}
+
+func NewTodoistSyncClientWithTimeout(token *Token, timeout time.Duration) *TodoistSyncClient {
 	return &TodoistSyncClient{
 		token:      token,
 		syncToken:  "*",
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: timeout},
 	}
 }
 
+// SyncToken returns the token the next Sync call will send.
+func (c *TodoistSyncClient) SyncToken() string { return c.syncToken }
+
+// SetSyncToken resumes from a previously stored token; "*" forces a full sync.
+func (c *TodoistSyncClient) SetSyncToken(token string) { c.syncToken = token }
+
 type TodoistSyncResponse struct {
 	SyncToken string          `json:"sync_token"`
+	FullSync  bool            `json:"full_sync"`
 	Items     json.RawMessage `json:"items"`
 	Projects  json.RawMessage `json:"projects"`
 }
@@ -196,6 +308,9 @@ func (c *TodoistSyncClient) Sync(ctx context.Context, resourceTypes []string) (*
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if resp.StatusCode == http.StatusGone {
+		return nil, ErrCursorExpired
+	}
 	if resp.StatusCode != 200 {
 		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("todoist sync API error (HTTP %d): %s", resp.StatusCode, respBody)
@@ -212,10 +327,11 @@ func (c *TodoistSyncClient) Sync(ctx context.Context, resourceTypes []string) (*
 
 // TodoistToCalendarItem maps a Todoist task to the unified model.
 func TodoistToCalendarItem(task TodoistTask) model.CalendarItem {
+	description, _ := splitDescriptionMarker(task.Description)
 	item := model.CalendarItem{
 		UID:         task.ID,
 		Title:       task.Content,
-		Description: task.Description,
+		Description: description,
 		ItemType:    model.ItemTypeTask,
 		Tags:        task.Labels,
 	}
@@ -262,7 +378,7 @@ func CalendarItemToTodoist(item model.CalendarItem, projectID string) TodoistTas
 		ID:          item.UID,
 		ProjectID:   projectID,
 		Content:     item.Title,
-		Description: item.Description,
+		Description: stampDescription(item.Description, item.UID),
 		Labels:      item.Tags,
 	}
 
@@ -74,7 +74,14 @@ func (w *CalendarWriter) createEvent(item model.CalendarItem, calendarName strin
 		props = append(props, `allday event:true`)
 	}
 
-	scriptParts = append(scriptParts, fmt.Sprintf(`    make new event with properties {%s}`, strings.Join(props, ", ")))
+	scriptParts = append(scriptParts, fmt.Sprintf(`    set newEvent to make new event with properties {%s}`, strings.Join(props, ", ")))
+	// Calendar takes attendees by address and sends no invitations
+	for _, a := range item.Attendees {
+		if a.Email == "" {
+			continue
+		}
+		scriptParts = append(scriptParts, fmt.Sprintf(`    make new attendee at end of attendees of newEvent with properties {email:"%s"}`, escapeAS(a.Email)))
+	}
 	scriptParts = append(scriptParts, `  end tell`)
 	scriptParts = append(scriptParts, `end tell`)
 
@@ -125,7 +132,17 @@ set evtLoc to ""
 try
 set evtLoc to location of evt
 end try
-set output to output & evtSummary & "|||" & evtStart & "|||" & evtEnd & "|||" & evtDesc & "|||" & evtLoc & linefeed
+set evtAtt to ""
+try
+repeat with att in attendees of evt
+set attName to ""
+try
+set attName to display name of att
+end try
+set evtAtt to evtAtt & (email of att) & "^^" & attName & "^^" & (participation status of att as string) & ";;"
+end repeat
+end try
+set output to output & evtSummary & "|||" & evtStart & "|||" & evtEnd & "|||" & evtDesc & "|||" & evtLoc & "|||" & evtAtt & linefeed
 end repeat
 end tell
 return output
@@ -172,9 +189,42 @@ end tell`,
 		if t, err := time.Parse("2006-01-02T15:04:05", parts[2]); err == nil {
 			item.EndTime = &t
 		}
+		if len(parts) > 5 {
+			item.Attendees = parseCalendarAttendees(parts[5])
+		}
 
 		collection.Items = append(collection.Items, item)
 	}
 
 	return collection, nil
 }
+
+// calendarPartStats maps Calendar's participation status names to PARTSTAT.
+var calendarPartStats = map[string]model.PartStat{
+	"unknown":   model.PartStatNeedsAction,
+	"accepted":  model.PartStatAccepted,
+	"declined":  model.PartStatDeclined,
+	"tentative": model.PartStatTentative,
+}
+
+// parseCalendarAttendees reads the email^^name^^status;; list the read
+// script builds for an event's attendees.
+func parseCalendarAttendees(field string) []model.Attendee {
+	var attendees []model.Attendee
+	for _, entry := range strings.Split(field, ";;") {
+		fields := strings.Split(entry, "^^")
+		if len(fields) < 3 || fields[0] == "" {
+			continue
+		}
+		email := fields[0]
+		if len(email) >= 7 && strings.EqualFold(email[:7], "mailto:") {
+			email = email[7:]
+		}
+		attendees = append(attendees, model.Attendee{
+			Email:  email,
+			Name:   fields[1],
+			Status: calendarPartStats[fields[2]],
+		})
+	}
+	return attendees
+}
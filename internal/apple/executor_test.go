@@ -1,6 +1,7 @@
 package apple
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -51,6 +52,7 @@ func TestMockCalendarWriterCreateEvent(t *testing.T) {
 			IsAllDay:    false,
 			ItemType:    model.ItemTypeEvent,
 			Status:      model.StatusPending,
+			Attendees:   []model.Attendee{{Email: "sam@example.com"}, {Name: "Front desk"}},
 		},
 	}
 
@@ -78,11 +80,15 @@ func TestMockCalendarWriterCreateEvent(t *testing.T) {
 			`set year of startD to 2025`,
 			`set year of endD to 2025`,
 			`make new event with properties`,
+			`make new attendee at end of attendees of newEvent with properties {email:"sam@example.com"}`,
 		} {
 			if !strings.Contains(script, want) {
 				t.Errorf("script missing %q\ngot:\n%s", want, script)
 			}
 		}
+		if strings.Count(script, "make new attendee") != 1 {
+			t.Errorf("attendees without an address should be skipped\ngot:\n%s", script)
+		}
 	})
 }
 
@@ -135,7 +141,7 @@ func TestMockCalendarWriterNoStartTime(t *testing.T) {
 }
 
 func TestMockCalendarReaderRead(t *testing.T) {
-	fakeOutput := "Meeting|||2025-06-15T10:00:00|||2025-06-15T11:00:00|||Notes here|||Office\nLunch|||2025-06-15T12:00:00|||2025-06-15T13:00:00||||||Cafe"
+	fakeOutput := "Meeting|||2025-06-15T10:00:00|||2025-06-15T11:00:00|||Notes here|||Office|||sam@example.com^^Sam Lee^^accepted;;mailto:kim@example.com^^^^unknown;;\nLunch|||2025-06-15T12:00:00|||2025-06-15T13:00:00||||||Cafe"
 
 	mock := &mockScriptRunner{outputs: []string{fakeOutput}}
 	withMock(mock, func() {
@@ -167,6 +173,13 @@ func TestMockCalendarReaderRead(t *testing.T) {
 		if item.ItemType != model.ItemTypeEvent {
 			t.Errorf("expected ItemType %q, got %q", model.ItemTypeEvent, item.ItemType)
 		}
+		wantAttendees := []model.Attendee{
+			{Email: "sam@example.com", Name: "Sam Lee", Status: model.PartStatAccepted},
+			{Email: "kim@example.com", Status: model.PartStatNeedsAction},
+		}
+		if !reflect.DeepEqual(item.Attendees, wantAttendees) {
+			t.Errorf("expected attendees %+v, got %+v", wantAttendees, item.Attendees)
+		}
 
 		script := mock.calls[0]
 		if !strings.Contains(script, `tell calendar "Work"`) {
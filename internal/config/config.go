@@ -2,12 +2,14 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/gongahkia/salja/internal/csvmap"
 	salerr "github.com/gongahkia/salja/internal/errors"
 	"github.com/gongahkia/salja/internal/logging"
 )
@@ -19,10 +21,37 @@ type Config struct {
 	DataLossMode         string             `toml:"data_loss_mode"`
 	StreamingThresholdMB int                `toml:"streaming_threshold_mb"`
 	APITimeoutSeconds    int                `toml:"api_timeout_seconds"`
+	SyncTimeoutMinutes   int                `toml:"sync_timeout_minutes"` // 0 scales with item count
 	PriorityMap          map[string]int     `toml:"priority_map"`
 	TagMap               map[string]string  `toml:"tag_map"`
 	ConflictThresholds   ConflictThresholds `toml:"conflict_thresholds"`
 	API                  APIConfig          `toml:"api"`
+	// RateLimits overrides the built-in request quotas, keyed by service name.
+	RateLimits map[string]RateLimitConfig `toml:"rate_limits"`
+	// SyncTargets picks remote calendars, projects and databases, keyed by
+	// service name.
+	SyncTargets map[string]SyncTargetConfig `toml:"sync_targets"`
+	// CSVMappings are column mapping profiles for the csv format, keyed by
+	// the name --mapping selects them with; "default" applies without it.
+	CSVMappings map[string]csvmap.Profile `toml:"csv_mappings"`
+}
+
+// SyncTargetConfig names the calendar, project or database a service syncs
+// with, by name or ID. Default applies when no flag is given. During a push,
+// Tags and Types route items to other targets by tag or item type, checked
+// in that order; an explicit flag overrides them.
+type SyncTargetConfig struct {
+	Default string            `toml:"default"`
+	Tags    map[string]string `toml:"tags"`
+	Types   map[string]string `toml:"types"`
+}
+
+// RateLimitConfig tunes how fast sync talks to one service. Zero fields keep
+// the service's default.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `toml:"requests_per_second"`
+	Burst             int     `toml:"burst"`
+	Concurrency       int     `toml:"concurrency"`
 }
 
 type ConflictThresholds struct {
@@ -37,6 +66,7 @@ type APIConfig struct {
 	Google    ServiceAuth `toml:"google"`
 	Microsoft ServiceAuth `toml:"microsoft"`
 	Notion    ServiceAuth `toml:"notion"`
+	CalDAV    CalDAVAuth  `toml:"caldav"`
 }
 
 type ServiceAuth struct {
@@ -46,6 +76,16 @@ type ServiceAuth struct {
 	Token        string `toml:"token"`
 }
 
+// CalDAVAuth configures a CalDAV server using HTTP basic auth. Password may
+// be an app-specific password; when empty, the one stored by
+// `salja auth login caldav` is used.
+type CalDAVAuth struct {
+	URL      string `toml:"url"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	Calendar string `toml:"calendar"`
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		PreferredMode:        "file",
@@ -148,6 +188,37 @@ func validate(cfg *Config) error {
 		}
 	}
 
+	if cfg.SyncTimeoutMinutes < 0 {
+		return &salerr.ValidationError{Field: "sync_timeout_minutes", Message: fmt.Sprintf("must be 0 (automatic) or a positive number of minutes, got %d", cfg.SyncTimeoutMinutes)}
+	}
+
+	for name, rl := range cfg.RateLimits {
+		if rl.RequestsPerSecond < 0 || rl.Burst < 0 || rl.Concurrency < 0 {
+			return &salerr.ValidationError{Field: "rate_limits." + name, Message: "requests_per_second, burst and concurrency must not be negative"}
+		}
+	}
+
+	for name, st := range cfg.SyncTargets {
+		for itemType := range st.Types {
+			if itemType != "event" && itemType != "task" && itemType != "journal" {
+				return &salerr.ValidationError{Field: "sync_targets." + name + ".types", Message: "item types must be 'event', 'task' or 'journal', got '" + itemType + "'"}
+			}
+		}
+	}
+
+	for name, profile := range cfg.CSVMappings {
+		if err := profile.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if cfg.API.CalDAV.URL != "" {
+		u, err := url.Parse(cfg.API.CalDAV.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return &salerr.ValidationError{Field: "api.caldav.url", Message: "must be an http(s) URL, got '" + cfg.API.CalDAV.URL + "'"}
+		}
+	}
+
 	return nil
 }
 
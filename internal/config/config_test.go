@@ -63,3 +63,53 @@ func TestUnknownKeyWarning(t *testing.T) {
 		t.Errorf("expected defaults preserved with unknown key")
 	}
 }
+
+func TestCSVMappings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	_ = os.WriteFile(path, []byte(`
+[csv_mappings.clickup]
+delimiter = ";"
+date_formats = ["01/02/2006"]
+
+[[csv_mappings.clickup.columns]]
+name = "Task Name"
+field = "title"
+
+[[csv_mappings.clickup.columns]]
+name = "Status"
+field = "status"
+values = { "open" = "pending", "complete" = "completed" }
+export = { completed = "complete" }
+`), 0644)
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, ok := cfg.CSVMappings["clickup"]
+	if !ok || p.Comma() != ';' || len(p.Columns) != 2 || p.Columns[1].Values["complete"] != "completed" {
+		t.Errorf("profile = %+v", p)
+	}
+
+	for _, bad := range []string{
+		`[[csv_mappings.x.columns]]
+name = "Due"
+field = "due"`,
+		`[[csv_mappings.x.columns]]
+name = "Title"
+field = "headline"`,
+		`[[csv_mappings.x.columns]]
+name = "Title"
+field = "title"
+[[csv_mappings.x.columns]]
+name = "State"
+field = "status"
+values = { "open" = "waiting" }`,
+	} {
+		_ = os.WriteFile(path, []byte(bad), 0644)
+		if _, err := LoadFrom(path); err == nil {
+			t.Errorf("expected a validation error for:\n%s", bad)
+		}
+	}
+}
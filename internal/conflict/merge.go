@@ -0,0 +1,412 @@
+package conflict
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// mergeField describes one user-editable field of a CalendarItem for
+// three-way merging.
+type mergeField struct {
+	name  string
+	equal func(a, b *model.CalendarItem) bool
+	take  func(dst, src *model.CalendarItem)
+	show  func(item *model.CalendarItem) string
+}
+
+var mergeFields = []mergeField{
+	{
+		name:  "title",
+		equal: func(a, b *model.CalendarItem) bool { return a.Title == b.Title },
+		take:  func(dst, src *model.CalendarItem) { dst.Title = src.Title },
+		show:  func(i *model.CalendarItem) string { return fmt.Sprintf("'%s'", i.Title) },
+	},
+	{
+		name:  "description",
+		equal: func(a, b *model.CalendarItem) bool { return a.Description == b.Description },
+		take:  func(dst, src *model.CalendarItem) { dst.Description = src.Description },
+		show:  func(i *model.CalendarItem) string { return fmt.Sprintf("'%s'", truncate(i.Description, 40)) },
+	},
+	{
+		name:  "start",
+		equal: func(a, b *model.CalendarItem) bool { return timeEqual(a.StartTime, b.StartTime) },
+		take:  func(dst, src *model.CalendarItem) { dst.StartTime = src.StartTime },
+		show:  func(i *model.CalendarItem) string { return showTime(i.StartTime) },
+	},
+	{
+		name:  "end",
+		equal: func(a, b *model.CalendarItem) bool { return timeEqual(a.EndTime, b.EndTime) },
+		take:  func(dst, src *model.CalendarItem) { dst.EndTime = src.EndTime },
+		show:  func(i *model.CalendarItem) string { return showTime(i.EndTime) },
+	},
+	{
+		name:  "due",
+		equal: func(a, b *model.CalendarItem) bool { return timeEqual(a.DueDate, b.DueDate) },
+		take:  func(dst, src *model.CalendarItem) { dst.DueDate = src.DueDate },
+		show:  func(i *model.CalendarItem) string { return showTime(i.DueDate) },
+	},
+	{
+		name:  "all-day",
+		equal: func(a, b *model.CalendarItem) bool { return a.IsAllDay == b.IsAllDay },
+		take:  func(dst, src *model.CalendarItem) { dst.IsAllDay = src.IsAllDay },
+		show:  func(i *model.CalendarItem) string { return fmt.Sprintf("%t", i.IsAllDay) },
+	},
+	{
+		name:  "timezone",
+		equal: func(a, b *model.CalendarItem) bool { return a.Timezone == b.Timezone },
+		take:  func(dst, src *model.CalendarItem) { dst.Timezone = src.Timezone },
+		show:  func(i *model.CalendarItem) string { return i.Timezone },
+	},
+	{
+		name:  "priority",
+		equal: func(a, b *model.CalendarItem) bool { return a.Priority == b.Priority },
+		take:  func(dst, src *model.CalendarItem) { dst.Priority = src.Priority },
+		show:  func(i *model.CalendarItem) string { return fmt.Sprintf("%d", i.Priority) },
+	},
+	{
+		name:  "status",
+		equal: func(a, b *model.CalendarItem) bool { return a.Status == b.Status },
+		take:  func(dst, src *model.CalendarItem) { dst.Status = src.Status },
+		show:  func(i *model.CalendarItem) string { return string(i.Status) },
+	},
+	{
+		name:  "completed",
+		equal: func(a, b *model.CalendarItem) bool { return timeEqual(a.CompletionDate, b.CompletionDate) },
+		take:  func(dst, src *model.CalendarItem) { dst.CompletionDate = src.CompletionDate },
+		show:  func(i *model.CalendarItem) string { return showTime(i.CompletionDate) },
+	},
+	{
+		name:  "location",
+		equal: func(a, b *model.CalendarItem) bool { return a.Location == b.Location },
+		take:  func(dst, src *model.CalendarItem) { dst.Location = src.Location },
+		show:  func(i *model.CalendarItem) string { return fmt.Sprintf("'%s'", i.Location) },
+	},
+	{
+		name:  "tags",
+		equal: func(a, b *model.CalendarItem) bool { return tagSetEqual(a.Tags, b.Tags) },
+		take:  func(dst, src *model.CalendarItem) { dst.Tags = src.Tags },
+		show:  func(i *model.CalendarItem) string { return fmt.Sprintf("%v", i.Tags) },
+	},
+	{
+		name: "recurrence",
+		equal: func(a, b *model.CalendarItem) bool {
+			return canonEqual(canonRecurrence(a.Recurrence), canonRecurrence(b.Recurrence))
+		},
+		take: func(dst, src *model.CalendarItem) { dst.Recurrence = src.Recurrence },
+		show: func(i *model.CalendarItem) string { return showRecurrence(i.Recurrence) },
+	},
+	{
+		name: "reminders",
+		equal: func(a, b *model.CalendarItem) bool {
+			return canonEqual(canonReminders(a.Reminders), canonReminders(b.Reminders))
+		},
+		take: func(dst, src *model.CalendarItem) { dst.Reminders = src.Reminders },
+		show: func(i *model.CalendarItem) string { return fmt.Sprintf("%d reminder(s)", len(i.Reminders)) },
+	},
+	{
+		name: "subtasks",
+		equal: func(a, b *model.CalendarItem) bool {
+			return (len(a.Subtasks) == 0 && len(b.Subtasks) == 0) || canonEqual(a.Subtasks, b.Subtasks)
+		},
+		take: func(dst, src *model.CalendarItem) { dst.Subtasks = src.Subtasks },
+		show: func(i *model.CalendarItem) string { return fmt.Sprintf("%d subtask(s)", len(i.Subtasks)) },
+	},
+	{
+		name: "attendees",
+		equal: func(a, b *model.CalendarItem) bool {
+			return canonEqual(canonAttendees(a), canonAttendees(b))
+		},
+		take: func(dst, src *model.CalendarItem) {
+			dst.Organizer = src.Organizer
+			dst.Attendees = src.Attendees
+		},
+		show: func(i *model.CalendarItem) string { return fmt.Sprintf("%d attendee(s)", len(i.Attendees)) },
+	},
+}
+
+// Merge is the outcome of comparing two versions of an item against the
+// versions both sides last agreed on.
+type Merge struct {
+	Source *model.CalendarItem
+	Target *model.CalendarItem
+	// Merged starts from Source and takes every field only Target changed.
+	// Fields in Conflicts still hold the source value.
+	Merged model.CalendarItem
+	// SourceChanged and TargetChanged list the fields each side edited.
+	SourceChanged []string
+	TargetChanged []string
+	// Conflicts lists fields both sides changed to different values.
+	Conflicts []string
+}
+
+// ThreeWayMerge compares source and target against their base snapshots.
+// sourceBase and targetBase are normally the same item; they differ when
+// each side is stored in its own representation (e.g. a local file and a
+// remote service that drops some fields), so changes are detected against
+// what that side looked like at the last sync.
+func ThreeWayMerge(sourceBase, source, targetBase, target *model.CalendarItem) *Merge {
+	m := &Merge{Source: source, Target: target, Merged: *source}
+	for _, f := range mergeFields {
+		sourceChanged := !f.equal(sourceBase, source)
+		targetChanged := !f.equal(targetBase, target)
+		if sourceChanged {
+			m.SourceChanged = append(m.SourceChanged, f.name)
+		}
+		if targetChanged {
+			m.TargetChanged = append(m.TargetChanged, f.name)
+		}
+		switch {
+		case sourceChanged && targetChanged:
+			if !f.equal(source, target) {
+				m.Conflicts = append(m.Conflicts, f.name)
+			}
+		case targetChanged:
+			f.take(&m.Merged, target)
+		}
+	}
+	return m
+}
+
+// HasConflicts reports whether any field was edited on both sides.
+func (m *Merge) HasConflicts() bool {
+	return len(m.Conflicts) > 0
+}
+
+// Resolved returns the merged item with conflicting fields taken from the
+// target when preferTarget is set, or kept from the source otherwise.
+func (m *Merge) Resolved(preferTarget bool) model.CalendarItem {
+	merged := m.Merged
+	if preferTarget {
+		for _, name := range m.Conflicts {
+			fieldByName(name).take(&merged, m.Target)
+		}
+	}
+	return merged
+}
+
+// ResolveThreeWay merges source and target against base, the version both
+// sides last agreed on. Fields edited on only one side are merged
+// automatically; the strategy only decides fields edited on both. A nil base
+// falls back to Resolve.
+func (r *Resolver) ResolveThreeWay(base, source, target *model.CalendarItem) (*model.CalendarItem, error) {
+	if base == nil {
+		return r.Resolve(source, target)
+	}
+	return r.ResolveMerge(ThreeWayMerge(base, source, base, target))
+}
+
+// ResolveMerge settles the conflicting fields of a three-way merge using the
+// resolver's strategy.
+func (r *Resolver) ResolveMerge(m *Merge) (*model.CalendarItem, error) {
+	if !m.HasConflicts() {
+		r.logMerge(m, "auto-merged", nil)
+		merged := m.Merged
+		return &merged, nil
+	}
+
+	switch r.strategy {
+	case StrategyPreferSource:
+		r.logMerge(m, "merged-prefer-source", m.Conflicts)
+		merged := m.Resolved(false)
+		return &merged, nil
+	case StrategyPreferTarget:
+		r.logMerge(m, "merged-prefer-target", m.Conflicts)
+		merged := m.Resolved(true)
+		return &merged, nil
+	case StrategySkip:
+		r.logMerge(m, "skip", m.Conflicts)
+		return nil, nil
+	case StrategyFail:
+		return nil, &salerr.ConflictError{
+			SourceItem: m.Source.Title,
+			TargetItem: m.Target.Title,
+			Message:    "both sides changed " + strings.Join(m.Conflicts, ", "),
+		}
+	case StrategyAsk:
+		return r.interactiveMerge(m)
+	default:
+		return nil, fmt.Errorf("unhandled conflict strategy: %q", r.strategy)
+	}
+}
+
+func (r *Resolver) interactiveMerge(m *Merge) (*model.CalendarItem, error) {
+	fmt.Println("\n=== CONFLICT DETECTED ===")
+	fmt.Printf("Source: %s\n", m.Source.Title)
+	fmt.Printf("Target: %s\n", m.Target.Title)
+	if len(m.TargetChanged) > len(m.Conflicts) || len(m.SourceChanged) > len(m.Conflicts) {
+		fmt.Println("Fields changed on one side only have been merged automatically.")
+	}
+	fmt.Println("\nBoth sides changed (press enter to keep source, 't' for target, 'k' to skip the item):")
+
+	merged := m.Merged
+	var fields []string
+	for _, name := range m.Conflicts {
+		f := fieldByName(name)
+		fmt.Printf("  %s: [s]%s / [t]%s? ", name, f.show(m.Source), f.show(m.Target))
+		input, err := r.reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("interactive conflict resolution requires terminal input (stdin closed): %w", err)
+		}
+		switch strings.TrimSpace(strings.ToLower(input)) {
+		case "t":
+			f.take(&merged, m.Target)
+			fields = append(fields, name+":target")
+		case "k":
+			r.logMerge(m, "skipped", nil)
+			return nil, nil
+		default:
+			fields = append(fields, name+":source")
+		}
+	}
+	r.logMerge(m, "merged", fields)
+	return &merged, nil
+}
+
+func (r *Resolver) logMerge(m *Merge, action string, fields []string) {
+	r.log(m.Source.Title, m.Target.Title, action)
+	r.resolutions[len(r.resolutions)-1].Fields = fields
+}
+
+func fieldByName(name string) mergeField {
+	for _, f := range mergeFields {
+		if f.name == name {
+			return f
+		}
+	}
+	panic("conflict: unknown merge field " + name)
+}
+
+func timeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func tagSetEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]string(nil), a...)
+	bs := append([]string(nil), b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	return tagsEqual(as, bs)
+}
+
+// canonRecurrence copies a recurrence with all times in UTC so that equal
+// instants compare equal regardless of the zone they were parsed in.
+func canonRecurrence(r *model.Recurrence) *model.Recurrence {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	if c.Until != nil {
+		u := c.Until.UTC()
+		c.Until = &u
+	}
+	c.ExDates = utcTimes(c.ExDates)
+	c.RDates = utcTimes(c.RDates)
+	c.Overrides = nil
+	for _, ov := range r.Overrides {
+		occurrence := ov.Item
+		// services give occurrences IDs of their own
+		occurrence.UID = ""
+		occurrence.CreatedAt, occurrence.UpdatedAt = nil, nil
+		occurrence.StartTime = utcTime(occurrence.StartTime)
+		occurrence.EndTime = utcTime(occurrence.EndTime)
+		occurrence.DueDate = utcTime(occurrence.DueDate)
+		c.Overrides = append(c.Overrides, model.Override{RecurrenceID: ov.RecurrenceID.UTC(), Item: occurrence})
+	}
+	sort.Slice(c.Overrides, func(i, j int) bool { return c.Overrides[i].RecurrenceID.Before(c.Overrides[j].RecurrenceID) })
+	return &c
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func canonReminders(rs []model.Reminder) []model.Reminder {
+	out := make([]model.Reminder, len(rs))
+	for i, rem := range rs {
+		out[i] = rem
+		if rem.AbsoluteTime != nil {
+			t := rem.AbsoluteTime.UTC()
+			out[i].AbsoluteTime = &t
+		}
+	}
+	return out
+}
+
+// canonAttendees lists the organizer first, then the attendees sorted by
+// address, which services compare case-insensitively.
+func canonAttendees(item *model.CalendarItem) []model.Attendee {
+	var out []model.Attendee
+	if item.Organizer != nil {
+		org := *item.Organizer
+		org.Email = strings.ToLower(org.Email)
+		out = append(out, org)
+	}
+	attendees := make([]model.Attendee, len(item.Attendees))
+	for i, a := range item.Attendees {
+		a.Email = strings.ToLower(a.Email)
+		attendees[i] = a
+	}
+	sort.SliceStable(attendees, func(i, j int) bool { return attendees[i].Email < attendees[j].Email })
+	return append(out, attendees...)
+}
+
+func utcTimes(ts []time.Time) []time.Time {
+	if ts == nil {
+		return nil
+	}
+	out := make([]time.Time, len(ts))
+	for i, t := range ts {
+		out[i] = t.UTC()
+	}
+	return out
+}
+
+func canonEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(ja) == string(jb)
+}
+
+func showTime(t *time.Time) string {
+	if t == nil {
+		return "(none)"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func showRecurrence(r *model.Recurrence) string {
+	if r == nil {
+		return "(none)"
+	}
+	if len(r.Overrides) > 0 {
+		return fmt.Sprintf("%s/%d, %d changed occurrence(s)", r.Freq, r.Interval, len(r.Overrides))
+	}
+	return fmt.Sprintf("%s/%d", r.Freq, r.Interval)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
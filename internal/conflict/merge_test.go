@@ -0,0 +1,256 @@
+package conflict
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func makeBaseItem() *model.CalendarItem {
+	due := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	return &model.CalendarItem{
+		UID:      "item-001",
+		Title:    "Quarterly report",
+		Priority: model.PriorityMedium,
+		Status:   model.StatusPending,
+		ItemType: model.ItemTypeTask,
+		Tags:     []string{"work"},
+		DueDate:  &due,
+	}
+}
+
+func TestThreeWayMergeDisjointEdits(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	source.Title = "Quarterly report (draft)"
+	target := *base
+	target.Priority = model.PriorityHigh
+	target.Tags = []string{"work", "urgent"}
+
+	m := ThreeWayMerge(base, &source, base, &target)
+	if m.HasConflicts() {
+		t.Fatalf("expected no conflicts, got %v", m.Conflicts)
+	}
+	if m.Merged.Title != "Quarterly report (draft)" {
+		t.Errorf("title: got %q", m.Merged.Title)
+	}
+	if m.Merged.Priority != model.PriorityHigh {
+		t.Errorf("priority: got %d", m.Merged.Priority)
+	}
+	if len(m.Merged.Tags) != 2 {
+		t.Errorf("tags: got %v", m.Merged.Tags)
+	}
+	if strings.Join(m.SourceChanged, ",") != "title" || strings.Join(m.TargetChanged, ",") != "priority,tags" {
+		t.Errorf("changed fields: source=%v target=%v", m.SourceChanged, m.TargetChanged)
+	}
+}
+
+func TestThreeWayMergeSameEditIsNotConflict(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	source.Status = model.StatusCompleted
+	target := *base
+	target.Status = model.StatusCompleted
+
+	m := ThreeWayMerge(base, &source, base, &target)
+	if m.HasConflicts() {
+		t.Errorf("identical edits should not conflict, got %v", m.Conflicts)
+	}
+}
+
+func TestThreeWayMergeEqualInstantsInOtherZone(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	sgt := base.DueDate.In(time.FixedZone("SGT", 8*3600))
+	source.DueDate = &sgt
+
+	m := ThreeWayMerge(base, &source, base, base)
+	if len(m.SourceChanged) != 0 {
+		t.Errorf("same instant in another zone should not count as a change, got %v", m.SourceChanged)
+	}
+}
+
+func TestThreeWayMergeAttendees(t *testing.T) {
+	base := makeBaseItem()
+	base.Attendees = []model.Attendee{{Email: "sam@example.com"}, {Email: "kim@example.com"}}
+	source := *base
+	source.Attendees = []model.Attendee{{Email: "KIM@example.com"}, {Email: "sam@example.com"}}
+	target := *base
+	target.Attendees = append([]model.Attendee{{Email: "jo@example.com"}}, base.Attendees...)
+
+	m := ThreeWayMerge(base, &source, base, &target)
+	if len(m.SourceChanged) != 0 {
+		t.Errorf("reordered attendees should not count as a change, got %v", m.SourceChanged)
+	}
+	if strings.Join(m.TargetChanged, ",") != "attendees" || len(m.Merged.Attendees) != 3 {
+		t.Errorf("target change not merged: changed=%v attendees=%v", m.TargetChanged, m.Merged.Attendees)
+	}
+}
+
+func TestThreeWayMergeOverrides(t *testing.T) {
+	base := makeBaseItem()
+	rid := time.Date(2025, 3, 8, 9, 0, 0, 0, time.UTC)
+	moved := rid.Add(2 * time.Hour)
+	base.Recurrence = &model.Recurrence{Freq: model.FreqWeekly, Interval: 1}
+	source := *base
+	// the same override as a service reports it, in another zone and with
+	// an occurrence ID of its own
+	sgt := moved.In(time.FixedZone("SGT", 8*3600))
+	source.Recurrence = &model.Recurrence{Freq: model.FreqWeekly, Interval: 1, Overrides: []model.Override{
+		{RecurrenceID: rid.In(time.FixedZone("SGT", 8*3600)), Item: model.CalendarItem{UID: "item-001_20250308", Title: "Quarterly report", DueDate: &sgt}},
+	}}
+	target := *base
+	target.Recurrence = &model.Recurrence{Freq: model.FreqWeekly, Interval: 1, Overrides: []model.Override{
+		{RecurrenceID: rid, Item: model.CalendarItem{UID: "item-001", Title: "Quarterly report", DueDate: &moved}},
+	}}
+
+	m := ThreeWayMerge(base, &source, base, &target)
+	if m.HasConflicts() {
+		t.Errorf("the same override should not conflict, got %v", m.Conflicts)
+	}
+	if strings.Join(m.SourceChanged, ",") != "recurrence" {
+		t.Errorf("changed fields: %v", m.SourceChanged)
+	}
+}
+
+func TestThreeWayMergeSeparateBases(t *testing.T) {
+	// The target drops the location, so its base has none either.
+	sourceBase := makeBaseItem()
+	sourceBase.Location = "Room 4"
+	targetBase := makeBaseItem()
+
+	source := *sourceBase
+	target := *targetBase
+	target.Title = "Quarterly report v2"
+
+	m := ThreeWayMerge(sourceBase, &source, targetBase, &target)
+	if m.HasConflicts() {
+		t.Fatalf("unexpected conflicts %v", m.Conflicts)
+	}
+	if m.Merged.Location != "Room 4" {
+		t.Errorf("location should be kept from source, got %q", m.Merged.Location)
+	}
+	if m.Merged.Title != "Quarterly report v2" {
+		t.Errorf("title: got %q", m.Merged.Title)
+	}
+}
+
+func TestResolveThreeWayStrategies(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	source.Title = "Source title"
+	source.Description = "notes from source"
+	target := *base
+	target.Title = "Target title"
+	target.Priority = model.PriorityHigh
+
+	tests := []struct {
+		strategy  Strategy
+		wantTitle string
+		wantNil   bool
+	}{
+		{StrategyPreferSource, "Source title", false},
+		{StrategyPreferTarget, "Target title", false},
+		{StrategySkip, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			r, _ := NewResolver(tt.strategy)
+			result, err := r.ResolveThreeWay(base, &source, &target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantNil {
+				if result != nil {
+					t.Errorf("expected nil result, got %+v", result)
+				}
+				return
+			}
+			if result.Title != tt.wantTitle {
+				t.Errorf("title: got %q, want %q", result.Title, tt.wantTitle)
+			}
+			// one-sided edits survive whichever side wins the conflict
+			if result.Description != "notes from source" || result.Priority != model.PriorityHigh {
+				t.Errorf("one-sided edits lost: description=%q priority=%d", result.Description, result.Priority)
+			}
+		})
+	}
+}
+
+func TestResolveThreeWayFailListsFields(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	source.Title = "A"
+	target := *base
+	target.Title = "B"
+
+	r, _ := NewResolver(StrategyFail)
+	_, err := r.ResolveThreeWay(base, &source, &target)
+	var ce *salerr.ConflictError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if !strings.Contains(ce.Message, "title") {
+		t.Errorf("message should name the field, got %q", ce.Message)
+	}
+}
+
+func TestResolveThreeWayAutoMergeDoesNotPrompt(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	source.Title = "Renamed"
+	target := *base
+	target.Status = model.StatusCompleted
+
+	r, _ := NewResolver(StrategyAsk)
+	r.reader = bufio.NewReader(strings.NewReader(""))
+	result, err := r.ResolveThreeWay(base, &source, &target)
+	if err != nil {
+		t.Fatalf("auto-merge should not need input: %v", err)
+	}
+	if result.Title != "Renamed" || result.Status != model.StatusCompleted {
+		t.Errorf("unexpected merge result: %+v", result)
+	}
+	if len(r.resolutions) != 1 || r.resolutions[0].Action != "auto-merged" {
+		t.Errorf("expected auto-merged resolution, got %+v", r.resolutions)
+	}
+}
+
+func TestResolveThreeWayAskPerField(t *testing.T) {
+	base := makeBaseItem()
+	source := *base
+	source.Title = "Source title"
+	source.Priority = model.PriorityLow
+	target := *base
+	target.Title = "Target title"
+	target.Priority = model.PriorityHighest
+
+	r, _ := NewResolver(StrategyAsk)
+	// title -> target, priority -> keep source
+	r.reader = bufio.NewReader(strings.NewReader("t\n\n"))
+	result, err := r.ResolveThreeWay(base, &source, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Title != "Target title" || result.Priority != model.PriorityLow {
+		t.Errorf("unexpected result: title=%q priority=%d", result.Title, result.Priority)
+	}
+}
+
+func TestResolveThreeWayNilBaseFallsBack(t *testing.T) {
+	r, _ := NewResolver(StrategyPreferTarget)
+	source := makeSourceItem()
+	target := makeTargetItem()
+	result, err := r.ResolveThreeWay(nil, source, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != target {
+		t.Error("expected two-way resolution to return target")
+	}
+}
@@ -0,0 +1,84 @@
+package csvmap
+
+// builtins are the shipped profiles. default uses the column names the xlsx
+// format writes; jira and linear read those tools' CSV exports and write
+// files their importers accept.
+var builtins = map[string]Profile{
+	"default": {
+		DateFormats: []string{"2006-01-02 15:04", "2006-01-02T15:04:05Z07:00", "2006-01-02"},
+		Columns: []Column{
+			{Name: "Title", Field: FieldTitle},
+			{Name: "Type", Field: FieldType},
+			{Name: "Status", Field: FieldStatus},
+			{Name: "Priority", Field: FieldPriority},
+			{Name: "Start", Field: FieldStart},
+			{Name: "End", Field: FieldEnd},
+			{Name: "Due", Field: FieldDue},
+			{Name: "All Day", Field: FieldAllDay},
+			{Name: "Completed", Field: FieldCompleted},
+			{Name: "Location", Field: FieldLocation},
+			{Name: "Tags", Field: FieldTags},
+			{Name: "Description", Field: FieldDescription},
+			{Name: "UID", Field: FieldUID},
+		},
+	},
+
+	// Jira's "Export CSV (all fields)": one Labels column per label, and
+	// dates in the default Jira format
+	"jira": {
+		DateFormats: []string{"02/Jan/06 3:04 PM", "02/Jan/06", "2006-01-02 15:04", "2006-01-02"},
+		Columns: []Column{
+			{Name: "Summary", Field: FieldTitle},
+			{Name: "Issue key", Field: FieldUID},
+			{Name: "Status", Field: FieldStatus, Values: map[string]string{
+				"Open": "pending", "To Do": "pending", "Backlog": "pending", "Selected for Development": "pending",
+				"In Progress": "in_progress", "In Review": "in_progress",
+				"Done": "completed", "Closed": "completed", "Resolved": "completed",
+				"Won't Do": "cancelled", "Cancelled": "cancelled",
+			}, Export: map[string]string{
+				"pending": "To Do", "in_progress": "In Progress", "completed": "Done", "cancelled": "Won't Do",
+			}},
+			{Name: "Priority", Field: FieldPriority, Values: map[string]string{
+				"Highest": "highest", "Blocker": "highest", "High": "high", "Critical": "high",
+				"Medium": "medium", "Major": "medium", "Low": "low", "Minor": "low", "Lowest": "lowest", "Trivial": "lowest",
+			}, Export: map[string]string{
+				"highest": "Highest", "high": "High", "medium": "Medium", "low": "Low", "lowest": "Lowest",
+			}},
+			{Name: "Project name", Field: FieldProject},
+			{Name: "Labels", Field: FieldTags, Separator: " "},
+			{Name: "Due Date", Field: FieldDue},
+			{Name: "Resolved", Field: FieldCompleted},
+			{Name: "Created", Field: FieldCreated},
+			{Name: "Updated", Field: FieldUpdated},
+			{Name: "Description", Field: FieldDescription},
+		},
+	},
+
+	// Linear's workspace CSV export
+	"linear": {
+		DateFormats: []string{"2006-01-02T15:04:05.000Z07:00", "2006-01-02T15:04:05Z07:00", "2006-01-02"},
+		Columns: []Column{
+			{Name: "Title", Field: FieldTitle},
+			{Name: "ID", Field: FieldUID},
+			{Name: "Status", Field: FieldStatus, Values: map[string]string{
+				"Triage": "pending", "Backlog": "pending", "Todo": "pending",
+				"In Progress": "in_progress", "In Review": "in_progress",
+				"Done": "completed", "Canceled": "cancelled", "Duplicate": "cancelled",
+			}, Export: map[string]string{
+				"pending": "Todo", "in_progress": "In Progress", "completed": "Done", "cancelled": "Canceled",
+			}},
+			{Name: "Priority", Field: FieldPriority, Values: map[string]string{
+				"No priority": "none", "Urgent": "highest", "High": "high", "Medium": "medium", "Low": "low",
+			}, Export: map[string]string{
+				"none": "No priority", "lowest": "Low",
+			}},
+			{Name: "Project", Field: FieldProject},
+			{Name: "Labels", Field: FieldTags, Separator: ","},
+			{Name: "Due Date", Field: FieldDue, DateFormat: "2006-01-02"},
+			{Name: "Completed", Field: FieldCompleted},
+			{Name: "Created", Field: FieldCreated},
+			{Name: "Updated", Field: FieldUpdated},
+			{Name: "Description", Field: FieldDescription},
+		},
+	},
+}
@@ -0,0 +1,240 @@
+// Package csvmap describes how the generic csv format maps the columns of a
+// CSV file to item fields. A profile lists the columns in the order they are
+// written; profiles come built in, from [csv_mappings.<name>] in the config
+// or from a TOML file of their own.
+package csvmap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	salerr "github.com/gongahkia/salja/internal/errors"
+)
+
+// The fields a column may feed. Project reads and writes the project:<name>
+// tag; Completed is the completion date.
+const (
+	FieldUID         = "uid"
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldLocation    = "location"
+	FieldType        = "type"
+	FieldStatus      = "status"
+	FieldPriority    = "priority"
+	FieldTags        = "tags"
+	FieldProject     = "project"
+	FieldStart       = "start"
+	FieldEnd         = "end"
+	FieldDue         = "due"
+	FieldCompleted   = "completed"
+	FieldCreated     = "created"
+	FieldUpdated     = "updated"
+	FieldAllDay      = "all_day"
+)
+
+var fields = map[string]bool{
+	FieldUID: true, FieldTitle: true, FieldDescription: true, FieldLocation: true,
+	FieldType: true, FieldStatus: true, FieldPriority: true, FieldTags: true, FieldProject: true,
+	FieldStart: true, FieldEnd: true, FieldDue: true, FieldCompleted: true, FieldCreated: true,
+	FieldUpdated: true, FieldAllDay: true,
+}
+
+// modelValues are the values Values may map cells to, per field.
+var modelValues = map[string][]string{
+	FieldStatus:   {"pending", "in_progress", "completed", "cancelled"},
+	FieldPriority: {"none", "lowest", "low", "medium", "high", "highest", "0", "1", "2", "3", "4", "5"},
+	FieldType:     {"event", "task", "journal"},
+}
+
+// Profile maps CSV columns to item fields.
+type Profile struct {
+	// Delimiter separates fields; empty is a comma.
+	Delimiter string `toml:"delimiter"`
+	// ItemType is the type of rows without a type column; empty is task.
+	ItemType string `toml:"item_type"`
+	// DateFormats are Go time layouts tried in order when reading a date;
+	// dates are written with the first. Dates no layout reads fall back to
+	// locale-aware parsing.
+	DateFormats []string `toml:"date_formats"`
+	// Columns are the mapped columns, in the order they are written.
+	Columns []Column `toml:"columns"`
+}
+
+// Column maps one column, by its header, to a field. Several columns may
+// share a header, as Jira repeats Labels for each label.
+type Column struct {
+	Name  string `toml:"name"`
+	Field string `toml:"field"`
+	// Separator splits a tags cell; empty is a comma.
+	Separator string `toml:"separator"`
+	// DateFormat overrides the profile's date formats for this column.
+	DateFormat string `toml:"date_format"`
+	// Values maps cell values, matched case-insensitively, to the model's
+	// status, priority or type names. Export picks the cell value written
+	// for a model value; without it the writer uses the alphabetically
+	// first cell value that maps to it.
+	Values map[string]string `toml:"values"`
+	Export map[string]string `toml:"export"`
+}
+
+// Comma returns the field delimiter.
+func (p *Profile) Comma() rune {
+	if p.Delimiter == "" {
+		return ','
+	}
+	r, _ := utf8.DecodeRuneInString(p.Delimiter)
+	return r
+}
+
+// Layouts returns the date layouts for the column, most preferred first.
+func (p *Profile) Layouts(c *Column) []string {
+	if c.DateFormat != "" {
+		return append([]string{c.DateFormat}, p.DateFormats...)
+	}
+	return p.DateFormats
+}
+
+// Validate checks the profile against the fields and model values it may use.
+func (p *Profile) Validate(name string) error {
+	field := "csv_mappings." + name
+	if utf8.RuneCountInString(p.Delimiter) > 1 || p.Delimiter == "\"" || p.Delimiter == "\n" {
+		return &salerr.ValidationError{Field: field + ".delimiter", Message: fmt.Sprintf("must be a single character other than a quote or newline, got %q", p.Delimiter)}
+	}
+	if p.ItemType != "" && p.ItemType != "event" && p.ItemType != "task" && p.ItemType != "journal" {
+		return &salerr.ValidationError{Field: field + ".item_type", Message: "must be 'event', 'task' or 'journal', got '" + p.ItemType + "'"}
+	}
+	hasTitle := false
+	for i, c := range p.Columns {
+		cf := fmt.Sprintf("%s.columns[%d]", field, i)
+		if c.Name == "" {
+			return &salerr.ValidationError{Field: cf + ".name", Message: "is required"}
+		}
+		if !fields[c.Field] {
+			return &salerr.ValidationError{Field: cf + ".field", Message: fmt.Sprintf("unknown field %q for column %q", c.Field, c.Name)}
+		}
+		hasTitle = hasTitle || c.Field == FieldTitle
+		allowed := modelValues[c.Field]
+		if (len(c.Values) > 0 || len(c.Export) > 0) && allowed == nil {
+			return &salerr.ValidationError{Field: cf + ".values", Message: fmt.Sprintf("column %q: only status, priority and type columns take values", c.Name)}
+		}
+		for _, v := range c.Values {
+			if !contains(allowed, v) {
+				return &salerr.ValidationError{Field: cf + ".values", Message: fmt.Sprintf("column %q: %q is not one of %s", c.Name, v, strings.Join(allowed, ", "))}
+			}
+		}
+		for v := range c.Export {
+			if !contains(allowed, v) {
+				return &salerr.ValidationError{Field: cf + ".export", Message: fmt.Sprintf("column %q: %q is not one of %s", c.Name, v, strings.Join(allowed, ", "))}
+			}
+		}
+	}
+	if !hasTitle {
+		return &salerr.ValidationError{Field: field + ".columns", Message: "no column feeds the title"}
+	}
+	return nil
+}
+
+// Import maps a cell value through Values, then back through Export,
+// reporting whether it matched.
+func (c *Column) Import(cell string) (string, bool) {
+	cell = strings.TrimSpace(cell)
+	for k, v := range c.Values {
+		if strings.EqualFold(k, cell) {
+			return v, true
+		}
+	}
+	for k, v := range c.Export {
+		if strings.EqualFold(v, cell) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// ExportValue returns the cell value for a model value, or "" when the
+// column has none.
+func (c *Column) ExportValue(value string) string {
+	if v, ok := c.Export[value]; ok {
+		return v
+	}
+	var keys []string
+	for k, v := range c.Values {
+		if v == value {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads a profile from a TOML file holding its keys at the top level.
+func Load(path string) (*Profile, error) {
+	var p Profile
+	if _, err := toml.DecodeFile(path, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping %s: %w", path, err)
+	}
+	if err := p.Validate(path); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Names returns the names of the built-in profiles.
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin returns a copy of the built-in profile of that name.
+func Builtin(name string) (*Profile, bool) {
+	p, ok := builtins[name]
+	if !ok {
+		return nil, false
+	}
+	cp := p
+	cp.Columns = append([]Column(nil), p.Columns...)
+	return &cp, true
+}
+
+var (
+	activeMu sync.RWMutex
+	active   *Profile
+)
+
+// Use sets the profile the csv format reads and writes with.
+func Use(p *Profile) {
+	activeMu.Lock()
+	active = p
+	activeMu.Unlock()
+}
+
+// Active returns the profile set with Use, or the built-in default.
+func Active() *Profile {
+	activeMu.RLock()
+	p := active
+	activeMu.RUnlock()
+	if p == nil {
+		p, _ = Builtin("default")
+	}
+	return p
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand"
@@ -56,6 +57,8 @@ type APIError struct {
 	StatusCode int
 	Message    string
 	Err        error
+	// RetryAfter is the wait the server asked for on a 429 or 503, if any.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -132,38 +135,78 @@ func Retry(cfg *RetryConfig, fn func() error) error {
 			return nil
 		}
 
+		var retryAfter time.Duration
 		if apiErr, ok := lastErr.(*APIError); ok {
 			if !apiErr.IsRetryable() {
 				return lastErr
 			}
+			retryAfter = apiErr.RetryAfter
 		}
 
 		if attempt < cfg.MaxRetries {
 			delay := cfg.BaseDelay * time.Duration(math.Pow(2, float64(attempt)))
 			jitter := time.Duration(rand.Intn(1000)) * time.Millisecond
+			if retryAfter > delay {
+				delay, jitter = retryAfter, 0
+			}
 			time.Sleep(delay + jitter)
 		}
 	}
 	return fmt.Errorf("max retries (%d) exceeded: %w", cfg.MaxRetries, lastErr)
 }
 
+// SignalHandler runs a cleanup function on the first SIGINT or SIGTERM and
+// exits. One made by NewCancelSignalHandler only cancels, so the command can
+// stop at a safe point and return normally; a second signal then exits
+// immediately.
 type SignalHandler struct {
 	cleanup func()
+	exit    bool
+	ch      chan os.Signal
 }
 
 func NewSignalHandler(cleanup func()) *SignalHandler {
-	return &SignalHandler{cleanup: cleanup}
+	return &SignalHandler{cleanup: cleanup, exit: true}
+}
+
+// NewCancelSignalHandler is for commands that watch the context cancel
+// belongs to and take over shutdown themselves, such as a sync push that
+// journals what it has written.
+func NewCancelSignalHandler(cancel context.CancelFunc) *SignalHandler {
+	return &SignalHandler{cleanup: cancel}
 }
 
 func (h *SignalHandler) Start() {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	ch := make(chan os.Signal, 2)
+	h.ch = ch
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		<-c
-		fmt.Fprintln(os.Stderr, "\nInterrupted. Cleaning up...")
+		if _, ok := <-ch; !ok {
+			return
+		}
+		if h.exit {
+			fmt.Fprintln(os.Stderr, "\nInterrupted. Cleaning up...")
+		} else {
+			fmt.Fprintln(os.Stderr, "\nInterrupted. Cleaning up... (press Ctrl-C again to quit immediately)")
+		}
 		if h.cleanup != nil {
 			h.cleanup()
 		}
-		os.Exit(1)
+		if h.exit {
+			os.Exit(1)
+		}
+		if _, ok := <-ch; ok {
+			os.Exit(1)
+		}
 	}()
 }
+
+// Stop restores default signal handling.
+func (h *SignalHandler) Stop() {
+	if h.ch == nil {
+		return
+	}
+	signal.Stop(h.ch)
+	close(h.ch)
+	h.ch = nil
+}
@@ -1,9 +1,13 @@
 package errors
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"os/exec"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMalformedCSVRow(t *testing.T) {
@@ -285,3 +289,45 @@ func TestErrorCollectorSummary(t *testing.T) {
 		t.Errorf("unexpected summary: %s", c.Summary())
 	}
 }
+
+func TestSignalHandlerCancelsWithoutExiting(t *testing.T) {
+	called := make(chan struct{})
+	h := NewCancelSignalHandler(func() { close(called) })
+	h.Start()
+	defer h.Stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send interrupt on this platform: %v", err)
+	}
+	select {
+	case <-called:
+	case <-time.After(2 * time.Second):
+		t.Fatal("cleanup was not called")
+	}
+}
+
+func TestSignalHandlerExitsAfterCleanup(t *testing.T) {
+	if os.Getenv("SALJA_SIGNAL_CHILD") == "1" {
+		h := NewSignalHandler(func() { fmt.Println("cleaned up") })
+		h.Start()
+		p, _ := os.FindProcess(os.Getpid())
+		_ = p.Signal(os.Interrupt)
+		// a blocking read such as a confirmation prompt must not swallow it
+		time.Sleep(5 * time.Second)
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSignalHandlerExitsAfterCleanup$")
+	cmd.Env = append(os.Environ(), "SALJA_SIGNAL_CHILD=1")
+	out, err := cmd.Output()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit status 1, got %v (%s)", err, out)
+	}
+	if !strings.Contains(string(out), "cleaned up") {
+		t.Errorf("cleanup did not run before exit: %s", out)
+	}
+}
@@ -2,6 +2,8 @@ package fidelity
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gongahkia/salja/internal/model"
 	"github.com/gongahkia/salja/internal/registry"
@@ -24,6 +26,11 @@ func Check(collection *model.CalendarCollection, targetFormat string) []DataLoss
 		return nil
 	}
 
+	label := caps.Label
+	if label == "" {
+		label = targetFormat
+	}
+
 	var warnings []DataLossWarning
 
 	for _, item := range collection.Items {
@@ -82,12 +89,83 @@ func Check(collection *model.CalendarCollection, targetFormat string) []DataLoss
 			})
 		}
 
-		// todoist priority collision (format-specific)
-		if targetFormat == "todoist" && item.Priority == model.PriorityLowest {
+		// attendee loss (registry-driven)
+		if item.HasAttendees() && !caps.SupportsAttendees {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "Attendees",
+				Reason:    fmt.Sprintf("the organizer and %d attendee(s) will be lost converting to '%s' (no attendee support)", len(item.Attendees), targetFormat),
+			})
+		}
+
+		// formats that address attendees by URI need an email
+		if caps.SupportsAttendees && caps.AttendeesNeedEmail {
+			if n := countWithoutEmail(item.Attendees); n > 0 {
+				warnings = append(warnings, DataLossWarning{
+					ItemTitle: item.Title,
+					Field:     "Attendees",
+					Reason:    fmt.Sprintf("%d attendee(s) without an email address will be dropped; %s needs an address", n, label),
+				})
+			}
+		}
+
+		// properties and columns salja has no field for only survive a
+		// writer for the format they came from
+		if dropped := droppedExtensions(item.Extensions, caps); len(dropped) > 0 {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "Extensions",
+				Reason:    fmt.Sprintf("target format '%s' will discard %s", targetFormat, strings.Join(dropped, ", ")),
+			})
+		}
+
+		// coarser priority scales fold some priorities into a neighbour
+		if caps.MergesPriority(item.Priority) {
 			warnings = append(warnings, DataLossWarning{
 				ItemTitle: item.Title,
 				Field:     "Priority",
-				Reason:    "PriorityLowest (1) maps to Todoist priority '1' (same as PriorityNone); priority distinction will be lost",
+				Reason:    fmt.Sprintf("priority %d maps onto %s's %s scale; the distinction from its neighbour will be lost", item.Priority, label, caps.PriorityScale),
+			})
+		}
+
+		if caps.SupportsReminders && caps.MaxReminders > 0 && len(item.Reminders) > caps.MaxReminders {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "Reminders",
+				Reason:    fmt.Sprintf("only the earliest %d of %d reminders will be kept by %s", caps.MaxReminders, len(item.Reminders), label),
+			})
+		}
+
+		// recurrence the format keeps only part of
+		if item.Recurrence != nil && caps.SupportsRecurrence && !keepsRule(item.Recurrence, caps) {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "Recurrence",
+				Reason:    fmt.Sprintf("%s recurrence holds %s; count, by-rules and exception dates beyond that will be dropped", label, caps.Recurrence),
+			})
+		}
+
+		if caps.DateOnly && (hasTimeOfDay(item.StartTime) || hasTimeOfDay(item.DueDate)) {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "DueDate",
+				Reason:    fmt.Sprintf("%s dates have no time of day; only the date will be kept", label),
+			})
+		}
+
+		if caps.NoDescription && item.Description != "" {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "Description",
+				Reason:    fmt.Sprintf("%s has no description; it will be dropped", label),
+			})
+		}
+
+		if caps.NoCancelled && item.Status == model.StatusCancelled {
+			warnings = append(warnings, DataLossWarning{
+				ItemTitle: item.Title,
+				Field:     "Status",
+				Reason:    fmt.Sprintf("%s has no cancelled state; the task will be marked done", label),
 			})
 		}
 
@@ -105,3 +183,103 @@ func Check(collection *model.CalendarCollection, targetFormat string) []DataLoss
 
 	return warnings
 }
+
+// keepsRule reports whether the format behind caps can write rec whole.
+func keepsRule(rec *model.Recurrence, caps registry.FormatCapabilities) bool {
+	switch caps.Recurrence {
+	case registry.RecurrencePeriod:
+		return isPeriodRule(rec, caps.WeekdaysFreq)
+	case registry.RecurrenceText:
+		return isRecurrenceText(rec)
+	}
+	return true
+}
+
+// isPeriodRule reports whether rec is a frequency and interval only, or the
+// Monday to Friday rule at weekdaysFreq for formats with a keyword for it
+// (pass "" for none).
+func isPeriodRule(rec *model.Recurrence, weekdaysFreq model.FreqType) bool {
+	if rec.Count != nil || len(rec.ByMonth) > 0 || len(rec.ByMonthDay) > 0 || len(rec.BySetPos) > 0 ||
+		len(rec.ExDates) > 0 || len(rec.RDates) > 0 || hasFineRuleParts(rec) {
+		return false
+	}
+	if len(rec.ByDay) == 0 {
+		return true
+	}
+	weekdays := map[model.Weekday]bool{
+		model.WeekdayMO: true, model.WeekdayTU: true, model.WeekdayWE: true, model.WeekdayTH: true, model.WeekdayFR: true,
+	}
+	if rec.Freq != weekdaysFreq || rec.Interval > 1 || len(rec.ByDay) != len(weekdays) {
+		return false
+	}
+	for _, d := range rec.ByDay {
+		if !weekdays[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// isRecurrenceText reports whether rec can be written as an Obsidian Tasks
+// rule: weekdays only on a weekly rule, one day only on a monthly one.
+func isRecurrenceText(rec *model.Recurrence) bool {
+	if len(rec.ByMonth) > 0 || len(rec.BySetPos) > 0 || len(rec.ExDates) > 0 || len(rec.RDates) > 0 || hasFineRuleParts(rec) {
+		return false
+	}
+	if len(rec.ByDay) > 0 && rec.Freq != model.FreqWeekly {
+		// outside weekly rules only "every weekday" names days
+		days := model.Recurrence{Freq: rec.Freq, Interval: rec.Interval, ByDay: rec.ByDay}
+		if !isPeriodRule(&days, model.FreqDaily) {
+			return false
+		}
+	}
+	if len(rec.ByMonthDay) > 0 && (rec.Freq != model.FreqMonthly || len(rec.ByMonthDay) > 1 || rec.ByMonthDay[0] < -1) {
+		return false
+	}
+	return true
+}
+
+// hasFineRuleParts reports whether rec uses rule parts or frequencies
+// only full RRULEs can hold.
+func hasFineRuleParts(rec *model.Recurrence) bool {
+	switch rec.Freq {
+	case model.FreqHourly, model.FreqMinutely, model.FreqSecondly:
+		return true
+	}
+	return len(rec.ByWeekNo) > 0 || len(rec.ByYearDay) > 0 || len(rec.ByHour) > 0 || len(rec.ByMinute) > 0 ||
+		len(rec.BySecond) > 0 || rec.WeekStart != ""
+}
+
+// droppedExtensions names the extensions caps does not keep, once each, as
+// namespace:name.
+func droppedExtensions(exts []model.Extension, caps registry.FormatCapabilities) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, ext := range exts {
+		name := ext.Namespace + ":" + ext.Name
+		if caps.KeepsExtension(ext.Namespace) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func countWithoutEmail(attendees []model.Attendee) int {
+	n := 0
+	for _, a := range attendees {
+		if a.Email == "" {
+			n++
+		}
+	}
+	return n
+}
+
+func hasTimeOfDay(t *time.Time) bool {
+	if t == nil {
+		return false
+	}
+	h, m, sec := t.Clock()
+	return h != 0 || m != 0 || sec != 0
+}
@@ -74,6 +74,62 @@ func TestCheckRemindersToTrello(t *testing.T) {
 	}
 }
 
+func TestCheckAttendees(t *testing.T) {
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:     "Design review",
+				ItemType:  model.ItemTypeEvent,
+				Organizer: &model.Attendee{Email: "ana@example.com"},
+				Attendees: []model.Attendee{{Email: "sam@example.com"}, {Name: "Front desk"}},
+			},
+		},
+	}
+	for format, want := range map[string]int{"gcal": 1, "todoist": 1, "ics": 1, "outlook": 0, "salja-json": 0} {
+		n := 0
+		for _, w := range Check(col, format) {
+			if w.Field == "Attendees" {
+				n++
+			}
+		}
+		if n != want {
+			t.Errorf("%s: expected %d Attendees warning(s), got %d", format, want, n)
+		}
+	}
+}
+
+func TestCheckExtensions(t *testing.T) {
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:    "Launch",
+				ItemType: model.ItemTypeEvent,
+				Extensions: []model.Extension{
+					{Namespace: "ics", Name: "ATTACH", Value: "https://example.com/a.pdf"},
+					{Namespace: "ics", Name: "ATTACH", Value: "https://example.com/b.pdf"},
+					{Namespace: "ics", Name: "URL", Value: "https://example.com"},
+				},
+			},
+		},
+	}
+	for format, want := range map[string]string{
+		"ics":        "",
+		"xcal":       "",
+		"salja-json": "",
+		"outlook":    "target format 'outlook' will discard ics:ATTACH, ics:URL",
+	} {
+		got := ""
+		for _, w := range Check(col, format) {
+			if w.Field == "Extensions" {
+				got = w.Reason
+			}
+		}
+		if got != want {
+			t.Errorf("%s: Extensions warning = %q, want %q", format, got, want)
+		}
+	}
+}
+
 func TestCheckNoWarnings(t *testing.T) {
 	col := &model.CalendarCollection{
 		Items: []model.CalendarItem{
@@ -91,3 +147,172 @@ func TestCheckNoWarnings(t *testing.T) {
 func durationPtr(d time.Duration) *time.Duration {
 	return &d
 }
+
+func TestCheckTaskwarriorLimits(t *testing.T) {
+	count := 3
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:      "Busy task",
+				Priority:   model.PriorityHighest,
+				Reminders:  []model.Reminder{{Offset: durationPtr(-time.Hour)}, {Offset: durationPtr(-time.Minute)}},
+				Recurrence: &model.Recurrence{Freq: model.FreqMonthly, Count: &count},
+				Subtasks:   []model.Subtask{{Title: "Sub1"}},
+			},
+			{
+				Title:      "Weekday task",
+				Priority:   model.PriorityHigh,
+				Reminders:  []model.Reminder{{Offset: durationPtr(-time.Hour)}},
+				Recurrence: &model.Recurrence{Freq: model.FreqWeekly, ByDay: []model.Weekday{"MO", "TU", "WE", "TH", "FR"}},
+			},
+		},
+	}
+	fields := map[string]int{}
+	for _, w := range Check(col, "taskwarrior") {
+		if w.ItemTitle == "Weekday task" {
+			t.Errorf("unexpected warning for a representable task: %s", w)
+		}
+		fields[w.Field]++
+	}
+	for _, f := range []string{"Priority", "Reminders", "Recurrence"} {
+		if fields[f] != 1 {
+			t.Errorf("expected one %s warning, got %d", f, fields[f])
+		}
+	}
+	if fields["Subtasks"] != 0 {
+		t.Error("taskwarrior keeps subtasks as dependencies; no Subtasks warning expected")
+	}
+}
+
+func TestCheckTodoTxtLimits(t *testing.T) {
+	due := time.Date(2026, 3, 5, 17, 0, 0, 0, time.UTC)
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:       "Detailed task",
+				Description: "notes",
+				DueDate:     &due,
+				Status:      model.StatusCancelled,
+				Recurrence:  &model.Recurrence{Freq: model.FreqWeekly, ByDay: []model.Weekday{"MO", "TU", "WE", "TH", "FR"}},
+			},
+			{
+				Title:      "Plain task",
+				Recurrence: &model.Recurrence{Freq: model.FreqDaily, ByDay: []model.Weekday{"MO", "TU", "WE", "TH", "FR"}},
+			},
+		},
+	}
+	fields := map[string]int{}
+	for _, w := range Check(col, "todotxt") {
+		if w.ItemTitle == "Plain task" {
+			t.Errorf("unexpected warning for a representable task: %s", w)
+		}
+		fields[w.Field]++
+	}
+	for _, f := range []string{"Description", "DueDate", "Recurrence", "Status"} {
+		if fields[f] != 1 {
+			t.Errorf("expected one %s warning, got %d", f, fields[f])
+		}
+	}
+}
+
+func TestCheckOrgLimits(t *testing.T) {
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:      "Urgent",
+				Priority:   model.PriorityHighest,
+				Recurrence: &model.Recurrence{Freq: model.FreqWeekly, Interval: 1, ByDay: []model.Weekday{"MO", "WE"}},
+			},
+			{
+				Title:      "Weekly review",
+				Priority:   model.PriorityHigh,
+				Recurrence: &model.Recurrence{Freq: model.FreqWeekly, Interval: 2},
+				Subtasks:   []model.Subtask{{Title: "Inbox zero"}},
+			},
+		},
+	}
+	fields := map[string]int{}
+	for _, w := range Check(col, "org") {
+		if w.ItemTitle == "Weekly review" {
+			t.Errorf("unexpected warning for a representable task: %s", w)
+		}
+		fields[w.Field]++
+	}
+	for _, f := range []string{"Priority", "Recurrence"} {
+		if fields[f] != 1 {
+			t.Errorf("expected one %s warning, got %d", f, fields[f])
+		}
+	}
+}
+
+func TestCheckMarkdownTasksLimits(t *testing.T) {
+	at := time.Date(2026, 3, 5, 17, 0, 0, 0, time.UTC)
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:      "Timed",
+				DueDate:    &at,
+				Priority:   model.PriorityHighest,
+				Recurrence: &model.Recurrence{Freq: model.FreqMonthly, ByDay: []model.Weekday{"TU"}, BySetPos: []int{2}},
+			},
+			{
+				Title:      "Stand-up",
+				Priority:   model.PriorityLowest,
+				Recurrence: &model.Recurrence{Freq: model.FreqDaily, Interval: 1, ByDay: []model.Weekday{"MO", "TU", "WE", "TH", "FR"}},
+			},
+			{
+				Title:      "Rent",
+				Recurrence: &model.Recurrence{Freq: model.FreqMonthly, Interval: 1, ByMonthDay: []int{-1}},
+			},
+		},
+	}
+	fields := map[string]int{}
+	for _, w := range Check(col, "markdown-tasks") {
+		if w.ItemTitle != "Timed" {
+			t.Errorf("unexpected warning for a representable task: %s", w)
+		}
+		fields[w.Field]++
+	}
+	for _, f := range []string{"DueDate", "Recurrence"} {
+		if fields[f] != 1 {
+			t.Errorf("expected one %s warning, got %d", f, fields[f])
+		}
+	}
+
+	fields = map[string]int{}
+	for _, w := range Check(col, "logseq") {
+		fields[w.Field]++
+	}
+	if fields["Priority"] != 2 || fields["Recurrence"] != 3 || fields["DueDate"] != 0 {
+		t.Errorf("logseq warnings = %v", fields)
+	}
+}
+
+func TestCheckMessagesFromCapabilities(t *testing.T) {
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{{
+			Title:      "Chore",
+			Priority:   model.PriorityLowest,
+			Recurrence: &model.Recurrence{Freq: model.FreqWeekly, ByDay: []model.Weekday{"MO", "WE"}},
+		}},
+	}
+	tests := []struct {
+		format, field, reason string
+	}{
+		{"todoist", "Priority", "priority 1 maps onto Todoist's 1-4 scale; the distinction from its neighbour will be lost"},
+		{"logseq", "Priority", "priority 1 maps onto Logseq's [#A]-[#C] scale; the distinction from its neighbour will be lost"},
+		{"taskwarrior", "Recurrence", "Taskwarrior recurrence holds a plain period; count, by-rules and exception dates beyond that will be dropped"},
+		{"todotxt", "Recurrence", "todo.txt recurrence holds a plain period; count, by-rules and exception dates beyond that will be dropped"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, w := range Check(col, tt.format) {
+			if w.Field == tt.field {
+				got = append(got, w.Reason)
+			}
+		}
+		if len(got) != 1 || got[0] != tt.reason {
+			t.Errorf("%s %s warnings = %q, want %q", tt.format, tt.field, got, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package ics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// JCalParser reads jCal (RFC 7265), the JSON form of iCalendar. Items are
+// mapped exactly as the ICS parser maps them.
+type JCalParser struct {
+	ics *Parser
+}
+
+func NewJCalParser() *JCalParser {
+	return &JCalParser{ics: NewParser()}
+}
+
+func (p *JCalParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open jCal file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.Parse(ctx, f, filePath)
+}
+
+func (p *JCalParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jCal: %w", err)
+	}
+	cals, err := decodeJCal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode jCal: %w", err)
+	}
+
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "jcal",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+	for _, cal := range cals {
+		if err := p.ics.ParseCalendar(cal, collection); err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+// JCalWriter writes jCal (RFC 7265) with the components and properties the
+// ICS writer produces.
+type JCalWriter struct {
+	ics *Writer
+}
+
+func NewJCalWriter() *JCalWriter {
+	return &JCalWriter{ics: NewWriter()}
+}
+
+func (w *JCalWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create jCal file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return w.Write(ctx, collection, f)
+}
+
+func (w *JCalWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	var buf bytes.Buffer
+	if err := encodeJCalComponent(&buf, w.ics.Calendar(collection).Component, ""); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := writer.Write(buf.Bytes())
+	return err
+}
+
+// encodeJCalComponent writes comp as ["name", [properties], [components]],
+// one property per line.
+func encodeJCalComponent(buf *bytes.Buffer, comp *ical.Component, indent string) error {
+	inner := indent + "  "
+	name, _ := json.Marshal(strings.ToLower(comp.Name))
+	buf.WriteString("[" + string(name) + ",\n" + inner + "[")
+
+	names := make([]string, 0, len(comp.Props))
+	for n := range comp.Props {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	first := true
+	for _, n := range names {
+		for i := range comp.Props[n] {
+			prop, err := jcalProperty(&comp.Props[n][i])
+			if err != nil {
+				return err
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.WriteString("\n" + inner + "  ")
+			buf.Write(prop)
+		}
+	}
+	if !first {
+		buf.WriteString("\n" + inner)
+	}
+	buf.WriteString("],\n" + inner + "[")
+
+	for i, child := range comp.Children {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\n" + inner + "  ")
+		if err := encodeJCalComponent(buf, child, inner+"  "); err != nil {
+			return err
+		}
+	}
+	if len(comp.Children) > 0 {
+		buf.WriteString("\n" + inner)
+	}
+	buf.WriteString("]\n" + indent + "]")
+	return nil
+}
+
+// jcalProperty encodes prop as [name, {params}, type, values...].
+func jcalProperty(prop *ical.Prop) ([]byte, error) {
+	typ, values, err := typedValues(prop)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]interface{})
+	for name, vals := range exportParams(prop) {
+		if len(vals) == 1 {
+			params[name] = vals[0]
+		} else {
+			params[name] = vals
+		}
+	}
+
+	out := []interface{}{strings.ToLower(prop.Name), params, typ}
+	if prop.Name == ical.PropGeo {
+		// GEO is one structured value, [latitude, longitude]
+		return json.Marshal(append(out, values))
+	}
+	for _, v := range values {
+		switch v := v.(type) {
+		case recur:
+			out = append(out, jcalRecur(v))
+		case period:
+			out = append(out, []string{v.start, v.end})
+		default:
+			out = append(out, v)
+		}
+	}
+	return json.Marshal(out)
+}
+
+// jcalRecur marshals a recur as an object with its parts in order. A part
+// with one value is a scalar, otherwise an array.
+type jcalRecur recur
+
+func (r jcalRecur) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, rp := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(rp.name)
+		var val []byte
+		var err error
+		if len(rp.values) == 1 {
+			val, err = json.Marshal(rp.values[0])
+		} else {
+			val, err = json.Marshal(rp.values)
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJCal accepts a single vcalendar component or an array of them.
+func decodeJCal(data []byte) ([]*ical.Calendar, error) {
+	var top []json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, err
+	}
+	if len(top) == 0 {
+		return nil, nil
+	}
+	raws := top
+	var name string
+	if json.Unmarshal(top[0], &name) == nil {
+		raws = []json.RawMessage{data}
+	}
+
+	var cals []*ical.Calendar
+	for _, raw := range raws {
+		comp, err := decodeJCalComponent(raw)
+		if err != nil {
+			return nil, err
+		}
+		if comp.Name != ical.CompCalendar {
+			return nil, fmt.Errorf("expected a vcalendar component, got %q", strings.ToLower(comp.Name))
+		}
+		cals = append(cals, &ical.Calendar{Component: comp})
+	}
+	return cals, nil
+}
+
+func decodeJCalComponent(data []byte) (*ical.Component, error) {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil || len(parts) != 3 {
+		return nil, fmt.Errorf("component must be [name, properties, components]")
+	}
+	var name string
+	if err := json.Unmarshal(parts[0], &name); err != nil {
+		return nil, fmt.Errorf("component name: %w", err)
+	}
+	comp := ical.NewComponent(name)
+
+	var props, children []json.RawMessage
+	if err := json.Unmarshal(parts[1], &props); err != nil {
+		return nil, fmt.Errorf("%s properties: %w", name, err)
+	}
+	if err := json.Unmarshal(parts[2], &children); err != nil {
+		return nil, fmt.Errorf("%s components: %w", name, err)
+	}
+	for _, raw := range props {
+		prop, err := decodeJCalProperty(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		comp.Props.Add(prop)
+	}
+	for _, raw := range children {
+		child, err := decodeJCalComponent(raw)
+		if err != nil {
+			return nil, err
+		}
+		comp.Children = append(comp.Children, child)
+	}
+	return comp, nil
+}
+
+func decodeJCalProperty(data []byte) (*ical.Prop, error) {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil || len(parts) < 4 {
+		return nil, fmt.Errorf("property must be [name, params, type, value...]")
+	}
+	var name, typ string
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(parts[0], &name); err != nil {
+		return nil, fmt.Errorf("property name: %w", err)
+	}
+	if err := json.Unmarshal(parts[1], &params); err != nil {
+		return nil, fmt.Errorf("%s params: %w", name, err)
+	}
+	if err := json.Unmarshal(parts[2], &typ); err != nil {
+		return nil, fmt.Errorf("%s type: %w", name, err)
+	}
+
+	prop := ical.NewProp(name)
+	for pname, raw := range params {
+		var one string
+		var many []string
+		if json.Unmarshal(raw, &one) == nil {
+			prop.Params.Add(pname, one)
+		} else if json.Unmarshal(raw, &many) == nil {
+			for _, v := range many {
+				prop.Params.Add(pname, v)
+			}
+		} else {
+			return nil, fmt.Errorf("%s param %s must be a string or an array of strings", name, pname)
+		}
+	}
+
+	rawValues := parts[3:]
+	if prop.Name == ical.PropGeo {
+		if err := json.Unmarshal(parts[3], &rawValues); err != nil {
+			return nil, fmt.Errorf("geo must be [latitude, longitude]")
+		}
+	}
+	values := make([]interface{}, 0, len(rawValues))
+	for _, raw := range rawValues {
+		v, err := decodeJCalValue(typ, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values = append(values, v)
+	}
+	if err := setTypedValues(prop, typ, values); err != nil {
+		return nil, err
+	}
+	return prop, nil
+}
+
+func decodeJCalValue(typ string, raw json.RawMessage) (interface{}, error) {
+	switch typ {
+	case "recur":
+		return decodeJCalRecur(raw)
+	case "period":
+		var p []string
+		if err := json.Unmarshal(raw, &p); err != nil || len(p) != 2 {
+			return nil, fmt.Errorf("period must be [start, end or duration]")
+		}
+		return period{start: p[0], end: p[1]}, nil
+	case "integer":
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, fmt.Errorf("invalid integer %s", raw)
+		}
+		return n, nil
+	case "float":
+		var f float64
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("invalid float %s", raw)
+		}
+		return f, nil
+	case "boolean":
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, fmt.Errorf("invalid boolean %s", raw)
+		}
+		return b, nil
+	default:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("invalid %s value %s", typ, raw)
+		}
+		return s, nil
+	}
+}
+
+// decodeJCalRecur reads a recur object, keeping its parts in order.
+func decodeJCalRecur(raw json.RawMessage) (recur, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("recur must be an object")
+	}
+	var r recur
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		rp := recurPart{name: strings.ToLower(tok.(string))}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		vals, ok := v.([]interface{})
+		if !ok {
+			vals = []interface{}{v}
+		}
+		for _, val := range vals {
+			switch val := val.(type) {
+			case json.Number:
+				n, err := val.Int64()
+				if err != nil {
+					return nil, fmt.Errorf("invalid recur %s %s", rp.name, val)
+				}
+				rp.values = append(rp.values, int(n))
+			case string:
+				if numericRecurParts[rp.name] {
+					var n int
+					if _, err := fmt.Sscanf(val, "%d", &n); err != nil {
+						return nil, fmt.Errorf("invalid recur %s %q", rp.name, val)
+					}
+					rp.values = append(rp.values, n)
+				} else {
+					rp.values = append(rp.values, val)
+				}
+			default:
+				return nil, fmt.Errorf("invalid recur %s value %v", rp.name, val)
+			}
+		}
+		r = append(r, rp)
+	}
+	return r, nil
+}
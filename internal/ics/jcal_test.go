@@ -0,0 +1,226 @@
+package ics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-ical"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// richICS exercises every property the ICS parser maps, plus parameters and
+// value types it does not, to check the typed conversions are lossless.
+const richICS = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//Test//EN
+BEGIN:VEVENT
+UID:ev-1
+DTSTAMP:20260301T000000Z
+SUMMARY:Planning\, Q3\; all hands
+DESCRIPTION:Line one\nLine two
+LOCATION:Room 4
+DTSTART;TZID=Europe/Berlin:20260302T090000
+DTEND;TZID=Europe/Berlin:20260302T100000
+RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=MO,WE;BYSETPOS=-1;UNTIL=20261231T000000Z
+EXDATE:20260504T070000Z,20260706T070000Z
+RDATE;VALUE=PERIOD:20260810T070000Z/PT2H
+CATEGORIES:work,planning
+PRIORITY:3
+ORGANIZER;CN=Ana Ruiz:mailto:ana@example.com
+ATTENDEE;CN="Lee, Sam";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:sam@example.com
+ATTENDEE;ROLE=OPT-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:kim@example.com
+GEO:52.52;13.405
+X-SALJA-NOTE;X-LANG=en:kept as is
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Reminder
+TRIGGER;RELATED=END:-PT15M
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:ev-1
+DTSTAMP:20260301T000000Z
+RECURRENCE-ID;TZID=Europe/Berlin:20260304T090000
+SUMMARY:Planning (moved)
+DTSTART;TZID=Europe/Berlin:20260304T110000
+DTEND;TZID=Europe/Berlin:20260304T120000
+END:VEVENT
+BEGIN:VTODO
+UID:todo-1
+DTSTAMP:20260301T000000Z
+SUMMARY:File report
+DUE;VALUE=DATE:20260315
+STATUS:COMPLETED
+COMPLETED:20260314T160000Z
+PERCENT-COMPLETE:100
+RRULE:FREQ=YEARLY;COUNT=3;BYMONTH=3;BYMONTHDAY=15
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Reminder
+TRIGGER;VALUE=DATE-TIME:20260314T080000Z
+END:VALARM
+END:VTODO
+BEGIN:VJOURNAL
+UID:journal-1
+DTSTAMP:20260301T000000Z
+SUMMARY:Retro notes
+DTSTART;VALUE=DATE:20260301
+END:VJOURNAL
+END:VCALENDAR
+`
+
+func encodeICS(t *testing.T, cal *ical.Calendar) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func decodeICS(t *testing.T, data string) *ical.Calendar {
+	t.Helper()
+	cal, err := ical.NewDecoder(strings.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
+var dtstamp = regexp.MustCompile(`(?m)^DTSTAMP:.*\r?\n`)
+
+func TestJCalPropertiesRoundTrip(t *testing.T) {
+	cal := decodeICS(t, richICS)
+	var buf bytes.Buffer
+	if err := encodeJCalComponent(&buf, cal.Component, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("invalid JSON:\n%s", buf.String())
+	}
+	for _, want := range []string{
+		`["dtstart",{"tzid":"Europe/Berlin"},"date-time","2026-03-02T09:00:00"]`,
+		`["rrule",{},"recur",{"freq":"MONTHLY","interval":2,"byday":["MO","WE"],"bysetpos":-1,"until":"2026-12-31T00:00:00Z"}]`,
+		`["exdate",{},"date-time","2026-05-04T07:00:00Z","2026-07-06T07:00:00Z"]`,
+		`["rdate",{},"period",["2026-08-10T07:00:00Z","PT2H"]]`,
+		`["categories",{},"text","work","planning"]`,
+		`["geo",{},"float",[52.52,13.405]]`,
+		`["recurrence-id",{"tzid":"Europe/Berlin"},"date-time","2026-03-04T09:00:00"]`,
+		`["x-salja-note",{"x-lang":"en"},"unknown","kept as is"]`,
+		`["due",{},"date","2026-03-15"]`,
+		`["trigger",{},"date-time","2026-03-14T08:00:00Z"]`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("jCal missing %s", want)
+		}
+	}
+
+	back, err := decodeJCal(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != 1 {
+		t.Fatalf("expected 1 calendar, got %d", len(back))
+	}
+	if got, want := encodeICS(t, back[0]), encodeICS(t, cal); got != want {
+		t.Errorf("ICS -> jCal -> ICS changed the calendar:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// roundTrip writes original through the ICS writer and through w, parses
+// each back, and checks the items and the ICS they regenerate are the same.
+func roundTrip(t *testing.T, w registryWriter, p registryParser) *model.CalendarCollection {
+	t.Helper()
+	ctx := context.Background()
+	original, err := NewParser().Parse(ctx, strings.NewReader(richICS), "rich.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var icsBuf, buf bytes.Buffer
+	if err := NewWriter().Write(ctx, original, &icsBuf); err != nil {
+		t.Fatal(err)
+	}
+	viaICS, err := NewParser().Parse(ctx, &icsBuf, "out.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(ctx, original, &buf); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := p.Parse(ctx, bytes.NewReader(buf.Bytes()), "out")
+	if err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(viaICS.Items, parsed.Items) {
+		t.Errorf("items differ from an ICS round trip:\ngot  %+v\nwant %+v", parsed.Items, viaICS.Items)
+	}
+
+	var want, got bytes.Buffer
+	if err := NewWriter().Write(ctx, original, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewWriter().Write(ctx, parsed, &got); err != nil {
+		t.Fatal(err)
+	}
+	if dtstamp.ReplaceAllString(got.String(), "") != dtstamp.ReplaceAllString(want.String(), "") {
+		t.Errorf("regenerated ICS differs:\ngot:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+	return parsed
+}
+
+type registryWriter interface {
+	Write(context.Context, *model.CalendarCollection, io.Writer) error
+}
+
+type registryParser interface {
+	Parse(context.Context, io.Reader, string) (*model.CalendarCollection, error)
+}
+
+func TestJCalRoundTripThroughParserAndWriter(t *testing.T) {
+	parsed := roundTrip(t, NewJCalWriter(), NewJCalParser())
+	if parsed.SourceApp != "jcal" {
+		t.Errorf("SourceApp = %q", parsed.SourceApp)
+	}
+	if len(parsed.Items) != 3 || parsed.Items[0].Recurrence == nil || len(parsed.Items[0].Recurrence.ExDates) != 2 || len(parsed.Items[0].Recurrence.Overrides) != 1 {
+		t.Errorf("parsed: %+v", parsed.Items)
+	}
+}
+
+func TestJCalParserAcceptsCalendarArray(t *testing.T) {
+	data := `[
+  ["vcalendar", [["version", {}, "text", "2.0"]], [
+    ["vevent", [
+      ["uid", {}, "text", "a"],
+      ["summary", {}, "text", "First"],
+      ["dtstart", {}, "date", "2026-04-01"]
+    ], []]
+  ]],
+  ["vcalendar", [], [
+    ["vtodo", [["uid", {}, "text", "b"], ["summary", {}, "text", "Second"], ["priority", {}, "integer", 1]], []]
+  ]]
+]`
+	col, err := NewJCalParser().Parse(context.Background(), strings.NewReader(data), "multi.jcal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(col.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(col.Items))
+	}
+	if !col.Items[0].IsAllDay || col.Items[0].StartTime.Day() != 1 {
+		t.Errorf("all-day event: %+v", col.Items[0])
+	}
+	if col.Items[1].ItemType != model.ItemTypeTask || col.Items[1].Priority != model.PriorityHighest {
+		t.Errorf("todo: %+v", col.Items[1])
+	}
+
+	if _, err := NewJCalParser().Parse(context.Background(), strings.NewReader(`["vevent", [], []]`), "x.jcal"); err == nil {
+		t.Error("expected an error for a top-level component other than vcalendar")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -47,33 +48,96 @@ func (p *Parser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*mo
 			return nil, fmt.Errorf("failed to decode ICS: %w", err)
 		}
 
-		for _, comp := range cal.Children {
-			switch comp.Name {
-			case ical.CompEvent:
-				item, err := p.parseEvent(comp)
-				if err != nil {
-					return nil, err
-				}
-				collection.Items = append(collection.Items, *item)
-			case ical.CompToDo:
-				item, err := p.parseTodo(comp)
-				if err != nil {
-					return nil, err
-				}
-				collection.Items = append(collection.Items, *item)
-			case ical.CompJournal:
-				item, err := p.parseJournal(comp)
-				if err != nil {
-					return nil, err
-				}
-				collection.Items = append(collection.Items, *item)
-			}
+		if err := p.ParseCalendar(cal, collection); err != nil {
+			return nil, err
 		}
 	}
 
 	return collection, nil
 }
 
+// ParseCalendar appends the events, todos and journals of a decoded calendar
+// to collection. It is shared by the formats that carry iCalendar data in
+// another syntax. A component with a RECURRENCE-ID becomes an override of
+// the recurring item with its UID, or an EXDATE when it is cancelled. TZIDs
+// are rewritten to IANA names first.
+func (p *Parser) ParseCalendar(cal *ical.Calendar, collection *model.CalendarCollection) error {
+	resolveTimezones(cal)
+	first := len(collection.Items)
+	var pending []*ical.Component
+	for _, comp := range cal.Children {
+		if comp.Props.Get(ical.PropRecurrenceID) != nil && comp.Name != ical.CompJournal {
+			pending = append(pending, comp)
+			continue
+		}
+		item, err := p.parseComponent(comp)
+		if err != nil {
+			return err
+		}
+		if item != nil {
+			collection.Items = append(collection.Items, *item)
+		}
+	}
+
+	for _, comp := range pending {
+		item, err := p.parseComponent(comp)
+		if err != nil {
+			return err
+		}
+		rid, _, _, err := parseDateTime(comp.Props.Get(ical.PropRecurrenceID))
+		if err != nil {
+			return fmt.Errorf("%s %q RECURRENCE-ID: %w", strings.ToLower(comp.Name), item.UID, err)
+		}
+		master := findRecurring(collection.Items[first:], item.UID)
+		if master == nil {
+			// an occurrence sent on its own, e.g. in an invitation update,
+			// keeps its RECURRENCE-ID as an extension
+			collection.Items = append(collection.Items, *item)
+			continue
+		}
+		item.Recurrence = nil
+		item.Extensions = withoutExtension(item.Extensions, ical.PropRecurrenceID)
+		if status, err := comp.Props.Text(ical.PropStatus); err == nil && strings.EqualFold(status, "CANCELLED") {
+			master.Recurrence.ExDates = append(master.Recurrence.ExDates, rid)
+			continue
+		}
+		master.Recurrence.Overrides = append(master.Recurrence.Overrides, model.Override{RecurrenceID: rid, Item: *item})
+	}
+	return nil
+}
+
+func (p *Parser) parseComponent(comp *ical.Component) (*model.CalendarItem, error) {
+	switch comp.Name {
+	case ical.CompEvent:
+		return p.parseEvent(comp)
+	case ical.CompToDo:
+		return p.parseTodo(comp)
+	case ical.CompJournal:
+		return p.parseJournal(comp)
+	}
+	return nil, nil
+}
+
+// findRecurring returns the recurring item with uid.
+func findRecurring(items []model.CalendarItem, uid string) *model.CalendarItem {
+	for i := range items {
+		if items[i].UID == uid && items[i].Recurrence != nil {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func withoutExtension(exts []model.Extension, name string) []model.Extension {
+	var kept []model.Extension
+	for _, ext := range exts {
+		if ext.Namespace != ExtensionNamespace || ext.Name != name {
+			kept = append(kept, ext)
+		}
+	}
+	return kept
+}
+
 func (p *Parser) parseEvent(comp *ical.Component) (*model.CalendarItem, error) {
 	item := &model.CalendarItem{
 		ItemType: model.ItemTypeEvent,
@@ -139,14 +203,15 @@ func (p *Parser) parseEvent(comp *ical.Component) (*model.CalendarItem, error) {
 		item.Recurrence.RDates = append(item.Recurrence.RDates, rdates...)
 	}
 
-	if categories, err := comp.Props.Text("CATEGORIES"); err == nil {
-		item.Tags = parseCategories(categories)
-	}
+	item.Tags = parseCategories(comp.Props)
 
 	if priority := comp.Props.Get("PRIORITY"); priority != nil {
 		item.Priority = parsePriority(priority.Value)
 	}
 
+	parseAttendees(comp.Props, item)
+	item.Extensions = parseExtensions(comp)
+
 	for _, child := range comp.Children {
 		if child.Name == "VALARM" {
 			reminder := parseAlarm(child)
@@ -219,9 +284,9 @@ func (p *Parser) parseTodo(comp *ical.Component) (*model.CalendarItem, error) {
 		item.Priority = parsePriority(priority.Value)
 	}
 
-	if categories, err := comp.Props.Text("CATEGORIES"); err == nil {
-		item.Tags = parseCategories(categories)
-	}
+	item.Tags = parseCategories(comp.Props)
+	parseAttendees(comp.Props, item)
+	item.Extensions = parseExtensions(comp)
 
 	if rrule := comp.Props.Get("RRULE"); rrule != nil {
 		rec, err := parseRRule(rrule.Value)
@@ -270,9 +335,61 @@ func (p *Parser) parseJournal(comp *ical.Component) (*model.CalendarItem, error)
 		}
 	}
 
+	item.Extensions = parseExtensions(comp)
+
 	return item, nil
 }
 
+// ExtensionNamespace is the model.Extension namespace of iCalendar
+// properties, shared by the ics, jcal and xcal formats.
+const ExtensionNamespace = "ics"
+
+// knownProps are the properties of each component the writer produces from
+// item fields. Any other property is kept as an extension.
+var knownProps = map[string]map[string]bool{
+	ical.CompEvent: {
+		"UID": true, "SUMMARY": true, "DTSTAMP": true, "DESCRIPTION": true, "LOCATION": true,
+		"DTSTART": true, "DTEND": true, "PRIORITY": true, "CATEGORIES": true,
+		"ORGANIZER": true, "ATTENDEE": true, "RRULE": true, "EXDATE": true, "RDATE": true,
+	},
+	ical.CompToDo: {
+		"UID": true, "SUMMARY": true, "DTSTAMP": true, "DESCRIPTION": true, "DUE": true,
+		"DTSTART": true, "STATUS": true, "COMPLETED": true, "PERCENT-COMPLETE": true,
+		"PRIORITY": true, "CATEGORIES": true, "ORGANIZER": true, "ATTENDEE": true, "RRULE": true,
+	},
+	ical.CompJournal: {
+		"UID": true, "SUMMARY": true, "DTSTAMP": true, "DESCRIPTION": true, "DTSTART": true,
+	},
+}
+
+// parseExtensions keeps the properties of comp that no item field holds,
+// such as URL, CLASS, TRANSP or X- properties, sorted by name.
+func parseExtensions(comp *ical.Component) []model.Extension {
+	known := knownProps[comp.Name]
+	var names []string
+	for name := range comp.Props {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var exts []model.Extension
+	for _, name := range names {
+		for _, prop := range comp.Props[name] {
+			ext := model.Extension{Namespace: ExtensionNamespace, Name: name, Value: prop.Value}
+			if len(prop.Params) > 0 {
+				ext.Params = make(map[string][]string, len(prop.Params))
+				for k, v := range prop.Params {
+					ext.Params[k] = append([]string(nil), v...)
+				}
+			}
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
 func parseDateTime(prop *ical.Prop) (time.Time, bool, string, error) {
 	isAllDay := false
 	tz := ""
@@ -385,6 +502,23 @@ func parseRRule(value string) (*model.Recurrence, error) {
 				}
 				rec.BySetPos = append(rec.BySetPos, p)
 			}
+		case "BYWEEKNO", "BYYEARDAY", "BYHOUR", "BYMINUTE", "BYSECOND":
+			list := map[string]*[]int{
+				"BYWEEKNO": &rec.ByWeekNo, "BYYEARDAY": &rec.ByYearDay,
+				"BYHOUR": &rec.ByHour, "BYMINUTE": &rec.ByMinute, "BYSECOND": &rec.BySecond,
+			}[key]
+			for _, s := range strings.Split(val, ",") {
+				var v int
+				if n, err := fmt.Sscanf(s, "%d", &v); n != 1 || err != nil {
+					return nil, fmt.Errorf("invalid RRULE %s value: %q", key, s)
+				}
+				*list = append(*list, v)
+			}
+		case "WKST":
+			if weekdayIndex(model.Weekday(val)) < 0 {
+				return nil, fmt.Errorf("invalid RRULE WKST value: %q", val)
+			}
+			rec.WeekStart = model.Weekday(val)
 		}
 	}
 
@@ -395,7 +529,8 @@ func parseExDate(prop *ical.Prop) []time.Time {
 	var dates []time.Time
 	values := strings.Split(prop.Value, ",")
 	for _, val := range values {
-		t, err := time.Parse("20060102T150405Z", val)
+		single := ical.Prop{Name: prop.Name, Params: prop.Params, Value: val}
+		t, _, _, err := parseDateTime(&single)
 		if err != nil {
 			t, err = time.Parse("20060102", val)
 		}
@@ -410,8 +545,49 @@ func parseRDate(prop *ical.Prop) []time.Time {
 	return parseExDate(prop)
 }
 
-func parseCategories(value string) []string {
-	return strings.Split(value, ",")
+// parseCategories collects the tags of every CATEGORIES property, each of
+// which may hold a comma-separated list.
+func parseCategories(props ical.Props) []string {
+	var tags []string
+	for _, prop := range props.Values("CATEGORIES") {
+		list, err := prop.TextList()
+		if err != nil {
+			continue
+		}
+		for _, tag := range list {
+			// older salja versions escaped the separating commas
+			tags = append(tags, strings.Split(tag, ",")...)
+		}
+	}
+	return tags
+}
+
+// parseAttendees reads the ORGANIZER and ATTENDEE properties into item.
+func parseAttendees(props ical.Props, item *model.CalendarItem) {
+	if prop := props.Get(ical.PropOrganizer); prop != nil {
+		item.Organizer = &model.Attendee{
+			Email: calAddress(prop.Value),
+			Name:  prop.Params.Get(ical.ParamCommonName),
+		}
+	}
+	for _, prop := range props.Values(ical.PropAttendee) {
+		item.Attendees = append(item.Attendees, model.Attendee{
+			Email:  calAddress(prop.Value),
+			Name:   prop.Params.Get(ical.ParamCommonName),
+			Role:   model.AttendeeRole(strings.ToUpper(prop.Params.Get(ical.ParamRole))),
+			Status: model.PartStat(strings.ToUpper(prop.Params.Get(ical.ParamParticipationStatus))),
+			RSVP:   strings.EqualFold(prop.Params.Get(ical.ParamRSVP), "TRUE"),
+		})
+	}
+}
+
+// calAddress returns the email address of a CAL-ADDRESS value, which is
+// normally a mailto: URI.
+func calAddress(value string) string {
+	if len(value) >= 7 && strings.EqualFold(value[:7], "mailto:") {
+		return value[7:]
+	}
+	return value
 }
 
 func parsePriority(value string) model.Priority {
@@ -471,33 +647,53 @@ func parseAlarm(comp *ical.Component) *model.Reminder {
 	return reminder
 }
 
+// parseDuration parses an RFC 5545 duration such as -PT15M or P1DT2H.
 func parseDuration(value string) (time.Duration, error) {
-	value = strings.TrimPrefix(value, "-")
-	value = strings.TrimPrefix(value, "+")
-
 	isNegative := strings.HasPrefix(value, "-")
-	value = strings.TrimPrefix(value, "P")
+	rest := strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(rest, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	rest = rest[1:]
 
 	var duration time.Duration
-
-	if strings.Contains(value, "W") {
-		var weeks int
-		_, _ = fmt.Sscanf(value, "%dW", &weeks)
-		duration = time.Duration(weeks) * 7 * 24 * time.Hour
-	} else {
-		parts := strings.Split(value, "T")
-		if len(parts) > 0 {
-			datePart := parts[0]
-			var days int
-			_, _ = fmt.Sscanf(datePart, "%dD", &days)
-			duration += time.Duration(days) * 24 * time.Hour
-		}
-		if len(parts) > 1 {
-			timePart := parts[1]
-			var hours, minutes, seconds int
-			_, _ = fmt.Sscanf(timePart, "%dH%dM%dS", &hours, &minutes, &seconds)
-			duration += time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	inTime := false
+	n := -1
+	for _, c := range rest {
+		switch {
+		case c >= '0' && c <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(c-'0')
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		if n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
 		}
+		var unit time.Duration
+		switch {
+		case c == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case c == 'H' && inTime:
+			unit = time.Hour
+		case c == 'M' && inTime:
+			unit = time.Minute
+		case c == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		duration += time.Duration(n) * unit
+		n = -1
+	}
+	if n >= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
 	}
 
 	if isNegative {
@@ -1,6 +1,7 @@
 package ics
 
 import (
+	"bytes"
 	"context"
 	"strings"
 	"testing"
@@ -185,6 +186,106 @@ END:VCALENDAR`
 	}
 }
 
+func TestParseTimezoneNames(t *testing.T) {
+	icsData := `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VTIMEZONE
+TZID:(UTC+10:00) Canberra, Melbourne, Sydney
+BEGIN:STANDARD
+DTSTART:16010101T030000
+TZOFFSETFROM:+1100
+TZOFFSETTO:+1000
+RRULE:FREQ=YEARLY;BYDAY=1SU;BYMONTH=4
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:16010101T020000
+TZOFFSETFROM:+1000
+TZOFFSETTO:+1100
+RRULE:FREQ=YEARLY;BYDAY=1SU;BYMONTH=10
+END:DAYLIGHT
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:windows
+DTSTART;TZID=W. Europe Standard Time:20260304T090000
+END:VEVENT
+BEGIN:VEVENT
+UID:mozilla
+DTSTART;TZID=/mozilla.org/20050126_1/America/New_York:20260304T090000
+END:VEVENT
+BEGIN:VEVENT
+UID:outlook
+DTSTART;TZID="(UTC+10:00) Canberra, Melbourne, Sydney":20260304T090000
+END:VEVENT
+END:VCALENDAR`
+
+	collection, err := NewParser().Parse(context.Background(), strings.NewReader(icsData), "test.ics")
+	if err != nil {
+		t.Fatalf("Failed to parse ICS: %v", err)
+	}
+	if len(collection.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(collection.Items))
+	}
+	want := []struct {
+		zone string
+		utc  time.Time
+	}{
+		{"Europe/Berlin", time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)},
+		{"America/New_York", time.Date(2026, 3, 4, 14, 0, 0, 0, time.UTC)},
+		// matched by its definition: daylight time in March
+		{"Australia/Sydney", time.Date(2026, 3, 3, 22, 0, 0, 0, time.UTC)},
+	}
+	for i, w := range want {
+		item := collection.Items[i]
+		if item.Timezone != w.zone || !item.StartTime.Equal(w.utc) {
+			t.Errorf("%s: zone %q, start %v; want %q, %v", item.UID, item.Timezone, item.StartTime, w.zone, w.utc)
+		}
+	}
+}
+
+func TestWriterAddsTimezones(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	start := time.Date(2026, 7, 1, 9, 0, 0, 0, berlin)
+	kolkata := time.Date(2026, 7, 2, 9, 0, 0, 0, time.UTC)
+	col := &model.CalendarCollection{Items: []model.CalendarItem{
+		{UID: "a", Title: "Berlin", ItemType: model.ItemTypeEvent, StartTime: &start, Timezone: "Europe/Berlin"},
+		{UID: "b", Title: "Kolkata", ItemType: model.ItemTypeEvent, StartTime: &kolkata, Timezone: "Asia/Kolkata"},
+		{UID: "c", Title: "Berlin again", ItemType: model.ItemTypeTask, DueDate: &start, Timezone: "Europe/Berlin"},
+	}}
+
+	var buf bytes.Buffer
+	if err := NewWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	if strings.Count(out, "BEGIN:VTIMEZONE") != 2 || strings.Index(out, "BEGIN:VTIMEZONE") > strings.Index(out, "BEGIN:VEVENT") {
+		t.Errorf("expected one VTIMEZONE per zone, before the events:\n%s", out)
+	}
+	for _, line := range []string{
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU", "RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+		"TZOFFSETFROM:+0100", "TZOFFSETTO:+0200", "TZNAME:CEST", "TZOFFSETTO:+0530",
+	} {
+		if !strings.Contains(out, line+"\r\n") {
+			t.Errorf("missing %s", line)
+		}
+	}
+
+	// a reader that does not know the zone's name still places the times
+	renamed := strings.ReplaceAll(out, "Europe/Berlin", "Mitteleuropa")
+	parsed, err := NewParser().Parse(context.Background(), strings.NewReader(renamed), "out.ics")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(parsed.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(parsed.Items))
+	}
+	if got := parsed.Items[0]; got.Timezone != "Europe/Berlin" || !got.StartTime.Equal(start) {
+		t.Errorf("zone %q, start %v", got.Timezone, got.StartTime)
+	}
+	if got := parsed.Items[1]; got.Timezone != "Asia/Kolkata" || !got.StartTime.Equal(kolkata) {
+		t.Errorf("zone %q, start %v", got.Timezone, got.StartTime)
+	}
+}
+
 func TestParseMalformedInput(t *testing.T) {
 	icsData := `BEGIN:VCALENDAR
 VERSION:2.0
@@ -337,3 +438,210 @@ func TestWriterRoundTrip(t *testing.T) {
 		t.Errorf("Expected 2 tags, got %d", len(item.Tags))
 	}
 }
+
+func TestParseAttendees(t *testing.T) {
+	icsData := `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:meeting-1
+SUMMARY:Design review
+DTSTART:20260310T140000Z
+ORGANIZER;CN=Ana Ruiz:MAILTO:ana@example.com
+ATTENDEE;CN="Lee, Sam";ROLE=CHAIR;PARTSTAT=ACCEPTED:mailto:sam@example.com
+ATTENDEE;ROLE=OPT-PARTICIPANT;PARTSTAT=tentative;RSVP=TRUE:mailto:kim@example.com
+ATTENDEE:mailto:jo@example.com
+END:VEVENT
+END:VCALENDAR`
+
+	collection, err := NewParser().Parse(context.Background(), strings.NewReader(icsData), "meeting.ics")
+	if err != nil {
+		t.Fatalf("Failed to parse ICS: %v", err)
+	}
+	item := collection.Items[0]
+	if item.Organizer == nil || item.Organizer.Email != "ana@example.com" || item.Organizer.Name != "Ana Ruiz" {
+		t.Errorf("organizer = %+v", item.Organizer)
+	}
+	want := []model.Attendee{
+		{Email: "sam@example.com", Name: "Lee, Sam", Role: model.RoleChair, Status: model.PartStatAccepted},
+		{Email: "kim@example.com", Role: model.RoleOptional, Status: model.PartStatTentative, RSVP: true},
+		{Email: "jo@example.com"},
+	}
+	if len(item.Attendees) != len(want) {
+		t.Fatalf("attendees = %+v", item.Attendees)
+	}
+	for i := range want {
+		if item.Attendees[i] != want[i] {
+			t.Errorf("attendee %d = %+v, want %+v", i, item.Attendees[i], want[i])
+		}
+	}
+
+	// people without an address cannot be written as a CAL-ADDRESS
+	collection.Items[0].Attendees = append(item.Attendees, model.Attendee{Name: "Front desk"})
+	var buf strings.Builder
+	if err := NewWriter().Write(context.Background(), collection, &buf); err != nil {
+		t.Fatalf("Failed to write ICS: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"ORGANIZER;CN=Ana Ruiz:mailto:ana@example.com",
+		"ATTENDEE;PARTSTAT=TENTATIVE;ROLE=OPT-PARTICIPANT;RSVP=TRUE:mailto:kim@example.com",
+		"ATTENDEE:mailto:jo@example.com",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output missing %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "Front desk") {
+		t.Errorf("attendee without an address was written:\n%s", out)
+	}
+}
+
+func TestExtensionsRoundTrip(t *testing.T) {
+	icsData := `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:launch-1
+SUMMARY:Launch
+DTSTART:20260310T140000Z
+STATUS:TENTATIVE
+TRANSP:TRANSPARENT
+CLASS:PRIVATE
+URL:https://example.com/launch
+ATTACH;FMTTYPE=application/pdf:https://example.com/a.pdf
+ATTACH:https://example.com/b.pdf
+SEQUENCE:3
+X-APPLE-TRAVEL-ADVISORY-BEHAVIOR:AUTOMATIC
+END:VEVENT
+END:VCALENDAR`
+
+	collection, err := NewParser().Parse(context.Background(), strings.NewReader(icsData), "launch.ics")
+	if err != nil {
+		t.Fatalf("Failed to parse ICS: %v", err)
+	}
+	item := collection.Items[0]
+	var names []string
+	for _, ext := range item.Extensions {
+		if ext.Namespace != ExtensionNamespace {
+			t.Errorf("extension %s has namespace %q", ext.Name, ext.Namespace)
+		}
+		names = append(names, ext.Name)
+	}
+	// sorted by name, repeated properties in file order
+	if got := strings.Join(names, ","); got != "ATTACH,ATTACH,CLASS,SEQUENCE,STATUS,TRANSP,URL,X-APPLE-TRAVEL-ADVISORY-BEHAVIOR" {
+		t.Errorf("extensions = %s", got)
+	}
+	if v, ok := item.ExtensionValue(ExtensionNamespace, "URL"); !ok || v != "https://example.com/launch" {
+		t.Errorf("URL = %q, %v", v, ok)
+	}
+
+	var buf strings.Builder
+	if err := NewWriter().Write(context.Background(), collection, &buf); err != nil {
+		t.Fatalf("Failed to write ICS: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"STATUS:TENTATIVE",
+		"TRANSP:TRANSPARENT",
+		"CLASS:PRIVATE",
+		"URL:https://example.com/launch",
+		"ATTACH;FMTTYPE=application/pdf:https://example.com/a.pdf",
+		"ATTACH:https://example.com/b.pdf",
+		"SEQUENCE:3",
+		"X-APPLE-TRAVEL-ADVISORY-BEHAVIOR:AUTOMATIC",
+	} {
+		if !strings.Contains(out, line+"\r\n") {
+			t.Errorf("output missing %q:\n%s", line, out)
+		}
+	}
+	if strings.Count(out, "SUMMARY:") != 1 || strings.Count(out, "DTSTAMP:") != 1 {
+		t.Errorf("a property was written twice:\n%s", out)
+	}
+}
+
+func TestParseRecurrenceOverrides(t *testing.T) {
+	icsData := `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:standup-1
+RECURRENCE-ID:20260317T090000Z
+SUMMARY:Standup (moved)
+DTSTART:20260317T110000Z
+DTEND:20260317T111500Z
+LOCATION:Room 2
+END:VEVENT
+BEGIN:VEVENT
+UID:standup-1
+SUMMARY:Standup
+DTSTART:20260310T090000Z
+DTEND:20260310T091500Z
+RRULE:FREQ=WEEKLY;UNTIL=20260331T090000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:standup-1
+RECURRENCE-ID:20260324T090000Z
+SUMMARY:Standup
+DTSTART:20260324T090000Z
+STATUS:CANCELLED
+END:VEVENT
+BEGIN:VEVENT
+UID:review-1
+RECURRENCE-ID:20260312T150000Z
+SUMMARY:Review (rescheduled)
+DTSTART:20260313T150000Z
+END:VEVENT
+END:VCALENDAR`
+
+	collection, err := NewParser().Parse(context.Background(), strings.NewReader(icsData), "team.ics")
+	if err != nil {
+		t.Fatalf("Failed to parse ICS: %v", err)
+	}
+	// the lone occurrence has no series to join and stays an item
+	if len(collection.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(collection.Items))
+	}
+	series := collection.Items[0]
+	rec := series.Recurrence
+	moved := time.Date(2026, 3, 17, 9, 0, 0, 0, time.UTC)
+	cancelled := time.Date(2026, 3, 24, 9, 0, 0, 0, time.UTC)
+	if len(rec.ExDates) != 1 || !rec.ExDates[0].Equal(cancelled) {
+		t.Errorf("ExDates = %v", rec.ExDates)
+	}
+	ov := rec.Override(moved)
+	if len(rec.Overrides) != 1 || ov == nil {
+		t.Fatalf("Overrides = %+v", rec.Overrides)
+	}
+	if ov.Item.Title != "Standup (moved)" || ov.Item.Location != "Room 2" || ov.Item.StartTime.Hour() != 11 || len(ov.Item.Extensions) != 0 {
+		t.Errorf("override = %+v", ov.Item)
+	}
+	if lone := collection.Items[1]; lone.Recurrence != nil || len(lone.Extensions) != 1 || lone.Extensions[0].Name != "RECURRENCE-ID" {
+		t.Errorf("lone occurrence = %+v", lone)
+	}
+
+	var buf strings.Builder
+	if err := NewWriter().Write(context.Background(), collection, &buf); err != nil {
+		t.Fatalf("Failed to write ICS: %v", err)
+	}
+	out := buf.String()
+	if strings.Count(out, "UID:standup-1") != 2 || !strings.Contains(out, "RECURRENCE-ID:20260317T090000Z\r\n") ||
+		!strings.Contains(out, "EXDATE:20260324T090000Z\r\n") || !strings.Contains(out, "RECURRENCE-ID:20260312T150000Z\r\n") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	again, err := NewParser().Parse(context.Background(), strings.NewReader(out), "out.ics")
+	if err != nil {
+		t.Fatalf("Failed to parse written ICS: %v", err)
+	}
+	if len(again.Items) != 2 || len(again.Items[0].Recurrence.Overrides) != 1 {
+		t.Errorf("round trip = %+v", again.Items)
+	}
+
+	// expanding uses the override and skips the cancelled occurrence
+	instances := NewFlattener().FlattenRecurrence(&series, 10, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC))
+	var got []string
+	for _, inst := range instances {
+		got = append(got, inst.StartTime.Format("01-02 15:04")+" "+inst.Title)
+	}
+	want := "03-10 09:00 Standup,03-17 11:00 Standup (moved),03-31 09:00 Standup"
+	if strings.Join(got, ",") != want {
+		t.Errorf("instances = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,444 @@
+package ics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// Expand returns, in order, the starts of the occurrences of a series
+// that starts at dtstart: dtstart itself, the starts rec's rule gives after
+// it and rec's RDATEs, less its EXDATEs, up to and including end. limit
+// caps how many are returned when positive; a zero end means no bound, so
+// then the rule or limit has to end the series.
+//
+// The rule is stepped on the wall clock of dtstart's location, so a daily
+// 09:00 meeting stays at 09:00 across DST changes. As RFC 5545 has it, a
+// time the clocks skip takes the offset from before the skip, and a time
+// they repeat is the first of the two.
+func Expand(rec *model.Recurrence, dtstart, end time.Time, limit int) []time.Time {
+	if end.IsZero() {
+		end = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	}
+	if dtstart.After(end) {
+		return nil
+	}
+	r := newRule(rec, dtstart)
+
+	// COUNT counts the occurrences before EXDATEs take any away, and no
+	// more than limit plus the EXDATEs are ever needed
+	want := -1
+	if rec.Count != nil {
+		want = *rec.Count
+	}
+	if limit > 0 && (want < 0 || limit+len(rec.ExDates) < want) {
+		want = limit + len(rec.ExDates)
+	}
+
+	starts := []time.Time{dtstart}
+	if want != 0 {
+		r.each(end, func(t time.Time) bool {
+			if len(starts) == want || rec.Until != nil && t.After(*rec.Until) || t.After(end) {
+				return false
+			}
+			starts = append(starts, t)
+			return true
+		})
+	}
+	if want == 0 {
+		starts = starts[:0]
+	}
+	for _, rdate := range rec.RDates {
+		if !rdate.Before(dtstart) && !rdate.After(end) {
+			starts = append(starts, rdate)
+		}
+	}
+	sort.SliceStable(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	var out []time.Time
+	for i, t := range starts {
+		if i > 0 && t.Equal(starts[i-1]) || isExDate(t, rec.ExDates) {
+			continue
+		}
+		out = append(out, t)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// isExDate reports whether one of exdates cancels the occurrence at t. A
+// date-only EXDATE, parsed as midnight UTC, cancels the day it names.
+func isExDate(t time.Time, exdates []time.Time) bool {
+	for _, ex := range exdates {
+		if ex.Equal(t) {
+			return true
+		}
+		y, m, d := t.Date()
+		if ex.Location() == time.UTC && ex.Hour() == 0 && ex.Minute() == 0 && ex.Second() == 0 &&
+			ex.Year() == y && ex.Month() == m && ex.Day() == d {
+			return true
+		}
+	}
+	return false
+}
+
+// rule is a recurrence rule ready to step. Times in it are wall-clock
+// times held in UTC, so stepping them never meets a DST change.
+type rule struct {
+	freq     model.FreqType
+	interval int
+	loc      *time.Location
+	start    time.Time
+	wkst     time.Weekday
+
+	byMonth, byWeekNo, byYearDay, byMonthDay []int
+	byDay                                    []weekdayNum
+	byHour, byMinute, bySecond               []int
+	bySetPos                                 []int
+}
+
+// weekdayNum is a BYDAY value such as MO, 2SU or -1FR; n is 0 without a
+// number.
+type weekdayNum struct {
+	n  int
+	wd time.Weekday
+}
+
+func newRule(rec *model.Recurrence, dtstart time.Time) *rule {
+	start := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, time.UTC)
+	r := &rule{
+		freq:     rec.Freq,
+		interval: max(rec.Interval, 1),
+		loc:      dtstart.Location(),
+		start:    start,
+		wkst:     time.Monday,
+		byMonth:  rec.ByMonth, byWeekNo: rec.ByWeekNo, byYearDay: rec.ByYearDay, byMonthDay: rec.ByMonthDay,
+		byHour: rec.ByHour, byMinute: rec.ByMinute, bySecond: rec.BySecond,
+		bySetPos: rec.BySetPos,
+	}
+	if wd := weekdayIndex(rec.WeekStart); wd >= 0 {
+		r.wkst = time.Weekday(wd)
+	}
+	for _, d := range rec.ByDay {
+		if wn, ok := parseWeekdayNum(string(d)); ok {
+			r.byDay = append(r.byDay, wn)
+		}
+	}
+
+	// a rule without days of its own takes them from DTSTART
+	if len(r.byWeekNo) == 0 && len(r.byYearDay) == 0 && len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		switch r.freq {
+		case model.FreqYearly:
+			if len(r.byMonth) == 0 {
+				r.byMonth = []int{int(start.Month())}
+			}
+			r.byMonthDay = []int{start.Day()}
+		case model.FreqMonthly:
+			r.byMonthDay = []int{start.Day()}
+		case model.FreqWeekly:
+			r.byDay = []weekdayNum{{wd: start.Weekday()}}
+		}
+	}
+	// and the same for times finer than its frequency
+	if len(r.byHour) == 0 && r.unit() > time.Hour {
+		r.byHour = []int{start.Hour()}
+	}
+	if len(r.byMinute) == 0 && r.unit() > time.Minute {
+		r.byMinute = []int{start.Minute()}
+	}
+	if len(r.bySecond) == 0 && r.unit() > time.Second {
+		r.bySecond = []int{start.Second()}
+	}
+	return r
+}
+
+// unit is the length of the rule's frequency, for comparing frequencies;
+// months and years only need to be longer than days.
+func (r *rule) unit() time.Duration {
+	switch r.freq {
+	case model.FreqSecondly:
+		return time.Second
+	case model.FreqMinutely:
+		return time.Minute
+	case model.FreqHourly:
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// each calls yield with the starts the rule gives after its DTSTART, in
+// order, until yield returns false or the periods pass end.
+func (r *rule) each(end time.Time, yield func(time.Time) bool) {
+	last := end.In(r.loc)
+	stop := time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, time.UTC)
+	for p := r.firstPeriod(); !p.After(stop); p = r.nextPeriod(p) {
+		if r.unit() < 24*time.Hour && !r.matchDay(p) {
+			// skip the rest of a day none of whose times can match
+			p = r.lastPeriodOfDay(p)
+			continue
+		}
+		for _, w := range r.setPos(r.period(p)) {
+			if !w.After(r.start) {
+				continue
+			}
+			if !yield(wallClock(w, r.loc)) {
+				return
+			}
+		}
+	}
+}
+
+func (r *rule) firstPeriod() time.Time {
+	s := r.start
+	switch r.freq {
+	case model.FreqYearly:
+		return time.Date(s.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case model.FreqMonthly:
+		return time.Date(s.Year(), s.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case model.FreqWeekly:
+		return time.Date(s.Year(), s.Month(), s.Day()-(int(s.Weekday())-int(r.wkst)+7)%7, 0, 0, 0, 0, time.UTC)
+	case model.FreqDaily:
+		return time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return s.Truncate(r.unit())
+}
+
+func (r *rule) nextPeriod(p time.Time) time.Time {
+	switch r.freq {
+	case model.FreqYearly:
+		return p.AddDate(r.interval, 0, 0)
+	case model.FreqMonthly:
+		return p.AddDate(0, r.interval, 0)
+	case model.FreqWeekly:
+		return p.AddDate(0, 0, 7*r.interval)
+	case model.FreqDaily:
+		return p.AddDate(0, 0, r.interval)
+	}
+	return p.Add(time.Duration(r.interval) * r.unit())
+}
+
+// lastPeriodOfDay returns the last period of a sub-daily rule on p's day.
+func (r *rule) lastPeriodOfDay(p time.Time) time.Time {
+	step := time.Duration(r.interval) * r.unit()
+	midnight := time.Date(p.Year(), p.Month(), p.Day()+1, 0, 0, 0, 0, time.UTC)
+	return p.Add((midnight.Sub(p) - 1) / step * step)
+}
+
+// period returns the wall-clock times, in order, the rule gives in the
+// period starting at p, before BYSETPOS picks among them.
+func (r *rule) period(p time.Time) []time.Time {
+	var days []time.Time
+	switch r.freq {
+	case model.FreqYearly:
+		for d := p; d.Year() == p.Year(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case model.FreqMonthly:
+		for d := p; d.Month() == p.Month(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case model.FreqWeekly:
+		for i := 0; i < 7; i++ {
+			days = append(days, p.AddDate(0, 0, i))
+		}
+	default:
+		days = []time.Time{time.Date(p.Year(), p.Month(), p.Day(), 0, 0, 0, 0, time.UTC)}
+	}
+
+	hours, minutes, seconds := r.byHour, r.byMinute, r.bySecond
+	if r.unit() <= time.Hour {
+		if hours = []int{p.Hour()}; len(r.byHour) > 0 && !contains(r.byHour, p.Hour()) {
+			return nil
+		}
+	}
+	if r.unit() <= time.Minute {
+		if minutes = []int{p.Minute()}; len(r.byMinute) > 0 && !contains(r.byMinute, p.Minute()) {
+			return nil
+		}
+	}
+	if r.unit() <= time.Second {
+		if seconds = []int{p.Second()}; len(r.bySecond) > 0 && !contains(r.bySecond, p.Second()) {
+			return nil
+		}
+	}
+	hours, minutes, seconds = sortedInts(hours), sortedInts(minutes), sortedInts(seconds)
+
+	var times []time.Time
+	for _, d := range days {
+		if !r.matchDay(d) {
+			continue
+		}
+		for _, h := range hours {
+			for _, m := range minutes {
+				for _, s := range seconds {
+					if h >= 0 && h < 24 && m >= 0 && m < 60 && s >= 0 && s < 60 {
+						times = append(times, time.Date(d.Year(), d.Month(), d.Day(), h, m, s, 0, time.UTC))
+					}
+				}
+			}
+		}
+	}
+	return times
+}
+
+// setPos keeps the times BYSETPOS picks, counting from 1 at the start of
+// the period or from -1 at its end.
+func (r *rule) setPos(times []time.Time) []time.Time {
+	if len(r.bySetPos) == 0 {
+		return times
+	}
+	var picked []time.Time
+	for i, t := range times {
+		if contains(r.bySetPos, i+1) || contains(r.bySetPos, i-len(times)) {
+			picked = append(picked, t)
+		}
+	}
+	return picked
+}
+
+// matchDay reports whether day d passes the rule's BYMONTH, BYWEEKNO,
+// BYYEARDAY, BYMONTHDAY and BYDAY parts.
+func (r *rule) matchDay(d time.Time) bool {
+	if len(r.byMonth) > 0 && !contains(r.byMonth, int(d.Month())) {
+		return false
+	}
+	if len(r.byWeekNo) > 0 && r.freq == model.FreqYearly {
+		n, weeks := weekNo(d, r.wkst)
+		if !contains(r.byWeekNo, n) && !contains(r.byWeekNo, n-weeks-1) {
+			return false
+		}
+	}
+	if len(r.byYearDay) > 0 {
+		days := time.Date(d.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		if !contains(r.byYearDay, d.YearDay()) && !contains(r.byYearDay, d.YearDay()-days-1) {
+			return false
+		}
+	}
+	if len(r.byMonthDay) > 0 && r.freq != model.FreqWeekly {
+		days := daysIn(d.Year(), d.Month())
+		if !contains(r.byMonthDay, d.Day()) && !contains(r.byMonthDay, d.Day()-days-1) {
+			return false
+		}
+	}
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, b := range r.byDay {
+		if b.wd != d.Weekday() {
+			continue
+		}
+		// a number counts within the month, or the year of a yearly rule
+		// without BYMONTH, and means nothing in other rules
+		var n, days int
+		switch {
+		case b.n == 0:
+			return true
+		case r.freq == model.FreqMonthly || r.freq == model.FreqYearly && len(r.byMonth) > 0:
+			n, days = d.Day(), daysIn(d.Year(), d.Month())
+		case r.freq == model.FreqYearly && len(r.byWeekNo) == 0:
+			n, days = d.YearDay(), time.Date(d.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		default:
+			return true
+		}
+		if b.n == (n-1)/7+1 || b.n == -((days-n)/7+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// weekNo returns the week d falls in, and how many weeks its year has.
+// Weeks start on wkst, and week 1 is the first with four days in the year.
+func weekNo(d time.Time, wkst time.Weekday) (int, int) {
+	week1 := func(year int) time.Time {
+		jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		off := (int(jan1.Weekday()) - int(wkst) + 7) % 7
+		if off <= 3 {
+			return jan1.AddDate(0, 0, -off)
+		}
+		return jan1.AddDate(0, 0, 7-off)
+	}
+	day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	year := d.Year()
+	if day.Before(week1(year)) {
+		year--
+	} else if !day.Before(week1(year + 1)) {
+		year++
+	}
+	first, next := week1(year), week1(year+1)
+	return int(day.Sub(first).Hours())/24/7 + 1, int(next.Sub(first).Hours()) / 24 / 7
+}
+
+// wallClock returns the instant loc shows as wall-clock time w. Of two
+// such instants, in the hour clocks go back, it takes the first; for a
+// time clocks skip, it takes the offset from before the skip.
+func wallClock(w time.Time, loc *time.Location) time.Time {
+	if loc == time.UTC {
+		return w
+	}
+	var found []time.Time
+	for _, probe := range []time.Duration{-24 * time.Hour, 24 * time.Hour} {
+		_, off := w.Add(probe).In(loc).Zone()
+		t := w.Add(-time.Duration(off) * time.Second).In(loc)
+		if t.Hour() == w.Hour() && t.Minute() == w.Minute() && t.Day() == w.Day() {
+			found = append(found, t)
+		}
+	}
+	switch {
+	case len(found) == 2 && found[1].Before(found[0]):
+		return found[1]
+	case len(found) > 0:
+		return found[0]
+	}
+	_, before := w.Add(-24 * time.Hour).In(loc).Zone()
+	return w.Add(-time.Duration(before) * time.Second).In(loc)
+}
+
+// parseWeekdayNum reads a BYDAY value such as MO, 2SU or -1FR.
+func parseWeekdayNum(s string) (weekdayNum, bool) {
+	if len(s) < 2 {
+		return weekdayNum{}, false
+	}
+	wd := weekdayIndex(model.Weekday(s[len(s)-2:]))
+	if wd < 0 {
+		return weekdayNum{}, false
+	}
+	var n int
+	if num := s[:len(s)-2]; num != "" {
+		var err error
+		if n, err = strconv.Atoi(num); err != nil || n == 0 {
+			return weekdayNum{}, false
+		}
+	}
+	return weekdayNum{n: n, wd: time.Weekday(wd)}, true
+}
+
+// weekdayIndex returns the time.Weekday of a two-letter iCalendar day, or
+// -1 for anything else.
+func weekdayIndex(d model.Weekday) int {
+	for i, name := range icalWeekdays {
+		if name == string(d) {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedInts(list []int) []int {
+	sorted := append([]int(nil), list...)
+	sort.Ints(sorted)
+	return sorted
+}
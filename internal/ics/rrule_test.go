@@ -0,0 +1,213 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// TestExpandRFC5545 runs the recurrence examples of RFC 5545 section
+// 3.8.5.3, all in America/New_York. want lists the first starts as local
+// wall-clock times; n, when set, is how many there are in all.
+func TestExpandRFC5545(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+	tests := []struct {
+		name    string
+		dtstart string
+		rrule   string
+		exdate  string
+		limit   int
+		n       int
+		want    string
+	}{
+		{"daily for 10", "19970902T0900", "FREQ=DAILY;COUNT=10", "", 0, 10,
+			"19970902T0900 19970903T0900 19970904T0900 19970905T0900 19970906T0900 19970907T0900 19970908T0900 19970909T0900 19970910T0900 19970911T0900"},
+		{"daily until", "19970902T0900", "FREQ=DAILY;UNTIL=19971224T000000Z", "", 0, 113,
+			"19970902T0900 19970903T0900"},
+		{"every other day", "19970902T0900", "FREQ=DAILY;INTERVAL=2", "", 4, 0,
+			"19970902T0900 19970904T0900 19970906T0900 19970908T0900"},
+		{"every 10 days", "19970902T0900", "FREQ=DAILY;INTERVAL=10;COUNT=5", "", 0, 5,
+			"19970902T0900 19970912T0900 19970922T0900 19971002T0900 19971012T0900"},
+		{"january yearly", "19980101T0900", "FREQ=YEARLY;UNTIL=20000131T140000Z;BYMONTH=1;BYDAY=SU,MO,TU,WE,TH,FR,SA", "", 0, 93,
+			"19980101T0900 19980102T0900"},
+		{"january daily", "19980101T0900", "FREQ=DAILY;UNTIL=20000131T140000Z;BYMONTH=1", "", 0, 93,
+			"19980101T0900 19980102T0900"},
+		{"weekly for 10", "19970902T0900", "FREQ=WEEKLY;COUNT=10", "", 0, 10,
+			"19970902T0900 19970909T0900 19970916T0900 19970923T0900 19970930T0900 19971007T0900 19971014T0900 19971021T0900 19971028T0900 19971104T0900"},
+		{"weekly until", "19970902T0900", "FREQ=WEEKLY;UNTIL=19971224T000000Z", "", 0, 17,
+			"19970902T0900 19970909T0900"},
+		{"every other week", "19970902T0900", "FREQ=WEEKLY;INTERVAL=2;WKST=SU", "", 10, 0,
+			"19970902T0900 19970916T0900 19970930T0900 19971014T0900 19971028T0900 19971111T0900 19971125T0900 19971209T0900 19971223T0900 19980106T0900"},
+		{"tuesday and thursday until", "19970902T0900", "FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH", "", 0, 10,
+			"19970902T0900 19970904T0900 19970909T0900 19970911T0900 19970916T0900 19970918T0900 19970923T0900 19970925T0900 19970930T0900 19971002T0900"},
+		{"tuesday and thursday count", "19970902T0900", "FREQ=WEEKLY;COUNT=10;WKST=SU;BYDAY=TU,TH", "", 0, 10,
+			"19970902T0900 19970904T0900 19970909T0900 19970911T0900 19970916T0900 19970918T0900 19970923T0900 19970925T0900 19970930T0900 19971002T0900"},
+		{"every other week mwf", "19970901T0900", "FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;WKST=SU;BYDAY=MO,WE,FR", "", 0, 25,
+			"19970901T0900 19970903T0900 19970905T0900 19970915T0900 19970917T0900 19970919T0900 19970929T0900 19971001T0900 19971003T0900 19971013T0900 19971015T0900 19971017T0900 19971027T0900 19971029T0900 19971031T0900 19971110T0900 19971112T0900 19971114T0900 19971124T0900 19971126T0900 19971128T0900 19971208T0900 19971210T0900 19971212T0900 19971222T0900"},
+		{"every other week tu th", "19970902T0900", "FREQ=WEEKLY;INTERVAL=2;COUNT=8;WKST=SU;BYDAY=TU,TH", "", 0, 8,
+			"19970902T0900 19970904T0900 19970916T0900 19970918T0900 19970930T0900 19971002T0900 19971014T0900 19971016T0900"},
+		{"first friday", "19970905T0900", "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", "", 0, 10,
+			"19970905T0900 19971003T0900 19971107T0900 19971205T0900 19980102T0900 19980206T0900 19980306T0900 19980403T0900 19980501T0900 19980605T0900"},
+		{"first friday until", "19970905T0900", "FREQ=MONTHLY;UNTIL=19971224T000000Z;BYDAY=1FR", "", 0, 4,
+			"19970905T0900 19971003T0900 19971107T0900 19971205T0900"},
+		{"first and last sunday", "19970907T0900", "FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU", "", 0, 10,
+			"19970907T0900 19970928T0900 19971102T0900 19971130T0900 19980104T0900 19980125T0900 19980301T0900 19980329T0900 19980503T0900 19980531T0900"},
+		{"second to last monday", "19970922T0900", "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO", "", 0, 6,
+			"19970922T0900 19971020T0900 19971117T0900 19971222T0900 19980119T0900 19980216T0900"},
+		{"third to last day", "19970928T0900", "FREQ=MONTHLY;BYMONTHDAY=-3", "", 6, 0,
+			"19970928T0900 19971029T0900 19971128T0900 19971229T0900 19980129T0900 19980226T0900"},
+		{"2nd and 15th", "19970902T0900", "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=2,15", "", 0, 10,
+			"19970902T0900 19970915T0900 19971002T0900 19971015T0900 19971102T0900 19971115T0900 19971202T0900 19971215T0900 19980102T0900 19980115T0900"},
+		{"first and last day", "19970930T0900", "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=1,-1", "", 0, 10,
+			"19970930T0900 19971001T0900 19971031T0900 19971101T0900 19971130T0900 19971201T0900 19971231T0900 19980101T0900 19980131T0900 19980201T0900"},
+		{"every 18 months", "19970910T0900", "FREQ=MONTHLY;INTERVAL=18;COUNT=10;BYMONTHDAY=10,11,12,13,14,15", "", 0, 10,
+			"19970910T0900 19970911T0900 19970912T0900 19970913T0900 19970914T0900 19970915T0900 19990310T0900 19990311T0900 19990312T0900 19990313T0900"},
+		{"tuesdays every other month", "19970902T0900", "FREQ=MONTHLY;INTERVAL=2;BYDAY=TU", "", 14, 0,
+			"19970902T0900 19970909T0900 19970916T0900 19970923T0900 19970930T0900 19971104T0900 19971111T0900 19971118T0900 19971125T0900 19980106T0900 19980113T0900 19980120T0900 19980127T0900 19980303T0900"},
+		{"june and july", "19970610T0900", "FREQ=YEARLY;COUNT=10;BYMONTH=6,7", "", 0, 10,
+			"19970610T0900 19970710T0900 19980610T0900 19980710T0900 19990610T0900 19990710T0900 20000610T0900 20000710T0900 20010610T0900 20010710T0900"},
+		{"first quarter every other year", "19970310T0900", "FREQ=YEARLY;INTERVAL=2;COUNT=10;BYMONTH=1,2,3", "", 0, 10,
+			"19970310T0900 19990110T0900 19990210T0900 19990310T0900 20010110T0900 20010210T0900 20010310T0900 20030110T0900 20030210T0900 20030310T0900"},
+		{"year days", "19970101T0900", "FREQ=YEARLY;INTERVAL=3;COUNT=10;BYYEARDAY=1,100,200", "", 0, 10,
+			"19970101T0900 19970410T0900 19970719T0900 20000101T0900 20000409T0900 20000718T0900 20030101T0900 20030410T0900 20030719T0900 20060101T0900"},
+		{"20th monday", "19970519T0900", "FREQ=YEARLY;BYDAY=20MO", "", 3, 0,
+			"19970519T0900 19980518T0900 19990517T0900"},
+		{"week 20 monday", "19970512T0900", "FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", "", 3, 0,
+			"19970512T0900 19980511T0900 19990517T0900"},
+		{"thursdays in march", "19970313T0900", "FREQ=YEARLY;BYMONTH=3;BYDAY=TH", "", 11, 0,
+			"19970313T0900 19970320T0900 19970327T0900 19980305T0900 19980312T0900 19980319T0900 19980326T0900 19990304T0900 19990311T0900 19990318T0900 19990325T0900"},
+		{"summer thursdays", "19970605T0900", "FREQ=YEARLY;BYDAY=TH;BYMONTH=6,7,8", "", 14, 0,
+			"19970605T0900 19970612T0900 19970619T0900 19970626T0900 19970703T0900 19970710T0900 19970717T0900 19970724T0900 19970731T0900 19970807T0900 19970814T0900 19970821T0900 19970828T0900 19980604T0900"},
+		{"friday the 13th", "19970902T0900", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "19970902T0900", 5, 0,
+			"19980213T0900 19980313T0900 19981113T0900 19990813T0900 20001013T0900"},
+		{"saturday after first sunday", "19970913T0900", "FREQ=MONTHLY;BYDAY=SA;BYMONTHDAY=7,8,9,10,11,12,13", "", 10, 0,
+			"19970913T0900 19971011T0900 19971108T0900 19971213T0900 19980110T0900 19980207T0900 19980307T0900 19980411T0900 19980509T0900 19980613T0900"},
+		{"election day", "19961105T0900", "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", "", 3, 0,
+			"19961105T0900 20001107T0900 20041102T0900"},
+		{"third tu we th", "19970904T0900", "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", "", 0, 3,
+			"19970904T0900 19971007T0900 19971106T0900"},
+		{"second to last weekday", "19970929T0900", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2", "", 7, 0,
+			"19970929T0900 19971030T0900 19971127T0900 19971230T0900 19980129T0900 19980226T0900 19980330T0900"},
+		// the RFC's UNTIL here is 170000Z, which its own listing contradicts
+		{"every 3 hours", "19970902T0900", "FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T210000Z", "", 0, 3,
+			"19970902T0900 19970902T1200 19970902T1500"},
+		{"every 15 minutes", "19970902T0900", "FREQ=MINUTELY;INTERVAL=15;COUNT=6", "", 0, 6,
+			"19970902T0900 19970902T0915 19970902T0930 19970902T0945 19970902T1000 19970902T1015"},
+		{"every 90 minutes", "19970902T0900", "FREQ=MINUTELY;INTERVAL=90;COUNT=4", "", 0, 4,
+			"19970902T0900 19970902T1030 19970902T1200 19970902T1330"},
+		{"every 20 minutes daily", "19970902T0900", "FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40", "", 26, 0,
+			"19970902T0900 19970902T0920 19970902T0940 19970902T1000 19970902T1020 19970902T1040 19970902T1100 19970902T1120 19970902T1140 19970902T1200 19970902T1220 19970902T1240 19970902T1300 19970902T1320 19970902T1340 19970902T1400 19970902T1420 19970902T1440 19970902T1500 19970902T1520 19970902T1540 19970902T1600 19970902T1620 19970902T1640 19970903T0900 19970903T0920"},
+		{"every 20 minutes minutely", "19970902T0900", "FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16", "", 26, 0,
+			"19970902T0900 19970902T0920 19970902T0940 19970902T1000 19970902T1020 19970902T1040 19970902T1100 19970902T1120 19970902T1140 19970902T1200 19970902T1220 19970902T1240 19970902T1300 19970902T1320 19970902T1340 19970902T1400 19970902T1420 19970902T1440 19970902T1500 19970902T1520 19970902T1540 19970902T1600 19970902T1620 19970902T1640 19970903T0900 19970903T0920"},
+		{"week start monday", "19970805T0900", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO", "", 0, 4,
+			"19970805T0900 19970810T0900 19970819T0900 19970824T0900"},
+		{"week start sunday", "19970805T0900", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", "", 0, 4,
+			"19970805T0900 19970817T0900 19970819T0900 19970831T0900"},
+		{"invalid dates skipped", "20070115T0900", "FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", "", 0, 5,
+			"20070115T0900 20070130T0900 20070215T0900 20070315T0900 20070330T0900"},
+	}
+
+	const layout = "20060102T1504"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtstart, err := time.ParseInLocation(layout, tt.dtstart, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec, err := parseRRule(tt.rrule)
+			if err != nil {
+				t.Fatalf("parseRRule: %v", err)
+			}
+			if tt.exdate != "" {
+				ex, _ := time.ParseInLocation(layout, tt.exdate, ny)
+				rec.ExDates = []time.Time{ex}
+			}
+			starts := Expand(rec, dtstart, time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), tt.limit)
+			var got []string
+			for _, s := range starts {
+				got = append(got, s.In(ny).Format(layout))
+			}
+			want := strings.Fields(tt.want)
+			if len(got) < len(want) || strings.Join(got[:len(want)], " ") != tt.want {
+				t.Errorf("got %v\nwant %s", got, tt.want)
+			}
+			if tt.n > 0 && len(got) != tt.n {
+				t.Errorf("got %d occurrences, want %d", len(got), tt.n)
+			}
+		})
+	}
+}
+
+func TestExpandWallClock(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+	daily := func(start time.Time) string {
+		count := 3
+		var got []string
+		for _, s := range Expand(&model.Recurrence{Freq: model.FreqDaily, Count: &count}, start, time.Time{}, 0) {
+			got = append(got, s.UTC().Format("01-02 15:04"))
+		}
+		return strings.Join(got, ",")
+	}
+
+	// 09:00 stays 09:00 across the change; 02:30 is skipped on March 8 and
+	// takes the offset from before; 01:30 on November 1 is the first one
+	if got := daily(time.Date(2026, 3, 7, 9, 0, 0, 0, ny)); got != "03-07 14:00,03-08 13:00,03-09 13:00" {
+		t.Errorf("09:00 = %s", got)
+	}
+	if got := daily(time.Date(2026, 3, 7, 2, 30, 0, 0, ny)); got != "03-07 07:30,03-08 07:30,03-09 06:30" {
+		t.Errorf("02:30 = %s", got)
+	}
+	if got := daily(time.Date(2026, 10, 31, 1, 30, 0, 0, ny)); got != "10-31 05:30,11-01 05:30,11-02 06:30" {
+		t.Errorf("01:30 = %s", got)
+	}
+}
+
+func TestExpandDatesAndLimits(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	count := 4
+	rec := &model.Recurrence{
+		Freq: model.FreqWeekly, Count: &count,
+		// COUNT includes the cancelled occurrence; a date-only EXDATE
+		// cancels the day
+		ExDates: []time.Time{start.AddDate(0, 0, 7), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		RDates:  []time.Time{start.AddDate(0, 0, 3), start.AddDate(0, 0, 14), start.AddDate(0, 0, -1)},
+	}
+	var got []string
+	for _, s := range Expand(rec, start, time.Time{}, 0) {
+		got = append(got, s.Format("01-02"))
+	}
+	if strings.Join(got, ",") != "03-02,03-05,03-23" {
+		t.Errorf("got %v", got)
+	}
+	if got := Expand(rec, start, time.Time{}, 2); len(got) != 2 {
+		t.Errorf("limit: got %v", got)
+	}
+	if got := Expand(rec, start, start.AddDate(0, 0, 5), 0); len(got) != 2 {
+		t.Errorf("end: got %v", got)
+	}
+}
+
+func TestRRuleAllParts(t *testing.T) {
+	value := "FREQ=YEARLY;BYMONTH=3;BYSETPOS=-1;BYWEEKNO=10,-1;BYYEARDAY=60;BYHOUR=9,17;BYMINUTE=30;BYSECOND=0;WKST=SU"
+	rec, err := parseRRule(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.WeekStart != model.WeekdaySU || len(rec.ByWeekNo) != 2 || rec.ByWeekNo[1] != -1 || len(rec.ByHour) != 2 || rec.ByYearDay[0] != 60 {
+		t.Errorf("parsed %+v", rec)
+	}
+	if again, _ := parseRRule(FormatRRule(rec)); FormatRRule(again) != FormatRRule(rec) || !strings.Contains(FormatRRule(rec), "BYWEEKNO=10,-1") {
+		t.Errorf("round trip = %s", FormatRRule(rec))
+	}
+	if _, err := parseRRule("FREQ=DAILY;WKST=XX"); err == nil {
+		t.Error("expected an error for a bad WKST")
+	}
+}
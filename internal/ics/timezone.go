@@ -0,0 +1,467 @@
+package ics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/gongahkia/salja/internal/tzmap"
+)
+
+// Strict clients such as Outlook need a VTIMEZONE for every TZID a file
+// uses, and files from them name zones their own way. The writer generates
+// the definitions from Go's zone database; the parser maps each TZID back
+// to an IANA name, by name where it can and otherwise by finding a zone
+// whose offsets match the file's definition.
+
+var icalWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// addTimezones puts a VTIMEZONE before the other components of cal for
+// each IANA zone their TZID parameters name, covering the years the
+// values fall in.
+func addTimezones(cal *ical.Calendar) {
+	type span struct{ from, to int }
+	used := make(map[string]*span)
+	var walk func(comps []*ical.Component)
+	walk = func(comps []*ical.Component) {
+		for _, comp := range comps {
+			for _, props := range comp.Props {
+				for _, prop := range props {
+					tzid := prop.Params.Get(ical.PropTimezoneID)
+					if tzid == "" {
+						continue
+					}
+					for _, v := range strings.Split(prop.Value, ",") {
+						year, err := strconv.Atoi(v[:min(4, len(v))])
+						if err != nil {
+							continue
+						}
+						if s, ok := used[tzid]; !ok {
+							used[tzid] = &span{year, year}
+						} else {
+							s.from, s.to = min(s.from, year), max(s.to, year)
+						}
+					}
+				}
+			}
+			walk(comp.Children)
+		}
+	}
+	walk(cal.Children)
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var zones []*ical.Component
+	for _, name := range names {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+		zones = append(zones, vtimezone(name, loc, used[name].from-1, used[name].to+1))
+	}
+	cal.Children = append(zones, cal.Children...)
+}
+
+// transition is a change of offset in a zone, at an instant.
+type transition struct {
+	at       time.Time
+	from, to int
+	name     string
+	dst      bool
+}
+
+// wall is the local time the transition happens at, on the clock before it.
+func (t transition) wall() time.Time {
+	return t.at.Add(time.Duration(t.from) * time.Second).UTC()
+}
+
+// zoneTransitions finds the offset changes of loc from the start of year
+// from to the end of year to, to the second.
+func zoneTransitions(loc *time.Location, from, to int) []transition {
+	var out []transition
+	t := time.Date(from, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, off := t.In(loc).Zone()
+	dst := t.In(loc).IsDST()
+	for t.Before(end) {
+		next := t.Add(24 * time.Hour)
+		_, nextOff := next.In(loc).Zone()
+		if nextOff != off || next.In(loc).IsDST() != dst {
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.In(loc).Zone(); o == off && mid.In(loc).IsDST() == dst {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			name, to := hi.In(loc).Zone()
+			out = append(out, transition{at: hi, from: off, to: to, name: name, dst: hi.In(loc).IsDST()})
+			off, dst = to, hi.In(loc).IsDST()
+		}
+		t = next
+	}
+	return out
+}
+
+// vtimezone describes loc from year from to year to. Zones that change
+// offset by one yearly weekday rule get a STANDARD and a DAYLIGHT
+// observance with RRULEs, which also cover later years; others get one
+// observance per change, the last of which holds after it.
+func vtimezone(name string, loc *time.Location, from, to int) *ical.Component {
+	comp := ical.NewComponent(ical.CompTimezone)
+	comp.Props.SetText(ical.PropTimezoneID, name)
+
+	trans := zoneTransitions(loc, from, to)
+	if len(trans) == 0 {
+		abbr, off := time.Date(from, 1, 1, 0, 0, 0, 0, loc).Zone()
+		comp.Children = append(comp.Children, observance(transition{
+			at:   time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(off) * time.Second),
+			from: off, to: off, name: abbr,
+		}, ""))
+		return comp
+	}
+
+	var std, dst []transition
+	for _, t := range trans {
+		if t.dst {
+			dst = append(dst, t)
+		} else {
+			std = append(std, t)
+		}
+	}
+	stdRule, stdOK := yearlyRule(std)
+	dstRule, dstOK := yearlyRule(dst)
+	if stdOK && dstOK && len(std)+len(dst) == len(trans) {
+		comp.Children = append(comp.Children, observance(dst[0], dstRule), observance(std[0], stdRule))
+		return comp
+	}
+	for _, t := range trans {
+		comp.Children = append(comp.Children, observance(t, ""))
+	}
+	return comp
+}
+
+// yearlyRule returns the RRULE that yields every transition in trans, one a
+// year, when they share a month, weekday, time and offsets.
+func yearlyRule(trans []transition) (string, bool) {
+	if len(trans) < 2 {
+		return "", false
+	}
+	first := trans[0].wall()
+	nth, last := true, true
+	for i, t := range trans {
+		w := t.wall()
+		if w.Year() != first.Year()+i || w.Month() != first.Month() || w.Weekday() != first.Weekday() ||
+			w.Hour() != first.Hour() || w.Minute() != first.Minute() || w.Second() != first.Second() ||
+			t.from != trans[0].from || t.to != trans[0].to || t.name != trans[0].name {
+			return "", false
+		}
+		nth = nth && (w.Day()-1)/7 == (first.Day()-1)/7
+		last = last && w.AddDate(0, 0, 7).Month() != w.Month()
+	}
+	pos := (first.Day()-1)/7 + 1
+	switch {
+	case nth && pos < 5:
+	case last:
+		pos = -1
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", int(first.Month()), pos, icalWeekdays[first.Weekday()]), true
+}
+
+func observance(t transition, rrule string) *ical.Component {
+	kind := ical.CompTimezoneStandard
+	if t.dst {
+		kind = ical.CompTimezoneDaylight
+	}
+	comp := ical.NewComponent(kind)
+	setValue(comp.Props, ical.PropDateTimeStart, t.wall().Format("20060102T150405"))
+	setValue(comp.Props, ical.PropTimezoneOffsetFrom, formatUTCOffset(t.from))
+	setValue(comp.Props, ical.PropTimezoneOffsetTo, formatUTCOffset(t.to))
+	if t.name != "" {
+		comp.Props.SetText(ical.PropTimezoneName, t.name)
+	}
+	if rrule != "" {
+		setValue(comp.Props, ical.PropRecurrenceRule, rrule)
+	}
+	return comp
+}
+
+func formatUTCOffset(secs int) string {
+	sign := "+"
+	if secs < 0 {
+		sign, secs = "-", -secs
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, secs/3600, secs/60%60)
+	if secs%60 != 0 {
+		s += fmt.Sprintf("%02d", secs%60)
+	}
+	return s
+}
+
+func parseUTCOffset(v string) (int, bool) {
+	v = strings.TrimSpace(v)
+	if len(v) != 5 && len(v) != 7 || (v[0] != '+' && v[0] != '-') {
+		return 0, false
+	}
+	n := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if 1+2*i >= len(v) {
+			break
+		}
+		d, err := strconv.Atoi(v[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, false
+		}
+		n += d * unit
+	}
+	if v[0] == '-' {
+		n = -n
+	}
+	return n, true
+}
+
+// resolveTimezones rewrites the TZID parameters of cal's components to IANA
+// names, using the VTIMEZONEs cal defines for names Go cannot load. TZIDs
+// nothing matches are left as they are.
+func resolveTimezones(cal *ical.Calendar) {
+	defs := make(map[string]*ical.Component)
+	for _, comp := range cal.Children {
+		if comp.Name != ical.CompTimezone {
+			continue
+		}
+		// Outlook leaves the commas of its zone names unescaped
+		if id := comp.Props.Get(ical.PropTimezoneID); id != nil {
+			defs[id.Value] = comp
+		}
+	}
+
+	resolved := make(map[string]string)
+	var walk func(comps []*ical.Component)
+	walk = func(comps []*ical.Component) {
+		for _, comp := range comps {
+			if comp.Name == ical.CompTimezone {
+				continue
+			}
+			for _, props := range comp.Props {
+				for _, prop := range props {
+					tzid := prop.Params.Get(ical.PropTimezoneID)
+					if tzid == "" {
+						continue
+					}
+					name, ok := resolved[tzid]
+					if !ok {
+						name = ianaName(tzid, defs[tzid])
+						resolved[tzid] = name
+					}
+					if name != "" && name != tzid {
+						prop.Params.Set(ical.PropTimezoneID, name)
+					}
+				}
+			}
+			walk(comp.Children)
+		}
+	}
+	walk(cal.Children)
+}
+
+// ianaName maps a TZID to an IANA name: as a zone or Windows name, through
+// the X-LIC-LOCATION some writers add, as a path ending in a zone name such
+// as /mozilla.org/20050126_1/Europe/Berlin, or by its definition.
+func ianaName(tzid string, def *ical.Component) string {
+	if name, ok := tzmap.ToIANA(tzid); ok {
+		return name
+	}
+	if def != nil {
+		if loc, err := def.Props.Text("X-LIC-LOCATION"); err == nil {
+			if name, ok := tzmap.ToIANA(loc); ok {
+				return name
+			}
+		}
+	}
+	if parts := strings.Split(strings.Trim(tzid, "/"), "/"); len(parts) > 1 {
+		for i := 1; i < len(parts); i++ {
+			if name, ok := tzmap.ToIANA(strings.Join(parts[i:], "/")); ok && strings.Contains(name, "/") {
+				return name
+			}
+		}
+	}
+	if def != nil {
+		return matchDefinition(def)
+	}
+	return ""
+}
+
+// onset is when an observance of a VTIMEZONE starts applying.
+type onset struct {
+	at     time.Time
+	offset int
+}
+
+// matchDefinition finds the IANA zone whose offsets agree with def on every
+// day of the latest year it describes, which is this year for rules
+// without an end, and the year before.
+func matchDefinition(def *ical.Component) string {
+	year := 0
+	for _, obs := range def.Children {
+		if p := obs.Props.Get(ical.PropDateTimeStart); p != nil && len(p.Value) >= 4 {
+			if y, err := strconv.Atoi(p.Value[:4]); err == nil {
+				year = max(year, y)
+			}
+		}
+		if rrule := obs.Props.Get(ical.PropRecurrenceRule); rrule != nil && !strings.Contains(rrule.Value, "UNTIL=") {
+			year = max(year, time.Now().Year())
+		}
+	}
+	if year == 0 {
+		return ""
+	}
+	onsets := definitionOnsets(def, year-1, year)
+	if len(onsets) == 0 {
+		return ""
+	}
+
+	// days before the definition's first observance say nothing
+	start := time.Date(year-1, 1, 1, 12, 0, 0, 0, time.UTC)
+	for start.Before(onsets[0].at) {
+		start = start.AddDate(0, 0, 1)
+	}
+	var want []int
+	for d := start; d.Year() <= year; d = d.AddDate(0, 0, 1) {
+		off := onsets[0].offset
+		for _, o := range onsets {
+			if o.at.After(d) {
+				break
+			}
+			off = o.offset
+		}
+		want = append(want, off)
+	}
+
+candidates:
+	for _, name := range tzmap.Candidates() {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+		i := 0
+		for d := start; d.Year() <= year; d = d.AddDate(0, 0, 1) {
+			if _, off := d.In(loc).Zone(); off != want[i] {
+				continue candidates
+			}
+			i++
+		}
+		return name
+	}
+	return ""
+}
+
+// definitionOnsets lists, in order, the starts of def's observances from
+// year from to year to.
+func definitionOnsets(def *ical.Component, from, to int) []onset {
+	var onsets []onset
+	for _, obs := range def.Children {
+		dtstart := obs.Props.Get(ical.PropDateTimeStart)
+		fromProp, toProp := obs.Props.Get(ical.PropTimezoneOffsetFrom), obs.Props.Get(ical.PropTimezoneOffsetTo)
+		if dtstart == nil || fromProp == nil || toProp == nil {
+			continue
+		}
+		wall, err := time.Parse("20060102T150405", dtstart.Value)
+		if err != nil {
+			continue
+		}
+		offFrom, ok1 := parseUTCOffset(fromProp.Value)
+		offTo, ok2 := parseUTCOffset(toProp.Value)
+		if !ok1 || !ok2 {
+			continue
+		}
+		add := func(w time.Time) {
+			onsets = append(onsets, onset{at: w.Add(-time.Duration(offFrom) * time.Second), offset: offTo})
+		}
+
+		add(wall)
+		for _, rdate := range obs.Props.Values(ical.PropRecurrenceDates) {
+			for _, v := range strings.Split(rdate.Value, ",") {
+				if w, err := time.Parse("20060102T150405", v); err == nil {
+					add(w)
+				}
+			}
+		}
+		rrule := obs.Props.Get(ical.PropRecurrenceRule)
+		if rrule == nil {
+			continue
+		}
+		rec, err := parseRRule(rrule.Value)
+		if err != nil || rec.Freq != "YEARLY" {
+			continue
+		}
+		month := wall.Month()
+		if len(rec.ByMonth) > 0 {
+			month = time.Month(rec.ByMonth[0])
+		}
+		for y := max(from, wall.Year()+1); y <= to; y++ {
+			day := time.Date(y, month, wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC)
+			if len(rec.ByDay) > 0 {
+				d, ok := nthWeekday(y, month, string(rec.ByDay[0]))
+				if !ok {
+					continue
+				}
+				day = time.Date(y, month, d, wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC)
+			} else if len(rec.ByMonthDay) > 0 {
+				day = time.Date(y, month, rec.ByMonthDay[0], wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC)
+			}
+			if rec.Until != nil && day.After(*rec.Until) {
+				break
+			}
+			add(day)
+		}
+	}
+	sort.SliceStable(onsets, func(i, j int) bool { return onsets[i].at.Before(onsets[j].at) })
+	return onsets
+}
+
+// nthWeekday returns the day of month of a BYDAY value such as 2SU or -1SU.
+func nthWeekday(year int, month time.Month, byday string) (int, bool) {
+	if len(byday) < 2 {
+		return 0, false
+	}
+	code := byday[len(byday)-2:]
+	wd := -1
+	for i, name := range icalWeekdays {
+		if name == code {
+			wd = i
+		}
+	}
+	n := 1
+	if num := byday[:len(byday)-2]; num != "" {
+		var err error
+		if n, err = strconv.Atoi(num); err != nil || n == 0 {
+			return 0, false
+		}
+	}
+	if wd < 0 {
+		return 0, false
+	}
+	if n > 0 {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		d := 1 + (wd-int(first.Weekday())+7)%7 + 7*(n-1)
+		return d, d <= daysIn(year, month)
+	}
+	lastDay := daysIn(year, month)
+	last := time.Date(year, month, lastDay, 0, 0, 0, 0, time.UTC)
+	d := lastDay - (int(last.Weekday())-wd+7)%7 + 7*(n+1)
+	return d, d >= 1
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
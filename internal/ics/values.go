@@ -0,0 +1,428 @@
+package ics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+// jCal (RFC 7265) and xCal (RFC 6321) carry the same properties as ICS but
+// give each value a type and a structured form. The helpers here convert
+// between an ical.Prop and that typed form, which both syntaxes share:
+// each value is a string, int, float64, bool, recur or period.
+
+// recur is a structured RRULE with its parts in their original order.
+type recur []recurPart
+
+type recurPart struct {
+	name   string        // lowercase rule part, e.g. "byday"
+	values []interface{} // strings, or ints for the numeric parts
+}
+
+// period is a PERIOD value; end is a date-time or a duration.
+type period struct {
+	start, end string
+}
+
+func (p period) hasDuration() bool {
+	return strings.HasPrefix(p.end, "P") || strings.HasPrefix(p.end, "+") || strings.HasPrefix(p.end, "-")
+}
+
+// multiTextProps are the text properties whose value is a comma-separated list.
+var multiTextProps = map[string]bool{"CATEGORIES": true, "RESOURCES": true}
+
+var numericRecurParts = map[string]bool{
+	"count": true, "interval": true, "bysecond": true, "byminute": true, "byhour": true,
+	"bymonthday": true, "byyearday": true, "byweekno": true, "bymonth": true, "bysetpos": true,
+}
+
+// propType returns the lowercase value type of prop, "unknown" if it has no
+// VALUE parameter and no default type.
+func propType(prop *ical.Prop) string {
+	t := ical.ValueType(strings.ToUpper(prop.Params.Get(ical.ParamValue)))
+	if t == ical.ValueDefault {
+		t = prop.ValueType()
+	}
+	if t == ical.ValueDefault {
+		return "unknown"
+	}
+	return strings.ToLower(string(t))
+}
+
+// exportParams returns the parameters of prop other than VALUE, which the
+// typed form carries as the value type instead.
+func exportParams(prop *ical.Prop) map[string][]string {
+	params := make(map[string][]string, len(prop.Params))
+	for name, values := range prop.Params {
+		if strings.EqualFold(name, ical.ParamValue) {
+			continue
+		}
+		params[strings.ToLower(name)] = values
+	}
+	return params
+}
+
+// typedValues splits the value of prop into its typed values.
+func typedValues(prop *ical.Prop) (string, []interface{}, error) {
+	typ := propType(prop)
+	var values []interface{}
+	fail := func(err error) (string, []interface{}, error) {
+		return "", nil, fmt.Errorf("%s: %w", prop.Name, err)
+	}
+
+	switch typ {
+	case "text":
+		list, err := prop.TextList()
+		if err != nil {
+			return fail(err)
+		}
+		if !multiTextProps[prop.Name] {
+			list = []string{strings.Join(list, ",")}
+		}
+		for _, s := range list {
+			values = append(values, s)
+		}
+	case "date", "date-time", "time":
+		for _, v := range strings.Split(prop.Value, ",") {
+			s, err := isoDateTime(typ, v)
+			if err != nil {
+				return fail(err)
+			}
+			values = append(values, s)
+		}
+	case "period":
+		for _, v := range strings.Split(prop.Value, ",") {
+			start, end, ok := strings.Cut(v, "/")
+			if !ok {
+				return fail(fmt.Errorf("invalid period %q", v))
+			}
+			p := period{end: end}
+			var err error
+			if p.start, err = isoDateTime("date-time", start); err != nil {
+				return fail(err)
+			}
+			if !p.hasDuration() {
+				if p.end, err = isoDateTime("date-time", end); err != nil {
+					return fail(err)
+				}
+			}
+			values = append(values, p)
+		}
+	case "recur":
+		r, err := parseRecur(prop.Value)
+		if err != nil {
+			return fail(err)
+		}
+		values = append(values, r)
+	case "integer":
+		for _, v := range strings.Split(prop.Value, ",") {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fail(fmt.Errorf("invalid integer %q", v))
+			}
+			values = append(values, n)
+		}
+	case "float":
+		sep := ","
+		if prop.Name == ical.PropGeo {
+			sep = ";"
+		}
+		for _, v := range strings.Split(prop.Value, sep) {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fail(fmt.Errorf("invalid float %q", v))
+			}
+			values = append(values, f)
+		}
+	case "boolean":
+		b, err := strconv.ParseBool(strings.ToLower(prop.Value))
+		if err != nil {
+			return fail(fmt.Errorf("invalid boolean %q", prop.Value))
+		}
+		values = append(values, b)
+	case "utc-offset":
+		s, err := isoOffset(prop.Value)
+		if err != nil {
+			return fail(err)
+		}
+		values = append(values, s)
+	default:
+		// duration, uri, cal-address, binary and unknown keep the ICS form
+		values = append(values, prop.Value)
+	}
+	return typ, values, nil
+}
+
+// setTypedValues is the inverse of typedValues.
+func setTypedValues(prop *ical.Prop, typ string, values []interface{}) error {
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", prop.Name, err)
+	}
+	strs := func() ([]string, error) {
+		out := make([]string, 0, len(values))
+		for _, v := range values {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a %s value, got %v", typ, v)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+
+	var parts []string
+	switch typ {
+	case "text":
+		list, err := strs()
+		if err != nil {
+			return fail(err)
+		}
+		prop.SetTextList(list)
+		return nil
+	case "date", "date-time", "time":
+		list, err := strs()
+		if err != nil {
+			return fail(err)
+		}
+		for _, s := range list {
+			v, err := icsDateTime(typ, s)
+			if err != nil {
+				return fail(err)
+			}
+			parts = append(parts, v)
+		}
+	case "period":
+		for _, v := range values {
+			p, ok := v.(period)
+			if !ok {
+				return fail(fmt.Errorf("expected a period value, got %v", v))
+			}
+			start, err := icsDateTime("date-time", p.start)
+			if err != nil {
+				return fail(err)
+			}
+			end := p.end
+			if !p.hasDuration() {
+				if end, err = icsDateTime("date-time", p.end); err != nil {
+					return fail(err)
+				}
+			}
+			parts = append(parts, start+"/"+end)
+		}
+	case "recur":
+		if len(values) != 1 {
+			return fail(fmt.Errorf("expected one recur value, got %d", len(values)))
+		}
+		r, ok := values[0].(recur)
+		if !ok {
+			return fail(fmt.Errorf("expected a recur value, got %v", values[0]))
+		}
+		s, err := formatRecur(r)
+		if err != nil {
+			return fail(err)
+		}
+		parts = append(parts, s)
+	case "integer":
+		for _, v := range values {
+			n, ok := v.(int)
+			if !ok {
+				return fail(fmt.Errorf("expected an integer value, got %v", v))
+			}
+			parts = append(parts, strconv.Itoa(n))
+		}
+	case "float":
+		for _, v := range values {
+			f, ok := v.(float64)
+			if !ok {
+				return fail(fmt.Errorf("expected a float value, got %v", v))
+			}
+			parts = append(parts, strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	case "boolean":
+		if len(values) != 1 {
+			return fail(fmt.Errorf("expected one boolean value, got %d", len(values)))
+		}
+		b, ok := values[0].(bool)
+		if !ok {
+			return fail(fmt.Errorf("expected a boolean value, got %v", values[0]))
+		}
+		parts = append(parts, strings.ToUpper(strconv.FormatBool(b)))
+	case "utc-offset":
+		list, err := strs()
+		if err != nil || len(list) != 1 {
+			return fail(fmt.Errorf("expected one utc-offset value"))
+		}
+		v, err := icsOffset(list[0])
+		if err != nil {
+			return fail(err)
+		}
+		parts = append(parts, v)
+	default:
+		list, err := strs()
+		if err != nil {
+			return fail(err)
+		}
+		parts = list
+	}
+
+	sep := ","
+	if prop.Name == ical.PropGeo {
+		sep = ";"
+	}
+	prop.Value = strings.Join(parts, sep)
+	if typ != "unknown" {
+		prop.SetValueType(ical.ValueType(strings.ToUpper(typ)))
+	}
+	return nil
+}
+
+// isoDateTime converts an ICS date, date-time or time to the extended
+// ISO 8601 form jCal and xCal use, e.g. 20260301T090000Z to
+// 2026-03-01T09:00:00Z.
+func isoDateTime(typ, v string) (string, error) {
+	utc := strings.HasSuffix(v, "Z")
+	v = strings.TrimSuffix(v, "Z")
+	var t time.Time
+	var err error
+	var out string
+	switch typ {
+	case "date":
+		t, err = time.Parse("20060102", v)
+		out = t.Format("2006-01-02")
+	case "time":
+		t, err = time.Parse("150405", v)
+		out = t.Format("15:04:05")
+	default:
+		t, err = time.Parse("20060102T150405", v)
+		out = t.Format("2006-01-02T15:04:05")
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q", typ, v)
+	}
+	if utc {
+		out += "Z"
+	}
+	return out, nil
+}
+
+// icsDateTime is the inverse of isoDateTime.
+func icsDateTime(typ, v string) (string, error) {
+	utc := strings.HasSuffix(v, "Z")
+	v = strings.TrimSuffix(v, "Z")
+	var t time.Time
+	var err error
+	var out string
+	switch typ {
+	case "date":
+		t, err = time.Parse("2006-01-02", v)
+		out = t.Format("20060102")
+	case "time":
+		t, err = time.Parse("15:04:05", v)
+		out = t.Format("150405")
+	default:
+		t, err = time.Parse("2006-01-02T15:04:05", v)
+		out = t.Format("20060102T150405")
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q", typ, v)
+	}
+	if utc {
+		out += "Z"
+	}
+	return out, nil
+}
+
+// isoOffset converts an ICS UTC offset such as -0500 to -05:00.
+func isoOffset(v string) (string, error) {
+	if (len(v) != 5 && len(v) != 7) || (v[0] != '+' && v[0] != '-') {
+		return "", fmt.Errorf("invalid utc-offset %q", v)
+	}
+	out := v[:3] + ":" + v[3:5]
+	if len(v) == 7 {
+		out += ":" + v[5:]
+	}
+	return out, nil
+}
+
+// icsOffset is the inverse of isoOffset.
+func icsOffset(v string) (string, error) {
+	out := strings.ReplaceAll(v, ":", "")
+	if (len(out) != 5 && len(out) != 7) || (out[0] != '+' && out[0] != '-') {
+		return "", fmt.Errorf("invalid utc-offset %q", v)
+	}
+	return out, nil
+}
+
+// parseRecur splits an RRULE value into its parts, converting UNTIL to the
+// typed date form and numeric parts to ints.
+func parseRecur(value string) (recur, error) {
+	var r recur
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recur part %q", part)
+		}
+		rp := recurPart{name: strings.ToLower(key)}
+		for _, v := range strings.Split(val, ",") {
+			switch {
+			case rp.name == "until":
+				typ := "date-time"
+				if len(v) == len("20060102") {
+					typ = "date"
+				}
+				s, err := isoDateTime(typ, v)
+				if err != nil {
+					return nil, err
+				}
+				rp.values = append(rp.values, s)
+			case numericRecurParts[rp.name]:
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("invalid recur %s %q", key, v)
+				}
+				rp.values = append(rp.values, n)
+			default:
+				rp.values = append(rp.values, v)
+			}
+		}
+		r = append(r, rp)
+	}
+	return r, nil
+}
+
+// formatRecur is the inverse of parseRecur.
+func formatRecur(r recur) (string, error) {
+	parts := make([]string, 0, len(r))
+	for _, rp := range r {
+		vals := make([]string, 0, len(rp.values))
+		for _, v := range rp.values {
+			switch v := v.(type) {
+			case int:
+				vals = append(vals, strconv.Itoa(v))
+			case string:
+				if rp.name == "until" {
+					typ := "date-time"
+					if len(v) == len("2006-01-02") {
+						typ = "date"
+					}
+					s, err := icsDateTime(typ, v)
+					if err != nil {
+						return "", err
+					}
+					v = s
+				}
+				vals = append(vals, v)
+			default:
+				return "", fmt.Errorf("invalid recur %s value %v", rp.name, v)
+			}
+		}
+		parts = append(parts, strings.ToUpper(rp.name)+"="+strings.Join(vals, ","))
+	}
+	return strings.Join(parts, ";"), nil
+}
@@ -29,6 +29,14 @@ func (w *Writer) WriteFile(ctx context.Context, collection *model.CalendarCollec
 }
 
 func (w *Writer) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	enc := ical.NewEncoder(writer)
+	return enc.Encode(w.Calendar(collection))
+}
+
+// Calendar builds the iCalendar object for collection, with a VTIMEZONE for
+// each zone it names. It is shared by the formats that carry iCalendar data
+// in another syntax.
+func (w *Writer) Calendar(collection *model.CalendarCollection) *ical.Calendar {
 	cal := ical.NewCalendar()
 	cal.Props.SetText(ical.PropVersion, "2.0")
 	cal.Props.SetText(ical.PropProductID, "-//gongahkia//salja//EN")
@@ -49,11 +57,35 @@ func (w *Writer) Write(ctx context.Context, collection *model.CalendarCollection
 
 		if comp != nil {
 			cal.Children = append(cal.Children, comp)
+			cal.Children = append(cal.Children, w.writeOverrides(&item)...)
 		}
 	}
 
-	enc := ical.NewEncoder(writer)
-	return enc.Encode(cal)
+	addTimezones(cal)
+	return cal
+}
+
+// writeOverrides writes the changed occurrences of a recurring item as
+// components sharing its UID, each naming the occurrence by RECURRENCE-ID.
+func (w *Writer) writeOverrides(item *model.CalendarItem) []*ical.Component {
+	if item.Recurrence == nil || item.ItemType == model.ItemTypeJournal {
+		return nil
+	}
+	var comps []*ical.Component
+	for _, ov := range item.Recurrence.Overrides {
+		occurrence := ov.Item
+		occurrence.UID = item.UID
+		occurrence.Recurrence = nil
+		var comp *ical.Component
+		if item.ItemType == model.ItemTypeTask {
+			comp = w.writeTodo(&occurrence)
+		} else {
+			comp = w.writeEvent(&occurrence)
+		}
+		w.setDateTime(comp.Props, ical.PropRecurrenceID, ov.RecurrenceID, item.IsAllDay, item.Timezone)
+		comps = append(comps, comp)
+	}
+	return comps
 }
 
 func (w *Writer) writeEvent(item *model.CalendarItem) *ical.Component {
@@ -80,16 +112,19 @@ func (w *Writer) writeEvent(item *model.CalendarItem) *ical.Component {
 	}
 
 	if item.Priority > 0 {
-		event.Props.SetText("PRIORITY", fmt.Sprintf("%d", exportPriority(item.Priority)))
+		setValue(event.Props, "PRIORITY", fmt.Sprintf("%d", exportPriority(item.Priority)))
 	}
 
 	if len(item.Tags) > 0 {
-		event.Props.SetText("CATEGORIES", strings.Join(item.Tags, ","))
+		setCategories(event.Props, item.Tags)
 	}
 
+	setAttendees(event.Props, item)
+	setExtensions(event.Props, item)
+
 	if item.Recurrence != nil {
 		rrule := w.formatRRule(item.Recurrence)
-		event.Props.SetText("RRULE", rrule)
+		setValue(event.Props, "RRULE", rrule)
 
 		if len(item.Recurrence.ExDates) > 0 {
 			w.setExDates(event.Props, item.Recurrence.ExDates, item.Timezone)
@@ -135,22 +170,25 @@ func (w *Writer) writeTodo(item *model.CalendarItem) *ical.Component {
 
 	if item.CompletionDate != nil {
 		w.setDateTime(todo.Props, "COMPLETED", *item.CompletionDate, false, item.Timezone)
-		todo.Props.SetText("PERCENT-COMPLETE", "100")
+		setValue(todo.Props, "PERCENT-COMPLETE", "100")
 	} else if item.Status == model.StatusCompleted {
-		todo.Props.SetText("PERCENT-COMPLETE", "100")
+		setValue(todo.Props, "PERCENT-COMPLETE", "100")
 	}
 
 	if item.Priority > 0 {
-		todo.Props.SetText("PRIORITY", fmt.Sprintf("%d", exportPriority(item.Priority)))
+		setValue(todo.Props, "PRIORITY", fmt.Sprintf("%d", exportPriority(item.Priority)))
 	}
 
 	if len(item.Tags) > 0 {
-		todo.Props.SetText("CATEGORIES", strings.Join(item.Tags, ","))
+		setCategories(todo.Props, item.Tags)
 	}
 
+	setAttendees(todo.Props, item)
+	setExtensions(todo.Props, item)
+
 	if item.Recurrence != nil {
 		rrule := w.formatRRule(item.Recurrence)
-		todo.Props.SetText("RRULE", rrule)
+		setValue(todo.Props, "RRULE", rrule)
 	}
 
 	for _, reminder := range item.Reminders {
@@ -168,6 +206,7 @@ func (w *Writer) writeJournal(item *model.CalendarItem) *ical.Component {
 
 	journal.Props.SetText("UID", item.UID)
 	journal.Props.SetText("SUMMARY", item.Title)
+	journal.Props.SetDateTime("DTSTAMP", time.Now().UTC())
 
 	if item.Description != "" {
 		journal.Props.SetText("DESCRIPTION", item.Description)
@@ -177,6 +216,8 @@ func (w *Writer) writeJournal(item *model.CalendarItem) *ical.Component {
 		w.setDateTime(journal.Props, "DTSTART", *item.StartTime, false, item.Timezone)
 	}
 
+	setExtensions(journal.Props, item)
+
 	return journal
 }
 
@@ -186,7 +227,7 @@ func (w *Writer) writeAlarm(reminder *model.Reminder) *ical.Component {
 	alarm.Props.SetText("DESCRIPTION", "Reminder")
 
 	if reminder.Offset != nil {
-		alarm.Props.SetText("TRIGGER", formatDuration(*reminder.Offset))
+		setValue(alarm.Props, "TRIGGER", formatDuration(*reminder.Offset))
 	} else if reminder.AbsoluteTime != nil {
 		alarm.Props.SetDateTime("TRIGGER", *reminder.AbsoluteTime)
 	} else {
@@ -198,13 +239,11 @@ func (w *Writer) writeAlarm(reminder *model.Reminder) *ical.Component {
 
 func (w *Writer) setDateTime(props ical.Props, propName string, t time.Time, isAllDay bool, tz string) {
 	if isAllDay {
-		props.SetText(propName, t.Format("20060102"))
-		props.Get(propName).Params["VALUE"] = []string{"DATE"}
+		setValue(props, propName, t.Format("20060102")).Params["VALUE"] = []string{"DATE"}
 	} else if tz != "" && tz != "UTC" {
-		props.SetText(propName, t.Format("20060102T150405"))
-		props.Get(propName).Params["TZID"] = []string{tz}
+		setValue(props, propName, inZone(t, tz).Format("20060102T150405")).Params["TZID"] = []string{tz}
 	} else {
-		props.SetText(propName, t.UTC().Format("20060102T150405Z"))
+		setValue(props, propName, t.UTC().Format("20060102T150405Z"))
 	}
 }
 
@@ -212,12 +251,12 @@ func (w *Writer) setExDates(props ical.Props, dates []time.Time, tz string) {
 	var formatted []string
 	for _, d := range dates {
 		if tz != "" && tz != "UTC" {
-			formatted = append(formatted, d.Format("20060102T150405"))
+			formatted = append(formatted, inZone(d, tz).Format("20060102T150405"))
 		} else {
 			formatted = append(formatted, d.UTC().Format("20060102T150405Z"))
 		}
 	}
-	props.SetText("EXDATE", strings.Join(formatted, ","))
+	setValue(props, "EXDATE", strings.Join(formatted, ","))
 	if tz != "" && tz != "UTC" {
 		props.Get("EXDATE").Params["TZID"] = []string{tz}
 	}
@@ -227,18 +266,97 @@ func (w *Writer) setRDates(props ical.Props, dates []time.Time, tz string) {
 	var formatted []string
 	for _, d := range dates {
 		if tz != "" && tz != "UTC" {
-			formatted = append(formatted, d.Format("20060102T150405"))
+			formatted = append(formatted, inZone(d, tz).Format("20060102T150405"))
 		} else {
 			formatted = append(formatted, d.UTC().Format("20060102T150405Z"))
 		}
 	}
-	props.SetText("RDATE", strings.Join(formatted, ","))
+	setValue(props, "RDATE", strings.Join(formatted, ","))
 	if tz != "" && tz != "UTC" {
 		props.Get("RDATE").Params["TZID"] = []string{tz}
 	}
 }
 
+// inZone returns t on the wall clock of tz, or t unchanged if tz is unknown.
+func inZone(t time.Time, tz string) time.Time {
+	if loc, err := time.LoadLocation(tz); err == nil {
+		return t.In(loc)
+	}
+	return t
+}
+
+// setValue sets a property that is not text to an already formatted value.
+// Props.SetText would escape it and mark it VALUE=TEXT.
+func setValue(props ical.Props, name, value string) *ical.Prop {
+	prop := ical.NewProp(name)
+	prop.Value = value
+	props.Set(prop)
+	return prop
+}
+
+func setCategories(props ical.Props, tags []string) {
+	prop := ical.NewProp("CATEGORIES")
+	prop.SetTextList(tags)
+	props.Set(prop)
+}
+
+// setAttendees writes the organizer and attendees of item. CAL-ADDRESS
+// values are URIs, so people known only by name are left out.
+func setAttendees(props ical.Props, item *model.CalendarItem) {
+	if org := item.Organizer; org != nil && org.Email != "" {
+		prop := setValue(props, ical.PropOrganizer, "mailto:"+org.Email)
+		if org.Name != "" {
+			prop.Params.Set(ical.ParamCommonName, org.Name)
+		}
+	}
+	for _, a := range item.Attendees {
+		if a.Email == "" {
+			continue
+		}
+		prop := ical.NewProp(ical.PropAttendee)
+		prop.Value = "mailto:" + a.Email
+		if a.Name != "" {
+			prop.Params.Set(ical.ParamCommonName, a.Name)
+		}
+		if a.Role != "" {
+			prop.Params.Set(ical.ParamRole, string(a.Role))
+		}
+		if a.Status != "" {
+			prop.Params.Set(ical.ParamParticipationStatus, string(a.Status))
+		}
+		if a.RSVP {
+			prop.Params.Set(ical.ParamRSVP, "TRUE")
+		}
+		props.Add(prop)
+	}
+}
+
+// setExtensions writes back the iCalendar properties item was read with but
+// has no field for. A property the writer already set from a field wins.
+func setExtensions(props ical.Props, item *model.CalendarItem) {
+	set := make(map[string]bool, len(props))
+	for name := range props {
+		set[name] = true
+	}
+	for _, ext := range item.ExtensionsIn(ExtensionNamespace) {
+		if set[ext.Name] {
+			continue
+		}
+		prop := ical.NewProp(ext.Name)
+		prop.Value = ext.Value
+		for k, v := range ext.Params {
+			prop.Params[k] = append([]string(nil), v...)
+		}
+		props.Add(prop)
+	}
+}
+
 func (w *Writer) formatRRule(rec *model.Recurrence) string {
+	return FormatRRule(rec)
+}
+
+// FormatRRule writes the RRULE value of rec, without the property name.
+func FormatRRule(rec *model.Recurrence) string {
 	var parts []string
 
 	parts = append(parts, fmt.Sprintf("FREQ=%s", rec.Freq))
@@ -287,6 +405,26 @@ func (w *Writer) formatRRule(rec *model.Recurrence) string {
 		parts = append(parts, fmt.Sprintf("BYSETPOS=%s", strings.Join(positions, ",")))
 	}
 
+	for _, by := range []struct {
+		name string
+		list []int
+	}{
+		{"BYWEEKNO", rec.ByWeekNo}, {"BYYEARDAY", rec.ByYearDay},
+		{"BYHOUR", rec.ByHour}, {"BYMINUTE", rec.ByMinute}, {"BYSECOND", rec.BySecond},
+	} {
+		if len(by.list) > 0 {
+			var values []string
+			for _, v := range by.list {
+				values = append(values, fmt.Sprintf("%d", v))
+			}
+			parts = append(parts, fmt.Sprintf("%s=%s", by.name, strings.Join(values, ",")))
+		}
+	}
+
+	if rec.WeekStart != "" {
+		parts = append(parts, fmt.Sprintf("WKST=%s", rec.WeekStart))
+	}
+
 	return strings.Join(parts, ";")
 }
 
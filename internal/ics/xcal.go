@@ -0,0 +1,370 @@
+package ics
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+const xcalNamespace = "urn:ietf:params:xml:ns:icalendar-2.0"
+
+// xcalParamTypes are the parameters whose xCal value is not text.
+var xcalParamTypes = map[string]string{
+	"altrep": "uri", "dir": "uri",
+	"delegated-from": "cal-address", "delegated-to": "cal-address", "member": "cal-address", "sent-by": "cal-address",
+}
+
+// XCalParser reads xCal (RFC 6321), the XML form of iCalendar. Items are
+// mapped exactly as the ICS parser maps them.
+type XCalParser struct {
+	ics *Parser
+}
+
+func NewXCalParser() *XCalParser {
+	return &XCalParser{ics: NewParser()}
+}
+
+func (p *XCalParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xCal file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.Parse(ctx, f, filePath)
+}
+
+func (p *XCalParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	var root xcalNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to decode xCal: %w", err)
+	}
+	if root.XMLName.Local != "icalendar" {
+		return nil, fmt.Errorf("failed to decode xCal: root element is %q, not icalendar", root.XMLName.Local)
+	}
+
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "xcal",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+	for _, node := range root.Nodes {
+		comp, err := decodeXCalComponent(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode xCal: %w", err)
+		}
+		if comp.Name != ical.CompCalendar {
+			return nil, fmt.Errorf("failed to decode xCal: expected vcalendar, got %q", node.XMLName.Local)
+		}
+		if err := p.ics.ParseCalendar(&ical.Calendar{Component: comp}, collection); err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+// XCalWriter writes xCal (RFC 6321) with the components and properties the
+// ICS writer produces.
+type XCalWriter struct {
+	ics *Writer
+}
+
+func NewXCalWriter() *XCalWriter {
+	return &XCalWriter{ics: NewWriter()}
+}
+
+func (w *XCalWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create xCal file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return w.Write(ctx, collection, f)
+}
+
+func (w *XCalWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	root := xcalNode{
+		XMLName: xml.Name{Local: "icalendar"},
+		Attrs:   []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: xcalNamespace}},
+	}
+	comp, err := encodeXCalComponent(w.ics.Calendar(collection).Component)
+	if err != nil {
+		return err
+	}
+	root.Nodes = []xcalNode{comp}
+
+	enc := xml.NewEncoder(writer)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, "\n")
+	return err
+}
+
+// xcalNode is a generic xCal element: either a container of other elements
+// or a leaf holding a value.
+type xcalNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xcalNode `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+func leaf(name, text string) xcalNode {
+	return xcalNode{XMLName: xml.Name{Local: name}, Text: text}
+}
+
+func container(name string, nodes ...xcalNode) xcalNode {
+	return xcalNode{XMLName: xml.Name{Local: name}, Nodes: nodes}
+}
+
+// child returns the first child element named name.
+func (n xcalNode) child(name string) (xcalNode, bool) {
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == name {
+			return c, true
+		}
+	}
+	return xcalNode{}, false
+}
+
+func encodeXCalComponent(comp *ical.Component) (xcalNode, error) {
+	props := container("properties")
+	names := make([]string, 0, len(comp.Props))
+	for n := range comp.Props {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for i := range comp.Props[n] {
+			prop, err := xcalProperty(&comp.Props[n][i])
+			if err != nil {
+				return xcalNode{}, err
+			}
+			props.Nodes = append(props.Nodes, prop)
+		}
+	}
+
+	node := container(strings.ToLower(comp.Name), props)
+	if len(comp.Children) > 0 {
+		children := container("components")
+		for _, child := range comp.Children {
+			c, err := encodeXCalComponent(child)
+			if err != nil {
+				return xcalNode{}, err
+			}
+			children.Nodes = append(children.Nodes, c)
+		}
+		node.Nodes = append(node.Nodes, children)
+	}
+	return node, nil
+}
+
+func xcalProperty(prop *ical.Prop) (xcalNode, error) {
+	typ, values, err := typedValues(prop)
+	if err != nil {
+		return xcalNode{}, err
+	}
+	node := container(strings.ToLower(prop.Name))
+
+	params := exportParams(prop)
+	if len(params) > 0 {
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		pnode := container("parameters")
+		for _, name := range names {
+			ptype := xcalParamTypes[name]
+			if ptype == "" {
+				ptype = "text"
+			}
+			param := container(name)
+			for _, v := range params[name] {
+				param.Nodes = append(param.Nodes, leaf(ptype, v))
+			}
+			pnode.Nodes = append(pnode.Nodes, param)
+		}
+		node.Nodes = append(node.Nodes, pnode)
+	}
+
+	if prop.Name == ical.PropGeo && len(values) == 2 {
+		node.Nodes = append(node.Nodes,
+			leaf("latitude", formatXCalScalar(values[0])),
+			leaf("longitude", formatXCalScalar(values[1])))
+		return node, nil
+	}
+	for _, v := range values {
+		switch v := v.(type) {
+		case recur:
+			rnode := container("recur")
+			for _, rp := range v {
+				for _, rv := range rp.values {
+					rnode.Nodes = append(rnode.Nodes, leaf(rp.name, formatXCalScalar(rv)))
+				}
+			}
+			node.Nodes = append(node.Nodes, rnode)
+		case period:
+			end := "end"
+			if v.hasDuration() {
+				end = "duration"
+			}
+			node.Nodes = append(node.Nodes, container("period", leaf("start", v.start), leaf(end, v.end)))
+		default:
+			node.Nodes = append(node.Nodes, leaf(typ, formatXCalScalar(v)))
+		}
+	}
+	return node, nil
+}
+
+func formatXCalScalar(v interface{}) string {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func decodeXCalComponent(node xcalNode) (*ical.Component, error) {
+	comp := ical.NewComponent(node.XMLName.Local)
+	if props, ok := node.child("properties"); ok {
+		for _, pnode := range props.Nodes {
+			prop, err := decodeXCalProperty(pnode)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", node.XMLName.Local, err)
+			}
+			comp.Props.Add(prop)
+		}
+	}
+	if children, ok := node.child("components"); ok {
+		for _, cnode := range children.Nodes {
+			child, err := decodeXCalComponent(cnode)
+			if err != nil {
+				return nil, err
+			}
+			comp.Children = append(comp.Children, child)
+		}
+	}
+	return comp, nil
+}
+
+func decodeXCalProperty(node xcalNode) (*ical.Prop, error) {
+	name := node.XMLName.Local
+	prop := ical.NewProp(name)
+
+	typ := ""
+	var values []interface{}
+	for _, vnode := range node.Nodes {
+		vname := vnode.XMLName.Local
+		if vname == "parameters" {
+			for _, param := range vnode.Nodes {
+				for _, pv := range param.Nodes {
+					prop.Params.Add(param.XMLName.Local, pv.Text)
+				}
+			}
+			continue
+		}
+		if prop.Name == ical.PropGeo && (vname == "latitude" || vname == "longitude") {
+			vname = "float"
+		}
+		if typ != "" && typ != vname {
+			return nil, fmt.Errorf("%s mixes %s and %s values", name, typ, vname)
+		}
+		typ = vname
+
+		v, err := decodeXCalValue(typ, vnode)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values = append(values, v)
+	}
+	if typ == "" {
+		return nil, fmt.Errorf("%s has no value", name)
+	}
+	if err := setTypedValues(prop, typ, values); err != nil {
+		return nil, err
+	}
+	return prop, nil
+}
+
+func decodeXCalValue(typ string, node xcalNode) (interface{}, error) {
+	text := strings.TrimSpace(node.Text)
+	switch typ {
+	case "recur":
+		var r recur
+		for _, part := range node.Nodes {
+			pname := part.XMLName.Local
+			var v interface{} = strings.TrimSpace(part.Text)
+			if numericRecurParts[pname] {
+				n, err := strconv.Atoi(strings.TrimSpace(part.Text))
+				if err != nil {
+					return nil, fmt.Errorf("invalid recur %s %q", pname, part.Text)
+				}
+				v = n
+			}
+			// repeated elements such as <byday> form one rule part
+			if len(r) > 0 && r[len(r)-1].name == pname {
+				r[len(r)-1].values = append(r[len(r)-1].values, v)
+			} else {
+				r = append(r, recurPart{name: pname, values: []interface{}{v}})
+			}
+		}
+		return r, nil
+	case "period":
+		start, ok := node.child("start")
+		if !ok {
+			return nil, fmt.Errorf("period without start")
+		}
+		end, ok := node.child("end")
+		if !ok {
+			if end, ok = node.child("duration"); !ok {
+				return nil, fmt.Errorf("period without end or duration")
+			}
+		}
+		return period{start: strings.TrimSpace(start.Text), end: strings.TrimSpace(end.Text)}, nil
+	case "integer":
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return n, nil
+	case "float":
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", text)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", text)
+		}
+		return b, nil
+	case "text", "unknown":
+		// text keeps its whitespace
+		return node.Text, nil
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,99 @@
+package ics
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-ical"
+)
+
+func TestXCalPropertiesRoundTrip(t *testing.T) {
+	cal := decodeICS(t, richICS)
+	node, err := encodeXCalComponent(cal.Component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := xml.Marshal(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		`<dtstart><parameters><tzid><text>Europe/Berlin</text></tzid></parameters><date-time>2026-03-02T09:00:00</date-time></dtstart>`,
+		`<rrule><recur><freq>MONTHLY</freq><interval>2</interval><byday>MO</byday><byday>WE</byday><bysetpos>-1</bysetpos><until>2026-12-31T00:00:00Z</until></recur></rrule>`,
+		`<rdate><period><start>2026-08-10T07:00:00Z</start><duration>PT2H</duration></period></rdate>`,
+		`<categories><text>work</text><text>planning</text></categories>`,
+		`<geo><latitude>52.52</latitude><longitude>13.405</longitude></geo>`,
+		`<trigger><parameters><related><text>END</text></related></parameters><duration>-PT15M</duration></trigger>`,
+		`<due><date>2026-03-15</date></due>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("xCal missing %s", want)
+		}
+	}
+
+	var decoded xcalNode
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	back, err := decodeXCalComponent(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := encodeICS(t, &ical.Calendar{Component: back}), encodeICS(t, cal); got != want {
+		t.Errorf("ICS -> xCal -> ICS changed the calendar:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestXCalRoundTripThroughParserAndWriter(t *testing.T) {
+	parsed := roundTrip(t, NewXCalWriter(), NewXCalParser())
+	if parsed.SourceApp != "xcal" {
+		t.Errorf("SourceApp = %q", parsed.SourceApp)
+	}
+
+	var buf bytes.Buffer
+	if err := NewXCalWriter().Write(context.Background(), parsed, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header+`<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0">`) {
+		t.Errorf("unexpected document start:\n%s", buf.String()[:200])
+	}
+}
+
+func TestXCalParserKeepsTextWhitespace(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0">
+  <vcalendar>
+    <components>
+      <vtodo>
+        <properties>
+          <uid><text>t-1</text></uid>
+          <summary><text>  padded  </text></summary>
+          <priority><integer>9</integer></priority>
+          <due>
+            <parameters><tzid><text>America/New_York</text></tzid></parameters>
+            <date-time>2026-05-01T17:00:00</date-time>
+          </due>
+        </properties>
+      </vtodo>
+    </components>
+  </vcalendar>
+</icalendar>`
+	col, err := NewXCalParser().Parse(context.Background(), strings.NewReader(data), "in.xcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(col.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(col.Items))
+	}
+	item := col.Items[0]
+	if item.Title != "  padded  " {
+		t.Errorf("title = %q", item.Title)
+	}
+	if item.Timezone != "America/New_York" || item.DueDate == nil || item.DueDate.Hour() != 17 {
+		t.Errorf("due = %v in %q", item.DueDate, item.Timezone)
+	}
+}
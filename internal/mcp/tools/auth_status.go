@@ -23,7 +23,7 @@ type authStatusEntry struct {
 
 // HandleAuthStatus handles the auth_status tool call.
 func HandleAuthStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	services := []string{"google", "microsoft", "todoist", "ticktick", "notion"}
+	services := []string{"google", "microsoft", "todoist", "ticktick", "notion", "caldav"}
 	statuses := make([]authStatusEntry, 0, len(services))
 
 	for _, svc := range services {
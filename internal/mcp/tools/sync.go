@@ -11,7 +11,7 @@ import (
 func SyncPushTool() mcp.Tool {
 	return mcp.NewTool("sync_push",
 		mcp.WithDescription("Push local calendar/task data to a cloud service"),
-		mcp.WithString("service", mcp.Required(), mcp.Description("Service name: google, microsoft, todoist, ticktick, notion")),
+		mcp.WithString("service", mcp.Required(), mcp.Description("Service name: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav")),
 		mcp.WithString("file_path", mcp.Required(), mcp.Description("Path to file to push")),
 		mcp.WithString("start_date", mcp.Description("Start date filter (ISO 8601)")),
 		mcp.WithString("end_date", mcp.Description("End date filter (ISO 8601)")),
@@ -23,7 +23,7 @@ func SyncPushTool() mcp.Tool {
 func SyncPullTool() mcp.Tool {
 	return mcp.NewTool("sync_pull",
 		mcp.WithDescription("Pull calendar/task data from a cloud service"),
-		mcp.WithString("service", mcp.Required(), mcp.Description("Service name: google, microsoft, todoist, ticktick, notion")),
+		mcp.WithString("service", mcp.Required(), mcp.Description("Service name: google, microsoft, microsoft-todo, todoist, ticktick, notion, caldav")),
 		mcp.WithString("file_path", mcp.Required(), mcp.Description("Path to write pulled data")),
 		mcp.WithString("start_date", mcp.Description("Start date filter (ISO 8601)")),
 		mcp.WithString("end_date", mcp.Description("End date filter (ISO 8601)")),
@@ -15,6 +15,10 @@ const (
 	ItemTypeJournal ItemType = "journal"
 )
 
+// ProjectTagPrefix marks the tag a task's project becomes, so projects carry
+// over between formats that have them, e.g. Taskwarrior and todo.txt.
+const ProjectTagPrefix = "project:"
+
 type Status string
 
 const (
@@ -42,6 +46,10 @@ const (
 	FreqWeekly  FreqType = "WEEKLY"
 	FreqMonthly FreqType = "MONTHLY"
 	FreqYearly  FreqType = "YEARLY"
+
+	FreqHourly   FreqType = "HOURLY"
+	FreqMinutely FreqType = "MINUTELY"
+	FreqSecondly FreqType = "SECONDLY"
 )
 
 type Weekday string
@@ -65,8 +73,36 @@ type Recurrence struct {
 	ByMonth    []int
 	ByMonthDay []int
 	BySetPos   []int
-	ExDates    []time.Time
-	RDates     []time.Time
+	ByWeekNo   []int
+	ByYearDay  []int
+	ByHour     []int
+	ByMinute   []int
+	BySecond   []int
+	// WeekStart is the WKST day weeks start on; empty means Monday.
+	WeekStart Weekday
+	ExDates   []time.Time
+	RDates    []time.Time
+	// Overrides are occurrences changed from what the rule gives, e.g.
+	// moved or retitled. Cancelled occurrences are ExDates instead.
+	Overrides []Override
+}
+
+// Override is one occurrence of a recurring item changed from the series.
+// RecurrenceID is the start the rule gives the occurrence; Item is the
+// occurrence as changed, without a Recurrence of its own.
+type Override struct {
+	RecurrenceID time.Time
+	Item         CalendarItem
+}
+
+// Override returns the override of the occurrence the rule starts at t.
+func (rec *Recurrence) Override(t time.Time) *Override {
+	for i := range rec.Overrides {
+		if rec.Overrides[i].RecurrenceID.Equal(t) {
+			return &rec.Overrides[i]
+		}
+	}
+	return nil
 }
 
 type Reminder struct {
@@ -75,12 +111,61 @@ type Reminder struct {
 }
 
 type Subtask struct {
+	// UID is the subtask's ID in formats that keep subtasks as items of
+	// their own, such as a Taskwarrior dependency's UUID; often empty.
+	UID       string
 	Title     string
 	Status    Status
 	Priority  Priority
 	SortOrder int
 }
 
+// AttendeeRole is an attendee's part in an event, named as the iCalendar
+// ROLE parameter names it.
+type AttendeeRole string
+
+const (
+	RoleChair          AttendeeRole = "CHAIR"
+	RoleRequired       AttendeeRole = "REQ-PARTICIPANT"
+	RoleOptional       AttendeeRole = "OPT-PARTICIPANT"
+	RoleNonParticipant AttendeeRole = "NON-PARTICIPANT"
+)
+
+// PartStat is an attendee's reply to an invitation, named as the iCalendar
+// PARTSTAT parameter names it.
+type PartStat string
+
+const (
+	PartStatNeedsAction PartStat = "NEEDS-ACTION"
+	PartStatAccepted    PartStat = "ACCEPTED"
+	PartStatDeclined    PartStat = "DECLINED"
+	PartStatTentative   PartStat = "TENTATIVE"
+	PartStatDelegated   PartStat = "DELEGATED"
+)
+
+// Attendee is a person invited to an event. Role and Status are empty when
+// the source did not say; a format that needs them assumes a required
+// attendee who has not replied. An item's Organizer uses only Email and Name.
+type Attendee struct {
+	Email  string
+	Name   string
+	Role   AttendeeRole
+	Status PartStat
+	// RSVP is whether a reply is expected.
+	RSVP bool
+}
+
+// Extension is a property or column salja has no field for, kept so the
+// writer for the format it came from can write it back. Namespace names
+// that format family, e.g. "ics" for iCalendar, jCal and xCal, or the
+// format name for a CSV column. Params holds iCalendar property parameters.
+type Extension struct {
+	Namespace string
+	Name      string
+	Value     string
+	Params    map[string][]string
+}
+
 type CalendarItem struct {
 	UID            string
 	Title          string
@@ -95,6 +180,9 @@ type CalendarItem struct {
 	Recurrence     *Recurrence
 	Reminders      []Reminder
 	Subtasks       []Subtask
+	Organizer      *Attendee
+	Attendees      []Attendee
+	Extensions     []Extension
 	CompletionDate *time.Time
 	Timezone       string
 	IsAllDay       bool
@@ -131,6 +219,32 @@ func (item *CalendarItem) HasSubtasks() bool {
 	return len(item.Subtasks) > 0
 }
 
+func (item *CalendarItem) HasAttendees() bool {
+	return item.Organizer != nil || len(item.Attendees) > 0
+}
+
+// ExtensionsIn returns the item's extensions from namespace, in order.
+func (item *CalendarItem) ExtensionsIn(namespace string) []Extension {
+	var exts []Extension
+	for _, ext := range item.Extensions {
+		if ext.Namespace == namespace {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// ExtensionValue returns the value of the first extension with namespace and
+// name.
+func (item *CalendarItem) ExtensionValue(namespace, name string) (string, bool) {
+	for _, ext := range item.Extensions {
+		if ext.Namespace == namespace && ext.Name == name {
+			return ext.Value, true
+		}
+	}
+	return "", false
+}
+
 func (item *CalendarItem) Duration() time.Duration {
 	if item.StartTime == nil || item.EndTime == nil {
 		return 0
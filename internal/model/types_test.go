@@ -63,6 +63,14 @@ func TestHelpers(t *testing.T) {
 	if !item.HasSubtasks() {
 		t.Error("expected HasSubtasks true")
 	}
+
+	if item.HasAttendees() {
+		t.Error("expected HasAttendees false")
+	}
+	item.Organizer = &Attendee{Email: "ana@example.com"}
+	if !item.HasAttendees() {
+		t.Error("expected HasAttendees true")
+	}
 }
 
 func TestDuration(t *testing.T) {
@@ -0,0 +1,568 @@
+// Package native reads and writes salja-json, a lossless, versioned dump of
+// model.CalendarCollection. It is meant as a pivot and backup format and for
+// piping into tools such as jq; schema.json documents it.
+//
+// A salja-json document is a single envelope object holding every item. The
+// NDJSON form streams the same data as one header line followed by one item
+// per line, and a stream without the header (for example after a jq filter)
+// is also accepted.
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// FormatName identifies salja-json documents and NDJSON headers.
+const FormatName = "salja-json"
+
+// Version is the schema version this package writes. Documents with a newer
+// version are rejected rather than read partially.
+const Version = 1
+
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON Schema describing salja-json documents.
+func Schema() []byte {
+	return schema
+}
+
+// Sniff reports whether head, the start of a file, reads as salja-json: an
+// envelope document or NDJSON header whose format is FormatName.
+func Sniff(head []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(head))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if tok == "format" {
+			var format string
+			return dec.Decode(&format) == nil && format == FormatName
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+type document struct {
+	header
+	Items []item `json:"items"`
+}
+
+type header struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	SourceApp  string `json:"source_app,omitempty"`
+	ExportDate string `json:"export_date,omitempty"`
+}
+
+type item struct {
+	UID            string      `json:"uid,omitempty"`
+	ItemType       string      `json:"item_type,omitempty"`
+	Title          string      `json:"title"`
+	Description    string      `json:"description,omitempty"`
+	StartTime      string      `json:"start_time,omitempty"`
+	EndTime        string      `json:"end_time,omitempty"`
+	DueDate        string      `json:"due_date,omitempty"`
+	IsAllDay       bool        `json:"is_all_day,omitempty"`
+	Timezone       string      `json:"timezone,omitempty"`
+	Priority       int         `json:"priority,omitempty"`
+	Status         string      `json:"status,omitempty"`
+	Location       string      `json:"location,omitempty"`
+	Tags           []string    `json:"tags,omitempty"`
+	Recurrence     *recurrence `json:"recurrence,omitempty"`
+	Reminders      []reminder  `json:"reminders,omitempty"`
+	Subtasks       []subtask   `json:"subtasks,omitempty"`
+	Organizer      *attendee   `json:"organizer,omitempty"`
+	Attendees      []attendee  `json:"attendees,omitempty"`
+	Extensions     []extension `json:"extensions,omitempty"`
+	CompletionDate string      `json:"completion_date,omitempty"`
+	CreatedAt      string      `json:"created_at,omitempty"`
+	UpdatedAt      string      `json:"updated_at,omitempty"`
+}
+
+type recurrence struct {
+	Freq       string     `json:"freq"`
+	Interval   int        `json:"interval,omitempty"`
+	Count      *int       `json:"count,omitempty"`
+	Until      string     `json:"until,omitempty"`
+	ByDay      []string   `json:"by_day,omitempty"`
+	ByMonth    []int      `json:"by_month,omitempty"`
+	ByMonthDay []int      `json:"by_month_day,omitempty"`
+	BySetPos   []int      `json:"by_set_pos,omitempty"`
+	ByWeekNo   []int      `json:"by_week_no,omitempty"`
+	ByYearDay  []int      `json:"by_year_day,omitempty"`
+	ByHour     []int      `json:"by_hour,omitempty"`
+	ByMinute   []int      `json:"by_minute,omitempty"`
+	BySecond   []int      `json:"by_second,omitempty"`
+	WeekStart  string     `json:"week_start,omitempty"`
+	ExDates    []string   `json:"ex_dates,omitempty"`
+	RDates     []string   `json:"r_dates,omitempty"`
+	Overrides  []override `json:"overrides,omitempty"`
+}
+
+type override struct {
+	RecurrenceID string `json:"recurrence_id"`
+	Item         item   `json:"item"`
+}
+
+type reminder struct {
+	Offset       string `json:"offset,omitempty"`
+	AbsoluteTime string `json:"absolute_time,omitempty"`
+}
+
+type subtask struct {
+	UID       string `json:"uid,omitempty"`
+	Title     string `json:"title"`
+	Status    string `json:"status,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+	SortOrder int    `json:"sort_order,omitempty"`
+}
+
+type attendee struct {
+	Email  string `json:"email,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Role   string `json:"role,omitempty"`
+	Status string `json:"status,omitempty"`
+	RSVP   bool   `json:"rsvp,omitempty"`
+}
+
+type extension struct {
+	Namespace string              `json:"namespace"`
+	Name      string              `json:"name"`
+	Value     string              `json:"value"`
+	Params    map[string][]string `json:"params,omitempty"`
+}
+
+// Parser reads salja-json documents and their NDJSON form.
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+func (p *Parser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open salja-json file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.Parse(ctx, f, filePath)
+}
+
+// Parse reads either an envelope document or a stream of JSON objects, one
+// optional header followed by items.
+func (p *Parser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        FormatName,
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var raw map[string]json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode salja-json value %d: %w", n+1, err)
+		}
+
+		if _, ok := raw["format"]; ok {
+			var doc document
+			if err := remarshal(raw, &doc); err != nil {
+				return nil, fmt.Errorf("failed to decode salja-json header: %w", err)
+			}
+			if err := applyHeader(doc.header, collection); err != nil {
+				return nil, err
+			}
+			for i, it := range doc.Items {
+				ci, err := it.toModel()
+				if err != nil {
+					return nil, fmt.Errorf("salja-json item %d: %w", i+1, err)
+				}
+				collection.Items = append(collection.Items, ci)
+			}
+			continue
+		}
+
+		var it item
+		if err := remarshal(raw, &it); err != nil {
+			return nil, fmt.Errorf("failed to decode salja-json item on value %d: %w", n+1, err)
+		}
+		ci, err := it.toModel()
+		if err != nil {
+			return nil, fmt.Errorf("salja-json value %d: %w", n+1, err)
+		}
+		collection.Items = append(collection.Items, ci)
+	}
+	return collection, nil
+}
+
+func remarshal(raw map[string]json.RawMessage, v interface{}) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func applyHeader(h header, collection *model.CalendarCollection) error {
+	if h.Format != FormatName {
+		return fmt.Errorf("not a salja-json document: format is %q", h.Format)
+	}
+	if h.Version < 1 || h.Version > Version {
+		return fmt.Errorf("unsupported salja-json version %d (this build reads up to %d)", h.Version, Version)
+	}
+	if h.SourceApp != "" {
+		collection.SourceApp = h.SourceApp
+	}
+	if h.ExportDate != "" {
+		t, err := time.Parse(time.RFC3339Nano, h.ExportDate)
+		if err != nil {
+			return fmt.Errorf("invalid export_date %q: %w", h.ExportDate, err)
+		}
+		collection.ExportDate = t
+	}
+	return nil
+}
+
+// Writer writes salja-json. With Stream set it writes NDJSON instead of a
+// single envelope document.
+type Writer struct {
+	Stream bool
+}
+
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+func NewNDJSONWriter() *Writer {
+	return &Writer{Stream: true}
+}
+
+func (w *Writer) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create salja-json file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return w.Write(ctx, collection, f)
+}
+
+func (w *Writer) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	h := header{
+		Format:     FormatName,
+		Version:    Version,
+		SourceApp:  collection.SourceApp,
+		ExportDate: formatTime(collection.ExportDate),
+	}
+	items := make([]item, 0, len(collection.Items))
+	for _, ci := range collection.Items {
+		items = append(items, fromModel(ci))
+	}
+
+	if !w.Stream {
+		enc := json.NewEncoder(writer)
+		enc.SetIndent("", "  ")
+		return enc.Encode(document{header: h, Items: items})
+	}
+
+	bw := bufio.NewWriter(writer)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(h); err != nil {
+		return err
+	}
+	for _, it := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(it); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func fromModel(ci model.CalendarItem) item {
+	it := item{
+		UID:            ci.UID,
+		ItemType:       string(ci.ItemType),
+		Title:          ci.Title,
+		Description:    ci.Description,
+		StartTime:      formatTimePtr(ci.StartTime),
+		EndTime:        formatTimePtr(ci.EndTime),
+		DueDate:        formatTimePtr(ci.DueDate),
+		IsAllDay:       ci.IsAllDay,
+		Timezone:       ci.Timezone,
+		Priority:       int(ci.Priority),
+		Status:         string(ci.Status),
+		Location:       ci.Location,
+		Tags:           ci.Tags,
+		CompletionDate: formatTimePtr(ci.CompletionDate),
+		CreatedAt:      formatTimePtr(ci.CreatedAt),
+		UpdatedAt:      formatTimePtr(ci.UpdatedAt),
+	}
+	if rec := ci.Recurrence; rec != nil {
+		r := &recurrence{
+			Freq:       string(rec.Freq),
+			Interval:   rec.Interval,
+			Count:      rec.Count,
+			Until:      formatTimePtr(rec.Until),
+			ByMonth:    rec.ByMonth,
+			ByMonthDay: rec.ByMonthDay,
+			BySetPos:   rec.BySetPos,
+			ByWeekNo:   rec.ByWeekNo,
+			ByYearDay:  rec.ByYearDay,
+			ByHour:     rec.ByHour,
+			ByMinute:   rec.ByMinute,
+			BySecond:   rec.BySecond,
+			WeekStart:  string(rec.WeekStart),
+		}
+		for _, d := range rec.ByDay {
+			r.ByDay = append(r.ByDay, string(d))
+		}
+		for _, t := range rec.ExDates {
+			r.ExDates = append(r.ExDates, formatTime(t))
+		}
+		for _, t := range rec.RDates {
+			r.RDates = append(r.RDates, formatTime(t))
+		}
+		for _, ov := range rec.Overrides {
+			r.Overrides = append(r.Overrides, override{RecurrenceID: formatTime(ov.RecurrenceID), Item: fromModel(ov.Item)})
+		}
+		it.Recurrence = r
+	}
+	for _, rem := range ci.Reminders {
+		var r reminder
+		if rem.Offset != nil {
+			r.Offset = rem.Offset.String()
+		}
+		r.AbsoluteTime = formatTimePtr(rem.AbsoluteTime)
+		it.Reminders = append(it.Reminders, r)
+	}
+	for _, st := range ci.Subtasks {
+		it.Subtasks = append(it.Subtasks, subtask{
+			UID:       st.UID,
+			Title:     st.Title,
+			Status:    string(st.Status),
+			Priority:  int(st.Priority),
+			SortOrder: st.SortOrder,
+		})
+	}
+	if org := ci.Organizer; org != nil {
+		it.Organizer = &attendee{Email: org.Email, Name: org.Name}
+	}
+	for _, a := range ci.Attendees {
+		it.Attendees = append(it.Attendees, attendee{
+			Email:  a.Email,
+			Name:   a.Name,
+			Role:   string(a.Role),
+			Status: string(a.Status),
+			RSVP:   a.RSVP,
+		})
+	}
+	for _, ext := range ci.Extensions {
+		it.Extensions = append(it.Extensions, extension(ext))
+	}
+	return it
+}
+
+func (it item) toModel() (model.CalendarItem, error) {
+	ci := model.CalendarItem{
+		UID:         it.UID,
+		ItemType:    model.ItemType(it.ItemType),
+		Title:       it.Title,
+		Description: it.Description,
+		IsAllDay:    it.IsAllDay,
+		Timezone:    it.Timezone,
+		Priority:    model.Priority(it.Priority),
+		Status:      model.Status(it.Status),
+		Location:    it.Location,
+		Tags:        it.Tags,
+	}
+	loc := location(it.Timezone)
+	var err error
+	fields := []struct {
+		name string
+		src  string
+		dst  **time.Time
+	}{
+		{"start_time", it.StartTime, &ci.StartTime},
+		{"end_time", it.EndTime, &ci.EndTime},
+		{"due_date", it.DueDate, &ci.DueDate},
+		{"completion_date", it.CompletionDate, &ci.CompletionDate},
+		{"created_at", it.CreatedAt, &ci.CreatedAt},
+		{"updated_at", it.UpdatedAt, &ci.UpdatedAt},
+	}
+	for _, f := range fields {
+		if *f.dst, err = parseTimePtr(f.name, f.src, loc); err != nil {
+			return ci, err
+		}
+	}
+
+	if r := it.Recurrence; r != nil {
+		rec := &model.Recurrence{
+			Freq:       model.FreqType(r.Freq),
+			Interval:   r.Interval,
+			Count:      r.Count,
+			ByMonth:    r.ByMonth,
+			ByMonthDay: r.ByMonthDay,
+			BySetPos:   r.BySetPos,
+			ByWeekNo:   r.ByWeekNo,
+			ByYearDay:  r.ByYearDay,
+			ByHour:     r.ByHour,
+			ByMinute:   r.ByMinute,
+			BySecond:   r.BySecond,
+			WeekStart:  model.Weekday(r.WeekStart),
+		}
+		if rec.Until, err = parseTimePtr("recurrence.until", r.Until, loc); err != nil {
+			return ci, err
+		}
+		for _, d := range r.ByDay {
+			rec.ByDay = append(rec.ByDay, model.Weekday(d))
+		}
+		for _, s := range r.ExDates {
+			t, err := parseTime("recurrence.ex_dates", s, loc)
+			if err != nil {
+				return ci, err
+			}
+			rec.ExDates = append(rec.ExDates, t)
+		}
+		for _, s := range r.RDates {
+			t, err := parseTime("recurrence.r_dates", s, loc)
+			if err != nil {
+				return ci, err
+			}
+			rec.RDates = append(rec.RDates, t)
+		}
+		for _, o := range r.Overrides {
+			rid, err := parseTime("recurrence.overrides.recurrence_id", o.RecurrenceID, loc)
+			if err != nil {
+				return ci, err
+			}
+			occurrence, err := o.Item.toModel()
+			if err != nil {
+				return ci, err
+			}
+			rec.Overrides = append(rec.Overrides, model.Override{RecurrenceID: rid, Item: occurrence})
+		}
+		ci.Recurrence = rec
+	}
+
+	for _, r := range it.Reminders {
+		var rem model.Reminder
+		if r.Offset != "" {
+			d, err := time.ParseDuration(r.Offset)
+			if err != nil {
+				return ci, fmt.Errorf("invalid reminder offset %q: %w", r.Offset, err)
+			}
+			rem.Offset = &d
+		}
+		if rem.AbsoluteTime, err = parseTimePtr("reminders.absolute_time", r.AbsoluteTime, loc); err != nil {
+			return ci, err
+		}
+		ci.Reminders = append(ci.Reminders, rem)
+	}
+	for _, st := range it.Subtasks {
+		ci.Subtasks = append(ci.Subtasks, model.Subtask{
+			UID:       st.UID,
+			Title:     st.Title,
+			Status:    model.Status(st.Status),
+			Priority:  model.Priority(st.Priority),
+			SortOrder: st.SortOrder,
+		})
+	}
+	if org := it.Organizer; org != nil {
+		ci.Organizer = &model.Attendee{Email: org.Email, Name: org.Name}
+	}
+	for _, a := range it.Attendees {
+		ci.Attendees = append(ci.Attendees, model.Attendee{
+			Email:  a.Email,
+			Name:   a.Name,
+			Role:   model.AttendeeRole(a.Role),
+			Status: model.PartStat(a.Status),
+			RSVP:   a.RSVP,
+		})
+	}
+	for _, ext := range it.Extensions {
+		ci.Extensions = append(ci.Extensions, model.Extension(ext))
+	}
+	return ci, nil
+}
+
+// Times are written as RFC 3339 with their offset and nanoseconds, so the
+// instant and wall clock survive; the zone name travels in timezone.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(field, s string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: %w", field, s, err)
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t, nil
+}
+
+func parseTimePtr(field, s string, loc *time.Location) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := parseTime(field, s, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// location returns the zone times of an item are read into, nil to keep the
+// offset they were written with.
+func location(tz string) *time.Location {
+	if tz == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
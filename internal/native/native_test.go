@@ -0,0 +1,246 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func fullCollection(t *testing.T) *model.CalendarCollection {
+	t.Helper()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	at := func(d, h int) *time.Time {
+		v := time.Date(2026, 3, d, h, 30, 0, 123456789, berlin)
+		return &v
+	}
+	count := 4
+	offset := -15 * time.Minute
+	zero := time.Duration(0)
+	return &model.CalendarCollection{
+		SourceApp:  "ics",
+		ExportDate: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Items: []model.CalendarItem{
+			{
+				UID:         "ev-1",
+				ItemType:    model.ItemTypeEvent,
+				Title:       "Planning",
+				Description: "Line one\nLine two",
+				StartTime:   at(2, 9),
+				EndTime:     at(2, 10),
+				Timezone:    "Europe/Berlin",
+				Priority:    model.PriorityHigh,
+				Status:      model.StatusInProgress,
+				Location:    "Room 4",
+				Tags:        []string{"work", "planning"},
+				Recurrence: &model.Recurrence{
+					Freq:       model.FreqMonthly,
+					Interval:   2,
+					Count:      &count,
+					ByDay:      []model.Weekday{"-1MO", model.WeekdayWE},
+					ByMonth:    []int{3, 6},
+					ByMonthDay: []int{-1},
+					BySetPos:   []int{1},
+					ByHour:     []int{9, 17},
+					WeekStart:  model.WeekdaySU,
+					ExDates:    []time.Time{*at(4, 9)},
+					RDates:     []time.Time{*at(5, 9)},
+					Overrides: []model.Override{{
+						RecurrenceID: *at(9, 9),
+						Item: model.CalendarItem{
+							UID:       "ev-1",
+							ItemType:  model.ItemTypeEvent,
+							Title:     "Planning (moved)",
+							StartTime: at(9, 11),
+							EndTime:   at(9, 12),
+							Timezone:  "Europe/Berlin",
+						},
+					}},
+				},
+				Reminders: []model.Reminder{{Offset: &offset}, {Offset: &zero}, {AbsoluteTime: at(1, 8)}},
+				Organizer: &model.Attendee{Email: "ana@example.com", Name: "Ana Ruiz"},
+				Attendees: []model.Attendee{
+					{Email: "sam@example.com", Name: "Sam Lee", Role: model.RoleChair, Status: model.PartStatAccepted},
+					{Email: "kim@example.com", Role: model.RoleOptional, Status: model.PartStatNeedsAction, RSVP: true},
+					{Name: "Front desk"},
+				},
+				Extensions: []model.Extension{
+					{Namespace: "ics", Name: "ATTACH", Value: "https://example.com/a.pdf", Params: map[string][]string{"FMTTYPE": {"application/pdf"}}},
+					{Namespace: "ics", Name: "X-APPLE-TRAVEL-ADVISORY-BEHAVIOR", Value: "AUTOMATIC"},
+				},
+				CreatedAt: at(1, 1),
+				UpdatedAt: at(1, 2),
+			},
+			{
+				UID:            "task-1",
+				ItemType:       model.ItemTypeTask,
+				Title:          "File report",
+				DueDate:        at(15, 0),
+				IsAllDay:       true,
+				Status:         model.StatusCompleted,
+				CompletionDate: at(14, 16),
+				Recurrence:     &model.Recurrence{Freq: model.FreqYearly, Until: at(28, 0)},
+				Subtasks: []model.Subtask{
+					{Title: "Draft", Status: model.StatusCompleted, Priority: model.PriorityLow, SortOrder: 1},
+					{Title: "Send", Status: model.StatusPending, SortOrder: 2},
+				},
+			},
+			{Title: "Retro notes", ItemType: model.ItemTypeJournal},
+		},
+	}
+}
+
+func TestRoundTripIsLossless(t *testing.T) {
+	for _, w := range []*Writer{NewWriter(), NewNDJSONWriter()} {
+		ctx := context.Background()
+		original := fullCollection(t)
+
+		var first bytes.Buffer
+		if err := w.Write(ctx, original, &first); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := NewParser().Parse(ctx, bytes.NewReader(first.Bytes()), "in")
+		if err != nil {
+			t.Fatalf("stream=%v: %v\n%s", w.Stream, err, first.String())
+		}
+		var second bytes.Buffer
+		if err := w.Write(ctx, parsed, &second); err != nil {
+			t.Fatal(err)
+		}
+		if first.String() != second.String() {
+			t.Errorf("stream=%v: rewrite differs:\n%s\n---\n%s", w.Stream, first.String(), second.String())
+		}
+
+		if parsed.SourceApp != "ics" || !parsed.ExportDate.Equal(original.ExportDate) {
+			t.Errorf("stream=%v: header = %q %v", w.Stream, parsed.SourceApp, parsed.ExportDate)
+		}
+		if len(parsed.Items) != len(original.Items) {
+			t.Fatalf("stream=%v: got %d items", w.Stream, len(parsed.Items))
+		}
+		ev := parsed.Items[0]
+		want := original.Items[0]
+		if !ev.StartTime.Equal(*want.StartTime) || ev.StartTime.Location().String() != "Europe/Berlin" {
+			t.Errorf("stream=%v: start = %v", w.Stream, ev.StartTime)
+		}
+		if !reflect.DeepEqual(ev.Reminders[0], want.Reminders[0]) || ev.Reminders[1].Offset == nil {
+			t.Errorf("stream=%v: reminders = %+v", w.Stream, ev.Reminders)
+		}
+		if *ev.Recurrence.Count != 4 || !reflect.DeepEqual(ev.Recurrence.ByDay, want.Recurrence.ByDay) ||
+			!reflect.DeepEqual(ev.Recurrence.Overrides, want.Recurrence.Overrides) {
+			t.Errorf("stream=%v: recurrence = %+v", w.Stream, ev.Recurrence)
+		}
+		if !reflect.DeepEqual(ev.Organizer, want.Organizer) || !reflect.DeepEqual(ev.Attendees, want.Attendees) {
+			t.Errorf("stream=%v: organizer, attendees = %+v %+v", w.Stream, ev.Organizer, ev.Attendees)
+		}
+		if !reflect.DeepEqual(ev.Extensions, want.Extensions) {
+			t.Errorf("stream=%v: extensions = %+v", w.Stream, ev.Extensions)
+		}
+		if !reflect.DeepEqual(parsed.Items[1].Subtasks, original.Items[1].Subtasks) {
+			t.Errorf("stream=%v: subtasks = %+v", w.Stream, parsed.Items[1].Subtasks)
+		}
+	}
+}
+
+func TestNDJSONWritesOneItemPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewNDJSONWriter().Write(context.Background(), fullCollection(t), &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 items, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], `{"format":"salja-json","version":1,`) {
+		t.Errorf("header = %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[3], `{"item_type":"journal","title":"Retro notes"}`) {
+		t.Errorf("last item = %s", lines[3])
+	}
+}
+
+func TestParserAcceptsItemsWithoutHeader(t *testing.T) {
+	data := `{"title":"A","item_type":"task","priority":5}
+{"title":"B","start_time":"2026-04-01T09:00:00-04:00","timezone":"America/New_York"}
+`
+	col, err := NewParser().Parse(context.Background(), strings.NewReader(data), "-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(col.Items) != 2 || col.SourceApp != FormatName {
+		t.Fatalf("got %+v", col)
+	}
+	if col.Items[0].Priority != model.PriorityHighest {
+		t.Errorf("priority = %d", col.Items[0].Priority)
+	}
+	if col.Items[1].StartTime == nil || col.Items[1].StartTime.Hour() != 9 {
+		t.Errorf("start = %v", col.Items[1].StartTime)
+	}
+}
+
+func TestParserRejectsUnknownVersionAndFormat(t *testing.T) {
+	for _, data := range []string{
+		`{"format":"salja-json","version":2,"items":[]}`,
+		`{"format":"other","version":1,"items":[]}`,
+		`{"format":"salja-json","version":1,"items":[{"title":"x","due_date":"tomorrow"}]}`,
+	} {
+		if _, err := NewParser().Parse(context.Background(), strings.NewReader(data), "in"); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
+
+func TestSniff(t *testing.T) {
+	for head, want := range map[string]bool{
+		`{"format":"salja-json","version":1,"items":[{"title":"x"`: true,
+		`{"format":"other","version":1}`:                           false,
+		`{"title":"x"}`:                                            false,
+		`[{"uuid":"a","description":"x"}]`:                         false,
+		"":                                                         false,
+	} {
+		if got := Sniff([]byte(head)); got != want {
+			t.Errorf("Sniff(%q) = %v, want %v", head, got, want)
+		}
+	}
+}
+
+// TestSchemaCoversWireFormat checks every JSON field written is declared in
+// the published schema, so the two cannot drift apart.
+func TestSchemaCoversWireFormat(t *testing.T) {
+	var s struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema(), &s); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	for def, v := range map[string]interface{}{
+		"headerFields": header{},
+		"item":         item{},
+		"recurrence":   recurrence{},
+		"reminder":     reminder{},
+		"subtask":      subtask{},
+		"attendee":     attendee{},
+		"extension":    extension{},
+		"override":     override{},
+	} {
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if _, ok := s.Defs[def].Properties[name]; !ok {
+				t.Errorf("schema %s is missing %q", def, name)
+			}
+		}
+	}
+	if _, ok := s.Defs["document"].Properties["items"]; !ok {
+		t.Error("schema document is missing items")
+	}
+}
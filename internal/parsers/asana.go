@@ -78,6 +78,7 @@ func (p *AsanaParser) Parse(ctx context.Context, r io.Reader, sourcePath string)
 		if err != nil {
 			return nil, fmt.Errorf("%s line %d: %w", sourcePath, lineNum, err)
 		}
+		item.Extensions = columnExtensions("asana", header, row, func(col string) bool { return asanaColumns[col] })
 		collection.Items = append(collection.Items, item)
 	}
 
@@ -90,6 +91,12 @@ func (p *AsanaParser) Parse(ctx context.Context, r io.Reader, sourcePath string)
 	return collection, nil
 }
 
+// asanaColumns are the columns parseAsanaRow reads; the rest, such as
+// Section or Assignee, are kept as extensions.
+var asanaColumns = map[string]bool{
+	"Name": true, "Description": true, "Due Date": true, "Tags": true, "Completed": true,
+}
+
 func parseAsanaRow(row []string, colMap map[string]int, ec *salerr.ErrorCollector, sourcePath string, lineNum int) (model.CalendarItem, error) {
 	item := model.CalendarItem{
 		ItemType: model.ItemTypeTask,
@@ -0,0 +1,258 @@
+package parsers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/csvmap"
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// CSVParser reads a CSV file from any tool, with a mapping profile naming
+// the columns that feed each field.
+type CSVParser struct {
+	Profile *csvmap.Profile
+}
+
+// NewCSVParser returns a parser for the profile chosen with csvmap.Use.
+func NewCSVParser() *CSVParser {
+	return &CSVParser{Profile: csvmap.Active()}
+}
+
+func (p *CSVParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV %s: %w", filePath, err)
+	}
+	defer func() { _ = f.Close() }()
+	return p.Parse(ctx, f, filePath)
+}
+
+func (p *CSVParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	tr, err := transcodeReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("charset detection failed: %w", err)
+	}
+	csvReader := csv.NewReader(tr)
+	csvReader.Comma = p.Profile.Comma()
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "csv",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return collection, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV %s: %w", sourcePath, err)
+	}
+
+	// spreadsheet apps start UTF-8 CSVs with a byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	// each profile column reads every header of its name
+	indexes := make([][]int, len(p.Profile.Columns))
+	var titleCols []string
+	hasTitle := false
+	for i, c := range p.Profile.Columns {
+		for j, col := range header {
+			if strings.EqualFold(strings.TrimSpace(col), strings.TrimSpace(c.Name)) {
+				indexes[i] = append(indexes[i], j)
+			}
+		}
+		if c.Field == csvmap.FieldTitle {
+			titleCols = append(titleCols, c.Name)
+			hasTitle = hasTitle || len(indexes[i]) > 0
+		}
+	}
+	if !hasTitle {
+		return nil, fmt.Errorf("CSV %s missing required columns: %s", sourcePath, strings.Join(titleCols, ", "))
+	}
+
+	ec := salerr.NewErrorCollector()
+	lineNum := 1
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV %s: %w", sourcePath, err)
+		}
+		lineNum++
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		item := p.parseRow(row, indexes, ec, sourcePath, lineNum)
+		item.Extensions = columnExtensions("csv", header, row, p.hasColumn)
+		if item.Title == "" {
+			ec.AddWarning((&salerr.ParseError{File: sourcePath, Line: lineNum, Message: "row has no title; skipped"}).Error())
+			continue
+		}
+		collection.Items = append(collection.Items, item)
+	}
+
+	if len(ec.Warnings) > 0 {
+		for _, w := range ec.Warnings {
+			fmt.Fprintf(os.Stderr, "csv parser: %s\n", w)
+		}
+	}
+
+	return collection, nil
+}
+
+func (p *CSVParser) parseRow(row []string, indexes [][]int, ec *salerr.ErrorCollector, sourcePath string, lineNum int) model.CalendarItem {
+	item := model.CalendarItem{
+		ItemType: model.ItemTypeTask,
+		Status:   model.StatusPending,
+	}
+	if p.Profile.ItemType != "" {
+		item.ItemType = model.ItemType(p.Profile.ItemType)
+	}
+	warn := func(format string, args ...interface{}) {
+		ec.AddWarning((&salerr.ParseError{File: sourcePath, Line: lineNum, Message: fmt.Sprintf(format, args...)}).Error())
+	}
+	setText := func(field *string, v string) {
+		if *field == "" {
+			*field = v
+		}
+	}
+
+	for i := range p.Profile.Columns {
+		c := &p.Profile.Columns[i]
+		for _, idx := range indexes[i] {
+			if idx >= len(row) || strings.TrimSpace(row[idx]) == "" {
+				continue
+			}
+			raw := row[idx]
+			v := strings.TrimSpace(raw)
+
+			switch c.Field {
+			case csvmap.FieldUID:
+				setText(&item.UID, v)
+			case csvmap.FieldTitle:
+				setText(&item.Title, v)
+			case csvmap.FieldDescription:
+				setText(&item.Description, strings.TrimSpace(strings.ReplaceAll(raw, "\r\n", "\n")))
+			case csvmap.FieldLocation:
+				setText(&item.Location, v)
+
+			case csvmap.FieldType:
+				name := v
+				if mapped, ok := c.Import(v); ok {
+					name = mapped
+				}
+				if t, ok := parseItemTypeName(name); ok {
+					item.ItemType = t
+				} else {
+					warn("unknown item type %q in field %s", v, c.Name)
+				}
+			case csvmap.FieldStatus:
+				if mapped, ok := c.Import(v); ok {
+					item.Status = model.Status(mapped)
+				} else if s, ok := parseStatusName(v); ok {
+					item.Status = s
+				} else {
+					warn("unknown status %q in field %s", v, c.Name)
+				}
+			case csvmap.FieldPriority:
+				name := v
+				if mapped, ok := c.Import(v); ok {
+					name = mapped
+				}
+				if pr, ok := parsePriorityName(name); ok {
+					item.Priority = pr
+				} else {
+					warn("unknown priority %q in field %s", v, c.Name)
+				}
+
+			case csvmap.FieldTags:
+				sep := c.Separator
+				if sep == "" {
+					sep = ","
+				}
+				for _, tag := range strings.Split(v, sep) {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						item.Tags = append(item.Tags, tag)
+					}
+				}
+			case csvmap.FieldProject:
+				item.Tags = append(item.Tags, model.ProjectTagPrefix+v)
+			case csvmap.FieldAllDay:
+				item.IsAllDay = isTruthWord(v)
+
+			default:
+				t, err := p.parseDate(c, v)
+				if err != nil {
+					warn("malformed date value %q in field %s", v, c.Name)
+					continue
+				}
+				switch c.Field {
+				case csvmap.FieldStart:
+					item.StartTime = &t
+				case csvmap.FieldEnd:
+					item.EndTime = &t
+				case csvmap.FieldDue:
+					item.DueDate = &t
+				case csvmap.FieldCompleted:
+					item.CompletionDate = &t
+				case csvmap.FieldCreated:
+					item.CreatedAt = &t
+				case csvmap.FieldUpdated:
+					item.UpdatedAt = &t
+				}
+			}
+		}
+	}
+
+	// a completion date without a status column closes the item
+	if item.CompletionDate != nil && item.Status == model.StatusPending && !p.hasField(csvmap.FieldStatus) {
+		item.Status = model.StatusCompleted
+	}
+	return item
+}
+
+// parseDate tries the column's layouts, then the locale-aware date parser.
+func (p *CSVParser) parseDate(c *csvmap.Column, v string) (time.Time, error) {
+	for _, layout := range p.Profile.Layouts(c) {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return parseAmbiguousDate(v)
+}
+
+func (p *CSVParser) hasColumn(name string) bool {
+	for _, c := range p.Profile.Columns {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(c.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CSVParser) hasField(field string) bool {
+	for _, c := range p.Profile.Columns {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package parsers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/csvmap"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func builtinProfile(t *testing.T, name string) *csvmap.Profile {
+	t.Helper()
+	p, ok := csvmap.Builtin(name)
+	if !ok {
+		t.Fatalf("no built-in profile %q", name)
+	}
+	return p
+}
+
+func TestCSVParseJira(t *testing.T) {
+	input := "\ufeffSummary,Issue key,Issue Type,Status,Project name,Priority,Labels,Labels,Due Date,Resolved,Created,Description\n" +
+		"Fix login,WEB-12,Bug,In Progress,Website,High,auth,urgent,21/Mar/26 12:00 AM,,02/Mar/26 9:15 AM,\"Users see a 500\r\non submit\"\n" +
+		"Ship docs,WEB-13,Task,Done,Website,Lowest,docs,,,04/Mar/26 5:30 PM,01/Mar/26 10:00 AM,\n" +
+		",,,,,,,,,,,\n" +
+		"Triage,WEB-14,Task,Parked,Website,Meh,,,someday,,,\n"
+	p := &CSVParser{Profile: builtinProfile(t, "jira")}
+	col, err := p.Parse(context.Background(), strings.NewReader(input), "jira.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if col.SourceApp != "csv" || len(col.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(col.Items))
+	}
+
+	login := col.Items[0]
+	if login.Title != "Fix login" || login.UID != "WEB-12" || login.ItemType != model.ItemTypeTask {
+		t.Errorf("login = %+v", login)
+	}
+	if login.Status != model.StatusInProgress || login.Priority != model.PriorityHigh {
+		t.Errorf("status, priority = %v %v", login.Status, login.Priority)
+	}
+	// repeated Labels columns are merged, after the project tag
+	if strings.Join(login.Tags, ",") != "project:Website,auth,urgent" {
+		t.Errorf("tags = %v", login.Tags)
+	}
+	if want := time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC); login.DueDate == nil || !login.DueDate.Equal(want) {
+		t.Errorf("due = %v", login.DueDate)
+	}
+	if want := time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC); login.CreatedAt == nil || !login.CreatedAt.Equal(want) {
+		t.Errorf("created = %v", login.CreatedAt)
+	}
+	if login.Description != "Users see a 500\non submit" {
+		t.Errorf("description = %q", login.Description)
+	}
+
+	docs := col.Items[1]
+	if docs.Status != model.StatusCompleted || docs.Priority != model.PriorityLowest || docs.CompletionDate == nil || docs.CompletionDate.Hour() != 17 {
+		t.Errorf("docs = %+v", docs)
+	}
+
+	// unknown values are warned about and left at their defaults
+	triage := col.Items[2]
+	if triage.Status != model.StatusPending || triage.Priority != model.PriorityNone || triage.DueDate != nil {
+		t.Errorf("triage = %+v", triage)
+	}
+}
+
+func TestCSVParseLinear(t *testing.T) {
+	input := `ID,Team,Title,Description,Status,Priority,Project,Labels,Created,Completed,Due Date
+ENG-7,Engineering,Rate limit API,,Canceled,Urgent,,"Backend, Infra",2026-03-01T10:00:00.000Z,,2026-03-20
+ENG-8,Engineering,Add SSO,Okta first,Todo,No priority,Auth,,2026-03-02T11:30:00.000Z,,
+`
+	p := &CSVParser{Profile: builtinProfile(t, "linear")}
+	col, err := p.Parse(context.Background(), strings.NewReader(input), "linear.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(col.Items))
+	}
+	limit := col.Items[0]
+	if limit.UID != "ENG-7" || limit.Status != model.StatusCancelled || limit.Priority != model.PriorityHighest {
+		t.Errorf("limit = %+v", limit)
+	}
+	if strings.Join(limit.Tags, ",") != "Backend,Infra" || limit.DueDate == nil || limit.DueDate.Day() != 20 {
+		t.Errorf("tags, due = %v %v", limit.Tags, limit.DueDate)
+	}
+	sso := col.Items[1]
+	if sso.Status != model.StatusPending || sso.Priority != model.PriorityNone || strings.Join(sso.Tags, ",") != "project:Auth" {
+		t.Errorf("sso = %+v", sso)
+	}
+	if sso.CreatedAt == nil || sso.CreatedAt.Hour() != 11 || sso.CreatedAt.Minute() != 30 {
+		t.Errorf("created = %v", sso.CreatedAt)
+	}
+}
+
+func TestCSVParseCustomProfile(t *testing.T) {
+	profile := &csvmap.Profile{
+		Delimiter:   ";",
+		ItemType:    "event",
+		DateFormats: []string{"02.01.2006 15:04"},
+		Columns: []csvmap.Column{
+			{Name: "Betreff", Field: csvmap.FieldTitle},
+			{Name: "Beginn", Field: csvmap.FieldStart},
+			{Name: "Ort", Field: csvmap.FieldLocation},
+			{Name: "Ganztägig", Field: csvmap.FieldAllDay},
+		},
+	}
+	input := "Betreff;Beginn;Ort;Ganztägig\nPlanung;10.03.2026 14:00;Raum 2;nein\nFeiertag;2026-04-03;;ja\n"
+	col, err := (&CSVParser{Profile: profile}).Parse(context.Background(), strings.NewReader(input), "kalender.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(col.Items))
+	}
+	plan := col.Items[0]
+	if plan.ItemType != model.ItemTypeEvent || plan.Location != "Raum 2" || plan.IsAllDay {
+		t.Errorf("plan = %+v", plan)
+	}
+	if want := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC); plan.StartTime == nil || !plan.StartTime.Equal(want) {
+		t.Errorf("start = %v", plan.StartTime)
+	}
+	// dates no layout reads fall back to the locale-aware parser
+	if holiday := col.Items[1]; holiday.StartTime == nil || holiday.StartTime.Month() != time.April {
+		t.Errorf("holiday = %+v", holiday)
+	}
+
+	if _, err := (&CSVParser{Profile: profile}).Parse(context.Background(), strings.NewReader("Subject;Start\nx;y\n"), "other.csv"); err == nil || !strings.Contains(err.Error(), "Betreff") {
+		t.Errorf("expected a missing column error, got %v", err)
+	}
+}
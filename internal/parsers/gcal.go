@@ -78,6 +78,7 @@ func (p *GoogleCalendarParser) Parse(ctx context.Context, r io.Reader, sourcePat
 		if err != nil {
 			return nil, fmt.Errorf("%s line %d: %w", sourcePath, lineNum, err)
 		}
+		item.Extensions = columnExtensions("gcal", header, row, func(col string) bool { return gcalColumns[col] })
 		collection.Items = append(collection.Items, item)
 	}
 
@@ -90,6 +91,13 @@ func (p *GoogleCalendarParser) Parse(ctx context.Context, r io.Reader, sourcePat
 	return collection, nil
 }
 
+// gcalColumns are the columns parseGCalRow reads; the rest, such as
+// Private, are kept as extensions.
+var gcalColumns = map[string]bool{
+	"Subject": true, "Start Date": true, "Start Time": true, "End Date": true, "End Time": true,
+	"All Day Event": true, "Description": true, "Location": true,
+}
+
 func parseGCalRow(row []string, colMap map[string]int, ec *salerr.ErrorCollector, sourcePath string, lineNum int) (model.CalendarItem, error) {
 	item := model.CalendarItem{
 		ItemType: model.ItemTypeEvent,
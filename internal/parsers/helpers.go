@@ -1,11 +1,15 @@
 package parsers
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
 	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
 )
 
 // findMissingColumns checks which required columns are absent from colMap.
@@ -19,6 +23,56 @@ func findMissingColumns(colMap map[string]int, required []string) []string {
 	return missing
 }
 
+// columnExtensions keeps the non-empty cells of the columns known does not
+// report, so the writer for the same format can write them back.
+func columnExtensions(namespace string, header, row []string, known func(col string) bool) []model.Extension {
+	var exts []model.Extension
+	for i, col := range header {
+		if i >= len(row) || col == "" || known(col) || strings.TrimSpace(row[i]) == "" {
+			continue
+		}
+		exts = append(exts, model.Extension{Namespace: namespace, Name: col, Value: row[i]})
+	}
+	return exts
+}
+
+// jsonHeadKeys returns the keys of the first JSON object in head, the start
+// of a file, and whether that object is an element of a top-level array.
+// Keys are collected until the object ends or head is cut off mid-value.
+func jsonHeadKeys(head []byte) (keys map[string]bool, inArray bool) {
+	dec := json.NewDecoder(bytes.NewReader(head))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, false
+	}
+	if tok == json.Delim('[') {
+		inArray = true
+		if tok, err = dec.Token(); err != nil {
+			return nil, inArray
+		}
+	}
+	if tok != json.Delim('{') {
+		return nil, inArray
+	}
+	keys = map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, ok := tok.(string)
+		if !ok {
+			break
+		}
+		keys[key] = true
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			break
+		}
+	}
+	return keys, inArray
+}
+
 // transcodeReader wraps an io.Reader with automatic charset detection and UTF-8 transcoding.
 func transcodeReader(r io.Reader) (io.Reader, error) {
 	tr, _, err := salerr.TranscodeToUTF8(r)
@@ -43,3 +97,70 @@ func SetLocale(locale string) {
 	defaultDateParser = salerr.NewAmbiguousDateParser(locale)
 	dateParserMu.Unlock()
 }
+
+// isTruthWord reads a checkbox or yes/no cell; isFalseWord its negatives.
+func isTruthWord(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "yes", "y", "x", "1", "done":
+		return true
+	}
+	return false
+}
+
+func isFalseWord(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "false", "no", "n", "0":
+		return true
+	}
+	return false
+}
+
+// parseStatusName reads the status names planners and trackers commonly use.
+func parseStatusName(s string) (model.Status, bool) {
+	switch strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(s, "_", " ")), " ")) {
+	case "pending", "todo", "to do", "not started", "open", "needs action":
+		return model.StatusPending, true
+	case "in progress", "doing", "started", "active":
+		return model.StatusInProgress, true
+	case "completed", "complete", "done", "closed", "finished":
+		return model.StatusCompleted, true
+	case "cancelled", "canceled":
+		return model.StatusCancelled, true
+	}
+	return "", false
+}
+
+// parsePriorityName reads a priority name or the model's 0-5 scale.
+func parsePriorityName(s string) (model.Priority, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "none":
+		return model.PriorityNone, true
+	case "lowest":
+		return model.PriorityLowest, true
+	case "low":
+		return model.PriorityLow, true
+	case "medium", "normal":
+		return model.PriorityMedium, true
+	case "high":
+		return model.PriorityHigh, true
+	case "highest", "urgent":
+		return model.PriorityHighest, true
+	case "0", "1", "2", "3", "4", "5":
+		return model.Priority(s[0] - '0'), true
+	}
+	return model.PriorityNone, false
+}
+
+// parseItemTypeName reads an item type, singular or plural.
+func parseItemTypeName(s string) (model.ItemType, bool) {
+	switch t := strings.ToLower(strings.TrimSpace(s)); {
+	case strings.HasPrefix(t, "event"):
+		return model.ItemTypeEvent, true
+	case strings.HasPrefix(t, "task"), t == "todo", t == "to-do":
+		return model.ItemTypeTask, true
+	case strings.HasPrefix(t, "journal"), t == "note":
+		return model.ItemTypeJournal, true
+	}
+	return "", false
+}
@@ -0,0 +1,525 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// MarkdownTasksDateLayout is the date form of Obsidian Tasks fields.
+const MarkdownTasksDateLayout = "2006-01-02"
+
+// MarkdownTasksParser reads task lists from markdown notes: Obsidian Tasks
+// checklists (- [ ] ...) and Logseq blocks (- TODO ...). Both syntaxes are
+// read whichever constructor is used.
+type MarkdownTasksParser struct {
+	// Logseq marks the collection as read from a Logseq graph.
+	Logseq bool
+}
+
+func NewMarkdownTasksParser() *MarkdownTasksParser {
+	return &MarkdownTasksParser{}
+}
+
+func NewLogseqParser() *MarkdownTasksParser {
+	return &MarkdownTasksParser{Logseq: true}
+}
+
+var (
+	mdListItem     = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+(.*?)\s*$`)
+	mdCheckbox     = regexp.MustCompile(`^\[(.)\]\s+(.*)$`)
+	mdFence        = regexp.MustCompile("^\\s*(```|~~~)")
+	mdTag          = regexp.MustCompile(`(^|\s)#(\[\[([^\]]+)\]\]|[\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+	mdInlineField  = regexp.MustCompile(`[\[(]([\w-]+)::\s*([^\])]*?)\s*[\])]`)
+	mdDate         = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	logseqMarker   = regexp.MustCompile(`^(TODO|LATER|NOW|DOING|IN-PROGRESS|WAIT|WAITING|DONE|CANCELED|CANCELLED)\s+(.*)$`)
+	logseqProperty = regexp.MustCompile(`^([\w-]+)::\s*(.*)$`)
+)
+
+// obsidianSignifiers are the Obsidian Tasks emoji and the field each marks.
+var obsidianSignifiers = map[rune]string{
+	'📅': "due", '📆': "due", '🗓': "due",
+	'⏳': "scheduled", '⌛': "scheduled",
+	'🛫': "start",
+	'➕': "created",
+	'✅': "done",
+	'❌': "cancelled",
+	'🔁': "repeat",
+	'🆔': "id",
+	'🔺': "highest", '⏫': "high", '🔼': "medium", '🔽': "low", '⏬': "lowest",
+}
+
+// mdNode is an open list item while the file is read.
+type mdNode struct {
+	indent int
+	root   *model.CalendarItem // the top-level task the item belongs to
+	own    bool                // the item is root's own block
+	drawer bool                // inside a Logseq :LOGBOOK: drawer
+}
+
+func (p *MarkdownTasksParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open markdown file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.Parse(ctx, f, filePath)
+}
+
+// Parse reads every task in the file. Nested tasks become subtasks of the
+// top-level task above them, and other nested lines its description.
+func (p *MarkdownTasksParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	tr, err := transcodeReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("charset detection failed: %w", err)
+	}
+
+	source := "markdown-tasks"
+	if p.Logseq {
+		source = "logseq"
+	}
+	ec := salerr.NewErrorCollector()
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        source,
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+
+	var items []*model.CalendarItem
+	desc := make(map[*model.CalendarItem][]string)
+	var stack []*mdNode
+	inFence := false
+
+	scanner := bufio.NewScanner(tr)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		warn := func(msg string) {
+			ec.AddWarning((&salerr.ParseError{File: sourcePath, Line: lineNum, Message: msg}).Error())
+		}
+
+		if mdFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if inFence || trimmed == "" {
+			continue
+		}
+		indent := mdIndent(line)
+
+		if m := mdListItem.FindStringSubmatch(line); m != nil {
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			node := &mdNode{indent: indent}
+			if len(stack) > 0 {
+				node.root = stack[len(stack)-1].root
+			}
+			task, ok, warnings := parseMarkdownTask(m[2])
+			for _, w := range warnings {
+				warn(w)
+			}
+			switch {
+			case ok && node.root == nil:
+				if task.Title == "" {
+					warn("skipping task with empty text")
+					break
+				}
+				items = append(items, &task)
+				node.root, node.own = &task, true
+			case ok:
+				node.root.Subtasks = append(node.root.Subtasks, model.Subtask{
+					Title:     task.Title,
+					Status:    task.Status,
+					Priority:  task.Priority,
+					SortOrder: len(node.root.Subtasks),
+				})
+			case node.root != nil:
+				desc[node.root] = append(desc[node.root], trimmed)
+			}
+			stack = append(stack, node)
+			continue
+		}
+
+		// a line indented under a list item continues it, any other ends the list
+		if len(stack) == 0 || indent <= stack[len(stack)-1].indent {
+			stack = nil
+			continue
+		}
+		node := stack[len(stack)-1]
+		if node.root == nil {
+			continue
+		}
+		switch {
+		case node.drawer:
+			node.drawer = !strings.EqualFold(trimmed, ":END:")
+		case orgDrawer.MatchString(trimmed) && !strings.EqualFold(trimmed, ":END:"):
+			node.drawer = true
+		case isLogseqPlanning(trimmed):
+			// subtasks have no dates of their own
+			if node.own {
+				for _, w := range applyOrgPlanning(node.root, trimmed) {
+					warn(w)
+				}
+			}
+		case logseqProperty.MatchString(trimmed):
+			if node.own {
+				m := logseqProperty.FindStringSubmatch(trimmed)
+				applyLogseqProperty(node.root, m[1], m[2])
+			}
+		default:
+			desc[node.root] = append(desc[node.root], trimmed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read markdown: %w", err)
+	}
+
+	for _, item := range items {
+		item.Description = strings.Join(desc[item], "\n")
+		collection.Items = append(collection.Items, *item)
+	}
+
+	for _, w := range ec.Warnings {
+		fmt.Fprintf(os.Stderr, "%s parser: %s\n", source, w)
+	}
+
+	return collection, nil
+}
+
+// mdIndent returns the width of the line's leading whitespace, a tab
+// counting as four columns.
+func mdIndent(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4 - n%4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// parseMarkdownTask reads a list item's text as a task, reporting false when
+// it is neither a checkbox nor a Logseq task block.
+func parseMarkdownTask(text string) (model.CalendarItem, bool, []string) {
+	item := model.CalendarItem{
+		ItemType: model.ItemTypeTask,
+		Status:   model.StatusPending,
+	}
+	if m := mdCheckbox.FindStringSubmatch(text); m != nil {
+		item.Status = mapMarkdownCheckbox(m[1])
+		warnings := applyObsidianFields(&item, m[2])
+		if item.Status != model.StatusCompleted && item.Status != model.StatusCancelled {
+			item.CompletionDate = nil
+		}
+		return item, true, warnings
+	}
+	if m := logseqMarker.FindStringSubmatch(text); m != nil {
+		item.Status = mapLogseqMarker(m[1])
+		rest := m[2]
+		if p := orgPriority.FindStringSubmatch(rest); p != nil {
+			item.Priority = mapOrgPriority(p[1])
+			rest = rest[len(p[0]):]
+		}
+		item.Title, item.Tags = takeMarkdownTags(rest)
+		return item, true, nil
+	}
+	return item, false, nil
+}
+
+// applyObsidianFields reads the Obsidian Tasks emoji fields, and Dataview
+// inline fields such as [due:: 2026-03-05], off a checkbox line. Values that
+// do not parse stay in the title.
+func applyObsidianFields(item *model.CalendarItem, text string) []string {
+	var warnings []string
+	text = strings.ReplaceAll(text, "\uFE0F", "")
+
+	var rest []string
+	text = mdInlineField.ReplaceAllStringFunc(text, func(field string) string {
+		m := mdInlineField.FindStringSubmatch(field)
+		key, value := strings.ToLower(m[1]), m[2]
+		switch key {
+		case "completion":
+			key = "done"
+		case "priority":
+			key, value = strings.ToLower(value), ""
+		}
+		leftover, err := setObsidianField(item, key, value)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			return field
+		}
+		if leftover != "" {
+			rest = append(rest, leftover)
+		}
+		return " "
+	})
+
+	// each emoji's value runs to the next emoji
+	type mark struct {
+		pos, end int
+		key      string
+	}
+	var marks []mark
+	for i, c := range text {
+		if key, ok := obsidianSignifiers[c]; ok {
+			marks = append(marks, mark{i, i + len(string(c)), key})
+		}
+	}
+	title := text
+	if len(marks) > 0 {
+		title = text[:marks[0].pos]
+	}
+	for i, m := range marks {
+		end := len(text)
+		if i+1 < len(marks) {
+			end = marks[i+1].pos
+		}
+		raw := text[m.pos:end]
+		leftover, err := setObsidianField(item, m.key, text[m.end:end])
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			title += " " + raw
+			continue
+		}
+		title += " " + leftover
+	}
+	title = strings.Join(append([]string{title}, rest...), " ")
+	item.Title, item.Tags = takeMarkdownTags(title)
+	return warnings
+}
+
+// setObsidianField sets one field from its value and returns what follows
+// the value, such as tags written after a date.
+func setObsidianField(item *model.CalendarItem, key, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch key {
+	case "due", "scheduled", "created", "done", "cancelled", "start":
+		d := mdDate.FindString(value)
+		t, err := time.Parse(MarkdownTasksDateLayout, d)
+		if err != nil {
+			return "", fmt.Errorf("malformed %s date %q", key, value)
+		}
+		switch key {
+		case "due":
+			item.DueDate = &t
+		case "scheduled":
+			item.StartTime = &t
+		case "start":
+			// the start date stands in for a missing scheduled date
+			if item.StartTime == nil {
+				item.StartTime = &t
+			}
+		case "created":
+			item.CreatedAt = &t
+		default:
+			item.CompletionDate = &t
+		}
+		return value[len(d):], nil
+	case "repeat":
+		// tags may follow the rule
+		rule, tags, _ := strings.Cut(value, " #")
+		if tags != "" {
+			tags = "#" + tags
+		}
+		rec, err := parseRecurrenceText(rule)
+		if err != nil {
+			return "", err
+		}
+		item.Recurrence = rec
+		return tags, nil
+	case "id":
+		id, after, _ := strings.Cut(value, " ")
+		item.UID = id
+		return after, nil
+	case "highest", "high", "medium", "low", "lowest":
+		item.Priority = map[string]model.Priority{
+			"highest": model.PriorityHighest,
+			"high":    model.PriorityHigh,
+			"medium":  model.PriorityMedium,
+			"low":     model.PriorityLow,
+			"lowest":  model.PriorityLowest,
+		}[key]
+		return value, nil
+	default:
+		return "", fmt.Errorf("unsupported field %s", key)
+	}
+}
+
+// takeMarkdownTags removes #tags, including Logseq #[[multi word]] tags, from
+// text. A #project/name tag is the item's project.
+func takeMarkdownTags(text string) (string, []string) {
+	var tags []string
+	text = mdTag.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdTag.FindStringSubmatch(s)
+		tag := m[2]
+		if m[3] != "" {
+			tag = m[3]
+		}
+		if name, ok := strings.CutPrefix(tag, "project/"); ok && name != "" {
+			tag = model.ProjectTagPrefix + name
+		}
+		tags = append(tags, tag)
+		return m[1]
+	})
+	return strings.Join(strings.Fields(text), " "), tags
+}
+
+// isLogseqPlanning reports whether line is a SCHEDULED or DEADLINE line.
+func isLogseqPlanning(line string) bool {
+	loc := orgPlanning.FindStringIndex(line)
+	return loc != nil && loc[0] == 0
+}
+
+func applyLogseqProperty(item *model.CalendarItem, key, value string) {
+	switch strings.ToLower(key) {
+	case "id":
+		item.UID = strings.TrimSpace(value)
+	case "tags":
+		for _, t := range strings.Split(value, ",") {
+			t = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(t), "#"), "[["), "]]")
+			if t != "" {
+				item.Tags = append(item.Tags, t)
+			}
+		}
+	}
+}
+
+// mapMarkdownCheckbox maps the Obsidian Tasks status characters; custom ones
+// such as [>] or [?] are open tasks.
+func mapMarkdownCheckbox(c string) model.Status {
+	switch c {
+	case "x", "X":
+		return model.StatusCompleted
+	case "-":
+		return model.StatusCancelled
+	case "/":
+		return model.StatusInProgress
+	default:
+		return model.StatusPending
+	}
+}
+
+func mapLogseqMarker(marker string) model.Status {
+	switch marker {
+	case "DONE":
+		return model.StatusCompleted
+	case "CANCELED", "CANCELLED":
+		return model.StatusCancelled
+	case "NOW", "DOING", "IN-PROGRESS":
+		return model.StatusInProgress
+	default:
+		return model.StatusPending
+	}
+}
+
+var (
+	recurrenceText = regexp.MustCompile(`^every\s+(?:(\d+)\s+)?(day|week|month|year|weekday)s?(?:\s+on\s+(.+?))?(?:\s+until\s+(\d{4}-\d{2}-\d{2}))?(?:\s+for\s+(\d+)\s+times?)?(?:\s+when\s+done)?$`)
+	recurrenceOrd  = regexp.MustCompile(`^the\s+(?:(\d{1,2})(?:st|nd|rd|th)|(last))(?:\s+day)?$`)
+)
+
+var weekdayNames = map[string]model.Weekday{
+	"monday": model.WeekdayMO, "tuesday": model.WeekdayTU, "wednesday": model.WeekdayWE,
+	"thursday": model.WeekdayTH, "friday": model.WeekdayFR, "saturday": model.WeekdaySA, "sunday": model.WeekdaySU,
+}
+
+// parseRecurrenceText reads an Obsidian Tasks rule such as "every 2 weeks",
+// "every week on Monday, Friday", "every weekday" or "every month on the
+// last". "when done" has no field of its own and is accepted as is.
+func parseRecurrenceText(text string) (*model.Recurrence, error) {
+	s := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	// "every monday" is the weekly rule on that day
+	if first, _, _ := strings.Cut(strings.TrimPrefix(s, "every "), " "); weekdayNames[strings.TrimSuffix(first, ",")] != "" {
+		s = "every week on " + strings.TrimPrefix(s, "every ")
+	}
+	m := recurrenceText.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported recurrence %q", text)
+	}
+	rec := &model.Recurrence{Interval: 1}
+	if m[1] != "" {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("unsupported recurrence %q", text)
+		}
+		rec.Interval = n
+	}
+	switch m[2] {
+	case "day":
+		rec.Freq = model.FreqDaily
+	case "week":
+		rec.Freq = model.FreqWeekly
+	case "month":
+		rec.Freq = model.FreqMonthly
+	case "year":
+		rec.Freq = model.FreqYearly
+	case "weekday":
+		if rec.Interval != 1 || m[3] != "" {
+			return nil, fmt.Errorf("unsupported recurrence %q", text)
+		}
+		rec.Freq = model.FreqWeekly
+		rec.ByDay = []model.Weekday{model.WeekdayMO, model.WeekdayTU, model.WeekdayWE, model.WeekdayTH, model.WeekdayFR}
+	}
+
+	if on := m[3]; on != "" {
+		switch rec.Freq {
+		case model.FreqWeekly:
+			for _, d := range strings.FieldsFunc(strings.ReplaceAll(on, " and ", ","), func(r rune) bool { return r == ',' }) {
+				day, ok := weekdayNames[strings.TrimSpace(d)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported recurrence %q", text)
+				}
+				rec.ByDay = append(rec.ByDay, day)
+			}
+		case model.FreqMonthly:
+			om := recurrenceOrd.FindStringSubmatch(on)
+			if om == nil {
+				return nil, fmt.Errorf("unsupported recurrence %q", text)
+			}
+			day := -1
+			if om[2] == "" {
+				day, _ = strconv.Atoi(om[1])
+				if day < 1 || day > 31 {
+					return nil, fmt.Errorf("unsupported recurrence %q", text)
+				}
+			}
+			rec.ByMonthDay = []int{day}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence %q", text)
+		}
+	}
+	if m[4] != "" {
+		t, err := time.Parse(MarkdownTasksDateLayout, m[4])
+		if err != nil {
+			return nil, fmt.Errorf("unsupported recurrence %q", text)
+		}
+		rec.Until = &t
+	}
+	if m[5] != "" {
+		n, err := strconv.Atoi(m[5])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("unsupported recurrence %q", text)
+		}
+		rec.Count = &n
+	}
+	return rec, nil
+}
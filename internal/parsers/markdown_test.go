@@ -0,0 +1,159 @@
+package parsers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func TestMarkdownTasksObsidian(t *testing.T) {
+	input := "# Inbox\n\n" +
+		"Some prose with #notatag-in-a-paragraph.\n\n" +
+		"- [ ] Call Mom #family ⏫ 🔁 every week on Monday, Friday ➕ 2026-03-01 ⏳ 2026-03-04 📅 2026-03-05\n" +
+		"  Ask about the trip.\n" +
+		"  - bring photos\n" +
+		"\t- [x] Buy card 🔼\n" +
+		"\t\t- [ ] Pick stamps\n" +
+		"- [x] File taxes #project/Admin ✅ 2026-03-04 📅 2026-03-05\n" +
+		"- [-] Dropped idea ❌ 2026-03-02\n" +
+		"- [/] Draft post [priority:: lowest] [due:: 2026-04-01] #writing\n" +
+		"- plain bullet, not a task\n" +
+		"```\n- [ ] inside a code block\n```\n" +
+		"1. [ ] Numbered 📅 2026-13-40\n"
+	col, err := NewMarkdownTasksParser().Parse(context.Background(), strings.NewReader(input), "tasks.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 5 {
+		t.Fatalf("expected 5 items, got %d: %+v", len(col.Items), col.Items)
+	}
+
+	call := col.Items[0]
+	if call.Title != "Call Mom" || call.Priority != model.PriorityHigh || strings.Join(call.Tags, ",") != "family" {
+		t.Errorf("call = %+v", call)
+	}
+	day := func(tm *time.Time) string {
+		if tm == nil {
+			return ""
+		}
+		return tm.Format("2006-01-02")
+	}
+	if day(call.CreatedAt) != "2026-03-01" || day(call.StartTime) != "2026-03-04" || day(call.DueDate) != "2026-03-05" {
+		t.Errorf("dates = %s %s %s", day(call.CreatedAt), day(call.StartTime), day(call.DueDate))
+	}
+	if rec := call.Recurrence; rec == nil || rec.Freq != model.FreqWeekly || len(rec.ByDay) != 2 || rec.ByDay[1] != model.WeekdayFR {
+		t.Errorf("recurrence = %+v", call.Recurrence)
+	}
+	if call.Description != "Ask about the trip.\n- bring photos" {
+		t.Errorf("description = %q", call.Description)
+	}
+	want := []model.Subtask{
+		{Title: "Buy card", Status: model.StatusCompleted, Priority: model.PriorityMedium, SortOrder: 0},
+		{Title: "Pick stamps", Status: model.StatusPending, SortOrder: 1},
+	}
+	if len(call.Subtasks) != 2 || call.Subtasks[0] != want[0] || call.Subtasks[1] != want[1] {
+		t.Errorf("subtasks = %+v", call.Subtasks)
+	}
+
+	taxes := col.Items[1]
+	if taxes.Status != model.StatusCompleted || day(taxes.CompletionDate) != "2026-03-04" || taxes.Tags[0] != "project:Admin" {
+		t.Errorf("taxes = %+v", taxes)
+	}
+	if dropped := col.Items[2]; dropped.Status != model.StatusCancelled || day(dropped.CompletionDate) != "2026-03-02" {
+		t.Errorf("dropped = %+v", dropped)
+	}
+	draft := col.Items[3]
+	if draft.Status != model.StatusInProgress || draft.Priority != model.PriorityLowest || day(draft.DueDate) != "2026-04-01" || draft.Title != "Draft post" {
+		t.Errorf("draft = %+v", draft)
+	}
+	// a malformed date stays in the title
+	if numbered := col.Items[4]; numbered.Title != "Numbered 📅 2026-13-40" || numbered.DueDate != nil {
+		t.Errorf("numbered = %+v", numbered)
+	}
+}
+
+func TestMarkdownTasksLogseq(t *testing.T) {
+	input := "title:: Plans\n\n" +
+		"- Monday notes\n" +
+		"\t- TODO [#A] Ship release #work #[[deep work]]\n" +
+		"\t  id:: 5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f\n" +
+		"\t  SCHEDULED: <2026-03-10 Tue 09:00 .+1w>\n" +
+		"\t  DEADLINE: <2026-03-15 Sun>\n" +
+		"\t  :LOGBOOK:\n" +
+		"\t  CLOCK: [2026-03-09 Mon 10:00:00]\n" +
+		"\t  :END:\n" +
+		"\t  Check the changelog.\n" +
+		"\t\t- DONE Write notes\n" +
+		"\t\t- LATER Tag build\n" +
+		"- NOW Review PR\n" +
+		"- CANCELED Old idea\n"
+	col, err := NewLogseqParser().Parse(context.Background(), strings.NewReader(input), "plans.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if col.SourceApp != "logseq" || len(col.Items) != 3 {
+		t.Fatalf("got %s with %d items", col.SourceApp, len(col.Items))
+	}
+	ship := col.Items[0]
+	if ship.Title != "Ship release" || ship.Priority != model.PriorityHigh || strings.Join(ship.Tags, ",") != "work,deep work" {
+		t.Errorf("ship = %+v", ship)
+	}
+	if ship.UID != "5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f" || ship.Description != "Check the changelog." {
+		t.Errorf("uid/description = %q %q", ship.UID, ship.Description)
+	}
+	if ship.StartTime == nil || ship.StartTime.Hour() != 9 || ship.DueDate == nil || ship.DueDate.Day() != 15 {
+		t.Errorf("planning = %v %v", ship.StartTime, ship.DueDate)
+	}
+	if ship.Recurrence == nil || ship.Recurrence.Freq != model.FreqWeekly {
+		t.Errorf("recurrence = %+v", ship.Recurrence)
+	}
+	if len(ship.Subtasks) != 2 || ship.Subtasks[0].Status != model.StatusCompleted || ship.Subtasks[1].Title != "Tag build" {
+		t.Errorf("subtasks = %+v", ship.Subtasks)
+	}
+	if col.Items[1].Status != model.StatusInProgress || col.Items[2].Status != model.StatusCancelled {
+		t.Errorf("statuses = %s %s", col.Items[1].Status, col.Items[2].Status)
+	}
+}
+
+func TestParseRecurrenceText(t *testing.T) {
+	count := 3
+	until := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	for in, want := range map[string]model.Recurrence{
+		"every day":                      {Freq: model.FreqDaily, Interval: 1},
+		"every 2 weeks":                  {Freq: model.FreqWeekly, Interval: 2},
+		"Every Tuesday and Thursday":     {Freq: model.FreqWeekly, Interval: 1, ByDay: []model.Weekday{model.WeekdayTU, model.WeekdayTH}},
+		"every weekday":                  {Freq: model.FreqWeekly, Interval: 1, ByDay: []model.Weekday{"MO", "TU", "WE", "TH", "FR"}},
+		"every month on the 15th":        {Freq: model.FreqMonthly, Interval: 1, ByMonthDay: []int{15}},
+		"every month on the last":        {Freq: model.FreqMonthly, Interval: 1, ByMonthDay: []int{-1}},
+		"every year until 2026-12-31":    {Freq: model.FreqYearly, Interval: 1, Until: &until},
+		"every 3 days for 3 times":       {Freq: model.FreqDaily, Interval: 3, Count: &count},
+		"every week on Sunday when done": {Freq: model.FreqWeekly, Interval: 1, ByDay: []model.Weekday{model.WeekdaySU}},
+	} {
+		rec, err := parseRecurrenceText(in)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if rec.Freq != want.Freq || rec.Interval != want.Interval || strings.Join(weekdayStrings(rec.ByDay), ",") != strings.Join(weekdayStrings(want.ByDay), ",") ||
+			len(rec.ByMonthDay) != len(want.ByMonthDay) || (len(want.ByMonthDay) > 0 && rec.ByMonthDay[0] != want.ByMonthDay[0]) ||
+			(want.Until == nil) != (rec.Until == nil) || (want.Count == nil) != (rec.Count == nil) {
+			t.Errorf("%q = %+v, want %+v", in, rec, want)
+		}
+	}
+	for _, in := range []string{"every hour", "every day on Monday", "every month on the 2nd Tuesday", "weekly"} {
+		if _, err := parseRecurrenceText(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func weekdayStrings(days []model.Weekday) []string {
+	var s []string
+	for _, d := range days {
+		s = append(s, string(d))
+	}
+	return s
+}
@@ -86,6 +86,7 @@ func (p *NotionParser) Parse(ctx context.Context, r io.Reader, sourcePath string
 		if err != nil {
 			return nil, fmt.Errorf("%s line %d: %w", sourcePath, lineNum, err)
 		}
+		item.Extensions = columnExtensions("notion", header, row, func(col string) bool { return notionColumns[col] })
 		collection.Items = append(collection.Items, item)
 	}
 
@@ -98,6 +99,13 @@ func (p *NotionParser) Parse(ctx context.Context, r io.Reader, sourcePath string
 	return collection, nil
 }
 
+// notionColumns are the column names parseNotionRow looks for; the rest of
+// a database's properties, such as Assignee, are kept as extensions.
+var notionColumns = map[string]bool{
+	"Title": true, "Name": true, "Task": true, "Date": true, "Due Date": true, "Due": true,
+	"Status": true, "Tags": true, "Labels": true, "Priority": true, "Description": true,
+}
+
 func parseNotionRow(row []string, colMap map[string]int, ec *salerr.ErrorCollector, sourcePath string, lineNum int) (model.CalendarItem, error) {
 	item := model.CalendarItem{
 		ItemType: model.ItemTypeTask,
@@ -112,6 +120,10 @@ func parseNotionRow(row []string, colMap map[string]int, ec *salerr.ErrorCollect
 		}
 	}
 
+	if idx, ok := colMap["Description"]; ok && idx < len(row) {
+		item.Description = row[idx]
+	}
+
 	dateCandidates := []string{"Date", "Due Date", "Due"}
 	for _, candidate := range dateCandidates {
 		if idx, ok := colMap[candidate]; ok && idx < len(row) && row[idx] != "" {
@@ -0,0 +1,746 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// Org timestamps have no zone and are read as local time.
+const (
+	OrgDateLayout     = "2006-01-02"
+	OrgDateTimeLayout = "2006-01-02 15:04"
+)
+
+type OrgParser struct{}
+
+func NewOrgParser() *OrgParser {
+	return &OrgParser{}
+}
+
+var (
+	orgHeadline = regexp.MustCompile(`^(\*+)\s+(.*?)\s*$`)
+	orgTags     = regexp.MustCompile(`\s+(:[^\s:]+(?::[^\s:]+)*:)$`)
+	orgPriority = regexp.MustCompile(`^\[#([A-Z0-9])\]\s*`)
+	orgPlanning = regexp.MustCompile(`\b(SCHEDULED|DEADLINE|CLOSED):\s*`)
+	orgDrawer   = regexp.MustCompile(`^:([\w-]+):\s*$`)
+	orgProperty = regexp.MustCompile(`^:([^\s:]+):\s*(.*)$`)
+	orgCheckbox = regexp.MustCompile(`^\s*[-+]\s+\[([ xX-])\]\s+(.*?)\s*$`)
+	orgStamp    = regexp.MustCompile(`([<\[])(\d{4}-\d{2}-\d{2})(?:\s+[^\s\d>\]+.-]+)?(?:\s+(\d{1,2}:\d{2})(?:-(\d{1,2}:\d{2}))?)?(?:\s+((?:\+\+|\.\+|\+)\d+[hdwmy]))?(?:\s+(--?\d+[hdwmy]))?[>\]]`)
+)
+
+// orgStampValue is one parsed timestamp.
+type orgStampValue struct {
+	active   bool
+	start    time.Time
+	end      *time.Time // set by a time range or a date range
+	allDay   bool
+	repeater string // e.g. +1w
+	warning  string // e.g. -2d
+}
+
+// setRangeEnd ends the stamp where end starts. A range of dates includes its
+// last day, which the model holds as an exclusive end as ICS does.
+func (s *orgStampValue) setRangeEnd(end *orgStampValue) {
+	t := end.start
+	if s.allDay && end.allDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	s.end = &t
+}
+
+type orgNode struct {
+	level    int
+	keyword  string
+	priority string
+	title    string
+	tags     []string
+	lines    []string
+	line     int
+	children []*orgNode
+}
+
+// orgKeywords holds the open and closed TODO keywords in effect.
+type orgKeywords struct {
+	open, done map[string]bool
+}
+
+func defaultOrgKeywords() orgKeywords {
+	return orgKeywords{
+		open: map[string]bool{"TODO": true, "NEXT": true, "STARTED": true, "WAITING": true},
+		done: map[string]bool{"DONE": true, "CANCELLED": true, "CANCELED": true},
+	}
+}
+
+func (p *OrgParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Org file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.Parse(ctx, f, filePath)
+}
+
+func (p *OrgParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	tr, err := transcodeReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("charset detection failed: %w", err)
+	}
+
+	ec := salerr.NewErrorCollector()
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "org",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+
+	keywords := defaultOrgKeywords()
+	var fileTags []string
+	var roots []*orgNode
+	var stack []*orgNode
+
+	scanner := bufio.NewScanner(tr)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		if m := orgHeadline.FindStringSubmatch(line); m != nil {
+			node := parseOrgHeadline(len(m[1]), m[2], keywords)
+			node.line = lineNum
+			for len(stack) > 0 && stack[len(stack)-1].level >= node.level {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				roots = append(roots, node)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+			continue
+		}
+
+		if len(stack) == 0 {
+			// settings before the first headline apply to the whole file
+			key, value, ok := orgSetting(line)
+			switch {
+			case !ok:
+			case key == "TODO" || key == "SEQ_TODO" || key == "TYP_TODO":
+				keywords = parseOrgKeywordSetting(value, keywords)
+			case key == "FILETAGS":
+				fileTags = append(fileTags, splitOrgTags(strings.TrimSpace(value))...)
+			}
+			continue
+		}
+		stack[len(stack)-1].lines = append(stack[len(stack)-1].lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Org file: %w", err)
+	}
+
+	for _, root := range roots {
+		for _, parsed := range orgNodeItems(root, nil, nil, keywords) {
+			for _, w := range parsed.warnings {
+				ec.AddWarning((&salerr.ParseError{File: sourcePath, Line: parsed.line, Message: w}).Error())
+			}
+			item := parsed.item
+			if item.Title == "" {
+				ec.AddWarning(fmt.Sprintf("%s line %d: skipping headline with empty title", sourcePath, parsed.line))
+				continue
+			}
+			item.Tags = mergeOrgTags(fileTags, item.Tags)
+			collection.Items = append(collection.Items, item)
+		}
+	}
+
+	for _, w := range ec.Warnings {
+		fmt.Fprintf(os.Stderr, "org parser: %s\n", w)
+	}
+
+	return collection, nil
+}
+
+func orgSetting(line string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "#+")
+	if !ok {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(rest, ":")
+	return strings.ToUpper(key), value, ok
+}
+
+// parseOrgKeywordSetting reads a #+TODO line such as "TODO NEXT | DONE
+// CANCELLED"; without a bar the last keyword is the done state. Fast-access
+// keys like DONE(d) are ignored.
+func parseOrgKeywordSetting(value string, kw orgKeywords) orgKeywords {
+	words := strings.Fields(value)
+	bar := -1
+	for i, w := range words {
+		if w == "|" {
+			bar = i
+		}
+	}
+	if bar < 0 && len(words) > 0 {
+		words = append(words[:len(words)-1], "|", words[len(words)-1])
+		bar = len(words) - 2
+	}
+	for i, w := range words {
+		if i == bar {
+			continue
+		}
+		if j := strings.IndexByte(w, '('); j > 0 {
+			w = w[:j]
+		}
+		if i < bar {
+			kw.open[w] = true
+		} else {
+			kw.done[w] = true
+		}
+	}
+	return kw
+}
+
+func parseOrgHeadline(level int, text string, kw orgKeywords) *orgNode {
+	node := &orgNode{level: level}
+	if m := orgTags.FindStringSubmatch(text); m != nil {
+		node.tags = splitOrgTags(m[1])
+		text = strings.TrimSuffix(text, m[0])
+	}
+	if word, rest, _ := strings.Cut(text, " "); kw.open[word] || kw.done[word] {
+		node.keyword = word
+		text = strings.TrimSpace(rest)
+	}
+	if m := orgPriority.FindStringSubmatch(text); m != nil {
+		node.priority = m[1]
+		text = text[len(m[0]):]
+	}
+	node.title = strings.TrimSpace(text)
+	return node
+}
+
+func splitOrgTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ":") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func mergeOrgTags(inherited, own []string) []string {
+	if len(inherited) == 0 {
+		return own
+	}
+	seen := make(map[string]bool)
+	var tags []string
+	for _, t := range append(append([]string(nil), inherited...), own...) {
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// orgParsed is an item read from a headline, with the line it starts on.
+type orgParsed struct {
+	item     model.CalendarItem
+	line     int
+	warnings []string
+}
+
+// orgNodeItems maps node and then the nested headlines that stand alone.
+// Those inherit the tags above them and name the headings above them in a
+// project tag. A plain heading left with nothing but such headlines under
+// it only groups them and is not an item itself.
+func orgNodeItems(node *orgNode, path, inherited []string, kw orgKeywords) []orgParsed {
+	item, warnings, nested := orgNodeToItem(node, kw)
+	tags := mergeOrgTags(inherited, node.tags)
+	item.Tags = tags
+	if len(path) > 0 {
+		item.Tags = mergeOrgTags(item.Tags, []string{model.ProjectTagPrefix + strings.Join(path, "/")})
+	}
+
+	var out []orgParsed
+	grouping := len(nested) > 0 && item.ItemType == model.ItemTypeJournal && item.StartTime == nil &&
+		item.Description == "" && len(item.Subtasks) == 0
+	if !grouping {
+		out = append(out, orgParsed{item: item, line: node.line, warnings: warnings})
+	}
+	path = append(path[:len(path):len(path)], item.Title)
+	for _, child := range nested {
+		out = append(out, orgNodeItems(child, path, tags, kw)...)
+	}
+	return out
+}
+
+// orgStandsAlone reports whether a nested headline is an item of its own
+// rather than a subtask, which keeps only its title, keyword and priority:
+// it has tags, an active timestamp in its title or a section, or a headline
+// under it stands alone.
+func orgStandsAlone(node *orgNode) bool {
+	if len(node.tags) > 0 {
+		return true
+	}
+	if _, _, ok := takeOrgStamp(node.title); ok {
+		return true
+	}
+	for _, line := range node.lines {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	for _, child := range node.children {
+		if orgStandsAlone(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// orgNodeToItem maps a headline and its section. Headlines with a TODO
+// keyword are tasks, those with an active timestamp are events, and the
+// rest are journal notes. Checkbox items and nested headlines become
+// subtasks; the nested headlines that stand alone are returned instead.
+func orgNodeToItem(node *orgNode, kw orgKeywords) (model.CalendarItem, []string, []*orgNode) {
+	var warnings []string
+	item := model.CalendarItem{
+		Priority: mapOrgPriority(node.priority),
+		Tags:     node.tags,
+	}
+
+	body := node.lines
+	// the planning line directly follows the headline
+	if len(body) > 0 && orgPlanning.MatchString(body[0]) {
+		warnings = append(warnings, applyOrgPlanning(&item, body[0])...)
+		// the writer puts the repeater on SCHEDULED unless told otherwise
+		if keyword, repeater := orgRepeaterStamp(body[0]); keyword == "DEADLINE" && item.StartTime != nil {
+			item.Extensions = append(item.Extensions, model.Extension{Namespace: "org", Name: keyword, Value: repeater})
+		}
+		body = body[1:]
+	}
+
+	var text []string
+	for i := 0; i < len(body); i++ {
+		if m := orgCheckbox.FindStringSubmatch(body[i]); m != nil {
+			appendOrgCheckbox(&item, m[1], m[2])
+			continue
+		}
+		trimmed := strings.TrimSpace(body[i])
+		m := orgDrawer.FindStringSubmatch(trimmed)
+		if m == nil || strings.EqualFold(m[1], "END") {
+			text = append(text, body[i])
+			continue
+		}
+		end := i + 1
+		for end < len(body) && !strings.EqualFold(strings.TrimSpace(body[end]), ":END:") {
+			end++
+		}
+		if strings.EqualFold(m[1], "PROPERTIES") {
+			applyOrgProperties(&item, body[i+1:min(end, len(body))])
+		}
+		i = end
+	}
+
+	// a plain active timestamp in the title or body makes an event
+	title := node.title
+	var plain *orgStampValue
+	if s, rest, ok := takeOrgStamp(title); ok {
+		plain, title = s, rest
+	}
+	for i, line := range text {
+		if plain != nil {
+			break
+		}
+		if s, rest, ok := takeOrgStamp(line); ok {
+			plain = s
+			text[i] = rest
+		}
+	}
+	item.Title = title
+
+	switch {
+	case node.keyword != "":
+		item.ItemType = model.ItemTypeTask
+		item.Status = mapOrgKeyword(node.keyword, kw)
+	case plain != nil:
+		item.ItemType = model.ItemTypeEvent
+		item.Status = model.StatusPending
+	default:
+		item.ItemType = model.ItemTypeJournal
+		// a journal note is dated by an inactive timestamp on its first line
+		first := strings.TrimSpace(firstNonEmpty(text))
+		if loc := orgStamp.FindStringIndex(first); loc != nil && loc[0] == 0 && loc[1] == len(first) {
+			if s, ok := parseOrgStampLine(first); ok && !s.active {
+				item.StartTime = &s.start
+				item.IsAllDay = s.allDay
+				text = dropFirstNonEmpty(text)
+			}
+		}
+	}
+	if plain != nil {
+		if item.ItemType == model.ItemTypeEvent {
+			item.StartTime = &plain.start
+			item.EndTime = plain.end
+			item.IsAllDay = plain.allDay
+		} else if item.StartTime == nil {
+			item.StartTime = &plain.start
+		}
+		if item.Recurrence == nil && plain.repeater != "" {
+			rec, err := ParseOrgRepeater(plain.repeater)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+			}
+			item.Recurrence = rec
+		}
+	}
+	if item.Status != model.StatusCompleted {
+		item.CompletionDate = nil
+	}
+
+	item.Description = orgBodyText(text)
+
+	var nested []*orgNode
+	for _, child := range node.children {
+		nested = appendOrgSubtasks(&item, child, nested, kw)
+	}
+	return item, warnings, nested
+}
+
+// appendOrgSubtasks folds a headline and its children into item's subtasks,
+// collecting the headlines that stand alone in nested.
+func appendOrgSubtasks(item *model.CalendarItem, node *orgNode, nested []*orgNode, kw orgKeywords) []*orgNode {
+	if orgStandsAlone(node) {
+		return append(nested, node)
+	}
+	status := model.StatusPending
+	if node.keyword != "" {
+		status = mapOrgKeyword(node.keyword, kw)
+	}
+	item.Subtasks = append(item.Subtasks, model.Subtask{
+		Title:     node.title,
+		Status:    status,
+		Priority:  mapOrgPriority(node.priority),
+		SortOrder: len(item.Subtasks),
+	})
+	for _, child := range node.children {
+		nested = appendOrgSubtasks(item, child, nested, kw)
+	}
+	return nested
+}
+
+// appendOrgCheckbox adds a checkbox list item such as "- [X] [#B] Call" as
+// a subtask; [-] marks one in progress.
+func appendOrgCheckbox(item *model.CalendarItem, box, text string) {
+	status := model.StatusPending
+	switch box {
+	case "X", "x":
+		status = model.StatusCompleted
+	case "-":
+		status = model.StatusInProgress
+	}
+	var priority model.Priority
+	if m := orgPriority.FindStringSubmatch(text); m != nil {
+		priority = mapOrgPriority(m[1])
+		text = text[len(m[0]):]
+	}
+	item.Subtasks = append(item.Subtasks, model.Subtask{
+		Title:     text,
+		Status:    status,
+		Priority:  priority,
+		SortOrder: len(item.Subtasks),
+	})
+}
+
+func applyOrgPlanning(item *model.CalendarItem, line string) []string {
+	var warnings []string
+	// Logseq writes each date on a line of its own
+	prior := item.StartTime != nil || item.DueDate != nil
+	dated, timed := false, false
+	locs := orgPlanning.FindAllStringSubmatchIndex(line, -1)
+	for _, loc := range locs {
+		keyword := line[loc[2]:loc[3]]
+		s, ok := parseOrgStampLine(line[loc[1]:])
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("malformed %s timestamp", keyword))
+			continue
+		}
+		if keyword != "CLOSED" {
+			dated = dated || s.allDay
+			timed = timed || !s.allDay
+		}
+		switch keyword {
+		case "SCHEDULED":
+			item.StartTime = &s.start
+		case "DEADLINE":
+			item.DueDate = &s.start
+			if d, err := parseOrgInterval(strings.TrimLeft(s.warning, "-")); s.warning != "" && err == nil {
+				offset := -d
+				item.Reminders = append(item.Reminders, model.Reminder{Offset: &offset})
+			}
+		case "CLOSED":
+			item.CompletionDate = &s.start
+		}
+		if s.repeater != "" && item.Recurrence == nil {
+			rec, err := ParseOrgRepeater(s.repeater)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+			}
+			item.Recurrence = rec
+		}
+	}
+	// a task is all-day when none of its dates has a time
+	switch {
+	case timed:
+		item.IsAllDay = false
+	case dated:
+		item.IsAllDay = item.IsAllDay || !prior
+	}
+	return warnings
+}
+
+// applyOrgProperties reads the property drawer. Properties salja has no
+// field for are kept as org extensions for the writer.
+func applyOrgProperties(item *model.CalendarItem, lines []string) {
+	for _, line := range lines {
+		m := orgProperty.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		value := strings.TrimSpace(m[2])
+		switch strings.ToUpper(m[1]) {
+		case "ID":
+			item.UID = value
+			continue
+		case "LOCATION":
+			item.Location = value
+			continue
+		case "CREATED":
+			if s, ok := parseOrgStampLine(value); ok {
+				item.CreatedAt = &s.start
+				continue
+			}
+		}
+		item.Extensions = append(item.Extensions, model.Extension{Namespace: "org", Name: m[1], Value: value})
+	}
+}
+
+// orgRepeaterStamp returns the planning keyword of the first timestamp on
+// line with a repeater, which is the one the item's rule is read from, and
+// that repeater. Org reserves the keyword names for planning, so no drawer
+// property clashes with the org extension that remembers a DEADLINE one.
+func orgRepeaterStamp(line string) (string, string) {
+	for _, loc := range orgPlanning.FindAllStringSubmatchIndex(line, -1) {
+		if s, ok := parseOrgStampLine(line[loc[1]:]); ok && s.repeater != "" {
+			return line[loc[2]:loc[3]], s.repeater
+		}
+	}
+	return "", ""
+}
+
+// parseOrgStampLine parses the timestamp (or range) at the start of s.
+func parseOrgStampLine(s string) (*orgStampValue, bool) {
+	m := orgStamp.FindStringSubmatchIndex(s)
+	if m == nil || m[0] != 0 {
+		return nil, false
+	}
+	stamp, ok := orgStampFromMatch(s, m)
+	if !ok {
+		return nil, false
+	}
+	if rest := s[m[1]:]; strings.HasPrefix(rest, "--") {
+		if end, ok := parseOrgStampLine(rest[2:]); ok {
+			stamp.setRangeEnd(end)
+		}
+	}
+	return stamp, true
+}
+
+// takeOrgStamp removes the first active timestamp or range from s.
+func takeOrgStamp(s string) (*orgStampValue, string, bool) {
+	for _, m := range orgStamp.FindAllStringSubmatchIndex(s, -1) {
+		if s[m[0]] != '<' {
+			continue
+		}
+		stamp, ok := orgStampFromMatch(s, m)
+		if !ok {
+			continue
+		}
+		end := m[1]
+		if rest := s[end:]; strings.HasPrefix(rest, "--") {
+			if m2 := orgStamp.FindStringSubmatchIndex(rest[2:]); m2 != nil && m2[0] == 0 {
+				if e, ok := orgStampFromMatch(rest[2:], m2); ok {
+					stamp.setRangeEnd(e)
+					end += 2 + m2[1]
+				}
+			}
+		}
+		rest := strings.Join(strings.Fields(s[:m[0]]+" "+s[end:]), " ")
+		return stamp, rest, true
+	}
+	return nil, s, false
+}
+
+func orgStampFromMatch(s string, m []int) (*orgStampValue, bool) {
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+		return s[m[2*i]:m[2*i+1]]
+	}
+	stamp := &orgStampValue{active: group(1) == "<", repeater: group(5), warning: group(6)}
+	date, clock, endClock := group(2), group(3), group(4)
+
+	var err error
+	if clock == "" {
+		stamp.allDay = true
+		stamp.start, err = time.ParseInLocation(OrgDateLayout, date, time.Local)
+		return stamp, err == nil
+	}
+	if stamp.start, err = time.ParseInLocation(OrgDateTimeLayout, date+" "+padOrgClock(clock), time.Local); err != nil {
+		return nil, false
+	}
+	if endClock != "" {
+		end, err := time.ParseInLocation(OrgDateTimeLayout, date+" "+padOrgClock(endClock), time.Local)
+		if err != nil {
+			return nil, false
+		}
+		stamp.end = &end
+	}
+	return stamp, true
+}
+
+func padOrgClock(c string) string {
+	if len(c) == 4 {
+		return "0" + c
+	}
+	return c
+}
+
+// ParseOrgRepeater maps a repeater such as +1w, ++2d or .+1m onto a rule.
+// The three kinds differ only in how Org shifts a task once done.
+func ParseOrgRepeater(r string) (*model.Recurrence, error) {
+	r = strings.TrimLeft(r, ".+")
+	n, err := strconv.Atoi(r[:len(r)-1])
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("unsupported repeater %q", r)
+	}
+	switch r[len(r)-1] {
+	case 'd':
+		return &model.Recurrence{Freq: model.FreqDaily, Interval: n}, nil
+	case 'w':
+		return &model.Recurrence{Freq: model.FreqWeekly, Interval: n}, nil
+	case 'm':
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: n}, nil
+	case 'y':
+		return &model.Recurrence{Freq: model.FreqYearly, Interval: n}, nil
+	}
+	return nil, fmt.Errorf("unsupported repeater %q (hourly repeats have no rule)", r)
+}
+
+// parseOrgInterval reads a warning period such as 2d or 3h.
+func parseOrgInterval(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	if unit == 0 {
+		return 0, fmt.Errorf("unsupported interval %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func mapOrgKeyword(keyword string, kw orgKeywords) model.Status {
+	switch {
+	case keyword == "CANCELLED" || keyword == "CANCELED":
+		return model.StatusCancelled
+	case kw.done[keyword]:
+		return model.StatusCompleted
+	case keyword == "NEXT" || keyword == "STARTED":
+		return model.StatusInProgress
+	default:
+		return model.StatusPending
+	}
+}
+
+// mapOrgPriority follows Org's default range: A is high, B (the default)
+// medium and C low.
+func mapOrgPriority(p string) model.Priority {
+	switch p {
+	case "A":
+		return model.PriorityHigh
+	case "B":
+		return model.PriorityMedium
+	case "C":
+		return model.PriorityLow
+	case "":
+		return model.PriorityNone
+	default:
+		return model.PriorityLowest
+	}
+}
+
+// orgBodyText joins the section text, dropping surrounding blank lines and
+// the indentation common to every line.
+func orgBodyText(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		out[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.Join(out, "\n")
+}
+
+func firstNonEmpty(lines []string) string {
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+func dropFirstNonEmpty(lines []string) []string {
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			return append(append([]string(nil), lines[:i]...), lines[i+1:]...)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,227 @@
+package parsers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+const orgAgenda = `#+TITLE: Plans
+#+FILETAGS: :home:
+#+TODO: TODO NEXT WAITING | DONE CANCELLED
+
+* NEXT [#A] Ship release :work:urgent:
+SCHEDULED: <2026-03-10 Tue 09:00 +1w> DEADLINE: <2026-03-15 Sun -2d>
+:PROPERTIES:
+:ID: 5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f
+:LOCATION: Office
+:CREATED: [2026-03-01 Sun 09:00]
+:CUSTOM: keep me
+:END:
+:LOGBOOK:
+CLOCK: [2026-03-02 Mon 10:00]--[2026-03-02 Mon 11:00] =>  1:00
+:END:
+  Check the changelog.
+  Ping QA.
+- [X] Write notes
+- [ ] [#C] Tag build
+  - [ ] Sign-off
+* DONE File taxes
+CLOSED: [2026-03-04 Wed 18:00] DEADLINE: <2026-03-05 Thu>
+* Team offsite <2026-04-02 Thu 10:00-16:30>
+Bring laptops.
+* Conference
+<2026-05-11 Mon>--<2026-05-13 Wed>
+* Retro notes
+[2026-03-01 Sun]
+Went well.
+* CANCELLED Old idea
+`
+
+func TestOrgParseAgenda(t *testing.T) {
+	col, err := NewOrgParser().Parse(context.Background(), strings.NewReader(orgAgenda), "plans.org")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 6 {
+		t.Fatalf("expected 6 items, got %d", len(col.Items))
+	}
+
+	ship := col.Items[0]
+	if ship.Title != "Ship release" || ship.ItemType != model.ItemTypeTask || ship.Status != model.StatusInProgress {
+		t.Errorf("ship = %+v", ship)
+	}
+	if ship.Priority != model.PriorityHigh || strings.Join(ship.Tags, ",") != "home,work,urgent" {
+		t.Errorf("priority/tags = %d %v", ship.Priority, ship.Tags)
+	}
+	if want := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local); ship.StartTime == nil || !ship.StartTime.Equal(want) {
+		t.Errorf("scheduled = %v", ship.StartTime)
+	}
+	if ship.DueDate == nil || ship.DueDate.Day() != 15 {
+		t.Errorf("deadline = %v", ship.DueDate)
+	}
+	if len(ship.Reminders) != 1 || *ship.Reminders[0].Offset != -48*time.Hour {
+		t.Errorf("warning = %+v", ship.Reminders)
+	}
+	if ship.Recurrence == nil || ship.Recurrence.Freq != model.FreqWeekly || ship.Recurrence.Interval != 1 {
+		t.Errorf("repeater = %+v", ship.Recurrence)
+	}
+	if ship.UID != "5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f" || ship.Location != "Office" || ship.CreatedAt == nil {
+		t.Errorf("properties = %q %q %v", ship.UID, ship.Location, ship.CreatedAt)
+	}
+	if v, ok := ship.ExtensionValue("org", "CUSTOM"); !ok || v != "keep me" || len(ship.Extensions) != 1 {
+		t.Errorf("extensions = %+v", ship.Extensions)
+	}
+	if ship.Description != "Check the changelog.\nPing QA." {
+		t.Errorf("description = %q", ship.Description)
+	}
+	wantSubs := []model.Subtask{
+		{Title: "Write notes", Status: model.StatusCompleted, SortOrder: 0},
+		{Title: "Tag build", Status: model.StatusPending, Priority: model.PriorityLow, SortOrder: 1},
+		{Title: "Sign-off", Status: model.StatusPending, SortOrder: 2},
+	}
+	if len(ship.Subtasks) != len(wantSubs) {
+		t.Fatalf("subtasks = %+v", ship.Subtasks)
+	}
+	for i, want := range wantSubs {
+		if ship.Subtasks[i] != want {
+			t.Errorf("subtask %d = %+v, want %+v", i, ship.Subtasks[i], want)
+		}
+	}
+
+	taxes := col.Items[1]
+	if taxes.Status != model.StatusCompleted || taxes.CompletionDate == nil || taxes.CompletionDate.Hour() != 18 {
+		t.Errorf("taxes = %+v", taxes)
+	}
+
+	offsite := col.Items[2]
+	if offsite.ItemType != model.ItemTypeEvent || offsite.Title != "Team offsite" || offsite.IsAllDay {
+		t.Errorf("offsite = %+v", offsite)
+	}
+	if offsite.StartTime == nil || offsite.EndTime == nil || offsite.Duration() != 6*time.Hour+30*time.Minute {
+		t.Errorf("offsite span = %v - %v", offsite.StartTime, offsite.EndTime)
+	}
+	if offsite.Description != "Bring laptops." {
+		t.Errorf("offsite description = %q", offsite.Description)
+	}
+
+	conf := col.Items[3]
+	if conf.ItemType != model.ItemTypeEvent || !conf.IsAllDay || conf.EndTime == nil || conf.EndTime.Day() != 14 {
+		t.Errorf("conference = %+v", conf)
+	}
+
+	retro := col.Items[4]
+	if retro.ItemType != model.ItemTypeJournal || retro.StartTime == nil || retro.StartTime.Day() != 1 || retro.Description != "Went well." {
+		t.Errorf("retro = %+v", retro)
+	}
+	if col.Items[5].Status != model.StatusCancelled {
+		t.Errorf("old idea = %+v", col.Items[5])
+	}
+}
+
+func TestOrgNestedHeadlines(t *testing.T) {
+	input := `* Work
+** TODO Write report :work:
+SCHEDULED: <2024-03-01 Fri>
+:PROPERTIES:
+:ID: report-1
+:END:
+*** Outline
+*** DONE Draft outline
+*** TODO [#A] Review
+*** TODO Book room
+Ask facilities first.
+** Meeting <2024-03-02 Sat 10:00-11:00>
+* Errands
+** Buy milk
+** Post letter
+`
+	col, err := NewOrgParser().Parse(context.Background(), strings.NewReader(input), "nested.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(col.Items) != 4 {
+		t.Fatalf("expected 4 items, got %d: %+v", len(col.Items), col.Items)
+	}
+
+	report := col.Items[0]
+	if report.Title != "Write report" || report.ItemType != model.ItemTypeTask || report.UID != "report-1" {
+		t.Errorf("report = %+v", report)
+	}
+	if report.StartTime == nil || report.StartTime.Day() != 1 || !report.IsAllDay {
+		t.Errorf("report scheduled = %v", report.StartTime)
+	}
+	if strings.Join(report.Tags, ",") != "work,project:Work" {
+		t.Errorf("report tags = %v", report.Tags)
+	}
+	wantSubs := []model.Subtask{
+		{Title: "Outline", Status: model.StatusPending, SortOrder: 0},
+		{Title: "Draft outline", Status: model.StatusCompleted, SortOrder: 1},
+		{Title: "Review", Status: model.StatusPending, Priority: model.PriorityHigh, SortOrder: 2},
+	}
+	if len(report.Subtasks) != len(wantSubs) {
+		t.Fatalf("report subtasks = %+v", report.Subtasks)
+	}
+	for i, want := range wantSubs {
+		if report.Subtasks[i] != want {
+			t.Errorf("subtask %d = %+v, want %+v", i, report.Subtasks[i], want)
+		}
+	}
+
+	// a section is more than a subtask holds
+	room := col.Items[1]
+	if room.Title != "Book room" || room.Description != "Ask facilities first." || strings.Join(room.Tags, ",") != "work,project:Work/Write report" {
+		t.Errorf("room = %+v", room)
+	}
+
+	meeting := col.Items[2]
+	if meeting.Title != "Meeting" || meeting.ItemType != model.ItemTypeEvent || meeting.Duration() != time.Hour {
+		t.Errorf("meeting = %+v", meeting)
+	}
+	if strings.Join(meeting.Tags, ",") != "project:Work" {
+		t.Errorf("meeting tags = %v", meeting.Tags)
+	}
+
+	errands := col.Items[3]
+	if errands.Title != "Errands" || len(errands.Subtasks) != 2 || errands.Subtasks[1].Title != "Post letter" {
+		t.Errorf("errands = %+v", errands)
+	}
+}
+
+func TestOrgKeywordSettingWithoutBar(t *testing.T) {
+	input := `#+SEQ_TODO: OPEN FINISHED
+* OPEN Thing
+* FINISHED Other
+* TODO is a word here
+`
+	col, err := NewOrgParser().Parse(context.Background(), strings.NewReader(input), "x.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if col.Items[0].Status != model.StatusPending || col.Items[1].Status != model.StatusCompleted {
+		t.Errorf("statuses = %q %q", col.Items[0].Status, col.Items[1].Status)
+	}
+	if col.Items[0].Title != "Thing" || col.Items[1].Title != "Other" {
+		t.Errorf("titles = %q %q", col.Items[0].Title, col.Items[1].Title)
+	}
+}
+
+func TestOrgRepeaters(t *testing.T) {
+	for in, want := range map[string]model.Recurrence{
+		"+1d":  {Freq: model.FreqDaily, Interval: 1},
+		"++2w": {Freq: model.FreqWeekly, Interval: 2},
+		".+1m": {Freq: model.FreqMonthly, Interval: 1},
+		"+3y":  {Freq: model.FreqYearly, Interval: 3},
+	} {
+		rec, err := ParseOrgRepeater(in)
+		if err != nil || rec.Freq != want.Freq || rec.Interval != want.Interval {
+			t.Errorf("%s = %+v %v", in, rec, err)
+		}
+	}
+	if _, err := ParseOrgRepeater("+4h"); err == nil {
+		t.Error("expected an error for an hourly repeater")
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	salerr "github.com/gongahkia/salja/internal/errors"
 	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/tzmap"
 )
 
 type OutlookParser struct{}
@@ -78,6 +79,7 @@ func (p *OutlookParser) Parse(ctx context.Context, r io.Reader, sourcePath strin
 		if err != nil {
 			return nil, fmt.Errorf("%s line %d: %w", sourcePath, lineNum, err)
 		}
+		item.Extensions = columnExtensions("outlook", header, row, func(col string) bool { return outlookColumns[col] })
 		collection.Items = append(collection.Items, item)
 	}
 
@@ -126,10 +128,10 @@ func parseOutlookRow(row []string, colMap map[string]int, ec *salerr.ErrorCollec
 		if isAllDay {
 			start, err = time.Parse("1/2/2006", row[startDateIdx])
 		} else if startTimeIdx, ok := colMap["Start Time"]; ok && startTimeIdx < len(row) && row[startTimeIdx] != "" {
-			dateTime := row[startDateIdx] + " " + row[startTimeIdx]
-			start, err = time.Parse("1/2/2006 3:04:05 PM", dateTime)
-			if err != nil {
-				start, err = time.Parse("1/2/2006 15:04:05", dateTime)
+			clock, zone := splitOutlookZone(row[startTimeIdx])
+			start, err = parseOutlookDateTime(row[startDateIdx], clock, zone)
+			if err == nil && zone != "" {
+				item.Timezone = zone
 			}
 		}
 
@@ -151,10 +153,10 @@ func parseOutlookRow(row []string, colMap map[string]int, ec *salerr.ErrorCollec
 		if isAllDay {
 			end, err = time.Parse("1/2/2006", row[endDateIdx])
 		} else if endTimeIdx, ok := colMap["End Time"]; ok && endTimeIdx < len(row) && row[endTimeIdx] != "" {
-			dateTime := row[endDateIdx] + " " + row[endTimeIdx]
-			end, err = time.Parse("1/2/2006 3:04:05 PM", dateTime)
-			if err != nil {
-				end, err = time.Parse("1/2/2006 15:04:05", dateTime)
+			clock, zone := splitOutlookZone(row[endTimeIdx])
+			end, err = parseOutlookDateTime(row[endDateIdx], clock, zone)
+			if err == nil && zone != "" {
+				item.Timezone = zone
 			}
 		}
 
@@ -180,5 +182,89 @@ func parseOutlookRow(row []string, colMap map[string]int, ec *salerr.ErrorCollec
 		}
 	}
 
+	if idx, ok := colMap["Meeting Organizer"]; ok && idx < len(row) {
+		if people := parseOutlookPeople(row[idx], ""); len(people) > 0 {
+			item.Organizer = &people[0]
+		}
+	}
+	for _, c := range outlookAttendeeColumns {
+		if idx, ok := colMap[c.name]; ok && idx < len(row) {
+			item.Attendees = append(item.Attendees, parseOutlookPeople(row[idx], c.role)...)
+		}
+	}
+
 	return item, nil
 }
+
+// splitOutlookZone splits the zone salja writes after a time, a Windows or
+// IANA name, off an Outlook time cell and returns its IANA name.
+func splitOutlookZone(cell string) (clock, zone string) {
+	fields := strings.Fields(cell)
+	n := 1
+	if len(fields) > 1 && (strings.EqualFold(fields[1], "AM") || strings.EqualFold(fields[1], "PM")) {
+		n = 2
+	}
+	if len(fields) <= n {
+		return strings.TrimSpace(cell), ""
+	}
+	if name, ok := tzmap.ToIANA(strings.Join(fields[n:], " ")); ok && name != "UTC" {
+		zone = name
+	}
+	return strings.Join(fields[:n], " "), zone
+}
+
+// parseOutlookDateTime reads a date and a 12- or 24-hour time on the wall
+// clock of zone, or in UTC when zone is empty.
+func parseOutlookDateTime(date, clock, zone string) (time.Time, error) {
+	loc := time.UTC
+	if zone != "" {
+		if l, err := time.LoadLocation(zone); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("1/2/2006 3:04:05 PM", date+" "+clock, loc)
+	if err != nil {
+		t, err = time.ParseInLocation("1/2/2006 15:04:05", date+" "+clock, loc)
+	}
+	return t, err
+}
+
+// outlookColumns are the columns parseOutlookRow reads; the rest, such as
+// Show time as or Sensitivity, are kept as extensions.
+var outlookColumns = map[string]bool{
+	"Subject": true, "Start Date": true, "Start Time": true, "End Date": true, "End Time": true,
+	"All day event": true, "Categories": true, "Description": true, "Location": true, "Priority": true,
+	"Meeting Organizer": true, "Required Attendees": true, "Optional Attendees": true, "Meeting Resources": true,
+}
+
+var outlookAttendeeColumns = []struct {
+	name string
+	role model.AttendeeRole
+}{
+	{"Required Attendees", model.RoleRequired},
+	{"Optional Attendees", model.RoleOptional},
+	{"Meeting Resources", model.RoleNonParticipant},
+}
+
+// parseOutlookPeople reads a semicolon-separated attendee cell. Outlook
+// writes display names, addresses or "Name <address>".
+func parseOutlookPeople(cell string, role model.AttendeeRole) []model.Attendee {
+	var people []model.Attendee
+	for _, entry := range strings.Split(cell, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		person := model.Attendee{Role: role}
+		if name, addr, ok := strings.Cut(entry, "<"); ok && strings.HasSuffix(addr, ">") {
+			person.Name = strings.Trim(strings.TrimSpace(name), `"`)
+			person.Email = strings.TrimSpace(strings.TrimSuffix(addr, ">"))
+		} else if strings.Contains(entry, "@") {
+			person.Email = entry
+		} else {
+			person.Name = entry
+		}
+		people = append(people, person)
+	}
+	return people
+}
@@ -2,8 +2,10 @@ package parsers
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gongahkia/salja/internal/model"
 )
@@ -139,3 +141,72 @@ func TestOutlookSourceApp(t *testing.T) {
 		t.Errorf("source_app = %q, want %q", col.SourceApp, "outlook")
 	}
 }
+
+func TestOutlookAttendees(t *testing.T) {
+	csv := `Subject,Start Date,Start Time,Meeting Organizer,Required Attendees,Optional Attendees,Meeting Resources
+Review,3/10/2026,2:00:00 PM,Ana Ruiz,"Lee, Sam <sam@example.com>; kim@example.com",Jo Park,Room 4`
+
+	col, err := NewOutlookParser().Parse(context.Background(), strings.NewReader(csv), "test.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	item := col.Items[0]
+	if item.Organizer == nil || item.Organizer.Name != "Ana Ruiz" || item.Organizer.Email != "" {
+		t.Errorf("organizer = %+v", item.Organizer)
+	}
+	want := []model.Attendee{
+		{Name: "Lee, Sam", Email: "sam@example.com", Role: model.RoleRequired},
+		{Email: "kim@example.com", Role: model.RoleRequired},
+		{Name: "Jo Park", Role: model.RoleOptional},
+		{Name: "Room 4", Role: model.RoleNonParticipant},
+	}
+	if len(item.Attendees) != len(want) {
+		t.Fatalf("attendees = %+v", item.Attendees)
+	}
+	for i := range want {
+		if item.Attendees[i] != want[i] {
+			t.Errorf("attendee %d = %+v, want %+v", i, item.Attendees[i], want[i])
+		}
+	}
+}
+
+func TestOutlookUnmappedColumns(t *testing.T) {
+	csv := `Subject,Start Date,Show time as,Private,Sensitivity,Mileage
+Dentist,3/10/2026,2,True,Private,
+`
+	col, err := NewOutlookParser().Parse(context.Background(), strings.NewReader(csv), "test.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// empty cells are not kept
+	want := []model.Extension{
+		{Namespace: "outlook", Name: "Show time as", Value: "2"},
+		{Namespace: "outlook", Name: "Private", Value: "True"},
+		{Namespace: "outlook", Name: "Sensitivity", Value: "Private"},
+	}
+	if got := col.Items[0].Extensions; !reflect.DeepEqual(got, want) {
+		t.Errorf("extensions = %+v", got)
+	}
+}
+
+func TestOutlookTimezones(t *testing.T) {
+	csv := `Subject,Start Date,Start Time,End Date,End Time,All day event
+Review,7/1/2026,9:00:00 AM W. Europe Standard Time,7/1/2026,10:00:00 AM W. Europe Standard Time,False
+Call,7/1/2026,14:30:00 America/New_York,7/1/2026,15:00:00 America/New_York,False
+`
+	col, err := NewOutlookParser().Parse(context.Background(), strings.NewReader(csv), "test.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	review := col.Items[0]
+	if review.Timezone != "Europe/Berlin" || !review.StartTime.Equal(time.Date(2026, 7, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("review: zone %q, start %v", review.Timezone, review.StartTime)
+	}
+	if review.EndTime == nil || review.EndTime.Sub(*review.StartTime) != time.Hour {
+		t.Errorf("review: end %v", review.EndTime)
+	}
+	call := col.Items[1]
+	if call.Timezone != "America/New_York" || !call.StartTime.Equal(time.Date(2026, 7, 1, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("call: zone %q, start %v", call.Timezone, call.StartTime)
+	}
+}
@@ -0,0 +1,369 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// TaskwarriorDateLayout is the UTC basic ISO 8601 form Taskwarrior uses for
+// every date attribute.
+const TaskwarriorDateLayout = "20060102T150405Z"
+
+type TaskwarriorParser struct{}
+
+func NewTaskwarriorParser() *TaskwarriorParser {
+	return &TaskwarriorParser{}
+}
+
+type TaskwarriorTask struct {
+	UUID        string                  `json:"uuid"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	Modified    string                  `json:"modified,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Scheduled   string                  `json:"scheduled,omitempty"`
+	Wait        string                  `json:"wait,omitempty"`
+	Until       string                  `json:"until,omitempty"`
+	Recur       string                  `json:"recur,omitempty"`
+	Parent      string                  `json:"parent,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Depends     TaskwarriorDepends      `json:"depends,omitempty"`
+	Annotations []TaskwarriorAnnotation `json:"annotations,omitempty"`
+}
+
+type TaskwarriorAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// TaskwarriorDepends is a list of task UUIDs. Taskwarrior 2.6+ exports it as
+// an array, older versions as one comma-separated string.
+type TaskwarriorDepends []string
+
+func (d *TaskwarriorDepends) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("depends must be an array or a comma-separated string")
+	}
+	*d = nil
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			*d = append(*d, id)
+		}
+	}
+	return nil
+}
+
+// SniffTaskwarrior reports whether head, the start of a file, reads as
+// `task export` output: its first task object has a uuid alongside a
+// description, status or entry.
+func SniffTaskwarrior(head []byte) bool {
+	keys, _ := jsonHeadKeys(head)
+	return keys["uuid"] && (keys["description"] || keys["status"] || keys["entry"])
+}
+
+func (p *TaskwarriorParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return p.Parse(ctx, f, filePath)
+}
+
+// Parse reads the output of `task export`: a JSON array of tasks, or one task
+// object per line as written with rc.json.array=off.
+func (p *TaskwarriorParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	tasks, err := decodeTaskwarriorTasks(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Taskwarrior JSON: %w", err)
+	}
+
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "taskwarrior",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+
+	byUUID := make(map[string]*TaskwarriorTask, len(tasks))
+	dependents := make(map[string]int)
+	for i := range tasks {
+		byUUID[tasks[i].UUID] = &tasks[i]
+		for _, dep := range tasks[i].Depends {
+			dependents[dep]++
+		}
+	}
+
+	// A dependency becomes a subtask of the one task waiting on it when a
+	// subtask holds all of it; anything else stays a top-level item that the
+	// dependent links to.
+	folded := make(map[string]bool)
+	for i := range tasks {
+		for _, dep := range tasks[i].Depends {
+			if d, ok := byUUID[dep]; ok && dependents[dep] == 1 && foldsIntoSubtask(d, &tasks[i]) {
+				folded[dep] = true
+			}
+		}
+	}
+
+	for i, task := range tasks {
+		if folded[task.UUID] {
+			continue
+		}
+		// recurring instances are generated from their template, which is
+		// exported alongside them and carries the rule
+		if task.Parent != "" && byUUID[task.Parent] != nil {
+			continue
+		}
+
+		item, err := taskwarriorToItem(task)
+		if err != nil {
+			return nil, fmt.Errorf("task %d (%q): %w", i, task.Description, err)
+		}
+		var links []string
+		for _, dep := range task.Depends {
+			if !folded[dep] {
+				links = append(links, dep)
+				continue
+			}
+			d := byUUID[dep]
+			item.Subtasks = append(item.Subtasks, model.Subtask{
+				UID:       d.UUID,
+				Title:     d.Description,
+				Status:    mapTaskwarriorStatus(d.Status),
+				Priority:  mapTaskwarriorPriority(d.Priority),
+				SortOrder: len(item.Subtasks),
+			})
+		}
+		if len(links) > 0 {
+			item.Extensions = append(item.Extensions, model.Extension{
+				Namespace: "taskwarrior", Name: "depends", Value: strings.Join(links, ","),
+			})
+		}
+		collection.Items = append(collection.Items, item)
+	}
+
+	return collection, nil
+}
+
+// foldsIntoSubtask reports whether dependency d has nothing a subtask would
+// drop: beyond its description, status and priority only the bookkeeping
+// dates and the project of dependent, which the writer gives subtasks.
+func foldsIntoSubtask(d, dependent *TaskwarriorTask) bool {
+	return len(d.Depends) == 0 && d.Recur == "" && d.Parent == "" && d.Until == "" &&
+		d.Due == "" && d.Scheduled == "" && d.Wait == "" &&
+		len(d.Tags) == 0 && len(d.Annotations) == 0 &&
+		(d.Project == "" || d.Project == dependent.Project)
+}
+
+func decodeTaskwarriorTasks(r io.Reader) ([]TaskwarriorTask, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	if trimmed[0] == '[' {
+		var tasks []TaskwarriorTask
+		if err := json.Unmarshal(trimmed, &tasks); err != nil {
+			return nil, err
+		}
+		return tasks, nil
+	}
+
+	var tasks []TaskwarriorTask
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	for dec.More() {
+		var task TaskwarriorTask
+		if err := dec.Decode(&task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func taskwarriorToItem(task TaskwarriorTask) (model.CalendarItem, error) {
+	item := model.CalendarItem{
+		UID:      task.UUID,
+		Title:    task.Description,
+		ItemType: model.ItemTypeTask,
+		Status:   mapTaskwarriorStatus(task.Status),
+		Priority: mapTaskwarriorPriority(task.Priority),
+		Tags:     append([]string(nil), task.Tags...),
+	}
+	if task.Project != "" {
+		item.Tags = append(item.Tags, model.ProjectTagPrefix+task.Project)
+	}
+
+	dates := []struct {
+		name string
+		src  string
+		dst  **time.Time
+	}{
+		{"due", task.Due, &item.DueDate},
+		{"scheduled", task.Scheduled, &item.StartTime},
+		{"end", task.End, &item.CompletionDate},
+		{"entry", task.Entry, &item.CreatedAt},
+		{"modified", task.Modified, &item.UpdatedAt},
+	}
+	for _, d := range dates {
+		if d.src == "" {
+			continue
+		}
+		t, err := parseTaskwarriorDate(d.src)
+		if err != nil {
+			return item, fmt.Errorf("invalid %s date %q: %w", d.name, d.src, err)
+		}
+		*d.dst = &t
+	}
+	if item.Status != model.StatusCompleted {
+		item.CompletionDate = nil
+	}
+
+	// wait hides a task until the given time, which is when it should
+	// come back to the user's attention
+	if task.Wait != "" {
+		t, err := parseTaskwarriorDate(task.Wait)
+		if err != nil {
+			return item, fmt.Errorf("invalid wait date %q: %w", task.Wait, err)
+		}
+		item.Reminders = append(item.Reminders, model.Reminder{AbsoluteTime: &t})
+	}
+
+	if task.Recur != "" {
+		rec, err := parseTaskwarriorRecur(task.Recur)
+		if err != nil {
+			return item, err
+		}
+		if task.Until != "" {
+			t, err := parseTaskwarriorDate(task.Until)
+			if err != nil {
+				return item, fmt.Errorf("invalid until date %q: %w", task.Until, err)
+			}
+			rec.Until = &t
+		}
+		item.Recurrence = rec
+	}
+
+	var notes []string
+	for _, a := range task.Annotations {
+		notes = append(notes, a.Description)
+	}
+	item.Description = strings.Join(notes, "\n")
+
+	return item, nil
+}
+
+func parseTaskwarriorDate(s string) (time.Time, error) {
+	if t, err := time.Parse(TaskwarriorDateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func mapTaskwarriorStatus(s string) model.Status {
+	switch s {
+	case "completed":
+		return model.StatusCompleted
+	case "deleted":
+		return model.StatusCancelled
+	default:
+		// pending, waiting and recurring templates are all open tasks
+		return model.StatusPending
+	}
+}
+
+func mapTaskwarriorPriority(p string) model.Priority {
+	switch strings.ToUpper(p) {
+	case "H":
+		return model.PriorityHigh
+	case "M":
+		return model.PriorityMedium
+	case "L":
+		return model.PriorityLow
+	default:
+		return model.PriorityNone
+	}
+}
+
+var taskwarriorRecurPattern = regexp.MustCompile(`^(\d*)\s*([a-z]+)$`)
+
+// parseTaskwarriorRecur maps a Taskwarrior recurrence period such as
+// "weekly", "2wk" or "quarterly" onto a recurrence rule.
+func parseTaskwarriorRecur(recur string) (*model.Recurrence, error) {
+	s := strings.ToLower(strings.TrimSpace(recur))
+	switch s {
+	case "daily", "day":
+		return &model.Recurrence{Freq: model.FreqDaily, Interval: 1}, nil
+	case "weekdays":
+		return &model.Recurrence{
+			Freq:     model.FreqWeekly,
+			Interval: 1,
+			ByDay:    []model.Weekday{model.WeekdayMO, model.WeekdayTU, model.WeekdayWE, model.WeekdayTH, model.WeekdayFR},
+		}, nil
+	case "weekly", "week", "sennight":
+		return &model.Recurrence{Freq: model.FreqWeekly, Interval: 1}, nil
+	case "biweekly", "fortnight":
+		return &model.Recurrence{Freq: model.FreqWeekly, Interval: 2}, nil
+	case "monthly", "month":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: 1}, nil
+	case "bimonthly":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: 2}, nil
+	case "quarterly":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: 3}, nil
+	case "semiannual":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: 6}, nil
+	case "yearly", "annual", "year":
+		return &model.Recurrence{Freq: model.FreqYearly, Interval: 1}, nil
+	case "biannual", "biyearly":
+		return &model.Recurrence{Freq: model.FreqYearly, Interval: 2}, nil
+	}
+
+	m := taskwarriorRecurPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported recurrence %q", recur)
+	}
+	n := 1
+	if m[1] != "" {
+		var err error
+		if n, err = strconv.Atoi(m[1]); err != nil || n < 1 {
+			return nil, fmt.Errorf("unsupported recurrence %q", recur)
+		}
+	}
+	switch m[2] {
+	case "d", "day", "days":
+		return &model.Recurrence{Freq: model.FreqDaily, Interval: n}, nil
+	case "w", "wk", "wks", "week", "weeks":
+		return &model.Recurrence{Freq: model.FreqWeekly, Interval: n}, nil
+	case "mo", "mos", "mth", "mths", "month", "months":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: n}, nil
+	case "q", "qtr", "qtrs", "quarter", "quarters":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: 3 * n}, nil
+	case "y", "yr", "yrs", "year", "years":
+		return &model.Recurrence{Freq: model.FreqYearly, Interval: n}, nil
+	}
+	return nil, fmt.Errorf("unsupported recurrence %q", recur)
+}
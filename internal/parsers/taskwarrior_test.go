@@ -0,0 +1,175 @@
+package parsers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+const taskwarriorExport = `[
+{"id":1,"uuid":"5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f","description":"Ship release","status":"pending","entry":"20260301T090000Z","modified":"20260302T090000Z","due":"20260315T170000Z","scheduled":"20260310T080000Z","wait":"20260309T080000Z","priority":"H","project":"Work.Release","tags":["urgent","ops"],"depends":["0d9c7f1e-1111-4a2b-8c3d-000000000001","0d9c7f1e-1111-4a2b-8c3d-000000000002"],"annotations":[{"entry":"20260301T091000Z","description":"check changelog"},{"entry":"20260301T092000Z","description":"ping QA"}],"urgency":12.3},
+{"id":0,"uuid":"0d9c7f1e-1111-4a2b-8c3d-000000000001","description":"Write notes","status":"completed","entry":"20260301T090000Z","end":"20260305T120000Z","priority":"L"},
+{"id":2,"uuid":"0d9c7f1e-1111-4a2b-8c3d-000000000002","description":"Tag build","status":"pending","entry":"20260301T090000Z","depends":[]},
+{"id":3,"uuid":"7f1e2d3c-aaaa-4bbb-8ccc-000000000010","description":"Water plants","status":"recurring","entry":"20260301T090000Z","due":"20260302T080000Z","recur":"2wk","until":"20261231T000000Z","mask":"-"},
+{"id":4,"uuid":"7f1e2d3c-aaaa-4bbb-8ccc-000000000011","description":"Water plants","status":"pending","entry":"20260301T090000Z","due":"20260302T080000Z","recur":"2wk","parent":"7f1e2d3c-aaaa-4bbb-8ccc-000000000010","imask":0},
+{"id":0,"uuid":"9a8b7c6d-0000-4000-8000-000000000099","description":"Old idea","status":"deleted","entry":"20260101T090000Z","end":"20260102T090000Z","priority":"M"}
+]`
+
+func TestTaskwarriorParseExport(t *testing.T) {
+	col, err := NewTaskwarriorParser().Parse(context.Background(), strings.NewReader(taskwarriorExport), "taskwarrior.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 3 {
+		t.Fatalf("expected 3 items (dependencies folded, instance skipped), got %d: %+v", len(col.Items), col.Items)
+	}
+
+	ship := col.Items[0]
+	if ship.UID != "5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f" || ship.Title != "Ship release" {
+		t.Errorf("uid/title = %q %q", ship.UID, ship.Title)
+	}
+	if ship.Priority != model.PriorityHigh || ship.Status != model.StatusPending {
+		t.Errorf("priority/status = %d %q", ship.Priority, ship.Status)
+	}
+	if want := time.Date(2026, 3, 15, 17, 0, 0, 0, time.UTC); ship.DueDate == nil || !ship.DueDate.Equal(want) {
+		t.Errorf("due = %v", ship.DueDate)
+	}
+	if ship.StartTime == nil || ship.StartTime.Day() != 10 {
+		t.Errorf("scheduled = %v", ship.StartTime)
+	}
+	if len(ship.Reminders) != 1 || ship.Reminders[0].AbsoluteTime == nil || ship.Reminders[0].AbsoluteTime.Day() != 9 {
+		t.Errorf("wait = %+v", ship.Reminders)
+	}
+	if strings.Join(ship.Tags, ",") != "urgent,ops,project:Work.Release" {
+		t.Errorf("tags = %v", ship.Tags)
+	}
+	if ship.Description != "check changelog\nping QA" {
+		t.Errorf("description = %q", ship.Description)
+	}
+	if ship.CreatedAt == nil || ship.UpdatedAt == nil || ship.UpdatedAt.Day() != 2 {
+		t.Errorf("entry/modified = %v %v", ship.CreatedAt, ship.UpdatedAt)
+	}
+	if len(ship.Subtasks) != 2 {
+		t.Fatalf("expected 2 subtasks, got %+v", ship.Subtasks)
+	}
+	if ship.Subtasks[0].Title != "Write notes" || ship.Subtasks[0].Status != model.StatusCompleted || ship.Subtasks[0].Priority != model.PriorityLow {
+		t.Errorf("subtask 0 = %+v", ship.Subtasks[0])
+	}
+	if ship.Subtasks[1].Title != "Tag build" || ship.Subtasks[1].SortOrder != 1 {
+		t.Errorf("subtask 1 = %+v", ship.Subtasks[1])
+	}
+
+	plants := col.Items[1]
+	if plants.Recurrence == nil || plants.Recurrence.Freq != model.FreqWeekly || plants.Recurrence.Interval != 2 {
+		t.Fatalf("recurrence = %+v", plants.Recurrence)
+	}
+	if plants.Recurrence.Until == nil || plants.Recurrence.Until.Year() != 2026 || plants.Status != model.StatusPending {
+		t.Errorf("until/status = %v %q", plants.Recurrence.Until, plants.Status)
+	}
+
+	idea := col.Items[2]
+	if idea.Status != model.StatusCancelled || idea.Priority != model.PriorityMedium || idea.CompletionDate != nil {
+		t.Errorf("deleted task = %+v", idea)
+	}
+}
+
+func TestTaskwarriorParseLinesAndLegacyDepends(t *testing.T) {
+	input := `{"uuid":"a","description":"Parent","status":"pending","depends":"b,c"}
+{"uuid":"b","description":"Shared","status":"waiting","wait":"20300101T000000Z"}
+{"uuid":"c","description":"Child","status":"pending"}
+{"uuid":"d","description":"Also needs shared","status":"pending","depends":"b"}
+`
+	col, err := NewTaskwarriorParser().Parse(context.Background(), strings.NewReader(input), "taskwarrior.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// b has two dependents, so it stays a task; c folds into a
+	if len(col.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(col.Items))
+	}
+	if len(col.Items[0].Subtasks) != 1 || col.Items[0].Subtasks[0].Title != "Child" {
+		t.Errorf("subtasks = %+v", col.Items[0].Subtasks)
+	}
+	if col.Items[1].Title != "Shared" || col.Items[1].Status != model.StatusPending {
+		t.Errorf("waiting task = %+v", col.Items[1])
+	}
+}
+
+func TestTaskwarriorKeepsRichDependencyAsLinkedItem(t *testing.T) {
+	input := `[
+{"uuid":"a","description":"Launch","status":"pending","project":"Web","depends":["b","c"]},
+{"uuid":"b","description":"Book venue","status":"pending","due":"20260410T120000Z","tags":["phone"]},
+{"uuid":"c","description":"Draft invite","status":"pending","project":"Web","priority":"M"}
+]`
+	col, err := NewTaskwarriorParser().Parse(context.Background(), strings.NewReader(input), "taskwarrior.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d: %+v", len(col.Items), col.Items)
+	}
+	launch, venue := col.Items[0], col.Items[1]
+	if len(launch.Subtasks) != 1 || launch.Subtasks[0].Title != "Draft invite" {
+		t.Errorf("subtasks = %+v", launch.Subtasks)
+	}
+	if links, _ := launch.ExtensionValue("taskwarrior", "depends"); links != "b" {
+		t.Errorf("depends link = %q, want %q", links, "b")
+	}
+	if venue.Title != "Book venue" || venue.DueDate == nil || strings.Join(venue.Tags, ",") != "phone" {
+		t.Errorf("dependency item = %+v", venue)
+	}
+}
+
+func TestSniffTaskwarrior(t *testing.T) {
+	for head, want := range map[string]bool{
+		taskwarriorExport[:200]: true,
+		`{"uuid":"a","description":"Parent","status":"pending"}` + "\n": true,
+		`{"name": "Board", "cards": [], "lists": []}`:                   false,
+		`{"format":"salja-json","version":1,"items":[]}`:                false,
+		"(A) Call Mom\n": false,
+	} {
+		if got := SniffTaskwarrior([]byte(head)); got != want {
+			t.Errorf("SniffTaskwarrior(%q) = %v, want %v", head, got, want)
+		}
+	}
+}
+
+func TestTaskwarriorRecurPeriods(t *testing.T) {
+	tests := []struct {
+		in       string
+		freq     model.FreqType
+		interval int
+	}{
+		{"daily", model.FreqDaily, 1},
+		{"3d", model.FreqDaily, 3},
+		{"weekly", model.FreqWeekly, 1},
+		{"biweekly", model.FreqWeekly, 2},
+		{"2wk", model.FreqWeekly, 2},
+		{"monthly", model.FreqMonthly, 1},
+		{"quarterly", model.FreqMonthly, 3},
+		{"2q", model.FreqMonthly, 6},
+		{"annual", model.FreqYearly, 1},
+		{"5yr", model.FreqYearly, 5},
+	}
+	for _, tt := range tests {
+		rec, err := parseTaskwarriorRecur(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if rec.Freq != tt.freq || rec.Interval != tt.interval {
+			t.Errorf("%s: got %s/%d, want %s/%d", tt.in, rec.Freq, rec.Interval, tt.freq, tt.interval)
+		}
+	}
+
+	rec, err := parseTaskwarriorRecur("weekdays")
+	if err != nil || len(rec.ByDay) != 5 {
+		t.Errorf("weekdays: %+v %v", rec, err)
+	}
+	if _, err := parseTaskwarriorRecur("every full moon"); err == nil {
+		t.Error("expected an error for an unknown period")
+	}
+}
@@ -79,6 +79,7 @@ func (p *TickTickParser) Parse(ctx context.Context, r io.Reader, sourcePath stri
 		}
 
 		item := p.parseRow(row, colMap, ec, sourcePath, lineNum)
+		item.Extensions = columnExtensions("ticktick", header, row, func(col string) bool { return tickTickColumns[col] })
 		collection.Items = append(collection.Items, item)
 	}
 
@@ -186,6 +187,14 @@ func (p *TickTickParser) parseRow(row []string, colMap map[string]int, ec *saler
 	return item
 }
 
+// tickTickColumns are the columns parseRow reads; the rest, such as folder,
+// list or reminder, are kept as extensions.
+var tickTickColumns = map[string]bool{
+	"title": true, "content": true, "tags": true, "start_date": true, "due_date": true,
+	"priority": true, "status": true, "completed_time": true, "is_all_day": true,
+	"timezone": true, "is_checklist": true, "repeat": true,
+}
+
 func mapTickTickPriority(val string) model.Priority {
 	switch val {
 	case "0", "":
@@ -83,6 +83,7 @@ func (p *TodoistParser) Parse(ctx context.Context, r io.Reader, sourcePath strin
 		}
 
 		item := p.parseRow(row, colMap, ec, sourcePath, lineNum)
+		item.Extensions = columnExtensions("todoist", header, row, func(col string) bool { return todoistColumns[col] })
 
 		indentIdx, hasIndent := colMap["INDENT"]
 		currentIndent := 0
@@ -162,6 +163,13 @@ func (p *TodoistParser) parseRow(row []string, colMap map[string]int, ec *salerr
 	return item
 }
 
+// todoistColumns are the columns parseRow and the parser read; the rest,
+// such as AUTHOR or DURATION, are kept as extensions.
+var todoistColumns = map[string]bool{
+	"TYPE": true, "CONTENT": true, "DESCRIPTION": true, "PRIORITY": true,
+	"INDENT": true, "DATE": true, "TIMEZONE": true,
+}
+
 func mapTodoistPriority(val string) model.Priority {
 	priority, err := strconv.Atoi(val)
 	if err != nil {
@@ -0,0 +1,280 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// TodoTxtDateLayout is the date form todo.txt uses for every date.
+const TodoTxtDateLayout = "2006-01-02"
+
+type TodoTxtParser struct{}
+
+func NewTodoTxtParser() *TodoTxtParser {
+	return &TodoTxtParser{}
+}
+
+func (p *TodoTxtParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open todo.txt file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.Parse(ctx, f, filePath)
+}
+
+func (p *TodoTxtParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	tr, err := transcodeReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("charset detection failed: %w", err)
+	}
+
+	ec := salerr.NewErrorCollector()
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "todotxt",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+
+	scanner := bufio.NewScanner(tr)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		item, warnings := parseTodoTxtLine(line)
+		for _, w := range warnings {
+			ec.AddWarning((&salerr.ParseError{File: sourcePath, Line: lineNum, Message: w}).Error())
+		}
+		if item.Title == "" {
+			ec.AddWarning(fmt.Sprintf("%s line %d: skipping task with empty text", sourcePath, lineNum))
+			continue
+		}
+		collection.Items = append(collection.Items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+
+	for _, w := range ec.Warnings {
+		fmt.Fprintf(os.Stderr, "todotxt parser: %s\n", w)
+	}
+
+	return collection, nil
+}
+
+var (
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtKeyValue = regexp.MustCompile(`^([^\s:]+):([^\s:][^\s]*)$`)
+	todoTxtRec      = regexp.MustCompile(`^\+?(\d*)([dwmyb])$`)
+)
+
+// parseTodoTxtLine reads one task. Malformed extension values are kept in
+// the text and reported as warnings.
+func parseTodoTxtLine(line string) (model.CalendarItem, []string) {
+	item := model.CalendarItem{
+		ItemType: model.ItemTypeTask,
+		Status:   model.StatusPending,
+	}
+	var warnings []string
+	fields := strings.Fields(line)
+
+	// x [completion date] [creation date] ... or [(A)] [creation date] ...
+	if len(fields) > 0 && fields[0] == "x" {
+		item.Status = model.StatusCompleted
+		fields = fields[1:]
+		if t, ok := todoTxtLeadingDate(fields); ok {
+			item.CompletionDate = &t
+			fields = fields[1:]
+		}
+	} else if len(fields) > 0 {
+		if m := todoTxtPriority.FindStringSubmatch(fields[0]); m != nil {
+			item.Priority = mapTodoTxtPriority(m[1])
+			fields = fields[1:]
+		}
+	}
+	if t, ok := todoTxtLeadingDate(fields); ok {
+		item.CreatedAt = &t
+		fields = fields[1:]
+	}
+
+	var text []string
+	for _, f := range fields {
+		switch {
+		case len(f) > 1 && f[0] == '+':
+			item.Tags = append(item.Tags, model.ProjectTagPrefix+f[1:])
+			continue
+		case len(f) > 1 && f[0] == '@':
+			item.Tags = append(item.Tags, f[1:])
+			continue
+		}
+
+		m := todoTxtKeyValue.FindStringSubmatch(f)
+		if m == nil {
+			text = append(text, f)
+			continue
+		}
+		key, value := strings.ToLower(m[1]), m[2]
+		switch key {
+		case "due", "t":
+			t, err := time.Parse(TodoTxtDateLayout, value)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("malformed date value %q in %s:", value, key))
+				text = append(text, f)
+				continue
+			}
+			if key == "due" {
+				item.DueDate = &t
+			} else {
+				item.StartTime = &t
+			}
+		case "rec":
+			rec, err := ParseTodoTxtRec(value)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+				text = append(text, f)
+				continue
+			}
+			item.Recurrence = rec
+			if !strings.HasPrefix(value, "+") {
+				item.Extensions = append(item.Extensions, model.Extension{Namespace: "todotxt", Name: "rec", Value: value})
+			}
+		case "pri":
+			// completed tasks keep their priority as pri:A by convention
+			if len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z' && item.Priority == model.PriorityNone {
+				item.Priority = mapTodoTxtPriority(value)
+				continue
+			}
+			text = append(text, f)
+		default:
+			text = append(text, f)
+		}
+	}
+	item.Title = strings.Join(text, " ")
+	if item.Status != model.StatusCompleted {
+		item.CompletionDate = nil
+	}
+	return item, warnings
+}
+
+// IsTodoTxtPriority reports whether word reads as a priority such as (A).
+func IsTodoTxtPriority(word string) bool { return todoTxtPriority.MatchString(word) }
+
+// IsTodoTxtDate reports whether word reads as a todo.txt date.
+func IsTodoTxtDate(word string) bool { return todoTxtDate.MatchString(word) }
+
+// IsTodoTxtField reports whether word is a key:value extension the parser
+// reads into a field, such as due:2026-04-01, rather than keeping as text.
+func IsTodoTxtField(word string) bool {
+	m := todoTxtKeyValue.FindStringSubmatch(word)
+	if m == nil {
+		return false
+	}
+	switch strings.ToLower(m[1]) {
+	case "due", "t":
+		_, err := time.Parse(TodoTxtDateLayout, m[2])
+		return err == nil
+	case "rec":
+		_, err := ParseTodoTxtRec(m[2])
+		return err == nil
+	case "pri":
+		return len(m[2]) == 1 && m[2][0] >= 'A' && m[2][0] <= 'Z'
+	}
+	return false
+}
+
+// SniffTodoTxt reports whether head, the start of a file, reads as
+// todo.txt: some line carries a completion mark, priority, date, project,
+// context or extension rather than being plain text.
+func SniffTodoTxt(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		item, _ := parseTodoTxtLine(line)
+		if item.Status == model.StatusCompleted || item.Priority != model.PriorityNone || item.CreatedAt != nil ||
+			len(item.Tags) > 0 || item.DueDate != nil || item.StartTime != nil || item.Recurrence != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func todoTxtLeadingDate(fields []string) (time.Time, bool) {
+	if len(fields) == 0 || !todoTxtDate.MatchString(fields[0]) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(TodoTxtDateLayout, fields[0])
+	return t, err == nil
+}
+
+// mapTodoTxtPriority maps (A) to the highest priority down to (D) as low;
+// (E) and beyond are all lowest.
+func mapTodoTxtPriority(letter string) model.Priority {
+	switch letter {
+	case "A":
+		return model.PriorityHighest
+	case "B":
+		return model.PriorityHigh
+	case "C":
+		return model.PriorityMedium
+	case "D":
+		return model.PriorityLow
+	default:
+		return model.PriorityLowest
+	}
+}
+
+// ParseTodoTxtRec reads the rec: extension, e.g. 1w, +2m or 1b for business
+// days. The leading + (recur from the due date rather than from completion)
+// is how calendar recurrence always behaves; the parser keeps a value without
+// it as a todotxt extension so the writer can write it back.
+func ParseTodoTxtRec(value string) (*model.Recurrence, error) {
+	m := todoTxtRec.FindStringSubmatch(value)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported recurrence rec:%s", value)
+	}
+	n := 1
+	if m[1] != "" {
+		var err error
+		if n, err = strconv.Atoi(m[1]); err != nil || n < 1 {
+			return nil, fmt.Errorf("unsupported recurrence rec:%s", value)
+		}
+	}
+	switch m[2] {
+	case "d":
+		return &model.Recurrence{Freq: model.FreqDaily, Interval: n}, nil
+	case "w":
+		return &model.Recurrence{Freq: model.FreqWeekly, Interval: n}, nil
+	case "m":
+		return &model.Recurrence{Freq: model.FreqMonthly, Interval: n}, nil
+	case "y":
+		return &model.Recurrence{Freq: model.FreqYearly, Interval: n}, nil
+	default:
+		if n != 1 {
+			return nil, fmt.Errorf("unsupported recurrence rec:%s (only 1b maps to a rule)", value)
+		}
+		return &model.Recurrence{
+			Freq:     model.FreqDaily,
+			Interval: 1,
+			ByDay:    []model.Weekday{model.WeekdayMO, model.WeekdayTU, model.WeekdayWE, model.WeekdayTH, model.WeekdayFR},
+		}, nil
+	}
+}
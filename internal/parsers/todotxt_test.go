@@ -0,0 +1,102 @@
+package parsers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func TestTodoTxtParseLines(t *testing.T) {
+	input := `(A) 2026-03-01 Call Mom +Family @phone due:2026-03-05 rec:+1w
+x 2026-03-04 2026-03-01 File taxes +Admin pri:B
+(E) Read https://example.com/article t:2026-03-10
+
+Stand-up notes at 10:30 rec:1b
+x Buy milk
+Bad date due:2026-13-40
+`
+	col, err := NewTodoTxtParser().Parse(context.Background(), strings.NewReader(input), "todo.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 6 {
+		t.Fatalf("expected 6 items, got %d", len(col.Items))
+	}
+
+	call := col.Items[0]
+	if call.Title != "Call Mom" || call.Priority != model.PriorityHighest || call.Status != model.StatusPending {
+		t.Errorf("call = %+v", call)
+	}
+	if strings.Join(call.Tags, ",") != "project:Family,phone" {
+		t.Errorf("tags = %v", call.Tags)
+	}
+	if want := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC); call.DueDate == nil || !call.DueDate.Equal(want) {
+		t.Errorf("due = %v", call.DueDate)
+	}
+	if call.CreatedAt == nil || call.CreatedAt.Day() != 1 {
+		t.Errorf("created = %v", call.CreatedAt)
+	}
+	if call.Recurrence == nil || call.Recurrence.Freq != model.FreqWeekly || call.Recurrence.Interval != 1 {
+		t.Errorf("recurrence = %+v", call.Recurrence)
+	}
+
+	taxes := col.Items[1]
+	if taxes.Status != model.StatusCompleted || taxes.Priority != model.PriorityHigh || taxes.Title != "File taxes" {
+		t.Errorf("taxes = %+v", taxes)
+	}
+	if taxes.CompletionDate == nil || taxes.CompletionDate.Day() != 4 || taxes.CreatedAt == nil || taxes.CreatedAt.Day() != 1 {
+		t.Errorf("dates = %v %v", taxes.CompletionDate, taxes.CreatedAt)
+	}
+
+	read := col.Items[2]
+	if read.Title != "Read https://example.com/article" || read.Priority != model.PriorityLowest {
+		t.Errorf("read = %+v", read)
+	}
+	if read.StartTime == nil || read.StartTime.Day() != 10 {
+		t.Errorf("threshold = %v", read.StartTime)
+	}
+
+	standup := col.Items[3]
+	if standup.Title != "Stand-up notes at 10:30" || standup.Recurrence == nil || len(standup.Recurrence.ByDay) != 5 {
+		t.Errorf("standup = %+v", standup)
+	}
+	// only a rec: without + needs remembering
+	if v, ok := standup.ExtensionValue("todotxt", "rec"); !ok || v != "1b" || len(call.Extensions) != 0 {
+		t.Errorf("rec extensions = %+v, %+v", standup.Extensions, call.Extensions)
+	}
+	if col.Items[4].Title != "Buy milk" || col.Items[4].Status != model.StatusCompleted || col.Items[4].CompletionDate != nil {
+		t.Errorf("milk = %+v", col.Items[4])
+	}
+	if bad := col.Items[5]; bad.Title != "Bad date due:2026-13-40" || bad.DueDate != nil {
+		t.Errorf("malformed due should stay in the text: %+v", bad)
+	}
+}
+
+func TestTodoTxtPriorityLetters(t *testing.T) {
+	for letter, want := range map[string]model.Priority{
+		"A": model.PriorityHighest, "B": model.PriorityHigh, "C": model.PriorityMedium,
+		"D": model.PriorityLow, "E": model.PriorityLowest, "Z": model.PriorityLowest,
+	} {
+		if got := mapTodoTxtPriority(letter); got != want {
+			t.Errorf("(%s) = %d, want %d", letter, got, want)
+		}
+	}
+}
+
+func TestSniffTodoTxt(t *testing.T) {
+	for head, want := range map[string]bool{
+		"(A) Call Mom\n":                 true,
+		"x 2026-03-04 File taxes\n":      true,
+		"\nWater plants +Home\n":         true,
+		"Pay rent due:2026-04-01":        true,
+		"Just some notes.\nNothing else": false,
+		"":                               false,
+	} {
+		if got := SniffTodoTxt([]byte(head)); got != want {
+			t.Errorf("SniffTodoTxt(%q) = %v, want %v", head, got, want)
+		}
+	}
+}
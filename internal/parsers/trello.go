@@ -51,6 +51,25 @@ type TrelloList struct {
 	Name string `json:"name"`
 }
 
+// trelloBoardKeys are top-level keys of a Trello board export that other JSON
+// formats do not use.
+var trelloBoardKeys = []string{"cards", "lists", "idOrganization", "shortUrl", "labelNames", "prefs"}
+
+// SniffTrello reports whether head, the start of a file, reads as a Trello
+// board export: a single object with board keys.
+func SniffTrello(head []byte) bool {
+	keys, inArray := jsonHeadKeys(head)
+	if inArray {
+		return false
+	}
+	for _, key := range trelloBoardKeys {
+		if keys[key] {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *TrelloParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
@@ -65,6 +84,9 @@ func (p *TrelloParser) Parse(ctx context.Context, r io.Reader, sourcePath string
 	if err := json.NewDecoder(r).Decode(&board); err != nil {
 		return nil, fmt.Errorf("failed to decode Trello JSON: %w", err)
 	}
+	if board.Name == "" && board.Cards == nil && board.Lists == nil {
+		return nil, fmt.Errorf("failed to decode Trello JSON: no board name, cards or lists")
+	}
 
 	collection := &model.CalendarCollection{
 		Items:            []model.CalendarItem{},
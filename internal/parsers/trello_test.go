@@ -151,6 +151,32 @@ func TestTrelloEmptyBoard(t *testing.T) {
 	}
 }
 
+func TestTrelloRejectsJSONWithoutBoard(t *testing.T) {
+	p := NewTrelloParser()
+	for _, json := range []string{
+		`{"format": "salja-json", "version": 1, "items": []}`,
+		`{}`,
+	} {
+		if _, err := p.Parse(context.Background(), strings.NewReader(json), "test.json"); err == nil {
+			t.Errorf("Parse(%s): expected an error", json)
+		}
+	}
+}
+
+func TestSniffTrello(t *testing.T) {
+	for head, want := range map[string]bool{
+		`{"id": "b1", "name": "Board", "idOrganization": null, "prefs": {`: true,
+		`{"name": "Board", "cards": [], "lists": []}`:                      true,
+		`[{"uuid": "5f1c", "description": "Pay rent"}]`:                    false,
+		`{"format": "salja-json", "version": 1, "items": []}`:              false,
+		"(A) Call Mom\n": false,
+	} {
+		if got := SniffTrello([]byte(head)); got != want {
+			t.Errorf("SniffTrello(%q) = %v, want %v", head, got, want)
+		}
+	}
+}
+
 func TestTrelloSourceApp(t *testing.T) {
 	json := `{"name": "Board", "cards": [], "lists": []}`
 	p := NewTrelloParser()
@@ -0,0 +1,395 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	salerr "github.com/gongahkia/salja/internal/errors"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/xlsx"
+)
+
+// XLSXParser reads an Excel workbook, one item per row below a header row.
+// Sheet names the one sheet to read; empty reads every sheet with a title
+// column, such as the Events, Tasks and Journal sheets the writer makes.
+type XLSXParser struct {
+	Sheet string
+}
+
+var (
+	xlsxSheetMu sync.RWMutex
+	xlsxSheet   string
+)
+
+// SetXLSXSheet names the sheet that parsers from NewXLSXParser read.
+func SetXLSXSheet(name string) {
+	xlsxSheetMu.Lock()
+	xlsxSheet = name
+	xlsxSheetMu.Unlock()
+}
+
+func NewXLSXParser() *XLSXParser {
+	xlsxSheetMu.RLock()
+	defer xlsxSheetMu.RUnlock()
+	return &XLSXParser{Sheet: xlsxSheet}
+}
+
+// xlsxColumns maps the columns the parser reads to the header names, in
+// lower case, that a planner sheet may give them.
+var xlsxColumns = map[string][]string{
+	"Title":       {"title", "name", "task", "task name", "subject", "summary"},
+	"Type":        {"type", "item type"},
+	"Status":      {"status", "state"},
+	"Priority":    {"priority"},
+	"Start":       {"start", "start date", "date", "begin", "scheduled"},
+	"Start Time":  {"start time"},
+	"End":         {"end", "end date", "finish", "finish date"},
+	"End Time":    {"end time"},
+	"Due":         {"due", "due date", "deadline"},
+	"Due Time":    {"due time"},
+	"All Day":     {"all day", "all-day", "all day event"},
+	"Completed":   {"completed", "completed at", "completion date", "done"},
+	"Location":    {"location", "where"},
+	"Tags":        {"tags", "labels", "categories"},
+	"Description": {"description", "notes", "details", "comments"},
+	"UID":         {"uid", "id"},
+}
+
+func (p *XLSXParser) ParseFile(ctx context.Context, filePath string) (*model.CalendarCollection, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xlsx %s: %w", filePath, err)
+	}
+	defer func() { _ = f.Close() }()
+	return p.Parse(ctx, f, filePath)
+}
+
+func (p *XLSXParser) Parse(ctx context.Context, r io.Reader, sourcePath string) (*model.CalendarCollection, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xlsx %s: %w", sourcePath, err)
+	}
+	wb, err := xlsx.Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xlsx %s: %w", sourcePath, err)
+	}
+	collection := &model.CalendarCollection{
+		Items:            []model.CalendarItem{},
+		SourceApp:        "xlsx",
+		ExportDate:       time.Now(),
+		OriginalFilePath: sourcePath,
+	}
+	ec := salerr.NewErrorCollector()
+	if p.Sheet != "" {
+		if err := p.parseSheet(ctx, wb, p.Sheet, collection, ec, sourcePath); err != nil {
+			return nil, err
+		}
+	} else {
+		// sheets without a title column are skipped unless nothing else is read
+		var missing []error
+		for _, sheet := range wb.Sheets {
+			err := p.parseSheet(ctx, wb, sheet, collection, ec, sourcePath)
+			var noTitle *xlsxNoTitleError
+			if errors.As(err, &noTitle) {
+				missing = append(missing, err)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(missing) > 0 && len(collection.Items) == 0 {
+			return nil, missing[0]
+		}
+		for _, err := range missing {
+			ec.AddWarning(err.Error() + "; sheet skipped")
+		}
+	}
+
+	if len(ec.Warnings) > 0 {
+		for _, w := range ec.Warnings {
+			fmt.Fprintf(os.Stderr, "xlsx parser: %s\n", w)
+		}
+	}
+
+	return collection, nil
+}
+
+// xlsxNoTitleError is a sheet without a title column, which a read of every
+// sheet passes over.
+type xlsxNoTitleError struct {
+	sourcePath, sheet string
+	missing           []string
+}
+
+func (e *xlsxNoTitleError) Error() string {
+	return fmt.Sprintf("xlsx %s sheet %q missing required columns: %s", e.sourcePath, e.sheet, strings.Join(e.missing, ", "))
+}
+
+// parseSheet appends the items of one sheet to collection.
+func (p *XLSXParser) parseSheet(ctx context.Context, wb *xlsx.File, sheet string, collection *model.CalendarCollection, ec *salerr.ErrorCollector, sourcePath string) error {
+	rows, err := wb.Rows(sheet)
+	if err != nil {
+		return fmt.Errorf("xlsx %s: %w", sourcePath, err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	colMap := make(map[string]int)
+	for i, cell := range rows[0].Cells {
+		header := strings.ToLower(strings.Join(strings.Fields(cell.String()), " "))
+		for col, names := range xlsxColumns {
+			for _, name := range names {
+				if _, seen := colMap[col]; !seen && header == name {
+					colMap[col] = i
+				}
+			}
+		}
+	}
+	if missing := findMissingColumns(colMap, []string{"Title"}); len(missing) > 0 {
+		return &xlsxNoTitleError{sourcePath: sourcePath, sheet: sheet, missing: missing}
+	}
+
+	defaultType := xlsxSheetType(sheet, colMap)
+	for _, row := range rows[1:] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		x := &xlsxRow{wb: wb, row: row, colMap: colMap, ec: ec, sourcePath: sourcePath}
+		if x.blank() {
+			continue
+		}
+		item := x.item(defaultType)
+		if item.Title == "" {
+			x.warn("row has no title; skipped")
+			continue
+		}
+		collection.Items = append(collection.Items, item)
+	}
+	return nil
+}
+
+// xlsxSheetType is the type of rows without a Type cell: the one the sheet is
+// named for, as XLSXWriter names them, or else a task when the sheet has a
+// task column and an event when it does not.
+func xlsxSheetType(sheet string, colMap map[string]int) model.ItemType {
+	switch strings.ToLower(strings.TrimSpace(sheet)) {
+	case "events", "event", "calendar":
+		return model.ItemTypeEvent
+	case "tasks", "task", "todo", "to do", "to-do":
+		return model.ItemTypeTask
+	case "journal", "journals", "notes":
+		return model.ItemTypeJournal
+	}
+	for _, col := range []string{"Due", "Status", "Priority", "Completed"} {
+		if _, ok := colMap[col]; ok {
+			return model.ItemTypeTask
+		}
+	}
+	return model.ItemTypeEvent
+}
+
+type xlsxRow struct {
+	wb         *xlsx.File
+	row        xlsx.Row
+	colMap     map[string]int
+	ec         *salerr.ErrorCollector
+	sourcePath string
+}
+
+func (x *xlsxRow) cell(col string) xlsx.Cell {
+	if idx, ok := x.colMap[col]; ok && idx < len(x.row.Cells) {
+		return x.row.Cells[idx]
+	}
+	return xlsx.Cell{}
+}
+
+func (x *xlsxRow) text(col string) string {
+	return strings.TrimSpace(x.cell(col).String())
+}
+
+func (x *xlsxRow) blank() bool {
+	for _, c := range x.row.Cells {
+		if !c.IsBlank() {
+			return false
+		}
+	}
+	return true
+}
+
+func (x *xlsxRow) warn(msg string) {
+	x.ec.AddWarning((&salerr.ParseError{
+		File:    x.sourcePath,
+		Line:    x.row.Num,
+		Message: msg,
+	}).Error())
+}
+
+func (x *xlsxRow) item(defaultType model.ItemType) model.CalendarItem {
+	item := model.CalendarItem{
+		UID:         x.text("UID"),
+		Title:       x.text("Title"),
+		Description: strings.TrimSpace(x.cell("Description").String()),
+		Location:    x.text("Location"),
+		ItemType:    defaultType,
+		Status:      model.StatusPending,
+	}
+
+	if t := x.text("Type"); t != "" {
+		if itemType, ok := parseItemTypeName(t); ok {
+			item.ItemType = itemType
+		} else {
+			x.warn(fmt.Sprintf("unknown item type %q in field Type", t))
+		}
+	}
+
+	if s := x.text("Status"); s != "" {
+		if status, ok := parseStatusName(s); ok {
+			item.Status = status
+		} else {
+			x.warn(fmt.Sprintf("unknown status %q in field Status", s))
+		}
+	}
+
+	if s := x.text("Priority"); s != "" {
+		if p, ok := parsePriorityName(s); ok {
+			item.Priority = p
+		} else {
+			x.warn(fmt.Sprintf("unknown priority %q in field Priority", s))
+		}
+	}
+
+	if s := x.text("Tags"); s != "" {
+		for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+	}
+
+	start, startHasTime := x.time("Start", "Start Time")
+	end, endHasTime := x.time("End", "End Time")
+	due, _ := x.time("Due", "Due Time")
+	item.StartTime, item.EndTime, item.DueDate = start, end, due
+
+	if c := x.cell("All Day"); !c.IsBlank() {
+		item.IsAllDay = xlsxTruth(c)
+	} else if item.ItemType == model.ItemTypeEvent && start != nil && !startHasTime && (end == nil || !endHasTime) {
+		item.IsAllDay = true
+	}
+
+	// a Completed column holds either the completion date or a checkbox
+	if c := x.cell("Completed"); !c.IsBlank() {
+		if c.Type == xlsx.Bool || isTruthWord(c.String()) || isFalseWord(c.String()) {
+			if xlsxTruth(c) {
+				item.Status = model.StatusCompleted
+			}
+		} else if t, _ := x.time("Completed", ""); t != nil {
+			item.CompletionDate = t
+			if item.Status == model.StatusPending {
+				item.Status = model.StatusCompleted
+			}
+		}
+	}
+
+	return item
+}
+
+// time reads a date column, joined with the time of day in timeCol when the
+// sheet keeps it apart, and reports whether the value has a time of day.
+// Numbers are Excel date serials; text is read as a date in the locale set
+// with SetLocale.
+func (x *xlsxRow) time(dateCol, timeCol string) (*time.Time, bool) {
+	c := x.cell(dateCol)
+	if c.IsBlank() {
+		return nil, false
+	}
+	var t time.Time
+	if wall, ok := x.wb.CellTime(c); ok {
+		t = xlsxLocal(wall)
+	} else if c.Type == xlsx.String {
+		var err error
+		if t, err = parseXLSXDateText(c.Text); err != nil {
+			x.warn(fmt.Sprintf("malformed date value %q in field %s", c.Text, dateCol))
+			return nil, false
+		}
+	} else {
+		x.warn(fmt.Sprintf("malformed date value %q in field %s", c.String(), dateCol))
+		return nil, false
+	}
+
+	if timeCol != "" {
+		if tc := x.cell(timeCol); !tc.IsBlank() {
+			if clock, ok := x.clock(tc); ok {
+				// set the wall clock, as adding the duration would cross DST changes
+				y, m, d := t.Date()
+				h, min, sec := clock.Clock()
+				t = time.Date(y, m, d, h, min, sec, 0, t.Location())
+			} else {
+				x.warn(fmt.Sprintf("malformed time value %q in field %s", tc.String(), timeCol))
+			}
+		}
+	}
+	return &t, !isMidnight(t)
+}
+
+// clock reads a time of day: the fraction of a serial or text such as 14:30
+// or 2:30 PM.
+func (x *xlsxRow) clock(c xlsx.Cell) (time.Time, bool) {
+	if wall, ok := x.wb.CellTime(c); ok {
+		return wall, true
+	}
+	for _, layout := range []string{"15:04", "15:04:05", "3:04 PM", "3:04:05 PM", "3:04PM", "3PM"} {
+		if t, err := time.Parse(layout, strings.ToUpper(strings.TrimSpace(c.Text))); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// xlsxLocal reads a serial's wall clock as local time; like Org timestamps,
+// spreadsheet dates carry no zone.
+func xlsxLocal(wall time.Time) time.Time {
+	y, m, d := wall.Date()
+	h, min, s := wall.Clock()
+	return time.Date(y, m, d, h, min, s, 0, time.Local)
+}
+
+// parseXLSXDateText reads a date typed as text, with or without a time of
+// day, falling back to the locale-aware date parser.
+func parseXLSXDateText(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := parseAmbiguousDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return xlsxLocal(t), nil
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+func xlsxTruth(c xlsx.Cell) bool {
+	switch c.Type {
+	case xlsx.Bool, xlsx.Number:
+		return c.Num != 0
+	}
+	return isTruthWord(c.String())
+}
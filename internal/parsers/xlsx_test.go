@@ -0,0 +1,166 @@
+package parsers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// excelWorkbook zips the parts of a workbook laid out as Excel saves one,
+// with shared strings and sheets that skip empty cells.
+func excelWorkbook(t *testing.T, date1904 bool, sheets map[string]string) []byte {
+	t.Helper()
+	pr := ""
+	if date1904 {
+		pr = `<workbookPr date1904="1"/>`
+	}
+	parts := map[string]string{
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`,
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` + pr + `<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Plan" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/><Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="12" uniqueCount="12"><si><t>Subject</t></si><si><t>Start</t></si><si><t>End</t></si><si><t>Launch</t></si><si><r><t>Off</t></r><r><rPr><b/></rPr><t>site</t></r></si><si><t>Task Name</t></si><si><t>Due Date</t></si><si><t>Priority</t></si><si><t>Status</t></si><si><t>Notes</t></si><si><t>Start Date</t></si><si><t>Start Time</t></si></sst>`,
+	}
+	for name, data := range sheets {
+		parts["xl/worksheets/"+name] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + data + `</sheetData></worksheet>`
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var excelSheets = map[string]string{
+	// 46091 is 2026-03-10
+	"sheet1.xml": `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2" s="1"><v>46091.375</v></c><c r="C2" s="1"><v>46091.4375</v></c></row>` +
+		`<row r="4"><c r="A4" t="s"><v>4</v></c><c r="B4" s="2"><v>46093</v></c><c r="C4" s="2"><v>46095</v></c></row>`,
+	"sheet2.xml": `<row r="1"><c r="A1" t="s"><v>5</v></c><c r="B1" t="s"><v>6</v></c><c r="C1" t="s"><v>7</v></c><c r="D1" t="s"><v>8</v></c><c r="E1" t="s"><v>9</v></c><c r="F1" t="s"><v>10</v></c><c r="G1" t="s"><v>11</v></c></row>` +
+		`<row r="2"><c r="A2" t="inlineStr"><is><t>File taxes</t></is></c><c r="B2"><v>46091</v></c><c r="C2" t="inlineStr"><is><t>High</t></is></c><c r="D2" t="inlineStr"><is><t>In progress</t></is></c><c r="E2" t="str"><f>"see "&amp;"folder"</f><v>see folder</v></c><c r="F2"><v>46089</v></c><c r="G2"><v>0.5625</v></c></row>` +
+		`<row r="3"><c r="A3" t="inlineStr"><is><t>Typed date</t></is></c><c r="B3" t="inlineStr"><is><t>03/04/2026</t></is></c><c r="D3" t="inlineStr"><is><t>done</t></is></c></row>` +
+		`<row r="4"><c r="B4"><v>46091</v></c></row>` +
+		`<row r="5"><c r="A5" t="inlineStr"><is><t>Bad date</t></is></c><c r="B5" t="inlineStr"><is><t>someday</t></is></c></row>`,
+}
+
+func TestXLSXParseAllSheets(t *testing.T) {
+	data := excelWorkbook(t, false, excelSheets)
+	col, err := NewXLSXParser().Parse(context.Background(), bytes.NewReader(data), "plan.xlsx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// two events from Summary, then three tasks from Plan
+	if col.SourceApp != "xlsx" || len(col.Items) != 5 {
+		t.Fatalf("collection = %+v", col)
+	}
+	if col.Items[2].Title != "File taxes" || col.Items[2].ItemType != model.ItemTypeTask {
+		t.Errorf("first Plan item = %+v", col.Items[2])
+	}
+
+	launch := col.Items[0]
+	if launch.Title != "Launch" || launch.ItemType != model.ItemTypeEvent || launch.IsAllDay {
+		t.Errorf("launch = %+v", launch)
+	}
+	if want := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local); launch.StartTime == nil || !launch.StartTime.Equal(want) {
+		t.Errorf("start = %v, want %v", launch.StartTime, want)
+	}
+	if want := time.Date(2026, 3, 10, 10, 30, 0, 0, time.Local); launch.EndTime == nil || !launch.EndTime.Equal(want) {
+		t.Errorf("end = %v, want %v", launch.EndTime, want)
+	}
+
+	// rich text runs are joined; dates without a time make an all-day event
+	offsite := col.Items[1]
+	if offsite.Title != "Offsite" || !offsite.IsAllDay || offsite.StartTime == nil || offsite.StartTime.Day() != 12 {
+		t.Errorf("offsite = %+v", offsite)
+	}
+}
+
+func TestXLSXParseNamedSheet(t *testing.T) {
+	data := excelWorkbook(t, false, excelSheets)
+	p := &XLSXParser{Sheet: "plan"}
+	col, err := p.Parse(context.Background(), bytes.NewReader(data), "plan.xlsx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the row without a title is skipped
+	if len(col.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d: %+v", len(col.Items), col.Items)
+	}
+
+	taxes := col.Items[0]
+	if taxes.ItemType != model.ItemTypeTask || taxes.Priority != model.PriorityHigh || taxes.Status != model.StatusInProgress {
+		t.Errorf("taxes = %+v", taxes)
+	}
+	if taxes.Description != "see folder" {
+		t.Errorf("description = %q", taxes.Description)
+	}
+	if want := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local); taxes.DueDate == nil || !taxes.DueDate.Equal(want) {
+		t.Errorf("due = %v, want %v", taxes.DueDate, want)
+	}
+	if want := time.Date(2026, 3, 8, 13, 30, 0, 0, time.Local); taxes.StartTime == nil || !taxes.StartTime.Equal(want) {
+		t.Errorf("start = %v, want %v", taxes.StartTime, want)
+	}
+
+	// text dates fall back to the locale
+	typed := col.Items[1]
+	if typed.Status != model.StatusCompleted || typed.DueDate == nil || typed.DueDate.Month() != time.March || typed.DueDate.Day() != 4 {
+		t.Errorf("typed = %+v", typed)
+	}
+
+	if bad := col.Items[2]; bad.DueDate != nil {
+		t.Errorf("bad date = %v", bad.DueDate)
+	}
+
+	if _, err := (&XLSXParser{Sheet: "Missing"}).Parse(context.Background(), bytes.NewReader(data), "plan.xlsx"); err == nil || !strings.Contains(err.Error(), "Summary, Plan") {
+		t.Errorf("missing sheet error = %v", err)
+	}
+}
+
+func TestXLSXParse1904Dates(t *testing.T) {
+	// 44629 days after 1904-01-01 is 2026-03-10
+	data := excelWorkbook(t, true, map[string]string{
+		"sheet1.xml": `<row r="1"><c r="A1" t="s"><v>5</v></c><c r="B1" t="s"><v>6</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>44629</v></c></row>`,
+		"sheet2.xml": ``,
+	})
+	col, err := NewXLSXParser().Parse(context.Background(), bytes.NewReader(data), "mac.xlsx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(col.Items) != 1 || col.Items[0].DueDate == nil || col.Items[0].DueDate.Format("2006-01-02") != "2026-03-10" {
+		t.Errorf("items = %+v", col.Items)
+	}
+}
+
+func TestXLSXParseMissingTitle(t *testing.T) {
+	data := excelWorkbook(t, false, map[string]string{
+		"sheet1.xml": `<row r="1"><c r="A1" t="s"><v>1</v></c></row>`,
+		"sheet2.xml": ``,
+	})
+	if _, err := NewXLSXParser().Parse(context.Background(), bytes.NewReader(data), "plan.xlsx"); err == nil || !strings.Contains(err.Error(), "Title") {
+		t.Errorf("expected a missing column error, got %v", err)
+	}
+	if _, err := NewXLSXParser().Parse(context.Background(), strings.NewReader("Title,Due\n"), "plan.xlsx"); err == nil {
+		t.Error("expected an error for a file that is not a workbook")
+	}
+}
@@ -3,6 +3,8 @@ package registry
 import (
 	"github.com/gongahkia/salja/internal/apple"
 	"github.com/gongahkia/salja/internal/ics"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/native"
 	"github.com/gongahkia/salja/internal/parsers"
 	"github.com/gongahkia/salja/internal/writers"
 )
@@ -19,6 +21,81 @@ func init() {
 			SupportsRecurrence: true,
 			SupportsSubtasks:   false,
 			SupportsReminders:  true,
+			SupportsAttendees:  true,
+			KeepsExtensions:    []string{"ics"},
+			Label:              "iCalendar",
+			AttendeesNeedEmail: true,
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:       "jcal",
+		Extensions: []string{".jcal"},
+		NewParser:  func() Parser { return ics.NewJCalParser() },
+		NewWriter:  func() Writer { return ics.NewJCalWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   false,
+			SupportsReminders:  true,
+			SupportsAttendees:  true,
+			KeepsExtensions:    []string{"ics"},
+			Label:              "iCalendar",
+			AttendeesNeedEmail: true,
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:       "xcal",
+		Extensions: []string{".xcs", ".xcal"},
+		NewParser:  func() Parser { return ics.NewXCalParser() },
+		NewWriter:  func() Writer { return ics.NewXCalWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   false,
+			SupportsReminders:  true,
+			SupportsAttendees:  true,
+			KeepsExtensions:    []string{"ics"},
+			Label:              "iCalendar",
+			AttendeesNeedEmail: true,
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:            "salja-json",
+		Extensions:      []string{},
+		FilenameHint:    []string{".salja.json"},
+		Sniff:           native.Sniff,
+		SniffExtensions: []string{".json"},
+		NewParser:       func() Parser { return native.NewParser() },
+		NewWriter:       func() Writer { return native.NewWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   true,
+			SupportsReminders:  true,
+			SupportsAttendees:  true,
+			KeepsExtensions:    []string{"*"},
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:       "salja-ndjson",
+		Extensions: []string{".ndjson"},
+		NewParser:  func() Parser { return native.NewParser() },
+		NewWriter:  func() Writer { return native.NewNDJSONWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   true,
+			SupportsReminders:  true,
+			SupportsAttendees:  true,
+			KeepsExtensions:    []string{"*"},
 		},
 	})
 
@@ -33,6 +110,7 @@ func init() {
 			SupportsTasks:      true,
 			SupportsRecurrence: true,
 			SupportsSubtasks:   true,
+			KeepsExtensions:    []string{"ticktick"},
 		},
 	})
 
@@ -47,6 +125,10 @@ func init() {
 			SupportsTasks:      true,
 			SupportsRecurrence: false,
 			SupportsSubtasks:   true,
+			KeepsExtensions:    []string{"todoist"},
+			Label:              "Todoist",
+			PriorityScale:      "1-4",
+			MergedPriorities:   []model.Priority{model.PriorityLowest},
 		},
 	})
 
@@ -61,6 +143,7 @@ func init() {
 			SupportsTasks:      false,
 			SupportsRecurrence: false,
 			SupportsSubtasks:   false,
+			KeepsExtensions:    []string{"gcal"},
 		},
 	})
 
@@ -75,6 +158,8 @@ func init() {
 			SupportsTasks:      false,
 			SupportsRecurrence: false,
 			SupportsSubtasks:   false,
+			SupportsAttendees:  true,
+			KeepsExtensions:    []string{"outlook"},
 		},
 	})
 
@@ -89,14 +174,17 @@ func init() {
 			SupportsTasks:      true,
 			SupportsRecurrence: false,
 			SupportsSubtasks:   false,
+			KeepsExtensions:    []string{"notion"},
 		},
 	})
 
 	Register(&FormatEntry{
-		Name:       "trello",
-		Extensions: []string{".json"},
-		NewParser:  func() Parser { return parsers.NewTrelloParser() },
-		NewWriter:  func() Writer { return writers.NewTrelloWriter() },
+		Name:            "trello",
+		Extensions:      []string{".json"},
+		Sniff:           parsers.SniffTrello,
+		SniffExtensions: []string{".json"},
+		NewParser:       func() Parser { return parsers.NewTrelloParser() },
+		NewWriter:       func() Writer { return writers.NewTrelloWriter() },
 		Capabilities: FormatCapabilities{
 			SupportsEvents:     false,
 			SupportsTasks:      true,
@@ -105,6 +193,100 @@ func init() {
 		},
 	})
 
+	Register(&FormatEntry{
+		Name:            "taskwarrior",
+		Extensions:      []string{},
+		FilenameHint:    []string{"taskwarrior"},
+		Sniff:           parsers.SniffTaskwarrior,
+		SniffExtensions: []string{".json"},
+		NewParser:       func() Parser { return parsers.NewTaskwarriorParser() },
+		NewWriter:       func() Writer { return writers.NewTaskwarriorWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     false,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   true,
+			SupportsReminders:  true,
+			KeepsExtensions:    []string{"taskwarrior"},
+			Label:              "Taskwarrior",
+			PriorityScale:      "H/M/L",
+			MergedPriorities:   []model.Priority{model.PriorityLowest, model.PriorityHighest},
+			Recurrence:         RecurrencePeriod,
+			WeekdaysFreq:       model.FreqWeekly,
+			MaxReminders:       1,
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:       "org",
+		Extensions: []string{".org"},
+		NewParser:  func() Parser { return parsers.NewOrgParser() },
+		NewWriter:  func() Writer { return writers.NewOrgWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   true,
+			KeepsExtensions:    []string{"org"},
+			Label:              "Org",
+			PriorityScale:      "[#A]-[#C]",
+			MergedPriorities:   []model.Priority{model.PriorityLowest, model.PriorityHighest},
+			Recurrence:         RecurrencePeriod,
+		},
+	})
+
+	// the parser reads Logseq blocks too; logseq exists to write them
+	Register(&FormatEntry{
+		Name:       "markdown-tasks",
+		Extensions: []string{".md"},
+		NewParser:  func() Parser { return parsers.NewMarkdownTasksParser() },
+		NewWriter:  func() Writer { return writers.NewMarkdownTasksWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   true,
+			Label:              "Obsidian Tasks",
+			Recurrence:         RecurrenceText,
+			DateOnly:           true,
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:      "logseq",
+		NewParser: func() Parser { return parsers.NewLogseqParser() },
+		NewWriter: func() Writer { return writers.NewLogseqWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   true,
+			Label:              "Logseq",
+			PriorityScale:      "[#A]-[#C]",
+			MergedPriorities:   []model.Priority{model.PriorityLowest, model.PriorityHighest},
+			Recurrence:         RecurrencePeriod,
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:         "todotxt",
+		FilenameHint: []string{"todo.txt", "done.txt"},
+		Sniff:        parsers.SniffTodoTxt,
+		NewParser:    func() Parser { return parsers.NewTodoTxtParser() },
+		NewWriter:    func() Writer { return writers.NewTodoTxtWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     false,
+			SupportsTasks:      true,
+			SupportsRecurrence: true,
+			SupportsSubtasks:   false,
+			KeepsExtensions:    []string{"todotxt"},
+			Label:              "todo.txt",
+			Recurrence:         RecurrencePeriod,
+			WeekdaysFreq:       model.FreqDaily,
+			DateOnly:           true,
+			NoDescription:      true,
+			NoCancelled:        true,
+		},
+	})
+
 	Register(&FormatEntry{
 		Name:         "asana",
 		Extensions:   []string{".csv"},
@@ -116,6 +298,35 @@ func init() {
 			SupportsTasks:      true,
 			SupportsRecurrence: false,
 			SupportsSubtasks:   false,
+			KeepsExtensions:    []string{"asana"},
+		},
+	})
+
+	// columns come from a mapping profile; detected for a .csv no hint matches
+	Register(&FormatEntry{
+		Name:       "csv",
+		Extensions: []string{".csv"},
+		NewParser:  func() Parser { return parsers.NewCSVParser() },
+		NewWriter:  func() Writer { return writers.NewCSVWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: false,
+			SupportsSubtasks:   false,
+			KeepsExtensions:    []string{"csv"},
+		},
+	})
+
+	Register(&FormatEntry{
+		Name:       "xlsx",
+		Extensions: []string{".xlsx"},
+		NewParser:  func() Parser { return parsers.NewXLSXParser() },
+		NewWriter:  func() Writer { return writers.NewXLSXWriter() },
+		Capabilities: FormatCapabilities{
+			SupportsEvents:     true,
+			SupportsTasks:      true,
+			SupportsRecurrence: false,
+			SupportsSubtasks:   false,
 		},
 	})
 
@@ -143,6 +354,7 @@ func init() {
 			SupportsTasks:      false,
 			SupportsRecurrence: false,
 			SupportsSubtasks:   false,
+			SupportsAttendees:  true,
 		},
 	})
 
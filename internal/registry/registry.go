@@ -29,6 +29,73 @@ type FormatCapabilities struct {
 	SupportsRecurrence bool
 	SupportsSubtasks   bool
 	SupportsReminders  bool
+	SupportsAttendees  bool
+	// KeepsExtensions lists the model.Extension namespaces the writer
+	// writes back; "*" keeps every namespace.
+	KeepsExtensions []string
+
+	// Label names the format in fidelity warnings.
+	Label string
+	// PriorityScale names a priority scale coarser than the model's; the
+	// MergedPriorities share a value on it with a neighbour.
+	PriorityScale    string
+	MergedPriorities []model.Priority
+	// Recurrence is how much of a rule the format keeps when it supports
+	// recurrence; WeekdaysFreq is the frequency at which it has a keyword
+	// for Monday to Friday.
+	Recurrence   RecurrenceForm
+	WeekdaysFreq model.FreqType
+	// MaxReminders is how many reminders the format keeps; 0 keeps all.
+	MaxReminders       int
+	DateOnly           bool // dates have no time of day
+	NoDescription      bool
+	NoCancelled        bool // cancelled tasks are written as done
+	AttendeesNeedEmail bool // attendees are addressed by mailto: URI
+}
+
+// RecurrenceForm is how much of a recurrence rule a format can write.
+type RecurrenceForm int
+
+const (
+	// RecurrenceRules writes whole RRULEs.
+	RecurrenceRules RecurrenceForm = iota
+	// RecurrencePeriod writes a frequency and interval only.
+	RecurrencePeriod
+	// RecurrenceText writes Obsidian Tasks rules: weekdays on a weekly
+	// rule, one day on a monthly one.
+	RecurrenceText
+)
+
+func (f RecurrenceForm) String() string {
+	switch f {
+	case RecurrencePeriod:
+		return "a plain period"
+	case RecurrenceText:
+		return "weekdays or one day of the month"
+	}
+	return "a full rule"
+}
+
+// MergesPriority reports whether p shares a value with a neighbouring
+// priority on the format's scale.
+func (c FormatCapabilities) MergesPriority(p model.Priority) bool {
+	for _, m := range c.MergedPriorities {
+		if m == p {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepsExtension reports whether the format writes back extensions from
+// namespace.
+func (c FormatCapabilities) KeepsExtension(namespace string) bool {
+	for _, ns := range c.KeepsExtensions {
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
 }
 
 type FormatEntry struct {
@@ -36,14 +103,27 @@ type FormatEntry struct {
 	Extensions   []string
 	FilenameHint []string // substrings in filename used for CSV disambiguation
 	Platform     string   // "" = all platforms, "darwin" = macOS only
-	NewParser    ParserFactory
-	NewWriter    WriterFactory
-	Capabilities FormatCapabilities
+	// Sniff reports whether the start of a file reads as this format; it is
+	// tried for files no extension or filename hint claims and for files
+	// with one of SniffExtensions.
+	Sniff func(head []byte) bool
+	// SniffExtensions are shared extensions, such as .json, whose files are
+	// sniffed for this format before the extension decides.
+	SniffExtensions []string
+	NewParser       ParserFactory
+	NewWriter       WriterFactory
+	Capabilities    FormatCapabilities
 }
 
-var formats = map[string]*FormatEntry{}
+var (
+	formats = map[string]*FormatEntry{}
+	order   []string // names in registration order, so detection is stable
+)
 
 func Register(entry *FormatEntry) {
+	if _, ok := formats[entry.Name]; !ok {
+		order = append(order, entry.Name)
+	}
 	formats[entry.Name] = entry
 }
 
@@ -71,8 +151,8 @@ func GetWriter(name string) (Writer, error) {
 
 func DetectByExtension(ext string) []string {
 	var matches []string
-	for name, entry := range formats {
-		for _, e := range entry.Extensions {
+	for _, name := range order {
+		for _, e := range formats[name].Extensions {
 			if e == ext {
 				matches = append(matches, name)
 			}
@@ -81,9 +161,11 @@ func DetectByExtension(ext string) []string {
 	return matches
 }
 
+// DetectByFilenameHint returns the first format, in registration order,
+// with a hint basename contains.
 func DetectByFilenameHint(basename string) string {
-	for name, entry := range formats {
-		for _, hint := range entry.FilenameHint {
+	for _, name := range order {
+		for _, hint := range formats[name].FilenameHint {
 			if containsLower(basename, hint) {
 				return name
 			}
@@ -92,6 +174,34 @@ func DetectByFilenameHint(basename string) string {
 	return ""
 }
 
+// DetectByContent returns the first format, in registration order, whose
+// Sniff accepts head.
+func DetectByContent(head []byte) string {
+	for _, name := range order {
+		if sniff := formats[name].Sniff; sniff != nil && sniff(head) {
+			return name
+		}
+	}
+	return ""
+}
+
+// DetectByContentFor returns the first format, in registration order, that
+// sniffs files with extension ext and whose Sniff accepts head.
+func DetectByContentFor(ext string, head []byte) string {
+	for _, name := range order {
+		entry := formats[name]
+		if entry.Sniff == nil {
+			continue
+		}
+		for _, e := range entry.SniffExtensions {
+			if e == ext && entry.Sniff(head) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
 func AllFormats() map[string]*FormatEntry {
 	return formats
 }
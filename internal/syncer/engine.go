@@ -0,0 +1,544 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/conflict"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// Remote is a cloud service the engine reads from and writes to. Items
+// returned by a Remote carry the remote ID in their UID field.
+type Remote interface {
+	List(ctx context.Context) ([]model.CalendarItem, error)
+	Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error)
+	Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error)
+	Delete(ctx context.Context, remoteID string) error
+}
+
+// CompletionHider is implemented by remotes whose List omits completed
+// items, so an item vanishing from the remote means it was completed rather
+// than deleted.
+type CompletionHider interface {
+	HidesCompleted() bool
+}
+
+// CompletionChecker is implemented by CompletionHiders that can look up one
+// hidden item, telling an item completed remotely from one deleted there.
+// at is the completion time when the remote records one.
+type CompletionChecker interface {
+	CheckCompleted(ctx context.Context, remoteID string) (completed bool, at *time.Time, err error)
+}
+
+type ActionKind string
+
+const (
+	ActionCreateRemote  ActionKind = "create-remote"
+	ActionUpdateRemote  ActionKind = "update-remote"
+	ActionDeleteRemote  ActionKind = "delete-remote"
+	ActionCreateLocal   ActionKind = "create-local"
+	ActionUpdateLocal   ActionKind = "update-local"
+	ActionDeleteLocal   ActionKind = "delete-local"
+	ActionCompleteLocal ActionKind = "complete-local"
+	ActionForget        ActionKind = "forget"
+)
+
+// Action is one planned change to either side of a sync.
+type Action struct {
+	Kind     ActionKind
+	LocalUID string
+	RemoteID string
+	// Item is the content to write for create/update actions.
+	Item model.CalendarItem
+	// Remote is the remote view the local side is being updated from; its
+	// hash becomes the new remote baseline.
+	Remote *model.CalendarItem
+}
+
+func (a Action) String() string {
+	title := a.Item.Title
+	if title == "" {
+		title = a.LocalUID
+	}
+	return fmt.Sprintf("%s: %s", strings.ReplaceAll(string(a.Kind), "-", " "), title)
+}
+
+// Result summarises an applied sync.
+type Result struct {
+	LocalCreated  int
+	LocalUpdated  int
+	LocalDeleted  int
+	RemoteCreated int
+	RemoteUpdated int
+	RemoteDeleted int
+	Conflicts     int
+	Errors        []error
+}
+
+func (r *Result) Summary() string {
+	return fmt.Sprintf("local +%d ~%d -%d, remote +%d ~%d -%d, %d conflict(s), %d error(s)",
+		r.LocalCreated, r.LocalUpdated, r.LocalDeleted,
+		r.RemoteCreated, r.RemoteUpdated, r.RemoteDeleted,
+		r.Conflicts, len(r.Errors))
+}
+
+// Window bounds the items a time-windowed remote returns from List. Mapped
+// items outside the window are left alone instead of being treated as
+// deleted remotely.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (w *Window) Contains(item model.CalendarItem) bool {
+	anchor := item.StartTime
+	if anchor == nil {
+		anchor = item.DueDate
+	}
+	if anchor == nil {
+		return true
+	}
+	return !anchor.Before(w.Start) && !anchor.After(w.End)
+}
+
+// Engine performs a stateful two-way sync between a local collection and a
+// Remote.
+type Engine struct {
+	Remote Remote
+	State  *State
+	// Resolver decides items changed on both sides. When nil the side with
+	// the newer UpdatedAt wins, ties going to the local item.
+	Resolver *conflict.Resolver
+	Window   *Window
+	// Interval paces remote writes to stay inside provider quotas.
+	Interval time.Duration
+	Now      func() time.Time
+
+	conflicts int
+}
+
+func NewEngine(remote Remote, state *State) *Engine {
+	return &Engine{Remote: remote, State: state, Now: time.Now}
+}
+
+// Plan lists the remote and computes the actions needed to bring both sides
+// in line. Local items without a UID are assigned one.
+func (e *Engine) Plan(ctx context.Context, local *model.CalendarCollection) ([]Action, error) {
+	remoteItems, err := e.Remote.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list remote items: %w", err)
+	}
+	e.conflicts = 0
+
+	localIdx := make(map[string]int, len(local.Items))
+	for i := range local.Items {
+		if local.Items[i].UID == "" {
+			local.Items[i].UID = NewUID()
+		}
+		localIdx[local.Items[i].UID] = i
+	}
+	remoteByID := make(map[string]model.CalendarItem, len(remoteItems))
+	for _, r := range remoteItems {
+		remoteByID[r.UID] = r
+	}
+	hidesCompleted := false
+	if h, ok := e.Remote.(CompletionHider); ok {
+		hidesCompleted = h.HidesCompleted()
+	}
+	checker, _ := e.Remote.(CompletionChecker)
+	mappedRemote := make(map[string]bool, len(e.State.Mappings))
+	for _, m := range e.State.Mappings {
+		mappedRemote[m.RemoteID] = true
+	}
+
+	var actions []Action
+	seenRemote := make(map[string]bool)
+
+	uids := make([]string, 0, len(e.State.Mappings))
+	for uid := range e.State.Mappings {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		m := e.State.Mappings[uid]
+		li, hasLocal := localIdx[m.LocalUID]
+		r, hasRemote := remoteByID[m.RemoteID]
+		if hasRemote {
+			seenRemote[m.RemoteID] = true
+		}
+
+		switch {
+		case hasLocal && hasRemote:
+			l := local.Items[li]
+			localChanged := ItemHash(l) != m.LocalHash
+			remoteChanged := ItemHash(r) != m.RemoteHash
+			switch {
+			case localChanged && remoteChanged:
+				resolved, err := e.resolve(l, r, m)
+				if err != nil {
+					return nil, err
+				}
+				actions = append(actions, resolved...)
+			case localChanged:
+				actions = append(actions, Action{Kind: ActionUpdateRemote, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: l})
+			case remoteChanged:
+				actions = append(actions, Action{Kind: ActionUpdateLocal, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: pullEdits(l, r, m), Remote: &r})
+			}
+		case hasLocal:
+			l := local.Items[li]
+			if e.Window != nil && !e.Window.Contains(l) {
+				continue
+			}
+			localChanged := ItemHash(l) != m.LocalHash
+			switch {
+			case hidesCompleted && l.IsCompleted():
+				actions = append(actions, Action{Kind: ActionForget, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: l})
+			case localChanged:
+				// edited locally after the remote copy went away: keep the edits
+				actions = append(actions, Action{Kind: ActionCreateRemote, LocalUID: m.LocalUID, Item: l})
+			case hidesCompleted:
+				kind := ActionCompleteLocal
+				if checker != nil {
+					completed, at, err := checker.CheckCompleted(ctx, m.RemoteID)
+					if err != nil {
+						return nil, fmt.Errorf("check %q remotely: %w", l.Title, err)
+					}
+					if !completed {
+						kind = ActionDeleteLocal
+					} else if at != nil && l.CompletionDate == nil {
+						l.CompletionDate = at
+					}
+				}
+				actions = append(actions, Action{Kind: kind, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: l})
+			default:
+				actions = append(actions, Action{Kind: ActionDeleteLocal, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: l})
+			}
+		case hasRemote:
+			if ItemHash(r) != m.RemoteHash {
+				// edited remotely after the local copy was deleted: restore it
+				actions = append(actions, Action{Kind: ActionCreateLocal, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: r, Remote: &r})
+			} else {
+				actions = append(actions, Action{Kind: ActionDeleteRemote, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: r})
+			}
+		default:
+			actions = append(actions, Action{Kind: ActionForget, LocalUID: m.LocalUID, RemoteID: m.RemoteID})
+		}
+	}
+
+	for _, l := range local.Items {
+		if _, mapped := e.State.Mappings[l.UID]; mapped {
+			continue
+		}
+		// A local file previously pulled from this service already carries
+		// remote IDs as UIDs; link those instead of duplicating them.
+		if r, ok := remoteByID[l.UID]; ok && !seenRemote[l.UID] && !mappedRemote[l.UID] {
+			seenRemote[l.UID] = true
+			if ItemHash(l) == ItemHash(r) {
+				actions = append(actions, Action{Kind: ActionUpdateLocal, LocalUID: l.UID, RemoteID: r.UID, Item: l, Remote: &r})
+				continue
+			}
+			m := &Mapping{LocalUID: l.UID, RemoteID: r.UID}
+			resolved, err := e.resolve(l, r, m)
+			if err != nil {
+				return nil, err
+			}
+			if len(resolved) == 0 {
+				continue
+			}
+			actions = append(actions, resolved...)
+			continue
+		}
+		actions = append(actions, Action{Kind: ActionCreateRemote, LocalUID: l.UID, Item: l})
+	}
+
+	for _, r := range remoteItems {
+		if seenRemote[r.UID] || mappedRemote[r.UID] {
+			continue
+		}
+		r := r
+		actions = append(actions, Action{Kind: ActionCreateLocal, LocalUID: r.UID, RemoteID: r.UID, Item: r, Remote: &r})
+	}
+
+	return actions, nil
+}
+
+// Changes drops the actions that would leave both sides as they are, such
+// as linking a local item to an identical remote one, so previews list only
+// real changes.
+func Changes(local *model.CalendarCollection, actions []Action) []Action {
+	hashes := make(map[string]string, len(local.Items))
+	for _, item := range local.Items {
+		hashes[item.UID] = ItemHash(item)
+	}
+	var changes []Action
+	for _, a := range actions {
+		if a.Kind == ActionUpdateLocal && a.Remote != nil && ItemHash(a.Item) == hashes[a.LocalUID] {
+			continue
+		}
+		if a.Kind == ActionForget {
+			continue
+		}
+		changes = append(changes, a)
+	}
+	return changes
+}
+
+// pullEdits returns the local item with the fields changed remotely since
+// the last sync applied to it, so fields a lossy remote cannot hold, such as
+// tags on Google Tasks, are kept. Without base snapshots the remote item is
+// taken whole.
+func pullEdits(l, r model.CalendarItem, m *Mapping) model.CalendarItem {
+	if m.LocalBase == nil || m.RemoteBase == nil {
+		return r
+	}
+	merged := conflict.ThreeWayMerge(m.LocalBase, &l, m.RemoteBase, &r).Merged
+	merged.UID = l.UID
+	return merged
+}
+
+// resolve decides an item changed on both sides. When the mapping holds base
+// snapshots, fields changed on only one side are merged and only fields both
+// sides edited count as a conflict.
+func (e *Engine) resolve(l, r model.CalendarItem, m *Mapping) ([]Action, error) {
+	remoteNewer := l.UpdatedAt != nil && r.UpdatedAt != nil && r.UpdatedAt.After(*l.UpdatedAt)
+
+	var winner model.CalendarItem
+	if m.LocalBase != nil && m.RemoteBase != nil {
+		merge := conflict.ThreeWayMerge(m.LocalBase, &l, m.RemoteBase, &r)
+		if merge.HasConflicts() {
+			e.conflicts++
+		}
+		if e.Resolver == nil {
+			winner = merge.Resolved(remoteNewer)
+		} else {
+			result, err := e.Resolver.ResolveMerge(merge)
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				return nil, nil
+			}
+			winner = *result
+		}
+	} else if e.Resolver == nil {
+		e.conflicts++
+		winner = l
+		if remoteNewer {
+			winner = r
+		}
+	} else {
+		e.conflicts++
+		result, err := e.Resolver.Resolve(&l, &r)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return nil, nil
+		}
+		winner = *result
+	}
+	winner.UID = l.UID
+
+	var actions []Action
+	winnerHash := ItemHash(winner)
+	if winnerHash != ItemHash(r) {
+		actions = append(actions, Action{Kind: ActionUpdateRemote, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: winner})
+	}
+	if winnerHash != ItemHash(l) {
+		a := Action{Kind: ActionUpdateLocal, LocalUID: m.LocalUID, RemoteID: m.RemoteID, Item: winner}
+		if len(actions) == 0 {
+			a.Remote = &r
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// Apply executes planned actions against the remote and the local
+// collection, updating the state mappings as it goes. Failed remote calls are
+// recorded in the result and do not stop the run.
+func (e *Engine) Apply(ctx context.Context, local *model.CalendarCollection, actions []Action) *Result {
+	res := &Result{Conflicts: e.conflicts}
+	now := e.now()
+
+	var ticker *time.Ticker
+	if e.Interval > 0 {
+		ticker = time.NewTicker(e.Interval)
+		defer ticker.Stop()
+	}
+	pace := func() error {
+		if ticker == nil {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			return nil
+		}
+	}
+
+	localIdx := make(map[string]int, len(local.Items))
+	for i, item := range local.Items {
+		localIdx[item.UID] = i
+	}
+	deleted := make(map[string]bool)
+
+	for _, a := range actions {
+		switch a.Kind {
+		case ActionCreateRemote, ActionUpdateRemote, ActionDeleteRemote:
+			if err := pace(); err != nil {
+				res.Errors = append(res.Errors, err)
+				return e.finish(local, deleted, res, now)
+			}
+		}
+
+		switch a.Kind {
+		case ActionCreateRemote:
+			created, err := e.Remote.Create(ctx, a.Item)
+			if err != nil {
+				res.Errors = append(res.Errors, fmt.Errorf("create %q remotely: %w", a.Item.Title, err))
+				continue
+			}
+			e.State.Mappings[a.LocalUID] = &Mapping{
+				LocalUID:        a.LocalUID,
+				RemoteID:        created.UID,
+				LocalHash:       ItemHash(a.Item),
+				RemoteHash:      ItemHash(created),
+				LocalUpdatedAt:  a.Item.UpdatedAt,
+				RemoteUpdatedAt: created.UpdatedAt,
+				SyncedAt:        now,
+				LocalBase:       snapshot(a.Item),
+				RemoteBase:      snapshot(created),
+			}
+			res.RemoteCreated++
+		case ActionUpdateRemote:
+			updated, err := e.Remote.Update(ctx, a.RemoteID, a.Item)
+			if err != nil {
+				res.Errors = append(res.Errors, fmt.Errorf("update %q remotely: %w", a.Item.Title, err))
+				continue
+			}
+			m := e.mapping(a)
+			m.LocalHash = ItemHash(a.Item)
+			m.RemoteHash = ItemHash(updated)
+			m.LocalUpdatedAt = a.Item.UpdatedAt
+			m.RemoteUpdatedAt = updated.UpdatedAt
+			m.SyncedAt = now
+			m.LocalBase = snapshot(a.Item)
+			m.RemoteBase = snapshot(updated)
+			res.RemoteUpdated++
+		case ActionDeleteRemote:
+			if err := e.Remote.Delete(ctx, a.RemoteID); err != nil {
+				res.Errors = append(res.Errors, fmt.Errorf("delete %q remotely: %w", a.Item.Title, err))
+				continue
+			}
+			delete(e.State.Mappings, a.LocalUID)
+			res.RemoteDeleted++
+		case ActionCreateLocal:
+			item := a.Item
+			item.UID = a.LocalUID
+			local.Items = append(local.Items, item)
+			localIdx[item.UID] = len(local.Items) - 1
+			e.State.Mappings[a.LocalUID] = &Mapping{
+				LocalUID:        a.LocalUID,
+				RemoteID:        a.RemoteID,
+				LocalHash:       ItemHash(item),
+				RemoteHash:      ItemHash(*a.Remote),
+				LocalUpdatedAt:  item.UpdatedAt,
+				RemoteUpdatedAt: a.Remote.UpdatedAt,
+				SyncedAt:        now,
+				LocalBase:       snapshot(item),
+				RemoteBase:      snapshot(*a.Remote),
+			}
+			res.LocalCreated++
+		case ActionUpdateLocal:
+			idx, ok := localIdx[a.LocalUID]
+			if !ok {
+				continue
+			}
+			item := a.Item
+			item.UID = a.LocalUID
+			if item.CreatedAt == nil {
+				item.CreatedAt = local.Items[idx].CreatedAt
+			}
+			changed := ItemHash(item) != ItemHash(local.Items[idx])
+			local.Items[idx] = item
+			m := e.mapping(a)
+			m.LocalHash = ItemHash(item)
+			m.LocalUpdatedAt = item.UpdatedAt
+			m.LocalBase = snapshot(item)
+			if a.Remote != nil {
+				m.RemoteHash = ItemHash(*a.Remote)
+				m.RemoteUpdatedAt = a.Remote.UpdatedAt
+				m.RemoteBase = snapshot(*a.Remote)
+			}
+			m.SyncedAt = now
+			if changed {
+				res.LocalUpdated++
+			}
+		case ActionCompleteLocal:
+			if idx, ok := localIdx[a.LocalUID]; ok {
+				local.Items[idx].Status = model.StatusCompleted
+				if local.Items[idx].CompletionDate == nil {
+					completed := now
+					if a.Item.CompletionDate != nil {
+						completed = *a.Item.CompletionDate
+					}
+					local.Items[idx].CompletionDate = &completed
+				}
+				res.LocalUpdated++
+			}
+			delete(e.State.Mappings, a.LocalUID)
+		case ActionDeleteLocal:
+			deleted[a.LocalUID] = true
+			delete(e.State.Mappings, a.LocalUID)
+			res.LocalDeleted++
+		case ActionForget:
+			delete(e.State.Mappings, a.LocalUID)
+		}
+	}
+
+	return e.finish(local, deleted, res, now)
+}
+
+func (e *Engine) finish(local *model.CalendarCollection, deleted map[string]bool, res *Result, now time.Time) *Result {
+	if len(deleted) > 0 {
+		kept := local.Items[:0]
+		for _, item := range local.Items {
+			if !deleted[item.UID] {
+				kept = append(kept, item)
+			}
+		}
+		local.Items = kept
+	}
+	e.State.LastSync = now
+	return res
+}
+
+// mapping returns the state mapping for an action, creating one for items
+// linked during planning.
+func (e *Engine) mapping(a Action) *Mapping {
+	m, ok := e.State.Mappings[a.LocalUID]
+	if !ok {
+		m = &Mapping{LocalUID: a.LocalUID, RemoteID: a.RemoteID}
+		e.State.Mappings[a.LocalUID] = m
+	}
+	return m
+}
+
+func (e *Engine) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// snapshot copies an item for use as a merge base.
+func snapshot(item model.CalendarItem) *model.CalendarItem {
+	return &item
+}
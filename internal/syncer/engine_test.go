@@ -0,0 +1,461 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/conflict"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// fakeRemote is an in-memory Remote keyed by remote ID.
+type fakeRemote struct {
+	items          map[string]model.CalendarItem
+	next           int
+	hidesCompleted bool
+	failCreate     bool
+	// lossy drops the tags and priority of stored items, as Google Tasks
+	// and To Do do.
+	lossy bool
+}
+
+func newFakeRemote() *fakeRemote {
+	return &fakeRemote{items: make(map[string]model.CalendarItem)}
+}
+
+func (f *fakeRemote) List(ctx context.Context) ([]model.CalendarItem, error) {
+	var out []model.CalendarItem
+	for _, item := range f.items {
+		if f.hidesCompleted && item.IsCompleted() {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (f *fakeRemote) Create(ctx context.Context, item model.CalendarItem) (model.CalendarItem, error) {
+	if f.failCreate {
+		return model.CalendarItem{}, fmt.Errorf("boom")
+	}
+	f.next++
+	item.UID = fmt.Sprintf("r%d", f.next)
+	f.store(&item)
+	return item, nil
+}
+
+func (f *fakeRemote) Update(ctx context.Context, remoteID string, item model.CalendarItem) (model.CalendarItem, error) {
+	if _, ok := f.items[remoteID]; !ok {
+		return model.CalendarItem{}, fmt.Errorf("not found: %s", remoteID)
+	}
+	item.UID = remoteID
+	f.store(&item)
+	return item, nil
+}
+
+func (f *fakeRemote) store(item *model.CalendarItem) {
+	if f.lossy {
+		item.Tags = nil
+		item.Priority = model.PriorityNone
+	}
+	f.items[item.UID] = *item
+}
+
+func (f *fakeRemote) Delete(ctx context.Context, remoteID string) error {
+	delete(f.items, remoteID)
+	return nil
+}
+
+func (f *fakeRemote) HidesCompleted() bool { return f.hidesCompleted }
+
+func newTestEngine(t *testing.T, remote Remote) *Engine {
+	t.Helper()
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"), "local.ics", "fake")
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	return NewEngine(remote, state)
+}
+
+func syncOnce(t *testing.T, e *Engine, local *model.CalendarCollection) *Result {
+	t.Helper()
+	ctx := context.Background()
+	actions, err := e.Plan(ctx, local)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	return e.Apply(ctx, local, actions)
+}
+
+func task(uid, title string) model.CalendarItem {
+	return model.CalendarItem{UID: uid, Title: title, ItemType: model.ItemTypeTask, Status: model.StatusPending}
+}
+
+func TestInitialSyncCreatesBothSides(t *testing.T) {
+	remote := newFakeRemote()
+	remote.items["r100"] = model.CalendarItem{UID: "r100", Title: "Remote only", ItemType: model.ItemTypeTask}
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "Local only"), {Title: "No UID"}}}
+
+	res := syncOnce(t, e, local)
+	if res.RemoteCreated != 2 || res.LocalCreated != 1 {
+		t.Fatalf("unexpected result: %s", res.Summary())
+	}
+	if len(remote.items) != 3 || len(local.Items) != 3 {
+		t.Fatalf("expected 3 items on each side, got remote=%d local=%d", len(remote.items), len(local.Items))
+	}
+	if local.Items[1].UID == "" {
+		t.Error("expected UID to be assigned to local item")
+	}
+	if len(e.State.Mappings) != 3 {
+		t.Errorf("expected 3 mappings, got %d", len(e.State.Mappings))
+	}
+
+	// A second run with no edits is a no-op.
+	actions, err := e.Plan(context.Background(), local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("expected no actions on idempotent run, got %v", actions)
+	}
+}
+
+func TestSyncPropagatesEdits(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One"), task("b", "Two")}}
+	syncOnce(t, e, local)
+
+	local.Items[0].Title = "One (edited locally)"
+	rb := e.State.Mappings["b"].RemoteID
+	edited := remote.items[rb]
+	edited.Title = "Two (edited remotely)"
+	remote.items[rb] = edited
+
+	res := syncOnce(t, e, local)
+	if res.RemoteUpdated != 1 || res.LocalUpdated != 1 {
+		t.Fatalf("unexpected result: %s", res.Summary())
+	}
+	if got := remote.items[e.State.Mappings["a"].RemoteID].Title; got != "One (edited locally)" {
+		t.Errorf("remote title = %q", got)
+	}
+	if local.Items[1].Title != "Two (edited remotely)" {
+		t.Errorf("local title = %q", local.Items[1].Title)
+	}
+	if local.Items[1].UID != "b" {
+		t.Errorf("local UID should be preserved, got %q", local.Items[1].UID)
+	}
+}
+
+func TestSyncRemoteEditKeepsFieldsLossyRemoteDrops(t *testing.T) {
+	remote := newFakeRemote()
+	remote.lossy = true
+	e := newTestEngine(t, remote)
+	item := task("a", "One")
+	item.Tags = []string{"home"}
+	item.Priority = model.PriorityHigh
+	local := &model.CalendarCollection{Items: []model.CalendarItem{item}}
+	syncOnce(t, e, local)
+
+	rid := e.State.Mappings["a"].RemoteID
+	edited := remote.items[rid]
+	edited.Title = "One (renamed remotely)"
+	remote.items[rid] = edited
+
+	res := syncOnce(t, e, local)
+	if res.LocalUpdated != 1 || res.RemoteUpdated != 0 {
+		t.Fatalf("unexpected result: %s", res.Summary())
+	}
+	got := local.Items[0]
+	if got.Title != "One (renamed remotely)" || len(got.Tags) != 1 || got.Priority != model.PriorityHigh {
+		t.Errorf("local = title %q tags %v priority %d", got.Title, got.Tags, got.Priority)
+	}
+
+	actions, err := e.Plan(context.Background(), local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("expected no actions after the merge, got %v", actions)
+	}
+}
+
+func TestSyncPropagatesDeletions(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One"), task("b", "Two")}}
+	syncOnce(t, e, local)
+
+	// delete a locally, b remotely
+	local.Items = local.Items[1:]
+	delete(remote.items, e.State.Mappings["b"].RemoteID)
+
+	res := syncOnce(t, e, local)
+	if res.RemoteDeleted != 1 || res.LocalDeleted != 1 {
+		t.Fatalf("unexpected result: %s", res.Summary())
+	}
+	if len(remote.items) != 0 || len(local.Items) != 0 {
+		t.Errorf("expected both sides empty, got remote=%d local=%d", len(remote.items), len(local.Items))
+	}
+	if len(e.State.Mappings) != 0 {
+		t.Errorf("expected mappings to be dropped, got %d", len(e.State.Mappings))
+	}
+}
+
+func TestSyncRemoteCompletionHidden(t *testing.T) {
+	remote := newFakeRemote()
+	remote.hidesCompleted = true
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One")}}
+	syncOnce(t, e, local)
+
+	rid := e.State.Mappings["a"].RemoteID
+	done := remote.items[rid]
+	done.Status = model.StatusCompleted
+	remote.items[rid] = done
+
+	res := syncOnce(t, e, local)
+	if res.LocalDeleted != 0 || len(local.Items) != 1 {
+		t.Fatalf("completed remote task should not be deleted locally: %s", res.Summary())
+	}
+	if local.Items[0].Status != model.StatusCompleted || local.Items[0].CompletionDate == nil {
+		t.Errorf("expected local task completed, got status %q", local.Items[0].Status)
+	}
+}
+
+// checkingRemote can tell completed items from deleted ones.
+type checkingRemote struct{ *fakeRemote }
+
+func (c checkingRemote) CheckCompleted(ctx context.Context, remoteID string) (bool, *time.Time, error) {
+	item, ok := c.items[remoteID]
+	if !ok || !item.IsCompleted() {
+		return false, nil, nil
+	}
+	return true, item.CompletionDate, nil
+}
+
+func TestSyncRemoteDeletionTellsFromCompletion(t *testing.T) {
+	remote := newFakeRemote()
+	remote.hidesCompleted = true
+	e := newTestEngine(t, checkingRemote{remote})
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "Done"), task("b", "Gone")}}
+	syncOnce(t, e, local)
+
+	doneAt := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	ra, rb := e.State.Mappings["a"].RemoteID, e.State.Mappings["b"].RemoteID
+	done := remote.items[ra]
+	done.Status = model.StatusCompleted
+	done.CompletionDate = &doneAt
+	remote.items[ra] = done
+	delete(remote.items, rb)
+
+	res := syncOnce(t, e, local)
+	if res.LocalDeleted != 1 || len(local.Items) != 1 || local.Items[0].UID != "a" {
+		t.Fatalf("expected the deleted task removed locally: %s, %+v", res.Summary(), local.Items)
+	}
+	if local.Items[0].Status != model.StatusCompleted || !local.Items[0].CompletionDate.Equal(doneAt) {
+		t.Errorf("expected the completed task completed at %v, got %+v", doneAt, local.Items[0])
+	}
+}
+
+func TestChangesDropsNoOps(t *testing.T) {
+	remote := newFakeRemote()
+	remote.items["r1"] = task("r1", "Pulled")
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("r1", "Pulled"), task("a", "New")}}
+
+	actions, err := e.Plan(context.Background(), local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := Changes(local, actions)
+	if len(actions) != 2 || len(changes) != 1 || changes[0].Kind != ActionCreateRemote {
+		t.Errorf("actions %v, changes %v", actions, changes)
+	}
+}
+
+func TestSyncConflictUsesResolver(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One")}}
+	syncOnce(t, e, local)
+
+	resolver, err := conflict.NewResolver(conflict.StrategyPreferTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Resolver = resolver
+
+	local.Items[0].Title = "Local wins?"
+	rid := e.State.Mappings["a"].RemoteID
+	r := remote.items[rid]
+	r.Title = "Remote wins"
+	remote.items[rid] = r
+
+	res := syncOnce(t, e, local)
+	if res.Conflicts != 1 {
+		t.Errorf("expected 1 conflict, got %d", res.Conflicts)
+	}
+	if local.Items[0].Title != "Remote wins" || remote.items[rid].Title != "Remote wins" {
+		t.Errorf("expected remote version on both sides, got local=%q remote=%q", local.Items[0].Title, remote.items[rid].Title)
+	}
+}
+
+func TestSyncConflictNewestWinsWithoutResolver(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One")}}
+	syncOnce(t, e, local)
+
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	local.Items[0].Title = "Local edit"
+	local.Items[0].UpdatedAt = &newer
+	rid := e.State.Mappings["a"].RemoteID
+	r := remote.items[rid]
+	r.Title = "Remote edit"
+	r.UpdatedAt = &older
+	remote.items[rid] = r
+
+	syncOnce(t, e, local)
+	if remote.items[rid].Title != "Local edit" {
+		t.Errorf("expected newer local edit on remote, got %q", remote.items[rid].Title)
+	}
+}
+
+func TestSyncLinksPreviouslyPulledItems(t *testing.T) {
+	remote := newFakeRemote()
+	remote.items["r1"] = task("r1", "Pulled")
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("r1", "Pulled")}}
+
+	res := syncOnce(t, e, local)
+	if res.RemoteCreated != 0 || res.LocalCreated != 0 {
+		t.Fatalf("expected items to be linked, not duplicated: %s", res.Summary())
+	}
+	if m := e.State.Mappings["r1"]; m == nil || m.RemoteID != "r1" {
+		t.Errorf("expected mapping r1 -> r1, got %+v", m)
+	}
+}
+
+func TestSyncWindowSkipsOutOfRangeItems(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{{UID: "a", Title: "Event", ItemType: model.ItemTypeEvent, StartTime: &start}}}
+	syncOnce(t, e, local)
+
+	// The remote now only returns a window that excludes the event.
+	delete(remote.items, e.State.Mappings["a"].RemoteID)
+	e.Window = &Window{Start: start.AddDate(0, 1, 0), End: start.AddDate(0, 2, 0)}
+
+	res := syncOnce(t, e, local)
+	if res.LocalDeleted != 0 || len(local.Items) != 1 {
+		t.Errorf("item outside the window should be left alone: %s", res.Summary())
+	}
+}
+
+func TestApplyCollectsRemoteErrors(t *testing.T) {
+	remote := newFakeRemote()
+	remote.failCreate = true
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One"), task("b", "Two")}}
+
+	res := syncOnce(t, e, local)
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	if len(e.State.Mappings) != 0 {
+		t.Errorf("failed creates should not be mapped, got %d", len(e.State.Mappings))
+	}
+}
+
+func TestStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync", "state.json")
+	st, err := LoadState(path, "tasks.ics", "todoist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Container = "proj-1"
+	st.Mappings["a"] = &Mapping{LocalUID: "a", RemoteID: "r1", LocalHash: "h1", RemoteHash: "h2"}
+	if err := st.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadState(path, "tasks.ics", "todoist")
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if loaded.Container != "proj-1" || loaded.Mappings["a"].RemoteID != "r1" {
+		t.Errorf("state did not round-trip: %+v", loaded)
+	}
+	if loaded.ByRemoteID("r1") == nil {
+		t.Error("ByRemoteID did not find mapping")
+	}
+}
+
+func TestItemHashIgnoresIdentity(t *testing.T) {
+	a := task("a", "Same")
+	b := task("b", "Same")
+	b.Tags = nil
+	now := time.Now()
+	b.UpdatedAt = &now
+	if ItemHash(a) != ItemHash(b) {
+		t.Error("hash should ignore UID and timestamps")
+	}
+	b.Title = "Different"
+	if ItemHash(a) == ItemHash(b) {
+		t.Error("hash should change with content")
+	}
+}
+
+func TestSyncThreeWayMergesDisjointEdits(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One")}}
+	syncOnce(t, e, local)
+
+	resolver, err := conflict.NewResolver(conflict.StrategyFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Resolver = resolver
+
+	local.Items[0].Title = "One (renamed locally)"
+	rid := e.State.Mappings["a"].RemoteID
+	r := remote.items[rid]
+	r.Priority = model.PriorityHigh
+	remote.items[rid] = r
+
+	res := syncOnce(t, e, local)
+	if len(res.Errors) != 0 || res.Conflicts != 0 {
+		t.Fatalf("disjoint edits should merge without conflict: %s", res.Summary())
+	}
+	for name, got := range map[string]model.CalendarItem{"local": local.Items[0], "remote": remote.items[rid]} {
+		if got.Title != "One (renamed locally)" || got.Priority != model.PriorityHigh {
+			t.Errorf("%s: expected merged item, got title=%q priority=%d", name, got.Title, got.Priority)
+		}
+	}
+}
+
+func TestSyncThreeWayBaseSurvivesSave(t *testing.T) {
+	remote := newFakeRemote()
+	e := newTestEngine(t, remote)
+	local := &model.CalendarCollection{Items: []model.CalendarItem{task("a", "One")}}
+	syncOnce(t, e, local)
+	if err := e.State.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(e.State.Path(), "local.ics", "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := loaded.Mappings["a"]
+	if m.LocalBase == nil || m.RemoteBase == nil || m.LocalBase.Title != "One" {
+		t.Errorf("expected base snapshots to persist, got %+v", m)
+	}
+}
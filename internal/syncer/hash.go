@@ -0,0 +1,124 @@
+package syncer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// hashable is the subset of CalendarItem that represents user-visible
+// content. Identifiers and bookkeeping timestamps are excluded so the same
+// item hashes identically on both sides of a sync.
+type hashable struct {
+	Title          string
+	Description    string
+	StartTime      string
+	EndTime        string
+	DueDate        string
+	Priority       model.Priority
+	Status         model.Status
+	Location       string
+	Tags           []string
+	Recurrence     *hashRecurrence
+	Subtasks       []hashSubtask
+	CompletionDate string
+	IsAllDay       bool
+	// omitted when empty so items without attendees keep their old hashes
+	Organizer *model.Attendee  `json:",omitempty"`
+	Attendees []model.Attendee `json:",omitempty"`
+	// each override as its recurrence ID and the hash of its item
+	Overrides []string `json:",omitempty"`
+}
+
+// hashSubtask is model.Subtask without its UID, which like an item's UID is
+// identity rather than content.
+type hashSubtask struct {
+	Title     string
+	Status    model.Status
+	Priority  model.Priority
+	SortOrder int
+}
+
+// hashRecurrence is model.Recurrence without its overrides, which are
+// hashed apart so series without any keep their old hashes.
+type hashRecurrence struct {
+	Freq       model.FreqType
+	Interval   int
+	Count      *int
+	Until      *time.Time
+	ByDay      []model.Weekday
+	ByMonth    []int
+	ByMonthDay []int
+	BySetPos   []int
+	ExDates    []time.Time
+	RDates     []time.Time
+	// the rarer rule parts are omitted when empty, keeping old hashes
+	ByWeekNo  []int         `json:",omitempty"`
+	ByYearDay []int         `json:",omitempty"`
+	ByHour    []int         `json:",omitempty"`
+	ByMinute  []int         `json:",omitempty"`
+	BySecond  []int         `json:",omitempty"`
+	WeekStart model.Weekday `json:",omitempty"`
+}
+
+// ItemHash returns a stable content hash for an item.
+func ItemHash(item model.CalendarItem) string {
+	tags := append([]string(nil), item.Tags...)
+	sort.Strings(tags)
+	h := hashable{
+		Title:          item.Title,
+		Description:    item.Description,
+		StartTime:      hashTime(item.StartTime),
+		EndTime:        hashTime(item.EndTime),
+		DueDate:        hashTime(item.DueDate),
+		Priority:       item.Priority,
+		Status:         item.Status,
+		Location:       item.Location,
+		Tags:           tags,
+		CompletionDate: hashTime(item.CompletionDate),
+		IsAllDay:       item.IsAllDay,
+		Organizer:      item.Organizer,
+	}
+	for _, st := range item.Subtasks {
+		h.Subtasks = append(h.Subtasks, hashSubtask{Title: st.Title, Status: st.Status, Priority: st.Priority, SortOrder: st.SortOrder})
+	}
+	if rec := item.Recurrence; rec != nil {
+		h.Recurrence = &hashRecurrence{
+			Freq: rec.Freq, Interval: rec.Interval, Count: rec.Count, Until: rec.Until,
+			ByDay: rec.ByDay, ByMonth: rec.ByMonth, ByMonthDay: rec.ByMonthDay, BySetPos: rec.BySetPos,
+			ExDates: rec.ExDates, RDates: rec.RDates,
+			ByWeekNo: rec.ByWeekNo, ByYearDay: rec.ByYearDay, ByHour: rec.ByHour, ByMinute: rec.ByMinute, BySecond: rec.BySecond,
+			WeekStart: rec.WeekStart,
+		}
+		for _, ov := range rec.Overrides {
+			h.Overrides = append(h.Overrides, hashTime(&ov.RecurrenceID)+" "+ItemHash(ov.Item))
+		}
+		sort.Strings(h.Overrides)
+	}
+	if len(item.Attendees) > 0 {
+		h.Attendees = append([]model.Attendee(nil), item.Attendees...)
+		sort.Slice(h.Attendees, func(i, j int) bool { return h.Attendees[i].Email < h.Attendees[j].Email })
+	}
+	data, _ := json.Marshal(h)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hashTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// NewUID generates a UID for local items that do not carry one.
+func NewUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b) + "@salja"
+}
@@ -0,0 +1,278 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gongahkia/salja/internal/config"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// JournalHeader is the first line of a push journal and identifies what was
+// being pushed where.
+type JournalHeader struct {
+	Service string `json:"service"`
+	Source  string `json:"source"`
+	// Target is the calendar, project or database named for the push, if
+	// any, so a resumed push routes items the same way.
+	Target string `json:"target,omitempty"`
+	// Container is the remote calendar, project or database ID of entries
+	// that do not name their own, as written by earlier versions.
+	Container string    `json:"container,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Journal entry actions.
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionUndone  = "undone"
+)
+
+// JournalEntry records one item written to the remote. Updates keep the
+// remote item as it was before the push so the change can be undone.
+type JournalEntry struct {
+	UID       string              `json:"uid"`
+	RemoteID  string              `json:"remote_id"`
+	Container string              `json:"container,omitempty"`
+	Action    string              `json:"action"`
+	At        time.Time           `json:"at"`
+	Before    *model.CalendarItem `json:"before,omitempty"`
+}
+
+// Journal is an append-only JSON Lines log of a push, and the manifest that
+// `sync undo` reverts. Each completed item is flushed to disk as it happens,
+// so an interrupted push can be resumed by skipping the UIDs already
+// recorded. Its file name without extension is the run ID. Record and Done
+// are safe for concurrent use.
+type Journal struct {
+	Header  JournalHeader
+	Entries []JournalEntry
+
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	done    map[string]bool
+	partial bool
+}
+
+// JournalDir is where push journals are kept under the salja data directory.
+func JournalDir() string {
+	return filepath.Join(config.DataDir(), "journals")
+}
+
+// CreateJournal starts a new journal in JournalDir.
+func CreateJournal(header JournalHeader) (*Journal, error) {
+	if err := os.MkdirAll(JournalDir(), 0755); err != nil {
+		return nil, fmt.Errorf("create journal dir: %w", err)
+	}
+	if header.StartedAt.IsZero() {
+		header.StartedAt = time.Now()
+	}
+	base := fmt.Sprintf("%s-%s", header.StartedAt.UTC().Format("20060102T150405Z"), header.Service)
+	var path string
+	var f *os.File
+	for n := 1; ; n++ {
+		name := base + ".jsonl"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.jsonl", base, n)
+		}
+		path = filepath.Join(JournalDir(), name)
+		var err error
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("create journal: %w", err)
+		}
+	}
+	j := &Journal{Header: header, path: path, f: f, done: make(map[string]bool)}
+	if err := j.writeLine(header); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// ReadJournal loads a journal without opening it for writing. A truncated
+// final line, left by a crash mid-write, is ignored.
+func ReadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+
+	j := &Journal{path: path, done: make(map[string]bool)}
+	lines := bytes.Split(data, []byte("\n"))
+	if err := json.Unmarshal(lines[0], &j.Header); err != nil || j.Header.Service == "" {
+		return nil, fmt.Errorf("journal %s has an invalid header", path)
+	}
+	for _, line := range lines[1:] {
+		var e JournalEntry
+		if err := json.Unmarshal(line, &e); err != nil || e.UID == "" {
+			continue
+		}
+		j.Entries = append(j.Entries, e)
+		j.done[e.UID] = e.Action != ActionUndone
+	}
+	j.partial = len(data) > 0 && data[len(data)-1] != '\n'
+	return j, nil
+}
+
+// OpenJournal loads an existing journal and reopens it for appending.
+func OpenJournal(path string) (*Journal, error) {
+	j, err := ReadJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	j.f, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal for append: %w", err)
+	}
+	if j.partial {
+		// terminate the partial line so the next entry starts cleanly
+		if _, err := j.f.Write([]byte("\n")); err != nil {
+			_ = j.f.Close()
+			return nil, fmt.Errorf("write journal: %w", err)
+		}
+		j.partial = false
+	}
+	return j, nil
+}
+
+// FindJournal resolves a run ID, or a path to a journal file, to a path.
+func FindJournal(runID string) (string, error) {
+	if _, err := os.Stat(runID); err == nil {
+		return runID, nil
+	}
+	path := filepath.Join(JournalDir(), strings.TrimSuffix(runID, ".jsonl")+".jsonl")
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no sync run %q in %s", runID, JournalDir())
+	}
+	return path, nil
+}
+
+// ListJournals reads every journal in JournalDir, newest first. Unreadable
+// files are skipped.
+func ListJournals() ([]*Journal, error) {
+	paths, err := filepath.Glob(filepath.Join(JournalDir(), "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var journals []*Journal
+	for _, p := range paths {
+		j, err := ReadJournal(p)
+		if err != nil {
+			continue
+		}
+		journals = append(journals, j)
+	}
+	sort.SliceStable(journals, func(a, b int) bool {
+		return journals[a].Header.StartedAt.After(journals[b].Header.StartedAt)
+	})
+	return journals, nil
+}
+
+// ID returns the run ID, the journal file name without its extension.
+func (j *Journal) ID() string {
+	return strings.TrimSuffix(filepath.Base(j.path), ".jsonl")
+}
+
+// Pending returns the created and updated entries not yet undone, most
+// recent first, which is the order to revert them in.
+func (j *Journal) Pending() []JournalEntry {
+	var out []JournalEntry
+	seen := make(map[string]bool)
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		e := j.Entries[i]
+		if seen[e.UID] {
+			continue
+		}
+		seen[e.UID] = true
+		if e.Action != ActionUndone {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Counts tallies the entries by action.
+func (j *Journal) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, e := range j.Entries {
+		counts[e.Action]++
+	}
+	return counts
+}
+
+// ContainerOf returns the remote container an entry was written to.
+func (j *Journal) ContainerOf(e JournalEntry) string {
+	if e.Container != "" {
+		return e.Container
+	}
+	return j.Header.Container
+}
+
+// Path returns the journal file location.
+func (j *Journal) Path() string { return j.path }
+
+// Done reports whether uid was written by this push and not undone since.
+func (j *Journal) Done(uid string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done[uid]
+}
+
+// Record appends an entry and syncs it to disk. before is the remote item as
+// it was prior to an update, or nil.
+func (j *Journal) Record(uid, remoteID, action string, before *model.CalendarItem) error {
+	return j.RecordIn("", uid, remoteID, action, before)
+}
+
+// RecordIn is Record for an item in a specific remote container.
+func (j *Journal) RecordIn(container, uid, remoteID, action string, before *model.CalendarItem) error {
+	e := JournalEntry{UID: uid, RemoteID: remoteID, Container: container, Action: action, At: time.Now(), Before: before}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.writeLine(e); err != nil {
+		return err
+	}
+	j.Entries = append(j.Entries, e)
+	j.done[uid] = action != ActionUndone
+	return nil
+}
+
+func (j *Journal) writeLine(v interface{}) error {
+	if j.f == nil {
+		return errors.New("journal is closed")
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	return j.f.Sync()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
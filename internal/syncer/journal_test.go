@@ -0,0 +1,150 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func TestJournalResume(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	j, err := CreateJournal(JournalHeader{Service: "google", Source: "/tmp/cal.ics", Container: "primary", StartedAt: started})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(j.Path(), JournalDir()) || filepath.Base(j.Path()) != "20260301T120000Z-google.jsonl" {
+		t.Errorf("unexpected journal path %s", j.Path())
+	}
+	if err := j.Record("uid-1", "remote-1", ActionCreated, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record("uid-2", "remote-2", ActionUpdated, &model.CalendarItem{UID: "remote-2", Title: "Old title"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+
+	// simulate a crash part-way through writing the next entry
+	f, err := os.OpenFile(j.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"uid":"uid-3","remo`)
+	_ = f.Close()
+
+	resumed, err := OpenJournal(j.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Header.Service != "google" || resumed.Header.Container != "primary" {
+		t.Errorf("header: %+v", resumed.Header)
+	}
+	if !resumed.Done("uid-1") || !resumed.Done("uid-2") || resumed.Done("uid-3") {
+		t.Errorf("unexpected done set after resume: %+v", resumed.Entries)
+	}
+	if err := resumed.Record("uid-3", "remote-3", ActionCreated, nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = resumed.Close()
+
+	again, err := OpenJournal(j.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = again.Close() }()
+	if len(again.Entries) != 3 || again.Entries[2].RemoteID != "remote-3" {
+		t.Errorf("entries after partial line: %+v", again.Entries)
+	}
+}
+
+func TestCreateJournalAvoidsCollisions(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	header := JournalHeader{Service: "todoist", StartedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	a, err := CreateJournal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = a.Close() }()
+	b, err := CreateJournal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	if a.Path() == b.Path() {
+		t.Error("two journals started in the same second must not share a file")
+	}
+}
+
+func TestJournalUndoBookkeeping(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	j, err := CreateJournal(JournalHeader{Service: "todoist", StartedAt: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = j.Close() }()
+	before := &model.CalendarItem{UID: "r2", Title: "Original"}
+	_ = j.Record("a", "r1", ActionCreated, nil)
+	_ = j.Record("b", "r2", ActionUpdated, before)
+	_ = j.Record("c", "r3", ActionCreated, nil)
+
+	pending := j.Pending()
+	if len(pending) != 3 || pending[0].UID != "c" || pending[2].UID != "a" {
+		t.Fatalf("pending should be newest first: %+v", pending)
+	}
+	if pending[1].Before == nil || pending[1].Before.Title != "Original" {
+		t.Errorf("update should keep the prior remote state: %+v", pending[1])
+	}
+
+	_ = j.Record("c", "r3", ActionUndone, nil)
+	if j.Done("c") {
+		t.Error("undone item should not count as done")
+	}
+	if got := len(j.Pending()); got != 2 {
+		t.Errorf("expected 2 pending after partial undo, got %d", got)
+	}
+	counts := j.Counts()
+	if counts[ActionCreated] != 2 || counts[ActionUpdated] != 1 || counts[ActionUndone] != 1 {
+		t.Errorf("counts: %v", counts)
+	}
+
+	path, err := FindJournal(j.ID())
+	if err != nil || path != j.Path() {
+		t.Errorf("FindJournal(%q) = %q, %v", j.ID(), path, err)
+	}
+	list, err := ListJournals()
+	if err != nil || len(list) != 1 || list[0].ID() != j.ID() {
+		t.Errorf("ListJournals: %v, %v", list, err)
+	}
+}
+
+func TestJournalEntryContainers(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	j, err := CreateJournal(JournalHeader{Service: "google", Container: "legacy-cal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = j.RecordIn("work-cal", "a", "r1", ActionCreated, nil)
+	_ = j.Record("b", "r2", ActionCreated, nil)
+	_ = j.Close()
+
+	reopened, err := OpenJournal(j.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reopened.Close() }()
+	got := map[string]string{}
+	for _, e := range reopened.Pending() {
+		got[e.UID] = reopened.ContainerOf(e)
+	}
+	if got["a"] != "work-cal" || got["b"] != "legacy-cal" {
+		t.Errorf("containers after reopen: %v", got)
+	}
+}
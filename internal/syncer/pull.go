@@ -0,0 +1,58 @@
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// PullCounts summarises changes applied by ApplyChanges.
+type PullCounts struct {
+	Added   int
+	Updated int
+	Removed int
+}
+
+func (c PullCounts) String() string {
+	return fmt.Sprintf("%d added, %d updated, %d removed", c.Added, c.Updated, c.Removed)
+}
+
+// ApplyChanges folds a remote change feed into local, matching items by UID.
+// changed items replace their local copy in place or are appended; items
+// listed in removed are dropped. When present is non-nil, any local item
+// whose UID is not in it is dropped as well.
+func ApplyChanges(local *model.CalendarCollection, changed []model.CalendarItem, removed []string, present map[string]bool) PullCounts {
+	var counts PullCounts
+
+	index := make(map[string]int, len(local.Items))
+	for i, item := range local.Items {
+		index[item.UID] = i
+	}
+	for _, item := range changed {
+		if i, ok := index[item.UID]; ok {
+			if ItemHash(local.Items[i]) != ItemHash(item) {
+				counts.Updated++
+			}
+			local.Items[i] = item
+			continue
+		}
+		index[item.UID] = len(local.Items)
+		local.Items = append(local.Items, item)
+		counts.Added++
+	}
+
+	drop := make(map[string]bool, len(removed))
+	for _, uid := range removed {
+		drop[uid] = true
+	}
+	kept := local.Items[:0]
+	for _, item := range local.Items {
+		if drop[item.UID] || (present != nil && !present[item.UID]) {
+			counts.Removed++
+			continue
+		}
+		kept = append(kept, item)
+	}
+	local.Items = kept
+	return counts
+}
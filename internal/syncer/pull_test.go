@@ -0,0 +1,49 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/gongahkia/salja/internal/model"
+)
+
+func TestApplyChanges(t *testing.T) {
+	local := &model.CalendarCollection{Items: []model.CalendarItem{
+		{UID: "a", Title: "Alpha"},
+		{UID: "b", Title: "Beta"},
+		{UID: "c", Title: "Gamma"},
+	}}
+	changed := []model.CalendarItem{
+		{UID: "b", Title: "Beta (edited)"},
+		{UID: "a", Title: "Alpha"},
+		{UID: "d", Title: "Delta"},
+	}
+
+	counts := ApplyChanges(local, changed, []string{"c", "missing"}, nil)
+	if counts != (PullCounts{Added: 1, Updated: 1, Removed: 1}) {
+		t.Errorf("counts: %+v", counts)
+	}
+	var titles []string
+	for _, item := range local.Items {
+		titles = append(titles, item.Title)
+	}
+	want := []string{"Alpha", "Beta (edited)", "Delta"}
+	if len(titles) != len(want) {
+		t.Fatalf("got %v, want %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Errorf("item %d: got %q, want %q", i, titles[i], want[i])
+		}
+	}
+}
+
+func TestApplyChangesPresentSet(t *testing.T) {
+	local := &model.CalendarCollection{Items: []model.CalendarItem{
+		{UID: "a", Title: "Alpha"},
+		{UID: "b", Title: "Beta"},
+	}}
+	counts := ApplyChanges(local, nil, nil, map[string]bool{"a": true})
+	if counts.Removed != 1 || len(local.Items) != 1 || local.Items[0].UID != "a" {
+		t.Errorf("counts=%+v items=%+v", counts, local.Items)
+	}
+}
@@ -0,0 +1,121 @@
+package syncer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gongahkia/salja/internal/config"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+const stateVersion = 1
+
+// Mapping links a local item UID to its remote counterpart and records the
+// content last seen on each side.
+type Mapping struct {
+	LocalUID        string     `json:"local_uid"`
+	RemoteID        string     `json:"remote_id"`
+	LocalHash       string     `json:"local_hash"`
+	RemoteHash      string     `json:"remote_hash"`
+	LocalUpdatedAt  *time.Time `json:"local_updated_at,omitempty"`
+	RemoteUpdatedAt *time.Time `json:"remote_updated_at,omitempty"`
+	SyncedAt        time.Time  `json:"synced_at"`
+	// LocalBase and RemoteBase snapshot each side as of the last sync. They
+	// are the common ancestor for three-way merges when both sides change.
+	LocalBase  *model.CalendarItem `json:"local_base,omitempty"`
+	RemoteBase *model.CalendarItem `json:"remote_base,omitempty"`
+}
+
+// State is the persistent sync database for one local file and one service.
+type State struct {
+	Version   int                 `json:"version"`
+	Service   string              `json:"service"`
+	LocalPath string              `json:"local_path"`
+	Container string              `json:"container,omitempty"` // remote calendar, project or database ID
+	LastSync  time.Time           `json:"last_sync"`
+	Mappings  map[string]*Mapping `json:"mappings"`
+	// Cursor is the provider sync token, delta link or timestamp for
+	// incremental pulls. CursorScope records the window it was issued for.
+	Cursor      string `json:"cursor,omitempty"`
+	CursorScope string `json:"cursor_scope,omitempty"`
+
+	path string
+}
+
+// StatePath returns the state file location for a local file and service
+// under the salja data directory.
+func StatePath(localPath, service string) (string, error) {
+	abs, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", localPath, err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := fmt.Sprintf("%s-%s.json", service, hex.EncodeToString(sum[:])[:12])
+	return filepath.Join(config.DataDir(), "sync", name), nil
+}
+
+// LoadState reads the state file at path, returning an empty state when the
+// file does not exist yet.
+func LoadState(path, localPath, service string) (*State, error) {
+	st := &State{
+		Version:   stateVersion,
+		Service:   service,
+		LocalPath: localPath,
+		Mappings:  make(map[string]*Mapping),
+		path:      path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sync state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", path, err)
+	}
+	if st.Version > stateVersion {
+		return nil, fmt.Errorf("sync state %s has version %d; this salja understands up to %d", path, st.Version, stateVersion)
+	}
+	if st.Mappings == nil {
+		st.Mappings = make(map[string]*Mapping)
+	}
+	st.path = path
+	return st, nil
+}
+
+// Path returns the file the state is persisted to.
+func (s *State) Path() string { return s.path }
+
+// Save atomically writes the state back to disk.
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create sync state dir: %w", err)
+	}
+	s.Version = stateVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// ByRemoteID returns the mapping for a remote ID, if any.
+func (s *State) ByRemoteID(remoteID string) *Mapping {
+	for _, m := range s.Mappings {
+		if m.RemoteID == remoteID {
+			return m
+		}
+	}
+	return nil
+}
@@ -26,7 +26,7 @@ type authService struct {
 	status string // "authenticated", "expired", "not authenticated"
 }
 
-var serviceNames = []string{"google", "microsoft", "todoist", "ticktick", "notion"}
+var serviceNames = []string{"google", "microsoft", "todoist", "ticktick", "notion", "caldav"}
 
 type authStatusMsg struct {
 	services []authService
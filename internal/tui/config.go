@@ -49,6 +49,7 @@ func buildSettings(cfg *config.Config) []configSetting {
 		{"data_loss_mode", cfg.DataLossMode, []string{"warn", "error", "silent"}},
 		{"streaming_threshold_mb", fmt.Sprintf("%d", cfg.StreamingThresholdMB), nil},
 		{"api_timeout_seconds", fmt.Sprintf("%d", cfg.APITimeoutSeconds), nil},
+		{"sync_timeout_minutes", syncTimeoutLabel(cfg.SyncTimeoutMinutes), nil},
 		{"google client_id", maskSecret(cfg.API.Google.ClientID), nil},
 		{"microsoft client_id", maskSecret(cfg.API.Microsoft.ClientID), nil},
 		{"todoist client_id", maskSecret(cfg.API.Todoist.ClientID), nil},
@@ -57,6 +58,13 @@ func buildSettings(cfg *config.Config) []configSetting {
 	}
 }
 
+func syncTimeoutLabel(minutes int) string {
+	if minutes == 0 {
+		return "auto"
+	}
+	return fmt.Sprintf("%d", minutes)
+}
+
 func maskSecret(s string) string {
 	if s == "" {
 		return "(not set)"
@@ -0,0 +1,86 @@
+// Package tzmap maps between the Windows time zone names Outlook and
+// Microsoft Graph use and the IANA names the rest of salja keeps in
+// CalendarItem.Timezone.
+package tzmap
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	toIANA    = make(map[string]string)
+	toWindows = make(map[string]string)
+)
+
+// aliases are IANA names replaced by the ones in the table; files written
+// by older software still use them.
+var aliases = map[string]string{
+	"Asia/Calcutta": "Asia/Kolkata", "Asia/Katmandu": "Asia/Kathmandu", "Asia/Rangoon": "Asia/Yangon",
+	"Asia/Saigon": "Asia/Ho_Chi_Minh", "Europe/Kiev": "Europe/Kyiv", "America/Godthab": "America/Nuuk",
+	"America/Buenos_Aires": "America/Argentina/Buenos_Aires", "America/Indianapolis": "America/Indiana/Indianapolis",
+	"Pacific/Enderbury": "Pacific/Kanton", "Pacific/Truk": "Pacific/Chuuk", "Pacific/Ponape": "Pacific/Pohnpei",
+	"Australia/ACT": "Australia/Sydney", "Australia/NSW": "Australia/Sydney", "Australia/Victoria": "Australia/Melbourne",
+	"US/Eastern": "America/New_York", "US/Central": "America/Chicago", "US/Mountain": "America/Denver",
+	"US/Pacific": "America/Los_Angeles", "US/Alaska": "America/Anchorage", "US/Hawaii": "Pacific/Honolulu",
+	"US/Arizona": "America/Phoenix", "Canada/Eastern": "America/Toronto", "Canada/Pacific": "America/Vancouver",
+	"GB": "Europe/London", "Japan": "Asia/Tokyo", "PRC": "Asia/Shanghai", "Singapore": "Asia/Singapore",
+	"Etc/Universal": "Etc/UTC", "Etc/Zulu": "Etc/UTC", "GMT": "Etc/GMT",
+}
+
+func init() {
+	for _, z := range windowsZones {
+		toIANA[strings.ToLower(z.windows)] = z.iana[0]
+		for _, name := range z.iana {
+			if _, ok := toWindows[name]; !ok {
+				toWindows[name] = z.windows
+			}
+		}
+	}
+}
+
+// ToIANA returns the IANA name for a zone named either way. IANA names Go
+// can load come back unchanged; Windows names match case-insensitively.
+func ToIANA(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return "", false
+	}
+	if iana, ok := toIANA[strings.ToLower(name)]; ok {
+		return iana, true
+	}
+	if _, err := time.LoadLocation(name); err == nil {
+		return name, true
+	}
+	return "", false
+}
+
+// ToWindows returns the Windows name for an IANA zone, or for a name that
+// already is one.
+func ToWindows(name string) (string, bool) {
+	if w, ok := toWindows[name]; ok {
+		return w, true
+	}
+	if w, ok := toWindows[aliases[name]]; ok {
+		return w, true
+	}
+	for _, z := range windowsZones {
+		if strings.EqualFold(z.windows, name) {
+			return z.windows, true
+		}
+	}
+	return "", false
+}
+
+// Candidates returns the IANA zones of the table, primary ones first, for
+// callers that match a zone by its rules rather than its name.
+func Candidates() []string {
+	names := make([]string, 0, len(toWindows))
+	for _, z := range windowsZones {
+		names = append(names, z.iana[0])
+	}
+	for _, z := range windowsZones {
+		names = append(names, z.iana[1:]...)
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package tzmap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTableZonesLoad(t *testing.T) {
+	for _, z := range windowsZones {
+		for _, name := range z.iana {
+			if _, err := time.LoadLocation(name); err != nil {
+				t.Errorf("%s: %v", z.windows, err)
+			}
+		}
+	}
+	for alias, name := range aliases {
+		if _, ok := toWindows[name]; !ok {
+			t.Errorf("alias %s names %s, which is not in the table", alias, name)
+		}
+	}
+}
+
+func TestMapping(t *testing.T) {
+	cases := []struct{ windows, iana string }{
+		{"W. Europe Standard Time", "Europe/Berlin"},
+		{"Eastern Standard Time", "America/New_York"},
+		{"India Standard Time", "Asia/Kolkata"},
+		{"UTC", "UTC"},
+	}
+	for _, c := range cases {
+		if got, ok := ToIANA(c.windows); !ok || got != c.iana {
+			t.Errorf("ToIANA(%q) = %q, %v", c.windows, got, ok)
+		}
+		if got, ok := ToWindows(c.iana); !ok || got != c.windows {
+			t.Errorf("ToWindows(%q) = %q, %v", c.iana, got, ok)
+		}
+	}
+	if got, _ := ToIANA("pacific standard time"); got != "America/Los_Angeles" {
+		t.Errorf("case-insensitive lookup = %q", got)
+	}
+	if got, _ := ToIANA("Europe/Amsterdam"); got != "Europe/Amsterdam" {
+		t.Errorf("IANA names pass through, got %q", got)
+	}
+	if got, _ := ToWindows("Europe/Amsterdam"); got != "W. Europe Standard Time" {
+		t.Errorf("secondary zone = %q", got)
+	}
+	if got, _ := ToWindows("Asia/Calcutta"); got != "India Standard Time" {
+		t.Errorf("alias = %q", got)
+	}
+	if _, ok := ToIANA("Mars/Olympus_Mons"); ok {
+		t.Error("unknown zone should not map")
+	}
+}
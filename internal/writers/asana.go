@@ -31,20 +31,23 @@ func (w *AsanaWriter) Write(ctx context.Context, collection *model.CalendarColle
 	defer csvWriter.Flush()
 
 	header := []string{"Name", "Section", "Due Date", "Assignee", "Description", "Tags", "Completed"}
+	// columns of an Asana export salja has no field for are written back
+	extra := extensionColumns(collection, "asana", header)
+	header = append(header, extra...)
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	for _, item := range collection.Items {
-		row := make([]string, 7)
+		row := make([]string, len(header))
 		row[0] = item.Title
-		row[1] = ""
+		row[1], _ = item.ExtensionValue("asana", "Section")
 
 		if item.DueDate != nil {
 			row[2] = item.DueDate.Format("2006-01-02")
 		}
 
-		row[3] = ""
+		row[3], _ = item.ExtensionValue("asana", "Assignee")
 		desc := flattenSubtasksToDescription(item.Description, item.Subtasks)
 		row[4] = recurrenceToDescription(desc, item.Recurrence)
 
@@ -58,6 +61,8 @@ func (w *AsanaWriter) Write(ctx context.Context, collection *model.CalendarColle
 			row[6] = "FALSE"
 		}
 
+		copy(row[7:], extensionCells(&item, "asana", extra))
+
 		if err := csvWriter.Write(row); err != nil {
 			return err
 		}
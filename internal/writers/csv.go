@@ -0,0 +1,183 @@
+package writers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/csvmap"
+	"github.com/gongahkia/salja/internal/model"
+)
+
+// CSVWriter writes the columns of a mapping profile, in its order.
+type CSVWriter struct {
+	Profile *csvmap.Profile
+}
+
+// NewCSVWriter returns a writer for the profile chosen with csvmap.Use.
+func NewCSVWriter() *CSVWriter {
+	return &CSVWriter{Profile: csvmap.Active()}
+}
+
+func (w *CSVWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(ctx, collection, f)
+}
+
+func (w *CSVWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = w.Profile.Comma()
+
+	header := make([]string, len(w.Profile.Columns))
+	hasProject := false
+	for i, c := range w.Profile.Columns {
+		header[i] = c.Name
+		hasProject = hasProject || c.Field == csvmap.FieldProject
+	}
+	// columns the profile does not map are written back after its own
+	extra := extensionColumns(collection, "csv", header)
+	header = append(header, extra...)
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range collection.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		row := make([]string, len(w.Profile.Columns), len(header))
+		for i := range w.Profile.Columns {
+			row[i] = w.cell(&w.Profile.Columns[i], &item, hasProject)
+		}
+		row = append(row, extensionCells(&item, "csv", extra)...)
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+var csvPriorityNames = map[model.Priority]string{
+	model.PriorityNone:    "none",
+	model.PriorityLowest:  "lowest",
+	model.PriorityLow:     "low",
+	model.PriorityMedium:  "medium",
+	model.PriorityHigh:    "high",
+	model.PriorityHighest: "highest",
+}
+
+func (w *CSVWriter) cell(c *csvmap.Column, item *model.CalendarItem, hasProject bool) string {
+	switch c.Field {
+	case csvmap.FieldUID:
+		return item.UID
+	case csvmap.FieldTitle:
+		return item.Title
+	case csvmap.FieldDescription:
+		return recurrenceToDescription(flattenSubtasksToDescription(item.Description, item.Subtasks), item.Recurrence)
+	case csvmap.FieldLocation:
+		return item.Location
+
+	case csvmap.FieldType:
+		itemType := item.ItemType
+		if itemType == "" {
+			itemType = model.ItemTypeTask
+		}
+		if v := c.ExportValue(string(itemType)); v != "" {
+			return v
+		}
+		return string(itemType)
+	case csvmap.FieldStatus:
+		status := item.Status
+		if status == "" {
+			status = model.StatusPending
+		}
+		if v := c.ExportValue(string(status)); v != "" {
+			return v
+		}
+		return statusName(status)
+	case csvmap.FieldPriority:
+		if v := c.ExportValue(csvPriorityNames[item.Priority]); v != "" {
+			return v
+		}
+		if v := c.ExportValue(strconv.Itoa(int(item.Priority))); v != "" {
+			return v
+		}
+		return priorityName(item.Priority)
+
+	case csvmap.FieldTags:
+		var tags []string
+		for _, tag := range item.Tags {
+			if hasProject && strings.HasPrefix(tag, model.ProjectTagPrefix) {
+				continue
+			}
+			tags = append(tags, tag)
+		}
+		sep := c.Separator
+		if sep == "" {
+			sep = ","
+		}
+		return strings.Join(tags, sep)
+	case csvmap.FieldProject:
+		for _, tag := range item.Tags {
+			if name, ok := strings.CutPrefix(tag, model.ProjectTagPrefix); ok {
+				return name
+			}
+		}
+		return ""
+	case csvmap.FieldAllDay:
+		return strconv.FormatBool(item.IsAllDay)
+
+	case csvmap.FieldStart:
+		return w.date(c, item.StartTime, item.IsAllDay)
+	case csvmap.FieldEnd:
+		return w.date(c, item.EndTime, item.IsAllDay)
+	case csvmap.FieldDue:
+		return w.date(c, item.DueDate, item.IsAllDay)
+	case csvmap.FieldCompleted:
+		return w.date(c, item.CompletionDate, false)
+	case csvmap.FieldCreated:
+		return w.date(c, item.CreatedAt, false)
+	case csvmap.FieldUpdated:
+		return w.date(c, item.UpdatedAt, false)
+	}
+	return ""
+}
+
+// date writes t with the column's first layout, or, for a date without a
+// time of day, its first layout that has none.
+func (w *CSVWriter) date(c *csvmap.Column, t *time.Time, allDay bool) string {
+	if t == nil {
+		return ""
+	}
+	layouts := w.Profile.Layouts(c)
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339, "2006-01-02"}
+	}
+	if allDay || isMidnight(*t) {
+		for _, layout := range layouts {
+			if !layoutHasClock(layout) {
+				return t.Format(layout)
+			}
+		}
+	}
+	return t.Format(layouts[0])
+}
+
+// layoutHasClock reports whether a Go time layout writes a time of day.
+func layoutHasClock(layout string) bool {
+	ref := time.Date(2001, 2, 3, 16, 5, 6, 0, time.UTC)
+	t, err := time.Parse(layout, ref.Format(layout))
+	return err != nil || t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0
+}
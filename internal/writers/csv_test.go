@@ -0,0 +1,91 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/csvmap"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+func TestCSVWriterProfile(t *testing.T) {
+	profile := &csvmap.Profile{
+		Delimiter:   ";",
+		DateFormats: []string{"02.01.2006 15:04", "02.01.2006"},
+		Columns: []csvmap.Column{
+			{Name: "Key", Field: csvmap.FieldUID},
+			{Name: "Summary", Field: csvmap.FieldTitle},
+			{Name: "State", Field: csvmap.FieldStatus, Values: map[string]string{"Open": "pending", "New": "pending", "Shipped": "completed"}},
+			{Name: "Urgency", Field: csvmap.FieldPriority, Export: map[string]string{"high": "P1"}},
+			{Name: "Team", Field: csvmap.FieldProject},
+			{Name: "Labels", Field: csvmap.FieldTags, Separator: "|"},
+			{Name: "Due", Field: csvmap.FieldDue},
+		},
+	}
+	due := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	review := time.Date(2026, 3, 16, 14, 30, 0, 0, time.UTC)
+	col := &model.CalendarCollection{Items: []model.CalendarItem{
+		{UID: "A-1", Title: "Write spec", Status: model.StatusPending, Priority: model.PriorityHigh,
+			Tags: []string{"docs", model.ProjectTagPrefix + "Core", "q1"}, DueDate: &due},
+		{UID: "A-2", Title: "Review; merge", Status: model.StatusCompleted, DueDate: &review},
+	}}
+
+	var buf bytes.Buffer
+	if err := (&CSVWriter{Profile: profile}).Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	// columns in profile order, the alphabetically first cell value when no
+	// export is given, and a date-only layout for midnight
+	want := "Key;Summary;State;Urgency;Team;Labels;Due\n" +
+		"A-1;Write spec;New;P1;Core;docs|q1;15.03.2026\n" +
+		"A-2;\"Review; merge\";Shipped;;;;16.03.2026 14:30\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	parsed, err := (&parsers.CSVParser{Profile: profile}).Parse(context.Background(), strings.NewReader(buf.String()), "out.csv")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(parsed.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(parsed.Items))
+	}
+	spec := parsed.Items[0]
+	if spec.Status != model.StatusPending || spec.Priority != model.PriorityHigh || strings.Join(spec.Tags, ",") != "project:Core,docs,q1" {
+		t.Errorf("spec = %+v", spec)
+	}
+	if spec.DueDate == nil || !spec.DueDate.Equal(due) {
+		t.Errorf("due = %v", spec.DueDate)
+	}
+	if merge := parsed.Items[1]; merge.Title != "Review; merge" || merge.Status != model.StatusCompleted || !merge.DueDate.Equal(review) {
+		t.Errorf("merge = %+v", merge)
+	}
+}
+
+func TestCSVWriterKeepsUnmappedColumns(t *testing.T) {
+	profile, _ := csvmap.Builtin("jira")
+	input := "Summary,Issue key,Watchers,Watchers,Sprint\n" +
+		"Fix login,WEB-12,ana,sam,Sprint 4\n" +
+		"Ship docs,WEB-13,kim,,\n"
+	col, err := (&parsers.CSVParser{Profile: profile}).Parse(context.Background(), strings.NewReader(input), "jira.csv")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := (&CSVWriter{Profile: profile}).Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// repeated columns come back as often as one row used them
+	if !strings.HasSuffix(lines[0], ",Description,Watchers,Watchers,Sprint") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], ",ana,sam,Sprint 4") || !strings.HasSuffix(lines[2], ",kim,,") {
+		t.Errorf("rows = %q", lines[1:])
+	}
+}
@@ -33,12 +33,15 @@ func (w *GoogleCalendarWriter) Write(ctx context.Context, collection *model.Cale
 		"Subject", "Start Date", "Start Time", "End Date", "End Time",
 		"All Day Event", "Description", "Location", "Private",
 	}
+	// columns of a Google Calendar export salja has no field for are written back
+	extra := extensionColumns(collection, "gcal", header)
+	header = append(header, extra...)
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	for _, item := range collection.Items {
-		row := make([]string, 9)
+		row := make([]string, len(header))
 		row[0] = item.Title
 
 		if item.StartTime != nil {
@@ -70,6 +73,11 @@ func (w *GoogleCalendarWriter) Write(ctx context.Context, collection *model.Cale
 		row[6] = flattenSubtasksToDescription(item.Description, item.Subtasks)
 		row[7] = item.Location
 		row[8] = "False"
+		if v, ok := item.ExtensionValue("gcal", "Private"); ok {
+			row[8] = v
+		}
+
+		copy(row[9:], extensionCells(&item, "gcal", extra))
 
 		if err := csvWriter.Write(row); err != nil {
 			return err
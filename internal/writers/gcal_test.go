@@ -166,3 +166,28 @@ func TestGCalWriterHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestGCalWriterKeepsUnmappedColumns(t *testing.T) {
+	input := `Subject,Start Date,Start Time,End Date,End Time,All Day Event,Description,Location,Private,Color
+Meeting,01/15/2024,2:00 PM,01/15/2024,3:00 PM,False,,,True,Tomato`
+
+	col, err := parsers.NewGoogleCalendarParser().Parse(context.Background(), strings.NewReader(input), "test.csv")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewGoogleCalendarWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(records[0], ","); got != "Subject,Start Date,Start Time,End Date,End Time,All Day Event,Description,Location,Private,Color" {
+		t.Errorf("header = %q", got)
+	}
+	if got := strings.Join(records[1], ","); got != "Meeting,01/15/2024,2:00 PM,01/15/2024,3:00 PM,False,,,True,Tomato" {
+		t.Errorf("row = %q", got)
+	}
+}
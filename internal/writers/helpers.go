@@ -27,6 +27,50 @@ func flattenSubtasksToDescription(desc string, subtasks []model.Subtask) string
 	return checklist
 }
 
+// extensionColumns returns the names of namespace's extensions across the
+// collection that header lacks, in the order they are first seen. A name an
+// item has more than once, like Jira's repeated Watchers column, is listed
+// as often as any one item has it.
+func extensionColumns(collection *model.CalendarCollection, namespace string, header []string) []string {
+	inHeader := make(map[string]bool, len(header))
+	for _, col := range header {
+		inHeader[col] = true
+	}
+	listed := make(map[string]int)
+	var cols []string
+	for i := range collection.Items {
+		count := make(map[string]int)
+		for _, ext := range collection.Items[i].ExtensionsIn(namespace) {
+			if inHeader[ext.Name] {
+				continue
+			}
+			count[ext.Name]++
+			if count[ext.Name] > listed[ext.Name] {
+				listed[ext.Name]++
+				cols = append(cols, ext.Name)
+			}
+		}
+	}
+	return cols
+}
+
+// extensionCells returns item's values for columns from extensionColumns,
+// the nth column of a name holding the item's nth value for it.
+func extensionCells(item *model.CalendarItem, namespace string, cols []string) []string {
+	values := make(map[string][]string)
+	for _, ext := range item.ExtensionsIn(namespace) {
+		values[ext.Name] = append(values[ext.Name], ext.Value)
+	}
+	cells := make([]string, len(cols))
+	for i, col := range cols {
+		if v := values[col]; len(v) > 0 {
+			cells[i] = v[0]
+			values[col] = v[1:]
+		}
+	}
+	return cells
+}
+
 // recurrenceToDescription appends a human-readable recurrence summary to the description.
 func recurrenceToDescription(desc string, rec *model.Recurrence) string {
 	if rec == nil {
@@ -69,3 +113,35 @@ func formatRecurrenceSummary(rec *model.Recurrence) string {
 	}
 	return strings.Join(parts, " ")
 }
+
+// statusName is the display name of a status.
+func statusName(s model.Status) string {
+	switch s {
+	case model.StatusInProgress:
+		return "In Progress"
+	case model.StatusCompleted:
+		return "Completed"
+	case model.StatusCancelled:
+		return "Cancelled"
+	default:
+		return "Pending"
+	}
+}
+
+// priorityName is the display name of a priority, empty for none.
+func priorityName(p model.Priority) string {
+	switch p {
+	case model.PriorityLowest:
+		return "Lowest"
+	case model.PriorityLow:
+		return "Low"
+	case model.PriorityMedium:
+		return "Medium"
+	case model.PriorityHigh:
+		return "High"
+	case model.PriorityHighest:
+		return "Highest"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,294 @@
+package writers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/google/uuid"
+)
+
+const markdownTasksDateLayout = "2006-01-02"
+
+// MarkdownTasksWriter writes a markdown task list, as Obsidian Tasks
+// checklists or, for Logseq, as task blocks.
+type MarkdownTasksWriter struct {
+	Logseq bool
+}
+
+func NewMarkdownTasksWriter() *MarkdownTasksWriter {
+	return &MarkdownTasksWriter{}
+}
+
+func NewLogseqWriter() *MarkdownTasksWriter {
+	return &MarkdownTasksWriter{Logseq: true}
+}
+
+// obsidianID is the character set Obsidian Tasks allows in a 🆔 field.
+var obsidianID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (w *MarkdownTasksWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create markdown file: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(ctx, collection, f)
+}
+
+// Write produces one top-level list item per item, its description as
+// indented lines and its subtasks as nested items.
+func (w *MarkdownTasksWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	bw := bufio.NewWriter(writer)
+	for _, item := range collection.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if w.Logseq {
+			writeLogseqBlock(bw, &item)
+		} else {
+			writeObsidianTask(bw, &item)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeObsidianTask writes the fields in the order the Obsidian Tasks plugin
+// does. Its fields are dates, so times of day are dropped.
+func writeObsidianTask(bw *bufio.Writer, item *model.CalendarItem) {
+	parts := []string{"- [" + obsidianCheckbox(item.Status) + "]"}
+	parts = append(parts, strings.Fields(item.Title)...)
+	parts = append(parts, markdownTags(item.Tags, false)...)
+	if p := obsidianPriority(item.Priority); p != "" {
+		parts = append(parts, p)
+	}
+	if rule := formatRecurrenceText(item.Recurrence); rule != "" {
+		parts = append(parts, "🔁", rule)
+	}
+	if obsidianID.MatchString(item.UID) {
+		parts = append(parts, "🆔", item.UID)
+	}
+	date := func(sig string, t time.Time) {
+		parts = append(parts, sig, t.Format(markdownTasksDateLayout))
+	}
+	if item.CreatedAt != nil {
+		date("➕", *item.CreatedAt)
+	}
+	if item.StartTime != nil {
+		date("⏳", *item.StartTime)
+	}
+	if item.DueDate != nil {
+		date("📅", *item.DueDate)
+	}
+	if item.CompletionDate != nil {
+		switch item.Status {
+		case model.StatusCancelled:
+			date("❌", *item.CompletionDate)
+		case model.StatusCompleted:
+			date("✅", *item.CompletionDate)
+		}
+	}
+	fmt.Fprintln(bw, strings.Join(parts, " "))
+
+	writeMarkdownDescription(bw, item.Description)
+	for _, st := range item.Subtasks {
+		sub := []string{"\t- [" + obsidianCheckbox(st.Status) + "]"}
+		sub = append(sub, strings.Fields(st.Title)...)
+		if p := obsidianPriority(st.Priority); p != "" {
+			sub = append(sub, p)
+		}
+		fmt.Fprintln(bw, strings.Join(sub, " "))
+	}
+}
+
+// writeLogseqBlock writes a task block with its properties and dates on the
+// lines below it, as Logseq does. Logseq dates are Org timestamps.
+func writeLogseqBlock(bw *bufio.Writer, item *model.CalendarItem) {
+	parts := []string{"-", logseqMarker(item.Status)}
+	if letter := exportOrgPriority(item.Priority); letter != "" {
+		parts = append(parts, "[#"+letter+"]")
+	}
+	parts = append(parts, strings.Fields(item.Title)...)
+	parts = append(parts, markdownTags(item.Tags, true)...)
+	fmt.Fprintln(bw, strings.Join(parts, " "))
+
+	// block ids are UUIDs; Logseq rejects any other
+	if id, err := uuid.Parse(item.UID); err == nil {
+		fmt.Fprintf(bw, "  id:: %s\n", id)
+	}
+	repeater := orgRepeater(item.Recurrence)
+	if item.StartTime != nil {
+		fmt.Fprintln(bw, "  SCHEDULED: "+orgPlanningStamp(item, *item.StartTime, repeater, ""))
+		repeater = ""
+	}
+	if item.DueDate != nil {
+		fmt.Fprintln(bw, "  DEADLINE: "+orgPlanningStamp(item, *item.DueDate, repeater, orgWarning(item.Reminders)))
+	}
+
+	writeMarkdownDescription(bw, item.Description)
+	for _, st := range item.Subtasks {
+		sub := []string{"\t-", logseqMarker(st.Status)}
+		if letter := exportOrgPriority(st.Priority); letter != "" {
+			sub = append(sub, "[#"+letter+"]")
+		}
+		sub = append(sub, strings.Fields(st.Title)...)
+		fmt.Fprintln(bw, strings.Join(sub, " "))
+	}
+}
+
+func writeMarkdownDescription(bw *bufio.Writer, desc string) {
+	for _, line := range strings.Split(desc, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			fmt.Fprintln(bw, "  "+line)
+		}
+	}
+}
+
+// markdownTags writes tags as #tags, a project as a #project/name tag. Tags
+// cannot hold spaces; Logseq writes such tags as #[[multi word]].
+func markdownTags(tags []string, logseq bool) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if name, ok := strings.CutPrefix(tag, model.ProjectTagPrefix); ok {
+			tag = "project/" + name
+		}
+		switch {
+		case tag == "":
+			continue
+		case logseq && strings.ContainsAny(tag, " \t"):
+			out = append(out, "#[["+strings.Join(strings.Fields(tag), " ")+"]]")
+		default:
+			out = append(out, "#"+strings.Join(strings.Fields(tag), "_"))
+		}
+	}
+	return out
+}
+
+func obsidianCheckbox(s model.Status) string {
+	switch s {
+	case model.StatusCompleted:
+		return "x"
+	case model.StatusCancelled:
+		return "-"
+	case model.StatusInProgress:
+		return "/"
+	default:
+		return " "
+	}
+}
+
+func obsidianPriority(p model.Priority) string {
+	switch p {
+	case model.PriorityHighest:
+		return "🔺"
+	case model.PriorityHigh:
+		return "⏫"
+	case model.PriorityMedium:
+		return "🔼"
+	case model.PriorityLow:
+		return "🔽"
+	case model.PriorityLowest:
+		return "⏬"
+	default:
+		return ""
+	}
+}
+
+func logseqMarker(s model.Status) string {
+	switch s {
+	case model.StatusCompleted:
+		return "DONE"
+	case model.StatusCancelled:
+		return "CANCELED"
+	case model.StatusInProgress:
+		return "DOING"
+	default:
+		return "TODO"
+	}
+}
+
+// formatRecurrenceText returns the Obsidian Tasks rule for rec, e.g. "every 2
+// weeks on Monday, Friday". By-rules other than weekdays for a weekly rule
+// and one day of the month for a monthly rule are dropped.
+func formatRecurrenceText(rec *model.Recurrence) string {
+	if rec == nil {
+		return ""
+	}
+	units := map[model.FreqType]string{
+		model.FreqDaily:   "day",
+		model.FreqWeekly:  "week",
+		model.FreqMonthly: "month",
+		model.FreqYearly:  "year",
+	}
+	unit, ok := units[rec.Freq]
+	if !ok {
+		return ""
+	}
+	n := rec.Interval
+	if n < 1 {
+		n = 1
+	}
+
+	var s string
+	switch {
+	case n == 1 && isWeekdays(rec.ByDay) && (rec.Freq == model.FreqWeekly || rec.Freq == model.FreqDaily):
+		s = "every weekday"
+	case n == 1:
+		s = "every " + unit
+	default:
+		s = fmt.Sprintf("every %d %ss", n, unit)
+	}
+	if s != "every weekday" {
+		switch {
+		case rec.Freq == model.FreqWeekly && len(rec.ByDay) > 0:
+			names := map[model.Weekday]string{
+				model.WeekdayMO: "Monday", model.WeekdayTU: "Tuesday", model.WeekdayWE: "Wednesday",
+				model.WeekdayTH: "Thursday", model.WeekdayFR: "Friday", model.WeekdaySA: "Saturday", model.WeekdaySU: "Sunday",
+			}
+			var days []string
+			for _, d := range rec.ByDay {
+				if name, ok := names[d]; ok {
+					days = append(days, name)
+				}
+			}
+			if len(days) > 0 {
+				s += " on " + strings.Join(days, ", ")
+			}
+		case rec.Freq == model.FreqMonthly && len(rec.ByMonthDay) == 1:
+			if d := rec.ByMonthDay[0]; d == -1 {
+				s += " on the last"
+			} else if d > 0 {
+				s += " on the " + ordinal(d)
+			}
+		}
+	}
+	if rec.Until != nil {
+		s += " until " + rec.Until.Format(markdownTasksDateLayout)
+	} else if rec.Count != nil {
+		s += fmt.Sprintf(" for %d times", *rec.Count)
+	}
+	return s
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
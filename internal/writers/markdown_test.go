@@ -0,0 +1,72 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+func TestMarkdownTasksWriterRoundtrip(t *testing.T) {
+	input := "- [ ] Call Mom #family #project/Home ⏫ 🔁 every 2 weeks on Monday, Friday ➕ 2026-03-01 ⏳ 2026-03-04 📅 2026-03-05\n" +
+		"  Ask about the trip.\n" +
+		"\t- [x] Buy card 🔼\n" +
+		"\t- [ ] Pick stamps\n" +
+		"- [x] File taxes 🆔 taxes-2026 📅 2026-03-05 ✅ 2026-03-04\n" +
+		"- [-] Dropped ❌ 2026-03-02\n" +
+		"- [/] Water plants 🔁 every month on the last\n" +
+		"- [ ] Stand-up 🔁 every weekday until 2026-12-31\n"
+	col, err := parsers.NewMarkdownTasksParser().Parse(context.Background(), strings.NewReader(input), "tasks.md")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewMarkdownTasksWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestLogseqWriterRoundtrip(t *testing.T) {
+	input := "- DOING [#A] Ship release #work #[[deep work]]\n" +
+		"  id:: 5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f\n" +
+		"  SCHEDULED: <2026-03-10 Tue 09:00 +1w>\n" +
+		"  DEADLINE: <2026-03-15 Sun -2d>\n" +
+		"  Check the changelog.\n" +
+		"\t- DONE Write notes\n" +
+		"\t- TODO [#C] Tag build\n" +
+		"- CANCELED Old idea\n"
+	col, err := parsers.NewLogseqParser().Parse(context.Background(), strings.NewReader(input), "plans.md")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewLogseqWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestMarkdownTasksWriterFromCalendar(t *testing.T) {
+	start := time.Date(2026, 4, 2, 10, 0, 0, 0, time.UTC)
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{UID: "evt@example.com", Title: "Team offsite", ItemType: model.ItemTypeEvent, StartTime: &start, Tags: []string{"team days"}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewMarkdownTasksWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if want := "- [ ] Team offsite #team_days ⏳ 2026-04-02\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -31,12 +31,15 @@ func (w *NotionWriter) Write(ctx context.Context, collection *model.CalendarColl
 	defer csvWriter.Flush()
 
 	header := []string{"Title", "Date", "Status", "Tags", "Priority", "Description"}
+	// properties of a Notion database salja has no field for are written back
+	extra := extensionColumns(collection, "notion", header)
+	header = append(header, extra...)
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	for _, item := range collection.Items {
-		row := make([]string, 6)
+		row := make([]string, len(header))
 		row[0] = item.Title
 
 		if item.DueDate != nil {
@@ -72,6 +75,8 @@ func (w *NotionWriter) Write(ctx context.Context, collection *model.CalendarColl
 		desc := flattenSubtasksToDescription(item.Description, item.Subtasks)
 		row[5] = recurrenceToDescription(desc, item.Recurrence)
 
+		copy(row[6:], extensionCells(&item, "notion", extra))
+
 		if err := csvWriter.Write(row); err != nil {
 			return err
 		}
@@ -0,0 +1,313 @@
+package writers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+type OrgWriter struct{}
+
+func NewOrgWriter() *OrgWriter {
+	return &OrgWriter{}
+}
+
+func (w *OrgWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create Org file: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(ctx, collection, f)
+}
+
+// Write produces one top-level headline per item: tasks with a TODO keyword,
+// events with an active timestamp and journal notes with an inactive one.
+// Subtasks become checkbox items, which the parser folds back.
+func (w *OrgWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	bw := bufio.NewWriter(writer)
+
+	// NEXT and CANCELLED are not default Org keywords, so declare them
+	for _, item := range collection.Items {
+		if orgNeedsKeywords(&item) {
+			fmt.Fprintln(bw, "#+TODO: TODO NEXT | DONE CANCELLED")
+			fmt.Fprintln(bw)
+			break
+		}
+	}
+
+	for _, item := range collection.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		writeOrgItem(bw, &item)
+	}
+	return bw.Flush()
+}
+
+func orgNeedsKeywords(item *model.CalendarItem) bool {
+	if item.Status == model.StatusInProgress || item.Status == model.StatusCancelled {
+		return item.ItemType != model.ItemTypeEvent && item.ItemType != model.ItemTypeJournal
+	}
+	return false
+}
+
+func writeOrgItem(bw *bufio.Writer, item *model.CalendarItem) {
+	isTask := item.ItemType != model.ItemTypeEvent && item.ItemType != model.ItemTypeJournal
+	keyword := ""
+	if isTask {
+		keyword = orgKeyword(item.Status)
+	}
+	fmt.Fprintln(bw, orgHeadlineText(1, keyword, item.Priority, strings.Join(strings.Fields(item.Title), " "), item.Tags))
+
+	repeater := orgRepeater(item.Recurrence)
+	var planning []string
+	if isTask {
+		if item.CompletionDate != nil && (item.Status == model.StatusCompleted || item.Status == model.StatusCancelled) {
+			planning = append(planning, "CLOSED: "+orgStamp(*item.CompletionDate, false, false, "", ""))
+		}
+		// a repeater read from DEADLINE goes back there, as it was written,
+		// while the rule is unchanged
+		deadlineRepeater := ""
+		if read, ok := item.ExtensionValue("org", "DEADLINE"); ok && repeater != "" && item.DueDate != nil {
+			if rec, err := parsers.ParseOrgRepeater(read); err == nil && orgRepeater(rec) == repeater {
+				repeater, deadlineRepeater = "", read
+			}
+		}
+		if item.StartTime != nil {
+			planning = append(planning, "SCHEDULED: "+orgPlanningStamp(item, *item.StartTime, repeater, ""))
+			repeater = ""
+		}
+		if item.DueDate != nil {
+			planning = append(planning, "DEADLINE: "+orgPlanningStamp(item, *item.DueDate, repeater+deadlineRepeater, orgWarning(item.Reminders)))
+		}
+	}
+	if len(planning) > 0 {
+		fmt.Fprintln(bw, strings.Join(planning, " "))
+	}
+
+	var props [][2]string
+	if item.UID != "" {
+		props = append(props, [2]string{"ID", item.UID})
+	}
+	if item.Location != "" {
+		props = append(props, [2]string{"LOCATION", item.Location})
+	}
+	if item.CreatedAt != nil {
+		props = append(props, [2]string{"CREATED", orgStamp(*item.CreatedAt, false, false, "", "")})
+	}
+	for _, ext := range item.ExtensionsIn("org") {
+		if ext.Name != "DEADLINE" {
+			props = append(props, [2]string{ext.Name, ext.Value})
+		}
+	}
+	if len(props) > 0 {
+		fmt.Fprintln(bw, ":PROPERTIES:")
+		for _, p := range props {
+			fmt.Fprintln(bw, strings.TrimSpace(":"+p[0]+": "+p[1]))
+		}
+		fmt.Fprintln(bw, ":END:")
+	}
+
+	switch {
+	case item.ItemType == model.ItemTypeEvent && item.StartTime != nil:
+		fmt.Fprintln(bw, orgEventStamp(item, repeater))
+	case item.ItemType == model.ItemTypeJournal && item.StartTime != nil:
+		fmt.Fprintln(bw, orgStamp(*item.StartTime, false, item.IsAllDay, "", ""))
+	}
+
+	if item.Description != "" {
+		for _, line := range strings.Split(item.Description, "\n") {
+			// a body line must not read as a headline
+			if strings.HasPrefix(line, "*") {
+				line = " " + line
+			}
+			fmt.Fprintln(bw, line)
+		}
+	}
+
+	for _, st := range item.Subtasks {
+		parts := []string{"-", orgCheckbox(st.Status)}
+		if letter := exportOrgPriority(st.Priority); letter != "" {
+			parts = append(parts, "[#"+letter+"]")
+		}
+		fmt.Fprintln(bw, strings.Join(append(parts, strings.Fields(st.Title)...), " "))
+	}
+}
+
+// orgCheckbox has no cancelled state; a cancelled subtask is checked off.
+func orgCheckbox(s model.Status) string {
+	switch s {
+	case model.StatusCompleted, model.StatusCancelled:
+		return "[X]"
+	case model.StatusInProgress:
+		return "[-]"
+	default:
+		return "[ ]"
+	}
+}
+
+func orgHeadlineText(level int, keyword string, p model.Priority, title string, tags []string) string {
+	parts := []string{strings.Repeat("*", level)}
+	if keyword != "" {
+		parts = append(parts, keyword)
+	}
+	if letter := exportOrgPriority(p); letter != "" {
+		parts = append(parts, "[#"+letter+"]")
+	}
+	parts = append(parts, title)
+	var clean []string
+	for _, t := range tags {
+		// tags are words joined by colons
+		t = strings.NewReplacer(" ", "_", ":", "_").Replace(strings.TrimSpace(t))
+		if t != "" {
+			clean = append(clean, t)
+		}
+	}
+	if len(clean) > 0 {
+		parts = append(parts, ":"+strings.Join(clean, ":")+":")
+	}
+	return strings.Join(parts, " ")
+}
+
+func orgKeyword(s model.Status) string {
+	switch s {
+	case model.StatusCompleted:
+		return "DONE"
+	case model.StatusCancelled:
+		return "CANCELLED"
+	case model.StatusInProgress:
+		return "NEXT"
+	default:
+		return "TODO"
+	}
+}
+
+func exportOrgPriority(p model.Priority) string {
+	switch {
+	case p >= model.PriorityHigh:
+		return "A"
+	case p == model.PriorityMedium:
+		return "B"
+	case p > model.PriorityNone:
+		return "C"
+	default:
+		return ""
+	}
+}
+
+// orgStamp formats t in local time, as Org timestamps carry no zone.
+func orgStamp(t time.Time, active, allDay bool, repeater, warning string) string {
+	t = orgLocal(t, allDay)
+	s := t.Format("2006-01-02 Mon")
+	if !allDay {
+		s += t.Format(" 15:04")
+	}
+	return orgWrap(s, active, repeater, warning)
+}
+
+// orgEventStamp writes an event's span as one timestamp when it stays within
+// a day and as a range otherwise.
+func orgEventStamp(item *model.CalendarItem, repeater string) string {
+	start := orgLocal(*item.StartTime, item.IsAllDay)
+	if item.EndTime == nil {
+		return orgStamp(start, true, item.IsAllDay, repeater, "")
+	}
+	end := orgLocal(*item.EndTime, item.IsAllDay)
+	if !item.IsAllDay && start.Format("2006-01-02") == end.Format("2006-01-02") {
+		return orgWrap(start.Format("2006-01-02 Mon 15:04")+end.Format("-15:04"), true, repeater, "")
+	}
+	if item.IsAllDay {
+		// ICS all-day ends are exclusive, Org ranges are inclusive
+		end = end.AddDate(0, 0, -1)
+		if !end.After(start) {
+			return orgStamp(start, true, true, repeater, "")
+		}
+	}
+	return orgStamp(start, true, item.IsAllDay, repeater, "") + "--" + orgStamp(end, true, item.IsAllDay, "", "")
+}
+
+// orgPlanningStamp writes a SCHEDULED or DEADLINE time. Task dates from most
+// formats are midnight rather than flagged all-day, so midnight, in the time's
+// own zone or locally, is written as a plain date.
+func orgPlanningStamp(item *model.CalendarItem, t time.Time, repeater, warning string) string {
+	if item.IsAllDay || isMidnight(t) {
+		return orgStamp(t, true, true, repeater, warning)
+	}
+	if local := t.In(time.Local); isMidnight(local) {
+		return orgStamp(local, true, true, repeater, warning)
+	}
+	return orgStamp(t, true, false, repeater, warning)
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+func orgLocal(t time.Time, allDay bool) time.Time {
+	if allDay {
+		// a date is the same day wherever it is read
+		return t
+	}
+	return t.In(time.Local)
+}
+
+func orgWrap(s string, active bool, repeater, warning string) string {
+	if repeater != "" {
+		s += " " + repeater
+	}
+	if warning != "" {
+		s += " " + warning
+	}
+	if active {
+		return "<" + s + ">"
+	}
+	return "[" + s + "]"
+}
+
+// orgRepeater returns the repeater cookie for rec; only a plain period is
+// representable.
+func orgRepeater(rec *model.Recurrence) string {
+	if rec == nil {
+		return ""
+	}
+	n := rec.Interval
+	if n < 1 {
+		n = 1
+	}
+	units := map[model.FreqType]string{
+		model.FreqDaily:   "d",
+		model.FreqWeekly:  "w",
+		model.FreqMonthly: "m",
+		model.FreqYearly:  "y",
+	}
+	u, ok := units[rec.Freq]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("+%d%s", n, u)
+}
+
+// orgWarning turns the first reminder before the deadline into a warning
+// period, in days when it is a whole number of them.
+func orgWarning(reminders []model.Reminder) string {
+	for _, r := range reminders {
+		if r.Offset == nil || *r.Offset >= 0 || *r.Offset%time.Hour != 0 {
+			continue
+		}
+		hours := int(-*r.Offset / time.Hour)
+		if hours%24 == 0 {
+			return fmt.Sprintf("-%dd", hours/24)
+		}
+		return fmt.Sprintf("-%dh", hours)
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/ics"
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+func TestOrgWriterRoundtrip(t *testing.T) {
+	input := `#+TODO: TODO NEXT | DONE CANCELLED
+
+* NEXT [#A] Ship release :work:urgent:
+SCHEDULED: <2026-03-10 Tue 09:00 +1w> DEADLINE: <2026-03-15 Sun -2d>
+:PROPERTIES:
+:ID: 5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f
+:LOCATION: Office
+:CUSTOM: keep me
+:EFFORT: 1:00
+:END:
+Check the changelog.
+ * not a headline
+- [X] Write notes
+- [ ] [#C] Tag build
+* DONE File taxes
+CLOSED: [2026-03-04 Wed 18:00] DEADLINE: <2026-03-05 Thu>
+* TODO Pay rent
+SCHEDULED: <2026-03-25 Wed> DEADLINE: <2026-04-01 Wed .+1m -3d>
+* Team offsite
+<2026-04-02 Thu 10:00-16:30>
+* Conference
+<2026-05-11 Mon>--<2026-05-13 Wed>
+* Retro notes
+[2026-03-01 Sun]
+Went well.
+* CANCELLED Old idea
+`
+	col, err := parsers.NewOrgParser().Parse(context.Background(), strings.NewReader(input), "plans.org")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewOrgWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestOrgICSRoundtrip(t *testing.T) {
+	start := time.Date(2026, 4, 2, 10, 0, 0, 0, time.Local)
+	end := start.Add(90 * time.Minute)
+	due := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{UID: "offsite-1", Title: "Team offsite", ItemType: model.ItemTypeEvent, StartTime: &start, EndTime: &end, Location: "HQ"},
+			{
+				UID:        "taxes-1",
+				Title:      "File taxes",
+				ItemType:   model.ItemTypeTask,
+				Status:     model.StatusPending,
+				Priority:   model.PriorityHigh,
+				DueDate:    &due,
+				IsAllDay:   true,
+				Recurrence: &model.Recurrence{Freq: model.FreqYearly, Interval: 1},
+			},
+		},
+	}
+	var org bytes.Buffer
+	if err := NewOrgWriter().Write(context.Background(), col, &org); err != nil {
+		t.Fatalf("org write error: %v", err)
+	}
+	fromOrg, err := parsers.NewOrgParser().Parse(context.Background(), bytes.NewReader(org.Bytes()), "x.org")
+	if err != nil {
+		t.Fatalf("org parse error: %v", err)
+	}
+	var icsOut bytes.Buffer
+	if err := ics.NewWriter().Write(context.Background(), fromOrg, &icsOut); err != nil {
+		t.Fatalf("ics write error: %v", err)
+	}
+	fromICS, err := ics.NewParser().Parse(context.Background(), bytes.NewReader(icsOut.Bytes()), "x.ics")
+	if err != nil {
+		t.Fatalf("ics parse error: %v", err)
+	}
+	var again bytes.Buffer
+	if err := NewOrgWriter().Write(context.Background(), fromICS, &again); err != nil {
+		t.Fatalf("org write error: %v", err)
+	}
+	if again.String() != org.String() {
+		t.Errorf("org -> ics -> org changed the file:\n%s\nwant:\n%s", again.String(), org.String())
+	}
+}
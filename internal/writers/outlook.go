@@ -7,8 +7,10 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/tzmap"
 )
 
 type OutlookWriter struct{}
@@ -34,13 +36,17 @@ func (w *OutlookWriter) Write(ctx context.Context, collection *model.CalendarCol
 		"Subject", "Start Date", "Start Time", "End Date", "End Time",
 		"All day event", "Reminder on/off", "Reminder Date", "Reminder Time",
 		"Categories", "Description", "Location", "Priority",
+		"Meeting Organizer", "Required Attendees", "Optional Attendees", "Meeting Resources",
 	}
+	// columns of an Outlook export salja has no field for are written back
+	extra := extensionColumns(collection, "outlook", header)
+	header = append(header, extra...)
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	for _, item := range collection.Items {
-		row := make([]string, 13)
+		row := make([]string, len(header))
 		row[0] = item.Title
 
 		if item.StartTime != nil {
@@ -48,11 +54,9 @@ func (w *OutlookWriter) Write(ctx context.Context, collection *model.CalendarCol
 				row[1] = item.StartTime.Format("1/2/2006")
 				row[2] = ""
 			} else {
-				row[1] = item.StartTime.Format("1/2/2006")
-				row[2] = item.StartTime.Format("3:04:05 PM")
-				if item.Timezone != "" && item.Timezone != "UTC" {
-					row[2] += " " + item.Timezone
-				}
+				t, zone := outlookZoned(*item.StartTime, item.Timezone)
+				row[1] = t.Format("1/2/2006")
+				row[2] = t.Format("3:04:05 PM") + zone
 			}
 		}
 
@@ -61,11 +65,9 @@ func (w *OutlookWriter) Write(ctx context.Context, collection *model.CalendarCol
 				row[3] = item.EndTime.Format("1/2/2006")
 				row[4] = ""
 			} else {
-				row[3] = item.EndTime.Format("1/2/2006")
-				row[4] = item.EndTime.Format("3:04:05 PM")
-				if item.Timezone != "" && item.Timezone != "UTC" {
-					row[4] += " " + item.Timezone
-				}
+				t, zone := outlookZoned(*item.EndTime, item.Timezone)
+				row[3] = t.Format("1/2/2006")
+				row[4] = t.Format("3:04:05 PM") + zone
 			}
 		}
 
@@ -76,8 +78,11 @@ func (w *OutlookWriter) Write(ctx context.Context, collection *model.CalendarCol
 		}
 
 		row[6] = "False"
-		row[7] = ""
-		row[8] = ""
+		if v, ok := item.ExtensionValue("outlook", "Reminder on/off"); ok {
+			row[6] = v
+		}
+		row[7], _ = item.ExtensionValue("outlook", "Reminder Date")
+		row[8], _ = item.ExtensionValue("outlook", "Reminder Time")
 
 		if len(item.Tags) > 0 {
 			row[9] = strings.Join(item.Tags, "; ")
@@ -97,6 +102,26 @@ func (w *OutlookWriter) Write(ctx context.Context, collection *model.CalendarCol
 			row[12] = "Normal"
 		}
 
+		if item.Organizer != nil {
+			row[13] = outlookPerson(*item.Organizer)
+		}
+		var required, optional, resources []string
+		for _, a := range item.Attendees {
+			switch a.Role {
+			case model.RoleOptional:
+				optional = append(optional, outlookPerson(a))
+			case model.RoleNonParticipant:
+				resources = append(resources, outlookPerson(a))
+			default:
+				required = append(required, outlookPerson(a))
+			}
+		}
+		row[14] = strings.Join(required, "; ")
+		row[15] = strings.Join(optional, "; ")
+		row[16] = strings.Join(resources, "; ")
+
+		copy(row[17:], extensionCells(&item, "outlook", extra))
+
 		if err := csvWriter.Write(row); err != nil {
 			return err
 		}
@@ -104,3 +129,30 @@ func (w *OutlookWriter) Write(ctx context.Context, collection *model.CalendarCol
 
 	return nil
 }
+
+// outlookZoned returns t on the wall clock of tz and the suffix naming tz
+// after a time cell, by its Windows name when it has one.
+func outlookZoned(t time.Time, tz string) (time.Time, string) {
+	if tz == "" || tz == "UTC" {
+		return t, ""
+	}
+	if loc, err := time.LoadLocation(tz); err == nil {
+		t = t.In(loc)
+	}
+	if name, ok := tzmap.ToWindows(tz); ok {
+		tz = name
+	}
+	return t, " " + tz
+}
+
+// outlookPerson writes an attendee as "Name <address>", or whichever of the
+// two is known.
+func outlookPerson(a model.Attendee) string {
+	switch {
+	case a.Name != "" && a.Email != "":
+		return a.Name + " <" + a.Email + ">"
+	case a.Email != "":
+		return a.Email
+	}
+	return a.Name
+}
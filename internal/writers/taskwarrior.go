@@ -0,0 +1,314 @@
+package writers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/google/uuid"
+)
+
+const taskwarriorDateLayout = "20060102T150405Z"
+
+// taskwarriorNamespace derives stable task UUIDs from salja UIDs, so importing
+// the same file twice updates tasks instead of duplicating them.
+var taskwarriorNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/gongahkia/salja#taskwarrior"))
+
+type TaskwarriorWriter struct{}
+
+func NewTaskwarriorWriter() *TaskwarriorWriter {
+	return &TaskwarriorWriter{}
+}
+
+type taskwarriorExport struct {
+	UUID        string                  `json:"uuid"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry"`
+	Modified    string                  `json:"modified,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Scheduled   string                  `json:"scheduled,omitempty"`
+	Wait        string                  `json:"wait,omitempty"`
+	Until       string                  `json:"until,omitempty"`
+	Recur       string                  `json:"recur,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Depends     []string                `json:"depends,omitempty"`
+	Annotations []taskwarriorAnnotation `json:"annotations,omitempty"`
+}
+
+type taskwarriorAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+func (w *TaskwarriorWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create Taskwarrior JSON: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(ctx, collection, f)
+}
+
+// Write produces a JSON array for `task import`, one task per line as `task
+// export` does. Subtasks become tasks of their own that the parent depends on.
+func (w *TaskwarriorWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	fallback := collection.ExportDate
+	if fallback.IsZero() {
+		fallback = time.Now()
+	}
+
+	var tasks []taskwarriorExport
+	for i, item := range collection.Items {
+		tasks = append(tasks, w.itemToTasks(&item, i, fallback)...)
+	}
+
+	if _, err := io.WriteString(writer, "["); err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if i == 0 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(writer, sep+string(data)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(writer, "\n]\n")
+	return err
+}
+
+func (w *TaskwarriorWriter) itemToTasks(item *model.CalendarItem, index int, fallback time.Time) []taskwarriorExport {
+	entry := fallback
+	if item.CreatedAt != nil {
+		entry = *item.CreatedAt
+	}
+	task := taskwarriorExport{
+		UUID:        taskwarriorUUID(item, index),
+		Description: item.Title,
+		Status:      exportTaskwarriorStatus(item.Status),
+		Entry:       formatTaskwarriorDate(entry),
+		Priority:    exportTaskwarriorPriority(item.Priority),
+	}
+	if item.UpdatedAt != nil {
+		task.Modified = formatTaskwarriorDate(*item.UpdatedAt)
+	}
+	if task.Status == "completed" || task.Status == "deleted" {
+		end := entry
+		if item.CompletionDate != nil {
+			end = *item.CompletionDate
+		} else if item.UpdatedAt != nil {
+			end = *item.UpdatedAt
+		}
+		task.End = formatTaskwarriorDate(end)
+	}
+
+	for _, tag := range item.Tags {
+		if name, ok := strings.CutPrefix(tag, model.ProjectTagPrefix); ok && task.Project == "" {
+			task.Project = name
+			continue
+		}
+		// Taskwarrior tags are single words
+		if tag = strings.Join(strings.Fields(tag), "_"); tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+
+	due := item.DueDate
+	if due == nil && item.ItemType == model.ItemTypeEvent {
+		due = item.EndTime
+	}
+	if due != nil {
+		task.Due = formatTaskwarriorDate(*due)
+	}
+	if item.StartTime != nil {
+		task.Scheduled = formatTaskwarriorDate(*item.StartTime)
+	}
+	if wait := earliestReminder(item, due); wait != nil {
+		task.Wait = formatTaskwarriorDate(*wait)
+	}
+
+	// Taskwarrior only recurs tasks with a due date
+	if rec := item.Recurrence; rec != nil {
+		if due == nil && item.StartTime != nil {
+			task.Due = formatTaskwarriorDate(*item.StartTime)
+		}
+		if period := formatTaskwarriorRecur(rec); period != "" && task.Due != "" {
+			task.Recur = period
+			if rec.Until != nil {
+				task.Until = formatTaskwarriorDate(*rec.Until)
+			}
+			if task.Status == "pending" {
+				task.Status = "recurring"
+			}
+		}
+	}
+
+	// annotations are keyed by their entry time, so each gets its own second
+	for i, line := range strings.Split(item.Description, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		task.Annotations = append(task.Annotations, taskwarriorAnnotation{
+			Entry:       formatTaskwarriorDate(entry.Add(time.Duration(i) * time.Second)),
+			Description: line,
+		})
+	}
+
+	// dependencies kept as items of their own are linked by UUID
+	if links, ok := item.ExtensionValue("taskwarrior", "depends"); ok && links != "" {
+		task.Depends = strings.Split(links, ",")
+	}
+
+	tasks := []taskwarriorExport{task}
+	for i, st := range item.Subtasks {
+		// a dependency read from Taskwarrior keeps its UUID, so importing the
+		// file updates it instead of adding a copy
+		id, err := uuid.Parse(st.UID)
+		if err != nil {
+			id = uuid.NewSHA1(taskwarriorNamespace, []byte(task.UUID+"/subtask/"+strconv.Itoa(i)))
+		}
+		sub := taskwarriorExport{
+			UUID:        id.String(),
+			Description: st.Title,
+			Status:      exportTaskwarriorStatus(st.Status),
+			Entry:       task.Entry,
+			Priority:    exportTaskwarriorPriority(st.Priority),
+			Project:     task.Project,
+		}
+		if sub.Status == "completed" || sub.Status == "deleted" {
+			sub.End = task.Entry
+			if task.End != "" {
+				sub.End = task.End
+			}
+		}
+		tasks[0].Depends = append(tasks[0].Depends, sub.UUID)
+		tasks = append(tasks, sub)
+	}
+	return tasks
+}
+
+// taskwarriorUUID keeps an item UID that already is a UUID, as it is for
+// items read from Taskwarrior, and derives one otherwise.
+func taskwarriorUUID(item *model.CalendarItem, index int) string {
+	if id, err := uuid.Parse(item.UID); err == nil {
+		return id.String()
+	}
+	name := item.UID
+	if name == "" {
+		name = fmt.Sprintf("%d/%s", index, item.Title)
+	}
+	return uuid.NewSHA1(taskwarriorNamespace, []byte(name)).String()
+}
+
+// earliestReminder resolves the item's reminders against its due or start
+// time and returns the first, which becomes the wait date.
+func earliestReminder(item *model.CalendarItem, due *time.Time) *time.Time {
+	anchor := due
+	if anchor == nil {
+		anchor = item.StartTime
+	}
+	var earliest *time.Time
+	for _, r := range item.Reminders {
+		var t time.Time
+		switch {
+		case r.AbsoluteTime != nil:
+			t = *r.AbsoluteTime
+		case r.Offset != nil && anchor != nil:
+			t = anchor.Add(*r.Offset)
+		default:
+			continue
+		}
+		if earliest == nil || t.Before(*earliest) {
+			earliest = &t
+		}
+	}
+	return earliest
+}
+
+func formatTaskwarriorDate(t time.Time) string {
+	return t.UTC().Format(taskwarriorDateLayout)
+}
+
+func exportTaskwarriorStatus(s model.Status) string {
+	switch s {
+	case model.StatusCompleted:
+		return "completed"
+	case model.StatusCancelled:
+		return "deleted"
+	default:
+		return "pending"
+	}
+}
+
+func exportTaskwarriorPriority(p model.Priority) string {
+	switch {
+	case p >= model.PriorityHigh:
+		return "H"
+	case p == model.PriorityMedium:
+		return "M"
+	case p > model.PriorityNone:
+		return "L"
+	default:
+		return ""
+	}
+}
+
+// formatTaskwarriorRecur returns the Taskwarrior period for rec. Only the
+// frequency and interval carry over, plus the weekdays shortcut.
+func formatTaskwarriorRecur(rec *model.Recurrence) string {
+	n := rec.Interval
+	if n < 1 {
+		n = 1
+	}
+	if rec.Freq == model.FreqWeekly && n == 1 && isWeekdays(rec.ByDay) {
+		return "weekdays"
+	}
+	units := map[model.FreqType][2]string{
+		model.FreqDaily:   {"daily", "d"},
+		model.FreqWeekly:  {"weekly", "w"},
+		model.FreqMonthly: {"monthly", "mo"},
+		model.FreqYearly:  {"yearly", "y"},
+	}
+	u, ok := units[rec.Freq]
+	if !ok {
+		return ""
+	}
+	if n == 1 {
+		return u[0]
+	}
+	if rec.Freq == model.FreqMonthly && n == 3 {
+		return "quarterly"
+	}
+	return strconv.Itoa(n) + u[1]
+}
+
+func isWeekdays(days []model.Weekday) bool {
+	if len(days) != 5 {
+		return false
+	}
+	want := map[model.Weekday]bool{
+		model.WeekdayMO: true, model.WeekdayTU: true, model.WeekdayWE: true, model.WeekdayTH: true, model.WeekdayFR: true,
+	}
+	for _, d := range days {
+		if !want[d] {
+			return false
+		}
+	}
+	return true
+}
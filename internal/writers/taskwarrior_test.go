@@ -0,0 +1,150 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+func TestTaskwarriorWriterImportJSON(t *testing.T) {
+	due := time.Date(2026, 3, 15, 17, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	before := -2 * time.Hour
+	col := &model.CalendarCollection{
+		ExportDate: created,
+		Items: []model.CalendarItem{
+			{
+				UID:         "not-a-uuid",
+				Title:       "Ship release",
+				Description: "check changelog\n\nping QA",
+				DueDate:     &due,
+				CreatedAt:   &created,
+				Priority:    model.PriorityHighest,
+				Tags:        []string{"release day", "project:Work"},
+				Reminders:   []model.Reminder{{Offset: &before}},
+				Recurrence: &model.Recurrence{
+					Freq:     model.FreqWeekly,
+					Interval: 1,
+					ByDay:    []model.Weekday{model.WeekdayMO, model.WeekdayTU, model.WeekdayWE, model.WeekdayTH, model.WeekdayFR},
+				},
+				Subtasks: []model.Subtask{{Title: "Write notes", Status: model.StatusCompleted}},
+			},
+			{Title: "Old idea", Status: model.StatusCancelled, Priority: model.PriorityLowest},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewTaskwarriorWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	var tasks []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &tasks); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks (one from the subtask), got %d", len(tasks))
+	}
+
+	ship := tasks[0]
+	for field, want := range map[string]interface{}{
+		"description": "Ship release",
+		"status":      "recurring",
+		"due":         "20260315T170000Z",
+		"wait":        "20260315T150000Z",
+		"recur":       "weekdays",
+		"priority":    "H",
+		"project":     "Work",
+		"entry":       "20260301T090000Z",
+	} {
+		if ship[field] != want {
+			t.Errorf("%s = %v, want %v", field, ship[field], want)
+		}
+	}
+	if tags := ship["tags"].([]interface{}); len(tags) != 1 || tags[0] != "release_day" {
+		t.Errorf("tags = %v", tags)
+	}
+	if anns := ship["annotations"].([]interface{}); len(anns) != 2 {
+		t.Errorf("annotations = %v", anns)
+	}
+	sub := tasks[1]
+	if deps := ship["depends"].([]interface{}); len(deps) != 1 || deps[0] != sub["uuid"] {
+		t.Errorf("depends = %v, subtask uuid %v", deps, sub["uuid"])
+	}
+	if sub["status"] != "completed" || sub["end"] == nil || sub["project"] != "Work" {
+		t.Errorf("subtask = %v", sub)
+	}
+	if tasks[2]["status"] != "deleted" || tasks[2]["priority"] != "L" || tasks[2]["end"] == nil {
+		t.Errorf("deleted task = %v", tasks[2])
+	}
+
+	// derived UUIDs are stable, so re-importing updates instead of duplicating
+	var again bytes.Buffer
+	if err := NewTaskwarriorWriter().Write(context.Background(), col, &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != buf.String() {
+		t.Error("writing the same collection twice gave different output")
+	}
+}
+
+func TestTaskwarriorWriterRoundtrip(t *testing.T) {
+	input := `[
+{"uuid":"5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f","description":"Ship release","status":"pending","entry":"20260301T090000Z","due":"20260315T170000Z","priority":"M","project":"Work","tags":["ops"],"depends":["0d9c7f1e-1111-4a2b-8c3d-000000000001","0d9c7f1e-1111-4a2b-8c3d-000000000002"],"annotations":[{"entry":"20260301T091000Z","description":"check changelog"}]},
+{"uuid":"0d9c7f1e-1111-4a2b-8c3d-000000000001","description":"Write notes","status":"pending","entry":"20260301T090000Z"},
+{"uuid":"0d9c7f1e-1111-4a2b-8c3d-000000000002","description":"Book venue","status":"pending","entry":"20260301T090000Z","due":"20260310T120000Z","tags":["phone"]},
+{"uuid":"7f1e2d3c-aaaa-4bbb-8ccc-000000000010","description":"Water plants","status":"recurring","entry":"20260301T090000Z","due":"20260302T080000Z","recur":"quarterly"}
+]`
+	p := parsers.NewTaskwarriorParser()
+	col, err := p.Parse(context.Background(), strings.NewReader(input), "taskwarrior.json")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewTaskwarriorWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	// every task keeps its UUID, so `task import` updates rather than adds
+	var written []struct {
+		UUID    string   `json:"uuid"`
+		Depends []string `json:"depends"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &written); err != nil {
+		t.Fatalf("written JSON: %v", err)
+	}
+	var uuids []string
+	for _, w := range written {
+		uuids = append(uuids, w.UUID)
+	}
+	if strings.Join(uuids, ",") != "5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f,0d9c7f1e-1111-4a2b-8c3d-000000000001,0d9c7f1e-1111-4a2b-8c3d-000000000002,7f1e2d3c-aaaa-4bbb-8ccc-000000000010" ||
+		strings.Join(written[0].Depends, ",") != "0d9c7f1e-1111-4a2b-8c3d-000000000002,0d9c7f1e-1111-4a2b-8c3d-000000000001" {
+		t.Errorf("written tasks = %+v", written)
+	}
+
+	back, err := p.Parse(context.Background(), &buf, "taskwarrior.json")
+	if err != nil {
+		t.Fatalf("reparse error: %v", err)
+	}
+	if len(back.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(back.Items))
+	}
+	ship := back.Items[0]
+	if ship.UID != "5b6e4b8a-3c1e-4f60-9f0a-1a2b3c4d5e6f" || ship.Priority != model.PriorityMedium || ship.Description != "check changelog" {
+		t.Errorf("ship = %+v", ship)
+	}
+	if strings.Join(ship.Tags, ",") != "ops,project:Work" || len(ship.Subtasks) != 1 || ship.Subtasks[0].Title != "Write notes" {
+		t.Errorf("tags/subtasks = %v %+v", ship.Tags, ship.Subtasks)
+	}
+	if venue := back.Items[1]; venue.Title != "Book venue" || venue.DueDate == nil {
+		t.Errorf("dependency item = %+v", venue)
+	}
+	if rec := back.Items[2].Recurrence; rec == nil || rec.Freq != model.FreqMonthly || rec.Interval != 3 {
+		t.Errorf("recurrence = %+v", rec)
+	}
+}
@@ -37,12 +37,15 @@ func (w *TickTickWriter) Write(ctx context.Context, collection *model.CalendarCo
 		"start_date", "due_date", "reminder", "repeat", "priority",
 		"status", "created_time", "completed_time", "timezone", "is_all_day",
 	}
+	// columns of a TickTick backup salja has no field for are written back
+	extra := extensionColumns(collection, "ticktick", header)
+	header = append(header, extra...)
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	for _, item := range collection.Items {
-		row := w.itemToRow(&item)
+		row := w.itemToRow(&item, len(header), extra)
 		if err := csvWriter.Write(row); err != nil {
 			return err
 		}
@@ -51,11 +54,11 @@ func (w *TickTickWriter) Write(ctx context.Context, collection *model.CalendarCo
 	return nil
 }
 
-func (w *TickTickWriter) itemToRow(item *model.CalendarItem) []string {
-	row := make([]string, 16)
+func (w *TickTickWriter) itemToRow(item *model.CalendarItem, width int, extra []string) []string {
+	row := make([]string, width)
 
-	row[0] = ""
-	row[1] = ""
+	row[0], _ = item.ExtensionValue("ticktick", "folder")
+	row[1], _ = item.ExtensionValue("ticktick", "list")
 	row[2] = item.Title
 
 	if len(item.Tags) > 0 {
@@ -87,7 +90,7 @@ func (w *TickTickWriter) itemToRow(item *model.CalendarItem) []string {
 		row[7] = item.DueDate.Format(time.RFC3339)
 	}
 
-	row[8] = ""
+	row[8], _ = item.ExtensionValue("ticktick", "reminder")
 
 	if item.Recurrence != nil {
 		row[9] = exportTickTickRepeat(item.Recurrence)
@@ -101,7 +104,7 @@ func (w *TickTickWriter) itemToRow(item *model.CalendarItem) []string {
 		row[11] = "0"
 	}
 
-	row[12] = ""
+	row[12], _ = item.ExtensionValue("ticktick", "created_time")
 
 	if item.CompletionDate != nil {
 		row[13] = item.CompletionDate.Format(time.RFC3339)
@@ -117,6 +120,8 @@ func (w *TickTickWriter) itemToRow(item *model.CalendarItem) []string {
 		row[15] = "false"
 	}
 
+	copy(row[16:], extensionCells(item, "ticktick", extra))
+
 	return row
 }
 
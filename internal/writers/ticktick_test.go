@@ -175,3 +175,32 @@ func TestTickTickWriterAllDay(t *testing.T) {
 		t.Errorf("non-all day item: is_all_day = %q", records[2][allDayIdx])
 	}
 }
+
+func TestTickTickWriterKeepsUnmappedColumns(t *testing.T) {
+	input := `folder,list,title,content,reminder,status,kind
+Work,Inbox,Call bank,,PT0S,0,TEXT`
+
+	col, err := parsers.NewTickTickParser().Parse(context.Background(), strings.NewReader(input), "test.csv")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if v, ok := col.Items[0].ExtensionValue("ticktick", "kind"); !ok || v != "TEXT" {
+		t.Errorf("kind = %q, %v", v, ok)
+	}
+
+	var buf bytes.Buffer
+	if err := NewTickTickWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(records[0][16:], ","); got != "kind" {
+		t.Errorf("extra header = %q", got)
+	}
+	row := records[1]
+	if row[0] != "Work" || row[1] != "Inbox" || row[8] != "PT0S" || row[16] != "TEXT" {
+		t.Errorf("row = %q", row)
+	}
+}
@@ -34,12 +34,15 @@ func (w *TodoistWriter) Write(ctx context.Context, collection *model.CalendarCol
 		"TYPE", "CONTENT", "DESCRIPTION", "PRIORITY", "INDENT",
 		"AUTHOR", "RESPONSIBLE", "DATE", "DATE_LANG", "TIMEZONE",
 	}
+	// columns of a Todoist export salja has no field for are written back
+	extra := extensionColumns(collection, "todoist", header)
+	header = append(header, extra...)
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	for _, item := range collection.Items {
-		rows := w.itemToRows(&item, 0)
+		rows := w.itemToRows(&item, 0, header, extra)
 		for _, row := range rows {
 			if err := csvWriter.Write(row); err != nil {
 				return err
@@ -50,32 +53,37 @@ func (w *TodoistWriter) Write(ctx context.Context, collection *model.CalendarCol
 	return nil
 }
 
-func (w *TodoistWriter) itemToRows(item *model.CalendarItem, indent int) [][]string {
+func (w *TodoistWriter) itemToRows(item *model.CalendarItem, indent int, header, extra []string) [][]string {
 	var rows [][]string
 
-	mainRow := make([]string, 10)
+	mainRow := make([]string, len(header))
 	mainRow[0] = "task"
 	mainRow[1] = item.Title
 	mainRow[2] = item.Description
 	mainRow[3] = exportTodoistPriority(item.Priority)
 	mainRow[4] = fmt.Sprintf("%d", indent)
-	mainRow[5] = ""
-	mainRow[6] = ""
+	mainRow[5], _ = item.ExtensionValue("todoist", "AUTHOR")
+	mainRow[6], _ = item.ExtensionValue("todoist", "RESPONSIBLE")
 
 	if item.DueDate != nil {
 		mainRow[7] = item.DueDate.Format("2006-01-02")
 	}
 
 	mainRow[8] = "en"
+	if lang, ok := item.ExtensionValue("todoist", "DATE_LANG"); ok {
+		mainRow[8] = lang
+	}
 
 	if item.Timezone != "" {
 		mainRow[9] = item.Timezone
 	}
 
+	copy(mainRow[10:], extensionCells(item, "todoist", extra))
+
 	rows = append(rows, mainRow)
 
 	for _, subtask := range item.Subtasks {
-		subtaskRow := make([]string, 10)
+		subtaskRow := make([]string, len(header))
 		subtaskRow[0] = "task"
 		subtaskRow[1] = subtask.Title
 		subtaskRow[2] = ""
@@ -164,3 +164,35 @@ func TestTodoistWriterHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestTodoistWriterKeepsUnmappedColumns(t *testing.T) {
+	input := `TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE,DURATION,DURATION_UNIT
+task,Call bank,,4,1,Ana (123),,2024-01-15,de,,30,minute
+task,Pay rent,,4,1,,,,,,,`
+
+	col, err := parsers.NewTodoistParser().Parse(context.Background(), strings.NewReader(input), "test.csv")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if v, ok := col.Items[0].ExtensionValue("todoist", "DURATION"); !ok || v != "30" {
+		t.Errorf("DURATION = %q, %v", v, ok)
+	}
+
+	var buf bytes.Buffer
+	if err := NewTodoistWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(records[0][10:], ","); got != "DURATION,DURATION_UNIT" {
+		t.Errorf("extra header = %q", got)
+	}
+	if got := strings.Join(records[1], ","); got != "task,Call bank,,4,0,Ana (123),,2024-01-15,de,,30,minute" {
+		t.Errorf("row = %q", got)
+	}
+	if got := strings.Join(records[2], ","); got != "task,Pay rent,,4,0,,,,en,,," {
+		t.Errorf("row = %q", got)
+	}
+}
@@ -0,0 +1,177 @@
+package writers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+const todoTxtDateLayout = "2006-01-02"
+
+type TodoTxtWriter struct{}
+
+func NewTodoTxtWriter() *TodoTxtWriter {
+	return &TodoTxtWriter{}
+}
+
+func (w *TodoTxtWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create todo.txt file: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(ctx, collection, f)
+}
+
+func (w *TodoTxtWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	bw := bufio.NewWriter(writer)
+	for _, item := range collection.Items {
+		if _, err := bw.WriteString(todoTxtLine(&item) + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// todoTxtLine formats one task. todo.txt is one line per task, so the title
+// is flattened and the description and subtasks are not written.
+func todoTxtLine(item *model.CalendarItem) string {
+	var parts []string
+	// todo.txt has no cancelled state; closing the task keeps it off the list
+	completed := item.Status == model.StatusCompleted || item.Status == model.StatusCancelled
+	letter := exportTodoTxtPriority(item.Priority)
+
+	// a completed task has a creation date only after its completion date
+	created := item.CreatedAt
+	if completed {
+		parts = append(parts, "x")
+		if item.CompletionDate != nil {
+			parts = append(parts, item.CompletionDate.Format(todoTxtDateLayout))
+		} else {
+			created = nil
+		}
+	} else if letter != "" {
+		parts = append(parts, "("+letter+")")
+	}
+	if created != nil {
+		parts = append(parts, created.Format(todoTxtDateLayout))
+	}
+
+	parts = append(parts, todoTxtTitleWords(item.Title)...)
+
+	for _, tag := range item.Tags {
+		tag = strings.Join(strings.Fields(tag), "_")
+		if tag == "" {
+			continue
+		}
+		if project, ok := strings.CutPrefix(tag, model.ProjectTagPrefix); ok {
+			parts = append(parts, "+"+project)
+		} else {
+			parts = append(parts, "@"+tag)
+		}
+	}
+
+	due := item.DueDate
+	if due == nil && item.ItemType == model.ItemTypeEvent {
+		due = item.StartTime
+	}
+	if due != nil {
+		parts = append(parts, "due:"+due.Format(todoTxtDateLayout))
+	}
+	if item.StartTime != nil && due != item.StartTime {
+		parts = append(parts, "t:"+item.StartTime.Format(todoTxtDateLayout))
+	}
+	if rec := exportTodoTxtRec(item.Recurrence); rec != "" {
+		// a rec: read without + recurs from completion; it is written as
+		// read while the rule is unchanged
+		if read, ok := item.ExtensionValue("todotxt", "rec"); ok {
+			if r, err := parsers.ParseTodoTxtRec(read); err == nil && exportTodoTxtRec(r) == rec {
+				rec = read
+			}
+		}
+		parts = append(parts, "rec:"+rec)
+	}
+	if completed && letter != "" {
+		parts = append(parts, "pri:"+letter)
+	}
+	return strings.Join(parts, " ")
+}
+
+// todoTxtTitleWords returns the title's words in an order readers take for
+// text. x, a priority and a date only mean something at the start of a line,
+// so leading ones move behind the rest of the title; a word the parser would
+// read into a field, such as due:2026-04-01, is dropped, as is a title made
+// only of markup. Projects and contexts stay where they are.
+func todoTxtTitleWords(title string) []string {
+	var words []string
+	for _, word := range strings.Fields(title) {
+		if !parsers.IsTodoTxtField(word) {
+			words = append(words, word)
+		}
+	}
+	lead := 0
+	for lead < len(words) && isTodoTxtLeadWord(words[lead]) {
+		lead++
+	}
+	if lead == len(words) {
+		return nil
+	}
+	ordered := make([]string, 0, len(words))
+	ordered = append(ordered, words[lead:]...)
+	return append(ordered, words[:lead]...)
+}
+
+func isTodoTxtLeadWord(word string) bool {
+	return word == "x" || parsers.IsTodoTxtPriority(word) || parsers.IsTodoTxtDate(word)
+}
+
+func exportTodoTxtPriority(p model.Priority) string {
+	switch p {
+	case model.PriorityHighest:
+		return "A"
+	case model.PriorityHigh:
+		return "B"
+	case model.PriorityMedium:
+		return "C"
+	case model.PriorityLow:
+		return "D"
+	case model.PriorityLowest:
+		return "E"
+	default:
+		return ""
+	}
+}
+
+// exportTodoTxtRec returns the rec: value for rec, strict (+) since calendar
+// recurrence follows the due date. Rules beyond a plain period are not
+// representable and are dropped.
+func exportTodoTxtRec(rec *model.Recurrence) string {
+	if rec == nil {
+		return ""
+	}
+	n := rec.Interval
+	if n < 1 {
+		n = 1
+	}
+	if rec.Freq == model.FreqDaily && n == 1 && isWeekdays(rec.ByDay) {
+		return "+1b"
+	}
+	units := map[model.FreqType]string{
+		model.FreqDaily:   "d",
+		model.FreqWeekly:  "w",
+		model.FreqMonthly: "m",
+		model.FreqYearly:  "y",
+	}
+	u, ok := units[rec.Freq]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("+%d%s", n, u)
+}
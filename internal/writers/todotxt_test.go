@@ -0,0 +1,106 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+)
+
+func TestTodoTxtWriterLines(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	done := time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 3, 5, 17, 0, 0, 0, time.UTC)
+	col := &model.CalendarCollection{
+		Items: []model.CalendarItem{
+			{
+				Title:      "Call  Mom",
+				Priority:   model.PriorityHighest,
+				CreatedAt:  &created,
+				DueDate:    &due,
+				Tags:       []string{"project:Family", "phone calls"},
+				Recurrence: &model.Recurrence{Freq: model.FreqMonthly, Interval: 2},
+			},
+			{Title: "File taxes", Status: model.StatusCompleted, Priority: model.PriorityHigh, CompletionDate: &done, CreatedAt: &created},
+			{Title: "Dropped", Status: model.StatusCancelled, CreatedAt: &created},
+			{Title: "Team offsite", ItemType: model.ItemTypeEvent, StartTime: &due},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewTodoTxtWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	want := []string{
+		"(A) 2026-03-01 Call Mom +Family @phone_calls due:2026-03-05 rec:+2m",
+		"x 2026-03-04 2026-03-01 File taxes pri:B",
+		"x Dropped",
+		"Team offsite due:2026-03-05",
+	}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTodoTxtWriterRoundtrip(t *testing.T) {
+	input := `(B) 2026-03-01 Call Mom +Family @phone due:2026-03-05 t:2026-03-04 rec:+1b
+x 2026-03-04 2026-03-01 File taxes +Admin pri:C
+Water plants rec:+3d
+Back up laptop rec:1w
+`
+	p := parsers.NewTodoTxtParser()
+	col, err := p.Parse(context.Background(), strings.NewReader(input), "todo.txt")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewTodoTxtWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("round trip changed the file:\ngot:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestTodoTxtWriterKeepsTitleMarkupAsText(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		item  model.CalendarItem
+		line  string
+		title string
+	}{
+		{"leading x", model.CalendarItem{Title: "x marks the spot"}, "marks the spot x", "marks the spot x"},
+		{"leading priority", model.CalendarItem{Title: "(A) grade papers"}, "grade papers (A)", "grade papers (A)"},
+		{"leading date", model.CalendarItem{Title: "2026-04-01 launch"}, "launch 2026-04-01", "launch 2026-04-01"},
+		{"date after created", model.CalendarItem{Title: "2026-04-01 launch", CreatedAt: &created}, "2026-03-01 launch 2026-04-01", "launch 2026-04-01"},
+		{"field word", model.CalendarItem{Title: "Check due:2026-04-01 wording"}, "Check wording", "Check wording"},
+		{"key with text value", model.CalendarItem{Title: "Check due:tomorrow wording"}, "Check due:tomorrow wording", "Check due:tomorrow wording"},
+		{"backslash", model.CalendarItem{Title: `Fix \n handling`}, `Fix \n handling`, `Fix \n handling`},
+		{"plain words", model.CalendarItem{Title: "Read https://example.com at 10:30 x"}, "Read https://example.com at 10:30 x", "Read https://example.com at 10:30 x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			col := &model.CalendarCollection{Items: []model.CalendarItem{tt.item}}
+			if err := NewTodoTxtWriter().Write(context.Background(), col, &buf); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+			line := strings.TrimSuffix(buf.String(), "\n")
+			if line != tt.line {
+				t.Fatalf("line = %q, want %q", line, tt.line)
+			}
+			back, err := parsers.NewTodoTxtParser().Parse(context.Background(), &buf, "todo.txt")
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			got := back.Items[0]
+			if got.Title != tt.title || got.Status == model.StatusCompleted || got.Priority != model.PriorityNone || len(got.Tags) != 0 || got.DueDate != nil {
+				t.Errorf("read back %+v, want title %q and nothing else", got, tt.title)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package writers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/xlsx"
+)
+
+type XLSXWriter struct{}
+
+func NewXLSXWriter() *XLSXWriter {
+	return &XLSXWriter{}
+}
+
+func (w *XLSXWriter) WriteFile(ctx context.Context, collection *model.CalendarCollection, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create xlsx file: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(ctx, collection, f)
+}
+
+// xlsxSheets are the sheets written, in tab order, with their columns.
+var xlsxSheets = []struct {
+	name     string
+	itemType model.ItemType
+	header   []string
+}{
+	{"Events", model.ItemTypeEvent, []string{"Title", "Start", "End", "All Day", "Location", "Tags", "Description", "UID"}},
+	{"Tasks", model.ItemTypeTask, []string{"Title", "Status", "Priority", "Start", "Due", "Completed", "Tags", "Description", "UID"}},
+	{"Journal", model.ItemTypeJournal, []string{"Title", "Date", "Tags", "Description", "UID"}},
+}
+
+// Write produces one sheet per item type that occurs, each with a frozen
+// header row. Dates are typed date cells in local time; event times keep
+// their time of day, other dates lose it at midnight. Subtasks and
+// recurrence are appended to the description.
+func (w *XLSXWriter) Write(ctx context.Context, collection *model.CalendarCollection, writer io.Writer) error {
+	rows := make(map[model.ItemType][][]xlsx.Cell)
+	for _, item := range collection.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		switch item.ItemType {
+		case model.ItemTypeEvent:
+			rows[model.ItemTypeEvent] = append(rows[model.ItemTypeEvent], xlsxEventRow(&item))
+		case model.ItemTypeJournal:
+			rows[model.ItemTypeJournal] = append(rows[model.ItemTypeJournal], xlsxJournalRow(&item))
+		default:
+			rows[model.ItemTypeTask] = append(rows[model.ItemTypeTask], xlsxTaskRow(&item))
+		}
+	}
+
+	var sheets []xlsx.Sheet
+	for _, s := range xlsxSheets {
+		if len(rows[s.itemType]) > 0 {
+			sheets = append(sheets, xlsx.Sheet{Name: s.name, Header: s.header, Rows: rows[s.itemType]})
+		}
+	}
+	if len(sheets) == 0 {
+		// a workbook needs a sheet, so an empty one gets the task columns
+		sheets = append(sheets, xlsx.Sheet{Name: xlsxSheets[1].name, Header: xlsxSheets[1].header})
+	}
+	return xlsx.Write(writer, sheets)
+}
+
+func xlsxEventRow(item *model.CalendarItem) []xlsx.Cell {
+	return []xlsx.Cell{
+		xlsx.StringCell(item.Title),
+		xlsxEventCell(item.StartTime, item.IsAllDay),
+		xlsxEventCell(item.EndTime, item.IsAllDay),
+		xlsx.BoolCell(item.IsAllDay),
+		xlsx.StringCell(item.Location),
+		xlsx.StringCell(strings.Join(item.Tags, ", ")),
+		xlsx.StringCell(xlsxDescription(item)),
+		xlsx.StringCell(item.UID),
+	}
+}
+
+func xlsxTaskRow(item *model.CalendarItem) []xlsx.Cell {
+	return []xlsx.Cell{
+		xlsx.StringCell(item.Title),
+		xlsx.StringCell(statusName(item.Status)),
+		xlsx.StringCell(priorityName(item.Priority)),
+		xlsxDateCell(item.StartTime, item.IsAllDay),
+		xlsxDateCell(item.DueDate, item.IsAllDay),
+		xlsxDateCell(item.CompletionDate, false),
+		xlsx.StringCell(strings.Join(item.Tags, ", ")),
+		xlsx.StringCell(xlsxDescription(item)),
+		xlsx.StringCell(item.UID),
+	}
+}
+
+func xlsxJournalRow(item *model.CalendarItem) []xlsx.Cell {
+	return []xlsx.Cell{
+		xlsx.StringCell(item.Title),
+		xlsxDateCell(item.StartTime, item.IsAllDay),
+		xlsx.StringCell(strings.Join(item.Tags, ", ")),
+		xlsx.StringCell(xlsxDescription(item)),
+		xlsx.StringCell(item.UID),
+	}
+}
+
+func xlsxDescription(item *model.CalendarItem) string {
+	desc := flattenSubtasksToDescription(item.Description, item.Subtasks)
+	return recurrenceToDescription(desc, item.Recurrence)
+}
+
+// xlsxEventCell writes an event time as local time, which Excel shows without
+// a zone, and an all-day date as its day.
+func xlsxEventCell(t *time.Time, allDay bool) xlsx.Cell {
+	switch {
+	case t == nil:
+		return xlsx.Cell{}
+	case allDay:
+		// a date is the same day wherever it is read
+		return xlsx.DateCell(*t)
+	default:
+		return xlsx.DateTimeCell(t.In(time.Local))
+	}
+}
+
+// xlsxDateCell writes a task or journal date, as a date alone when it falls
+// on midnight.
+func xlsxDateCell(t *time.Time, allDay bool) xlsx.Cell {
+	if t == nil {
+		return xlsx.Cell{}
+	}
+	if allDay || isMidnight(*t) {
+		// task dates from most formats are midnight rather than flagged all-day
+		return xlsx.DateCell(*t)
+	}
+	local := t.In(time.Local)
+	if isMidnight(local) {
+		return xlsx.DateCell(local)
+	}
+	return xlsx.DateTimeCell(local)
+}
@@ -0,0 +1,137 @@
+package writers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gongahkia/salja/internal/model"
+	"github.com/gongahkia/salja/internal/parsers"
+	"github.com/gongahkia/salja/internal/xlsx"
+)
+
+func TestXLSXWriterRoundtrip(t *testing.T) {
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	end := start.Add(90 * time.Minute)
+	day := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	dayEnd := day.AddDate(0, 0, 1)
+	due := time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)
+	done := time.Date(2026, 3, 9, 17, 45, 0, 0, time.Local)
+	col := &model.CalendarCollection{Items: []model.CalendarItem{
+		{UID: "t1", Title: "File taxes", ItemType: model.ItemTypeTask, Status: model.StatusCompleted, Priority: model.PriorityHigh,
+			DueDate: &due, CompletionDate: &done, Tags: []string{"admin", "home"}, Description: "Receipts",
+			Subtasks: []model.Subtask{{Title: "Scan forms", Status: model.StatusCompleted}}},
+		{UID: "e1", Title: "Launch", ItemType: model.ItemTypeEvent, StartTime: &start, EndTime: &end, Location: "Room 4"},
+		{UID: "e2", Title: "Offsite", ItemType: model.ItemTypeEvent, StartTime: &day, EndTime: &dayEnd, IsAllDay: true},
+		{UID: "t2", Title: "Plan trip", Status: model.StatusPending},
+	}}
+
+	var buf bytes.Buffer
+	if err := NewXLSXWriter().Write(context.Background(), col, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	data := buf.Bytes()
+
+	wb, err := xlsx.Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	if strings.Join(wb.Sheets, ",") != "Events,Tasks" {
+		t.Errorf("sheets = %v", wb.Sheets)
+	}
+
+	// a frozen header and dates as numbers in a date format
+	sheet := readZipPart(t, data, "xl/worksheets/sheet1.xml")
+	if !strings.Contains(sheet, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`) {
+		t.Errorf("header row is not frozen:\n%s", sheet)
+	}
+	if !strings.Contains(sheet, `<c r="B2" s="3"><v>46091.375</v></c>`) || !strings.Contains(sheet, `<c r="B3" s="2"><v>46093</v></c>`) {
+		t.Errorf("dates are not typed cells:\n%s", sheet)
+	}
+
+	events, err := (&parsers.XLSXParser{Sheet: "Events"}).Parse(context.Background(), bytes.NewReader(data), "out.xlsx")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(events.Items) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events.Items))
+	}
+	launch := events.Items[0]
+	if launch.ItemType != model.ItemTypeEvent || launch.IsAllDay || launch.Location != "Room 4" || launch.UID != "e1" {
+		t.Errorf("launch = %+v", launch)
+	}
+	if launch.StartTime == nil || !launch.StartTime.Equal(start) || launch.EndTime == nil || !launch.EndTime.Equal(end) {
+		t.Errorf("launch times = %v %v", launch.StartTime, launch.EndTime)
+	}
+	offsite := events.Items[1]
+	if !offsite.IsAllDay || offsite.StartTime.Format("2006-01-02") != "2026-03-12" || offsite.EndTime.Format("2006-01-02") != "2026-03-13" {
+		t.Errorf("offsite = %+v", offsite)
+	}
+
+	tasks, err := (&parsers.XLSXParser{Sheet: "Tasks"}).Parse(context.Background(), bytes.NewReader(data), "out.xlsx")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(tasks.Items) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks.Items))
+	}
+	taxes := tasks.Items[0]
+	if taxes.ItemType != model.ItemTypeTask || taxes.Status != model.StatusCompleted || taxes.Priority != model.PriorityHigh {
+		t.Errorf("taxes = %+v", taxes)
+	}
+	if taxes.DueDate == nil || !taxes.DueDate.Equal(due) || taxes.CompletionDate == nil || !taxes.CompletionDate.Equal(done) {
+		t.Errorf("taxes dates = %v %v", taxes.DueDate, taxes.CompletionDate)
+	}
+	if strings.Join(taxes.Tags, ",") != "admin,home" || taxes.Description != "Receipts\n\n- [x] Scan forms" {
+		t.Errorf("taxes tags, description = %v %q", taxes.Tags, taxes.Description)
+	}
+	if trip := tasks.Items[1]; trip.Title != "Plan trip" || trip.Status != model.StatusPending || trip.Priority != model.PriorityNone {
+		t.Errorf("trip = %+v", trip)
+	}
+
+	// without a sheet named every sheet is read back
+	all, err := parsers.NewXLSXParser().Parse(context.Background(), bytes.NewReader(data), "out.xlsx")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	var uids []string
+	for _, item := range all.Items {
+		uids = append(uids, item.UID+":"+string(item.ItemType))
+	}
+	if got := strings.Join(uids, ","); got != "e1:event,e2:event,t1:task,t2:task" {
+		t.Errorf("all sheets = %s", got)
+	}
+}
+
+func TestXLSXWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewXLSXWriter().Write(context.Background(), &model.CalendarCollection{}, &buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	col, err := parsers.NewXLSXParser().Parse(context.Background(), bytes.NewReader(buf.Bytes()), "empty.xlsx")
+	if err != nil || len(col.Items) != 0 {
+		t.Errorf("empty workbook = %+v, %v", col, err)
+	}
+}
+
+func readZipPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
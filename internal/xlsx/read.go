@@ -0,0 +1,283 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File is a workbook opened for reading.
+type File struct {
+	// Sheets holds the sheet names in tab order.
+	Sheets []string
+	// Date1904 is set for workbooks in the 1904 date system, the default of
+	// older Mac Excel.
+	Date1904 bool
+
+	zr     *zip.Reader
+	parts  map[string]string
+	shared []string
+}
+
+// Row is a row of a sheet; Num is its 1-based row number and Cells its
+// values from column A, blank where a cell is missing.
+type Row struct {
+	Num   int
+	Cells []Cell
+}
+
+type xmlRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xmlWorkbook struct {
+	Pr struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name  string     `xml:"name,attr"`
+		Attrs []xml.Attr `xml:",any,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xmlRichText is a shared or inline string: plain text or runs of
+// formatted text, whose phonetic guides are left out.
+type xmlRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xmlRichText) text() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var b strings.Builder
+	b.WriteString(rt.T)
+	for _, r := range rt.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xmlWorksheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R  string      `xml:"r,attr"`
+			T  string      `xml:"t,attr"`
+			V  string      `xml:"v"`
+			IS xmlRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Open reads the workbook's sheet list and shared strings.
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx workbook: %w", err)
+	}
+	f := &File{zr: zr, parts: make(map[string]string)}
+
+	workbook := "xl/workbook.xml"
+	var rootRels xmlRelationships
+	if err := f.decode("_rels/.rels", &rootRels); err == nil {
+		for _, rel := range rootRels.Relationships {
+			if strings.HasSuffix(rel.Type, "/officeDocument") {
+				workbook = resolvePart("", rel.Target)
+			}
+		}
+	}
+
+	var wb xmlWorkbook
+	if err := f.decode(workbook, &wb); err != nil {
+		return nil, fmt.Errorf("not an xlsx workbook: %w", err)
+	}
+	f.Date1904 = wb.Pr.Date1904 == "1" || wb.Pr.Date1904 == "true"
+
+	var rels xmlRelationships
+	if err := f.decode(relsPart(workbook), &rels); err != nil {
+		return nil, fmt.Errorf("failed to read workbook relationships: %w", err)
+	}
+	targets := make(map[string]string)
+	sharedPart := ""
+	for _, rel := range rels.Relationships {
+		target := resolvePart(path.Dir(workbook), rel.Target)
+		targets[rel.ID] = target
+		if strings.HasSuffix(rel.Type, "/sharedStrings") {
+			sharedPart = target
+		}
+	}
+
+	for _, sheet := range wb.Sheets {
+		for _, a := range sheet.Attrs {
+			// r:id, in whichever namespace the workbook's flavour of OOXML uses
+			if a.Name.Local == "id" && a.Name.Space != "" {
+				if target, ok := targets[a.Value]; ok {
+					f.Sheets = append(f.Sheets, sheet.Name)
+					f.parts[sheet.Name] = target
+				}
+			}
+		}
+	}
+	if len(f.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+
+	if sharedPart != "" {
+		var sst struct {
+			Items []xmlRichText `xml:"si"`
+		}
+		if err := f.decode(sharedPart, &sst); err != nil {
+			return nil, fmt.Errorf("failed to read shared strings: %w", err)
+		}
+		f.shared = make([]string, len(sst.Items))
+		for i, si := range sst.Items {
+			f.shared[i] = si.text()
+		}
+	}
+	return f, nil
+}
+
+// Rows returns the non-empty rows of the named sheet, or of the first sheet
+// when name is empty. The sheet name is matched case-insensitively.
+func (f *File) Rows(name string) ([]Row, error) {
+	if name == "" {
+		name = f.Sheets[0]
+	}
+	part := ""
+	for _, s := range f.Sheets {
+		if strings.EqualFold(s, name) {
+			part = f.parts[s]
+			break
+		}
+	}
+	if part == "" {
+		return nil, fmt.Errorf("no sheet named %q (sheets: %s)", name, strings.Join(f.Sheets, ", "))
+	}
+
+	var ws xmlWorksheet
+	if err := f.decode(part, &ws); err != nil {
+		return nil, fmt.Errorf("failed to read sheet %q: %w", name, err)
+	}
+
+	var rows []Row
+	num := 0
+	for _, xr := range ws.Rows {
+		if n, err := strconv.Atoi(xr.R); err == nil && n > num {
+			num = n
+		} else {
+			num++
+		}
+		row := Row{Num: num}
+		col := -1
+		for _, xc := range xr.Cells {
+			if i := columnIndex(xc.R); i > col {
+				col = i
+			} else {
+				col++
+			}
+			if col >= maxColumns {
+				break
+			}
+			c := f.cell(xc.T, xc.V, xc.IS)
+			if c.Type == Blank {
+				continue
+			}
+			for len(row.Cells) < col {
+				row.Cells = append(row.Cells, Cell{})
+			}
+			row.Cells = append(row.Cells, c)
+		}
+		if len(row.Cells) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// CellTime returns the wall clock, in UTC, of a date cell or of a number
+// cell read as a serial in the workbook's date system.
+func (f *File) CellTime(c Cell) (time.Time, bool) {
+	switch c.Type {
+	case Number:
+		return SerialTime(c.Num, f.Date1904), true
+	case Date, DateTime:
+		// written by this package or converted from ISO 8601 as 1900 serials
+		return SerialTime(c.Num, false), true
+	}
+	return time.Time{}, false
+}
+
+func (f *File) cell(t, v string, is xmlRichText) Cell {
+	switch t {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || i < 0 || i >= len(f.shared) {
+			return Cell{}
+		}
+		return StringCell(f.shared[i])
+	case "inlineStr":
+		return StringCell(is.text())
+	case "str":
+		// a formula's string result
+		return StringCell(v)
+	case "b":
+		return BoolCell(strings.TrimSpace(v) == "1")
+	case "e":
+		return Cell{Type: Error, Text: v}
+	case "d":
+		// ISO 8601, written by some producers in place of a serial
+		v = strings.TrimSpace(v)
+		if ts, err := time.Parse("2006-01-02T15:04:05", strings.TrimSuffix(v, "Z")); err == nil {
+			return DateTimeCell(ts)
+		}
+		if ts, err := time.Parse("2006-01-02", v); err == nil {
+			return DateCell(ts)
+		}
+		return StringCell(v)
+	default:
+		if v = strings.TrimSpace(v); v == "" {
+			return Cell{}
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return StringCell(v)
+		}
+		return NumberCell(n)
+	}
+}
+
+func (f *File) decode(name string, v interface{}) error {
+	zf, err := f.zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zf.Close() }()
+	return xml.NewDecoder(zf).Decode(v)
+}
+
+// resolvePart resolves a relationship target against the directory of the
+// part it belongs to; absolute targets are relative to the package root.
+func resolvePart(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(dir, target)
+}
+
+// relsPart returns the relationships part of the part name.
+func relsPart(name string) string {
+	return path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")
+}
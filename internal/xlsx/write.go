@@ -0,0 +1,256 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sheet is a sheet to write: a bold header row, frozen so it stays in view
+// while scrolling, above the rows.
+type Sheet struct {
+	Name   string
+	Header []string
+	Rows   [][]Cell
+}
+
+// maxCellText is the most characters a cell holds; Excel refuses workbooks
+// with longer text.
+const maxCellText = 32767
+
+// cell style indexes into the cellXfs of stylesXML
+const (
+	styleHeader   = 1
+	styleDate     = 2
+	styleDateTime = 3
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const stylesXML = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// Write writes a workbook of the sheets, in order, in the 1900 date system.
+func Write(w io.Writer, sheets []Sheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("a workbook needs at least one sheet")
+	}
+	seen := make(map[string]bool)
+	for _, s := range sheets {
+		if err := checkSheetName(s.Name); err != nil {
+			return err
+		}
+		if seen[strings.ToLower(s.Name)] {
+			return fmt.Errorf("duplicate sheet name %q", s.Name)
+		}
+		seen[strings.ToLower(s.Name)] = true
+	}
+
+	zw := zip.NewWriter(w)
+	part := func(name string, write func(*bufio.Writer)) error {
+		pw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(pw)
+		bw.WriteString(xmlHeader)
+		write(bw)
+		return bw.Flush()
+	}
+
+	err := part("[Content_Types].xml", func(bw *bufio.Writer) {
+		bw.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+		bw.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+		bw.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+		bw.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+		bw.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+		for i := range sheets {
+			fmt.Fprintf(bw, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		}
+		bw.WriteString(`</Types>`)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = part("_rels/.rels", func(bw *bufio.Writer) {
+		bw.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+		bw.WriteString(`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>`)
+		bw.WriteString(`</Relationships>`)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = part("xl/workbook.xml", func(bw *bufio.Writer) {
+		bw.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+		for i, s := range sheets {
+			fmt.Fprintf(bw, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.Name), i+1, i+1)
+		}
+		bw.WriteString(`</sheets></workbook>`)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = part("xl/_rels/workbook.xml.rels", func(bw *bufio.Writer) {
+		bw.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+		for i := range sheets {
+			fmt.Fprintf(bw, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		}
+		fmt.Fprintf(bw, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+		bw.WriteString(`</Relationships>`)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = part("xl/styles.xml", func(bw *bufio.Writer) {
+		bw.WriteString(stylesXML)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, s := range sheets {
+		selected := i == 0
+		if err := part(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), func(bw *bufio.Writer) { writeSheet(bw, &s, selected) }); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeSheet(bw *bufio.Writer, s *Sheet, selected bool) {
+	bw.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"`)
+	if selected {
+		bw.WriteString(` tabSelected="1"`)
+	}
+	bw.WriteString(`><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/><selection pane="bottomLeft" activeCell="A2" sqref="A2"/></sheetView></sheetViews>`)
+	bw.WriteString(`<sheetFormatPr defaultRowHeight="15"/>`)
+
+	if widths := columnWidths(s); len(widths) > 0 {
+		bw.WriteString(`<cols>`)
+		for i, w := range widths {
+			fmt.Fprintf(bw, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, w)
+		}
+		bw.WriteString(`</cols>`)
+	}
+
+	bw.WriteString(`<sheetData>`)
+	header := make([]Cell, len(s.Header))
+	for i, h := range s.Header {
+		header[i] = StringCell(h)
+	}
+	writeRow(bw, 1, header, true)
+	for i, row := range s.Rows {
+		writeRow(bw, i+2, row, false)
+	}
+	bw.WriteString(`</sheetData></worksheet>`)
+}
+
+func writeRow(bw *bufio.Writer, num int, cells []Cell, header bool) {
+	fmt.Fprintf(bw, `<row r="%d">`, num)
+	for i, c := range cells {
+		ref := ColumnName(i) + strconv.Itoa(num)
+		switch c.Type {
+		case String, Error:
+			style := ""
+			if header {
+				style = fmt.Sprintf(` s="%d"`, styleHeader)
+			}
+			text := truncate(c.Text)
+			space := ""
+			if strings.TrimSpace(text) != text {
+				space = ` xml:space="preserve"`
+			}
+			fmt.Fprintf(bw, `<c r="%s"%s t="inlineStr"><is><t%s>%s</t></is></c>`, ref, style, space, escape(text))
+		case Number:
+			fmt.Fprintf(bw, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(c.Num, 'f', -1, 64))
+		case Bool:
+			fmt.Fprintf(bw, `<c r="%s" t="b"><v>%d</v></c>`, ref, int(c.Num))
+		case Date:
+			fmt.Fprintf(bw, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDate, strconv.FormatFloat(c.Num, 'f', -1, 64))
+		case DateTime:
+			fmt.Fprintf(bw, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDateTime, strconv.FormatFloat(c.Num, 'f', -1, 64))
+		}
+	}
+	bw.WriteString(`</row>`)
+}
+
+// columnWidths sizes each column to its longest value, within limits, and
+// wide enough for its dates, which Excel shows as #### when they do not fit.
+func columnWidths(s *Sheet) []int {
+	widths := make([]int, len(s.Header))
+	for i, h := range s.Header {
+		widths[i] = utf8.RuneCountInString(h) + 2
+	}
+	for _, row := range s.Rows {
+		for i, c := range row {
+			for len(widths) <= i {
+				widths = append(widths, 0)
+			}
+			w := 0
+			switch c.Type {
+			case String, Number:
+				w = utf8.RuneCountInString(strings.SplitN(c.String(), "\n", 2)[0]) + 2
+			case Date:
+				w = 12
+			case DateTime:
+				w = 17
+			}
+			if w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	for i, w := range widths {
+		widths[i] = min(max(w, 10), 60)
+	}
+	return widths
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxCellText {
+		return s
+	}
+	return string([]rune(s)[:maxCellText])
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// checkSheetName applies Excel's rules for sheet names.
+func checkSheetName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("sheet name is empty")
+	case utf8.RuneCountInString(name) > 31:
+		return fmt.Errorf("sheet name %q is longer than 31 characters", name)
+	case strings.ContainsAny(name, `[]:*?/\`):
+		return fmt.Errorf("sheet name %q contains one of []:*?/\\", name)
+	case strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'"):
+		return fmt.Errorf("sheet name %q starts or ends with an apostrophe", name)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Package xlsx reads and writes the cell values of Excel workbooks (Office
+// Open XML spreadsheets) with the standard library alone. It covers what a
+// table of items needs: strings, numbers, booleans and dates, a header row
+// and one or more sheets. Formulas are read as their cached values; styles,
+// merged cells and charts are not supported.
+package xlsx
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CellType is the kind of value a cell holds.
+type CellType int
+
+const (
+	Blank CellType = iota
+	String
+	Number
+	Bool
+	// Date and DateTime hold serials; Date is written with a date format and
+	// DateTime with a date and time format.
+	Date
+	DateTime
+	Error
+)
+
+// Cell is one cell value. Text holds String and Error values; Num holds
+// Number values, Bool values as 0 or 1 and Date and DateTime values as
+// serials.
+type Cell struct {
+	Type CellType
+	Text string
+	Num  float64
+}
+
+func StringCell(s string) Cell {
+	if s == "" {
+		return Cell{}
+	}
+	return Cell{Type: String, Text: s}
+}
+
+func NumberCell(n float64) Cell {
+	return Cell{Type: Number, Num: n}
+}
+
+func BoolCell(b bool) Cell {
+	c := Cell{Type: Bool}
+	if b {
+		c.Num = 1
+	}
+	return c
+}
+
+// DateCell is the date of t, without its time of day.
+func DateCell(t time.Time) Cell {
+	return Cell{Type: Date, Num: math.Floor(Serial(t))}
+}
+
+func DateTimeCell(t time.Time) Cell {
+	return Cell{Type: DateTime, Num: Serial(t)}
+}
+
+// IsBlank reports whether the cell is empty or holds only whitespace.
+func (c Cell) IsBlank() bool {
+	return c.Type == Blank || (c.Type == String && strings.TrimSpace(c.Text) == "")
+}
+
+// String returns the value as text: numbers in their shortest form, booleans
+// as TRUE or FALSE and dates as ISO 8601.
+func (c Cell) String() string {
+	switch c.Type {
+	case String, Error:
+		return c.Text
+	case Number:
+		return strconv.FormatFloat(c.Num, 'f', -1, 64)
+	case Bool:
+		if c.Num != 0 {
+			return "TRUE"
+		}
+		return "FALSE"
+	case Date:
+		return SerialTime(c.Num, false).Format("2006-01-02")
+	case DateTime:
+		return SerialTime(c.Num, false).Format("2006-01-02T15:04:05")
+	default:
+		return ""
+	}
+}
+
+// Serials count days, with the time of day as the fraction, from the epoch
+// of the workbook's date system. The 1900 system counts from 1900-01-01 as
+// day 1 and, as Lotus 1-2-3 did, takes 1900 for a leap year.
+var (
+	epoch1900 = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	epoch1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Serial returns the 1900-system serial of t's wall clock; serials carry no
+// time zone.
+func Serial(t time.Time) float64 {
+	y, m, d := t.Date()
+	days := math.Round(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(epoch1900).Hours() / 24)
+	if days < 61 {
+		// before the phantom 1900-02-29
+		days--
+	}
+	h, min, s := t.Clock()
+	return days + float64(h*3600+min*60+s)/86400
+}
+
+// SerialTime returns the wall clock of a serial in UTC, to the second.
+func SerialTime(serial float64, date1904 bool) time.Time {
+	days := math.Floor(serial)
+	secs := math.Round((serial - days) * 86400)
+	epoch := epoch1904
+	if !date1904 {
+		epoch = epoch1900
+		if days < 60 {
+			days++
+		}
+	}
+	return epoch.AddDate(0, 0, int(days)).Add(time.Duration(secs) * time.Second)
+}
+
+// ColumnName returns the letters of the zero-based column i, e.g. 27 is AB.
+func ColumnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// maxColumns is the width of a sheet, column A to XFD.
+const maxColumns = 16384
+
+// columnIndex returns the zero-based column of a cell reference such as
+// "AB12", or -1 when ref does not start with column letters.
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if n++; col > maxColumns {
+			return -1
+		}
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
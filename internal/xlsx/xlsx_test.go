@@ -0,0 +1,126 @@
+package xlsx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSerials(t *testing.T) {
+	cases := []struct {
+		t      time.Time
+		serial float64
+	}{
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC), 59},
+		{time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC), 61},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 45292},
+		{time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC), 45292.75},
+	}
+	for _, c := range cases {
+		if got := Serial(c.t); got != c.serial {
+			t.Errorf("Serial(%v) = %v, want %v", c.t, got, c.serial)
+		}
+		if got := SerialTime(c.serial, false); !got.Equal(c.t) {
+			t.Errorf("SerialTime(%v) = %v, want %v", c.serial, got, c.t)
+		}
+	}
+
+	// the wall clock is kept, whatever the zone
+	tokyo := time.FixedZone("JST", 9*3600)
+	if got := Serial(time.Date(2024, 1, 1, 9, 30, 0, 0, tokyo)); got != 45292+9.5/24 {
+		t.Errorf("zoned serial = %v", got)
+	}
+	if got := SerialTime(45292+9.5/24, false); got.Hour() != 9 || got.Minute() != 30 || got.Second() != 0 {
+		t.Errorf("fraction = %v", got)
+	}
+	if got := SerialTime(0, true); !got.Equal(time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("1904 epoch = %v", got)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA", 16383: "XFD"} {
+		if got := ColumnName(i); got != name {
+			t.Errorf("ColumnName(%d) = %q, want %q", i, got, name)
+		}
+		if got := columnIndex(name + "12"); got != i {
+			t.Errorf("columnIndex(%q) = %d, want %d", name+"12", got, i)
+		}
+	}
+	if got := columnIndex("12"); got != -1 {
+		t.Errorf("columnIndex without letters = %d", got)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	when := time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+	sheets := []Sheet{
+		{Name: "First", Header: []string{"Name", "When", "Count", "Done"}, Rows: [][]Cell{
+			{StringCell(" padded <&> "), DateTimeCell(when), NumberCell(2.5), BoolCell(true)},
+			{StringCell("dated"), DateCell(when)},
+			{},
+			{{}, {}, NumberCell(7)},
+		}},
+		{Name: "Second", Header: []string{"Only"}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, sheets); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	f, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	if len(f.Sheets) != 2 || f.Sheets[0] != "First" || f.Sheets[1] != "Second" {
+		t.Fatalf("sheets = %v", f.Sheets)
+	}
+	rows, err := f.Rows("")
+	if err != nil {
+		t.Fatalf("rows error: %v", err)
+	}
+	// the empty row is left out
+	if len(rows) != 4 || rows[3].Num != 5 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[0].Cells[3].String() != "Done" {
+		t.Errorf("header = %+v", rows[0].Cells)
+	}
+	first := rows[1].Cells
+	if first[0].Text != " padded <&> " {
+		t.Errorf("text = %q", first[0].Text)
+	}
+	if got, ok := f.CellTime(first[1]); !ok || !got.Equal(when) {
+		t.Errorf("date time = %v", got)
+	}
+	if first[2].Num != 2.5 || first[3].Type != Bool || first[3].Num != 1 {
+		t.Errorf("number, bool = %+v %+v", first[2], first[3])
+	}
+	if got, _ := f.CellTime(rows[2].Cells[1]); !got.Equal(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %v", got)
+	}
+	if len(rows[3].Cells) != 3 || !rows[3].Cells[0].IsBlank() || rows[3].Cells[2].Num != 7 {
+		t.Errorf("sparse row = %+v", rows[3].Cells)
+	}
+
+	if rows, err := f.Rows("second"); err != nil || len(rows) != 1 {
+		t.Errorf("named sheet = %+v, %v", rows, err)
+	}
+	if _, err := f.Rows("Third"); err == nil {
+		t.Error("expected an error for a missing sheet")
+	}
+}
+
+func TestWriteRejectsBadSheetNames(t *testing.T) {
+	for _, sheets := range [][]Sheet{
+		nil,
+		{{Name: "a/b"}},
+		{{Name: "This sheet name is far too long to fit"}},
+		{{Name: "Tasks"}, {Name: "tasks"}},
+	} {
+		if err := Write(&bytes.Buffer{}, sheets); err == nil {
+			t.Errorf("expected an error for %+v", sheets)
+		}
+	}
+}